@@ -64,7 +64,7 @@ func ArtworkRecentWorks(illust core.Illust) templ.Component {
 			}
 			// If there are more works to show, define an "end card" for the carousel
 			var endCard templ.Component
-			if len(illust.RecentWorks) >= core.ArtworkRecentLimit {
+			if len(illust.RecentWorks) >= core.RecentWorksLimit() {
 				endCard = artworkRecentWorksEndCard(illust.RecentWorks[0])
 			}
 			templ_7745c5c3_Err = fragments.VerticalImageCarousel(fragments.VerticalImageCarouselProps{
@@ -23,7 +23,7 @@ import (
 
 // key models a gettext entry identified by context, singular msgid,
 // and optional plural msgid_plural. For non-plural entries, plural is empty.
-// NOTE: Comments, flags, or translator notes are currently not modeled.
+// Translator comments are collected separately, keyed by this identity.
 type key struct {
 	ctx    string
 	id     string
@@ -35,13 +35,22 @@ type ref struct {
 	line int
 }
 
+// translatorCommentPrefix marks a comment immediately preceding a Tr* call
+// that should be emitted as a #. extracted-comment line for translators.
+const translatorCommentPrefix = "TRANSLATORS:"
+
 // extractor holds the shared state and context for AST analysis within a package.
 type extractor struct {
 	refs        map[key][]ref
+	comments    map[key]map[string]struct{}
 	projectRoot string
 	fset        *token.FileSet
 	info        *types.Info
 	i18nPkgs    map[string]struct{}
+
+	// translatorComments maps, for the file currently being inspected, the end
+	// line of each TRANSLATORS comment group to its text.
+	translatorComments map[int]string
 }
 
 func main() {
@@ -64,9 +73,90 @@ func main() {
 		log.Fatal("failed to load packages due to errors")
 	}
 
-	refs := extractRefs(pkgs, findProjectRoot(wd), findI18nPkgPaths(pkgs))
+	refs, comments := extractRefs(pkgs, findProjectRoot(wd), findI18nPkgPaths(pkgs))
 
 	// Emit POT
+	var b strings.Builder
+	writeHeader(&b)
+	writeEntries(&b, refs, comments)
+
+	// Ensure output directory exists
+	if err := os.MkdirAll(filepath.Dir(*outPath), 0o755); err != nil {
+		log.Fatalf("failed to create output directory: %v", err)
+	}
+
+	if err := os.WriteFile(*outPath, []byte(b.String()), 0o644); err != nil {
+		log.Fatalf("failed to write output file %s: %v", *outPath, err)
+	}
+}
+
+// extractRefs traverses all Go source files in the given packages,
+// looking for i18n function calls and message keys to extract.
+// It returns the references and the translator comments attached to them.
+func extractRefs(
+	pkgs []*packages.Package,
+	projectRoot string,
+	i18nPkgPaths map[string]struct{},
+) (map[key][]ref, map[key]map[string]struct{}) {
+	refs := map[key][]ref{}
+	comments := map[key]map[string]struct{}{}
+
+	for _, p := range pkgs {
+		if p.TypesInfo == nil {
+			continue
+		}
+
+		// Create an extractor with the context for this package's files.
+		e := &extractor{
+			refs:        refs,
+			comments:    comments,
+			projectRoot: projectRoot,
+			fset:        p.Fset,
+			info:        p.TypesInfo,
+			i18nPkgs:    i18nPkgPaths,
+		}
+
+		for _, f := range p.Syntax {
+			e.translatorComments = collectTranslatorComments(p.Fset, f)
+
+			ast.Inspect(f, func(n ast.Node) bool {
+				switch x := n.(type) {
+				case *ast.CallExpr:
+					e.handleCallExpr(x)
+				case *ast.CompositeLit:
+					e.handleCompositeLit(x)
+				}
+
+				return true
+			})
+		}
+	}
+
+	return refs, comments
+}
+
+// collectTranslatorComments maps the end line of each TRANSLATORS comment
+// group in f to its text, so a comment immediately preceding a Tr* call can
+// be attached to the extracted entry.
+func collectTranslatorComments(fset *token.FileSet, f *ast.File) map[int]string {
+	out := make(map[int]string)
+
+	for _, cg := range f.Comments {
+		text := strings.TrimSpace(cg.Text())
+		if !strings.HasPrefix(text, translatorCommentPrefix) {
+			continue
+		}
+
+		// Collapse multi-line comment groups onto one extracted-comment line.
+		out[fset.Position(cg.End()).Line] = strings.ReplaceAll(text, "\n", " ")
+	}
+
+	return out
+}
+
+// writeEntries emits the POT entries in a stable order: translator comments
+// first, then source references, then a go-format flag where applicable.
+func writeEntries(b *strings.Builder, refs map[key][]ref, comments map[key]map[string]struct{}) {
 	keys := make([]key, 0, len(refs))
 	for k := range refs {
 		keys = append(keys, k)
@@ -84,10 +174,21 @@ func main() {
 		return keys[i].plural < keys[j].plural
 	})
 
-	var b strings.Builder
-	writeHeader(&b)
-
 	for i, k := range keys {
+		// Translator comments, deduplicated across references and sorted.
+		if set := comments[k]; len(set) > 0 {
+			lines := make([]string, 0, len(set))
+			for c := range set {
+				lines = append(lines, c)
+			}
+
+			sort.Strings(lines)
+
+			for _, c := range lines {
+				fmt.Fprintf(b, "#. %s\n", c)
+			}
+		}
+
 		rs := refs[k]
 		sort.Slice(rs, func(i, j int) bool {
 			if rs[i].file != rs[j].file {
@@ -99,87 +200,58 @@ func main() {
 
 		// After sorting by file and line, duplicates will be adjacent.
 		// Avoid a per-key set while producing identical output.
-		fmt.Fprint(&b, "#:")
+		fmt.Fprint(b, "#:")
 
 		lastFile := ""
 
 		lastLine := 0
 		for _, r := range rs {
 			if r.file != lastFile || r.line != lastLine {
-				fmt.Fprintf(&b, " %s:%d", r.file, r.line)
+				fmt.Fprintf(b, " %s:%d", r.file, r.line)
 
 				lastFile = r.file
 				lastLine = r.line
 			}
 		}
 
-		fmt.Fprintln(&b)
+		fmt.Fprintln(b)
+
+		if isGoFormat(k) {
+			fmt.Fprintln(b, "#, go-format")
+		}
 
 		if k.ctx != "" {
-			fmt.Fprintf(&b, "msgctxt %q\n", k.ctx)
+			fmt.Fprintf(b, "msgctxt %q\n", k.ctx)
 		}
 
 		// Plural or singular entry
 		if k.plural != "" {
-			fmt.Fprintf(&b, "msgid %q\n", k.id)
-			fmt.Fprintf(&b, "msgid_plural %q\n", k.plural)
-			fmt.Fprintf(&b, "msgstr[0] \"\"\n")
-			fmt.Fprintf(&b, "msgstr[1] \"\"\n")
+			fmt.Fprintf(b, "msgid %q\n", k.id)
+			fmt.Fprintf(b, "msgid_plural %q\n", k.plural)
+			fmt.Fprintf(b, "msgstr[0] \"\"\n")
+			fmt.Fprintf(b, "msgstr[1] \"\"\n")
 		} else {
-			fmt.Fprintf(&b, "msgid %q\n", k.id)
-			fmt.Fprintf(&b, "msgstr \"\"\n")
+			fmt.Fprintf(b, "msgid %q\n", k.id)
+			fmt.Fprintf(b, "msgstr \"\"\n")
 		}
 
 		// Add a separating blank line, but not after the very last entry.
 		if i < len(keys)-1 {
-			fmt.Fprintln(&b)
+			fmt.Fprintln(b)
 		}
 	}
-
-	// Ensure output directory exists
-	if err := os.MkdirAll(filepath.Dir(*outPath), 0o755); err != nil {
-		log.Fatalf("failed to create output directory: %v", err)
-	}
-
-	if err := os.WriteFile(*outPath, []byte(b.String()), 0o644); err != nil {
-		log.Fatalf("failed to write output file %s: %v", *outPath, err)
-	}
 }
 
-// extractRefs traverses all Go source files in the given packages,
-// looking for i18n function calls and message keys to extract.
-func extractRefs(pkgs []*packages.Package, projectRoot string, i18nPkgPaths map[string]struct{}) map[key][]ref {
-	refs := map[key][]ref{}
-
-	for _, p := range pkgs {
-		if p.TypesInfo == nil {
-			continue
-		}
-
-		// Create an extractor with the context for this package's files.
-		e := &extractor{
-			refs:        refs,
-			projectRoot: projectRoot,
-			fset:        p.Fset,
-			info:        p.TypesInfo,
-			i18nPkgs:    i18nPkgPaths,
-		}
-
-		for _, f := range p.Syntax {
-			ast.Inspect(f, func(n ast.Node) bool {
-				switch x := n.(type) {
-				case *ast.CallExpr:
-					e.handleCallExpr(x)
-				case *ast.CompositeLit:
-					e.handleCompositeLit(x)
-				}
-
-				return true
-			})
+// isGoFormat reports whether a key's msgids carry text/template placeholders,
+// in which case translations must preserve them.
+func isGoFormat(k key) bool {
+	for _, s := range []string{k.id, k.plural} {
+		if strings.Contains(s, "{{") && strings.Contains(s, "}}") {
+			return true
 		}
 	}
 
-	return refs
+	return false
 }
 
 // findI18nPkgPaths returns the set of package paths in this build that
@@ -398,7 +470,7 @@ func (e *extractor) handleCallExpr(x *ast.CallExpr) {
 					}
 
 					return // Handled as TrC call.
-				case "TrN": // TrN(ctx, "singular", "plural", n, ...)
+				case "TrN", "TrNf": // TrN(ctx, "singular", "plural", n, ...)
 					if len(x.Args) >= 4 {
 						singular, ok1 := constString(e.info, x.Args[1])
 
@@ -409,7 +481,7 @@ func (e *extractor) handleCallExpr(x *ast.CallExpr) {
 					}
 
 					return // Handled as TrN call.
-				case "TrNC": // TrNC(ctx, "ctx", "singular", "plural", n, ...)
+				case "TrNC", "TrNCf": // TrNC(ctx, "ctx", "singular", "plural", n, ...)
 					if len(x.Args) >= 5 {
 						ctx, ok1 := constString(e.info, x.Args[1])
 						singular, ok2 := constString(e.info, x.Args[2])
@@ -421,6 +493,12 @@ func (e *extractor) handleCallExpr(x *ast.CallExpr) {
 					}
 
 					return // Handled as TrNC call.
+				case "TrSelect": // TrSelect(ctx, selector, map[string]string{...}, ...)
+					if len(x.Args) >= 3 {
+						e.addSelectCases(x.Args[2])
+					}
+
+					return // Handled as TrSelect call.
 				}
 			}
 		}
@@ -470,6 +548,27 @@ func (e *extractor) handleCallExpr(x *ast.CallExpr) {
 	}
 }
 
+// addSelectCases emits each case string of a TrSelect cases map as its own
+// msgid. Only map literals can be extracted; cases built dynamically are
+// invisible to static analysis, like any other non-constant msgid.
+func (e *extractor) addSelectCases(casesArg ast.Expr) {
+	lit, ok := casesArg.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+
+		if msg, ok := constString(e.info, kv.Value); ok {
+			e.addRef(kv.Value.Pos(), msg, "", "")
+		}
+	}
+}
+
 // addRef records a reference to a msgid, normalising the file path relative
 // to the computed project root.
 func (e *extractor) addRef(pos token.Pos, msg, ctx, plural string) {
@@ -485,6 +584,17 @@ func (e *extractor) addRef(pos token.Pos, msg, ctx, plural string) {
 	k := key{ctx: ctx, id: msg, plural: plural}
 
 	e.refs[k] = append(e.refs[k], ref{file: file, line: p.Line})
+
+	// A TRANSLATORS comment on the line directly above belongs to this entry.
+	if comment, ok := e.translatorComments[p.Line-1]; ok {
+		set := e.comments[k]
+		if set == nil {
+			set = make(map[string]struct{})
+			e.comments[k] = set
+		}
+
+		set[comment] = struct{}{}
+	}
 }
 
 // writeHeader emits a POT header.
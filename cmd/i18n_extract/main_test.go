@@ -0,0 +1,195 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// i18nStubSrc is a minimal stand-in for the real i18n package; the extractor
+// matches calls by function name and package path, not by signature.
+const i18nStubSrc = `package i18n
+
+type MsgKey string
+
+func Tr(ctx any, msgid string, kv ...any) string { return "" }
+
+func TrSelect(ctx any, selector string, cases map[string]string, kv ...any) string { return "" }
+`
+
+const consumerSrc = `package consumer
+
+import "test/i18n"
+
+func followLabel(state string) string {
+	return i18n.TrSelect(nil, state, map[string]string{
+		"following":   "Following",
+		"follows_you": "Follows you",
+		"mutual":      "Mutual follows",
+		"other":       "Follow",
+	})
+}
+`
+
+// stubImporter resolves imports from a fixed set of pre-checked packages.
+type stubImporter map[string]*types.Package
+
+func (si stubImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := si[path]; ok {
+		return pkg, nil
+	}
+
+	return nil, fmt.Errorf("unknown import %q", path)
+}
+
+// checkSource parses and type-checks src, recording type information in info.
+func checkSource(t *testing.T, fset *token.FileSet, path, src string, imp types.Importer, info *types.Info) (*types.Package, *ast.File) {
+	t.Helper()
+
+	file, err := parser.ParseFile(fset, path+".go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile(%q) error: %v", path, err)
+	}
+
+	conf := types.Config{Importer: imp}
+
+	pkg, err := conf.Check(path, fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatalf("Check(%q) error: %v", path, err)
+	}
+
+	return pkg, file
+}
+
+func TestHandleCallExpr_TrSelect(t *testing.T) {
+	t.Parallel()
+
+	fset := token.NewFileSet()
+
+	i18nPkg, _ := checkSource(t, fset, "test/i18n", i18nStubSrc, nil, nil)
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+
+	_, consumerFile := checkSource(
+		t, fset, "test/consumer", consumerSrc, stubImporter{"test/i18n": i18nPkg}, info)
+
+	e := &extractor{
+		refs:     map[key][]ref{},
+		fset:     fset,
+		info:     info,
+		i18nPkgs: map[string]struct{}{"test/i18n": {}},
+	}
+
+	ast.Inspect(consumerFile, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			e.handleCallExpr(call)
+		}
+
+		return true
+	})
+
+	// Each case string must be extracted as its own msgid.
+	for _, want := range []string{"Following", "Follows you", "Mutual follows", "Follow"} {
+		if _, ok := e.refs[key{id: want}]; !ok {
+			t.Errorf("Expected msgid %q to be extracted, got %v", want, e.refs)
+		}
+	}
+
+	// The selector keys are not UI strings and must not be extracted.
+	for _, notWant := range []string{"following", "follows_you", "mutual", "other"} {
+		if _, ok := e.refs[key{id: notWant}]; ok {
+			t.Errorf("Selector key %q must not be extracted as a msgid", notWant)
+		}
+	}
+}
+
+// annotatedSrc exercises translator comments and the go-format flag.
+const annotatedSrc = `package annotated
+
+import "test/i18n"
+
+func labels() {
+	// TRANSLATORS: this is a button label, keep it short.
+	i18n.Tr(nil, "Follow")
+
+	i18n.Tr(nil, "Bookmarked by {{.Count}} users")
+
+	// An ordinary comment is not extracted.
+	i18n.Tr(nil, "Settings")
+}
+
+func moreLabels() {
+	// TRANSLATORS: this is a button label, keep it short.
+	i18n.Tr(nil, "Follow")
+}
+`
+
+func TestExtract_TranslatorCommentsAndFlags(t *testing.T) {
+	t.Parallel()
+
+	fset := token.NewFileSet()
+
+	i18nPkg, _ := checkSource(t, fset, "test/i18n", i18nStubSrc, nil, nil)
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+
+	_, annotatedFile := checkSource(
+		t, fset, "test/annotated", annotatedSrc, stubImporter{"test/i18n": i18nPkg}, info)
+
+	e := &extractor{
+		refs:     map[key][]ref{},
+		comments: map[key]map[string]struct{}{},
+		fset:     fset,
+		info:     info,
+		i18nPkgs: map[string]struct{}{"test/i18n": {}},
+	}
+
+	e.translatorComments = collectTranslatorComments(fset, annotatedFile)
+
+	ast.Inspect(annotatedFile, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			e.handleCallExpr(call)
+		}
+
+		return true
+	})
+
+	var b strings.Builder
+
+	writeEntries(&b, e.refs, e.comments)
+
+	pot := b.String()
+
+	wantComment := "#. TRANSLATORS: this is a button label, keep it short."
+	if got := strings.Count(pot, wantComment); got != 1 {
+		t.Errorf("Expected exactly 1 occurrence of %q (comments must dedup), got %d in:\n%s",
+			wantComment, got, pot)
+	}
+
+	wantFlagged := "#, go-format\nmsgid \"Bookmarked by {{.Count}} users\""
+	if !strings.Contains(pot, wantFlagged) {
+		t.Errorf("Expected go-format flag before the templated msgid in:\n%s", pot)
+	}
+
+	if strings.Contains(pot, "An ordinary comment") {
+		t.Errorf("Ordinary comments must not be extracted:\n%s", pot)
+	}
+
+	// Entries without placeholders must not be flagged.
+	if strings.Contains(pot, "#, go-format\nmsgid \"Follow\"") {
+		t.Errorf("Unexpected go-format flag on a plain msgid:\n%s", pot)
+	}
+}
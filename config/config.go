@@ -0,0 +1,466 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package config
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/rs/zerolog/log"
+
+	_ "codeberg.org/pixivfe/pixivfe/v3/core/audit" // setup better logging format
+	"codeberg.org/pixivfe/pixivfe/v3/core/idgen"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+)
+
+// Global exposes the server configuration.
+var Global ServerConfig
+
+// Possible values for limiterDetectionMethod.
+const (
+	None      LimiterDetectionMethod = ""
+	LinkToken LimiterDetectionMethod = "linktoken"
+	Turnstile LimiterDetectionMethod = "turnstile"
+)
+
+// LimiterDetectionMethod is a method used by package limiter to detect unwanted automated requests.
+type LimiterDetectionMethod string
+
+// Possible values for Artwork.RecentWorksOrder.
+const (
+	RecentWorksOrderID   = "id"
+	RecentWorksOrderDate = "date"
+)
+
+// ServerConfig holds the application configuration.
+type ServerConfig struct {
+	Build buildInfo `yaml:"-"`
+
+	Basic struct {
+		Host                     string      `env:"PIXIVFE_HOST,overwrite" yaml:"host"`
+		Port                     string      `env:"PIXIVFE_PORT,overwrite" yaml:"port"`
+		UnixSocket               string      `env:"PIXIVFE_UNIXSOCKET" yaml:"unixSocket"`
+		RawUnixSocketPermissions string      `env:"PIXIVFE_UNIXSOCKET_PERMISSIONS" yaml:"unixSocketPermissions"`
+		UnixSocketPermissions    os.FileMode `yaml:"-"`
+		UnixSocketUser           string      `env:"PIXIVFE_UNIXSOCKET_USER" yaml:"unixSocketUser"`
+		UnixSocketGroup          string      `env:"PIXIVFE_UNIXSOCKET_GROUP" yaml:"unixSocketGroup"`
+		Token                    []string    `env:"PIXIVFE_TOKEN" yaml:"token"`
+		// raw bytes of v4.public secret key
+		PasetoSecret string `env:"PIXIVFE_SECRET" yaml:"secret"`
+		// ShutdownTimeout bounds how long a shutdown waits for in-flight
+		// requests to drain before forcing exit.
+		ShutdownTimeout time.Duration `env:"PIXIVFE_SHUTDOWN_TIMEOUT,overwrite" yaml:"shutdownTimeout"`
+	} `yaml:"basic"`
+
+	ContentProxies struct {
+		RawImage  string  `env:"PIXIVFE_IMAGEPROXY,overwrite" yaml:"imageProxy"`
+		Image     url.URL `yaml:"-"` // For i.pximg.net
+		RawStatic string  `env:"PIXIVFE_STATICPROXY,overwrite" yaml:"staticProxy"`
+		Static    url.URL `yaml:"-"` // For s.pximg.net
+		RawUgoira string  `env:"PIXIVFE_UGOIRAPROXY,overwrite" yaml:"ugoiraProxy"`
+		Ugoira    url.URL `yaml:"-"` // For ugoira.com
+
+		// FallbackAvatar and FallbackThumbnail replace missing or deleted
+		// avatars and work thumbnails. Absolute pixiv URLs are routed through
+		// the proxy; relative paths are served as local assets.
+		FallbackAvatar    string `env:"PIXIVFE_FALLBACK_AVATAR,overwrite" yaml:"fallbackAvatar"`
+		FallbackThumbnail string `env:"PIXIVFE_FALLBACK_THUMBNAIL,overwrite" yaml:"fallbackThumbnail"`
+
+		// AllowedContentTypes lists the media types the built-in proxies may
+		// relay. Entries are either exact media types ("text/css") or
+		// wildcard subtypes ("image/*"); anything else is refused with a 415.
+		AllowedContentTypes []string `env:"PIXIVFE_PROXY_ALLOWED_CONTENT_TYPES,overwrite" yaml:"allowedContentTypes"`
+
+		// RequireSameOriginReferer rejects cross-origin proxy requests with a
+		// 403, stopping third-party sites from hotlinking proxied images.
+		RequireSameOriginReferer bool `env:"PIXIVFE_PROXY_REQUIRE_SAME_ORIGIN_REFERER,overwrite" yaml:"requireSameOriginReferer"`
+
+		// AllowedReferers lists additional hosts permitted to embed proxied
+		// content when RequireSameOriginReferer is enabled.
+		AllowedReferers []string `env:"PIXIVFE_PROXY_ALLOWED_REFERERS,overwrite" yaml:"allowedReferers"`
+	} `yaml:"contentProxies"`
+
+	TokenManager struct {
+		LoadBalancing string `env:"PIXIVFE_TOKEN_LOAD_BALANCING,overwrite" yaml:"tokenLoadBalancing"`
+		// TokenWeights lists per-token selection weights for the "weighted"
+		// load balancing method, in the same order as PIXIVFE_TOKEN.
+		TokenWeights   []int         `env:"PIXIVFE_TOKEN_WEIGHTS,overwrite" yaml:"tokenWeights"`
+		MaxRetries     int           `env:"PIXIVFE_TOKEN_MAX_RETRIES,overwrite" yaml:"tokenMaxRetries"`
+		BaseTimeout    time.Duration `env:"PIXIVFE_TOKEN_BASE_TIMEOUT,overwrite" yaml:"tokenBaseTimeout"`
+		MaxBackoffTime time.Duration `env:"PIXIVFE_TOKEN_MAX_BACKOFF_TIME,overwrite" yaml:"tokenMaxBackoffTime"`
+		// ScrapeURLs overrides the pages scraped for the CSRF token and ab
+		// cookies during login; when empty, a known artwork page is tried
+		// first with the landing page as fallback.
+		ScrapeURLs []string `env:"PIXIVFE_TOKEN_SCRAPE_URLS,overwrite" yaml:"tokenScrapeUrls"`
+	} `yaml:"tokenManager"`
+
+	Cache struct {
+		Enabled bool          `env:"PIXIVFE_CACHE,overwrite" yaml:"enabled"`
+		Size    int           `env:"PIXIVFE_CACHE_SIZE,overwrite" yaml:"cacheSize"`
+		TTL     time.Duration `env:"PIXIVFE_CACHE_TTL,overwrite" yaml:"cacheTTL"`
+
+		// Dedicated TTL for user basic information responses, which are
+		// fetched on nearly every page but change rarely.
+		UserInfoTTL time.Duration `env:"PIXIVFE_CACHE_USER_INFO_TTL,overwrite" yaml:"userInfoTTL"`
+
+		// StaleWhileRevalidate is the grace window after expiry during which
+		// a cached response is still served while a single background refresh
+		// runs. Zero disables serving stale responses.
+		StaleWhileRevalidate time.Duration `env:"PIXIVFE_CACHE_STALE_WHILE_REVALIDATE,overwrite" yaml:"staleWhileRevalidate"`
+
+		// DiskPath is a directory for a persistent second cache tier that
+		// survives restarts. Empty disables the disk tier.
+		DiskPath string `env:"PIXIVFE_CACHE_DISK_PATH,overwrite" yaml:"diskPath"`
+	} `yaml:"cache"`
+
+	HTTPCache struct {
+		MaxAge               time.Duration `env:"PIXIVFE_CACHE_CONTROL_MAX_AGE,overwrite" yaml:"cacheControlMaxAge"`
+		StaleWhileRevalidate time.Duration `env:"PIXIVFE_CACHE_CONTROL_STALE_WHILE_REVALIDATE,overwrite" yaml:"cacheControlStaleWhileRevalidate"`
+	} `yaml:"httpCache"`
+
+	Request struct {
+		AcceptLanguage string `env:"PIXIVFE_ACCEPTLANGUAGE,overwrite" yaml:"acceptLanguage"`
+		// PreserveForwardedHeaders lists forwarding headers (X-Forwarded-*,
+		// Forwarded, Via) that are copied from the client request onto
+		// outbound pixiv requests instead of being stripped.
+		PreserveForwardedHeaders []string `env:"PIXIVFE_PRESERVE_FORWARDED_HEADERS,overwrite" yaml:"preserveForwardedHeaders"`
+		// MaxRetries is how many times a GET request is retried after a
+		// connection error or transient 5xx response; 0 disables retries.
+		MaxRetries int `env:"PIXIVFE_REQUEST_MAX_RETRIES,overwrite" yaml:"maxRetries"`
+		// Timeout bounds each upstream pixiv call so a single slow endpoint
+		// fails fast instead of holding a goroutine; 0 disables the per-call
+		// deadline.
+		Timeout time.Duration `env:"PIXIVFE_REQUEST_TIMEOUT,overwrite" yaml:"timeout"`
+		// GroupByHost reorders a page's fan-out so requests to the same host
+		// are issued back-to-back, with pixiv's API host first. Grouping
+		// favors keep-alive reuse over strict submission order; it never
+		// changes which requests are made.
+		GroupByHost bool `env:"PIXIVFE_REQUEST_GROUP_BY_HOST,overwrite" yaml:"groupByHost"`
+	} `yaml:"request"`
+
+	Response struct {
+		EarlyHintsResponses bool `env:"PIXIVFE_EARLY_HINTS_RESPONSES,overwrite" yaml:"earlyHintsResponses"`
+	} `yaml:"response"`
+
+	Feature struct {
+		PopularSearch      bool `env:"PIXIVFE_POPULAR_SEARCH,overwrite" yaml:"popularSearch"`
+		FastTagSuggestions bool `env:"PIXIVFE_FAST_TAG_SUGGESTIONS,overwrite" yaml:"fastTagSuggestions"`
+		OpenAllButton      bool `env:"PIXIVFE_OPEN_ALL_BUTTON,overwrite" yaml:"openAllButton"`
+		// ProbeThumbnailDimensions probes images whose API data lacks
+		// width/height so justified layouts avoid reflow. Opt-in because it
+		// issues extra (tiny, cached) image requests.
+		ProbeThumbnailDimensions bool `env:"PIXIVFE_PROBE_THUMBNAIL_DIMENSIONS,overwrite" yaml:"probeThumbnailDimensions"`
+		// PrefetchSearchAuthors warms the cache with basic information for
+		// each unique author in a search result set, making author popovers
+		// instant. Opt-in because it issues extra upstream calls per search.
+		PrefetchSearchAuthors bool `env:"PIXIVFE_PREFETCH_SEARCH_AUTHORS,overwrite" yaml:"prefetchSearchAuthors"`
+		// MergePixivMutes folds a logged-in user's native pixiv mute list
+		// (muted tags and users) into the effective filter profile,
+		// refreshed periodically. Opt-in because it issues extra pixiv
+		// requests on behalf of the user's token.
+		MergePixivMutes bool `env:"PIXIVFE_MERGE_PIXIV_MUTES,overwrite" yaml:"mergePixivMutes"`
+	} `yaml:"feature"`
+
+	Image struct {
+		// WebpSize is the bounding box, in pixels, that master WebP
+		// conversions are limited to. Zero uses pixiv's standard 1200px.
+		WebpSize int `env:"PIXIVFE_IMAGE_WEBP_SIZE,overwrite" yaml:"webpSize"`
+		// WebpQuality is the WebP conversion quality from 1 to 100.
+		// Zero uses the standard quality of 80.
+		WebpQuality int `env:"PIXIVFE_IMAGE_WEBP_QUALITY,overwrite" yaml:"webpQuality"`
+		// WebpCategories lists the image path categories eligible for
+		// master WebP conversion. Defaults to every convertible category;
+		// operators can drop entries (e.g. "img-original") to proxy those
+		// paths unchanged.
+		WebpCategories []string `env:"PIXIVFE_IMAGE_WEBP_CATEGORIES,overwrite" yaml:"webpCategories"`
+		// CompactThumbnails limits list-view thumbnail generation to the
+		// variants list templates actually render, cutting per-page URL
+		// computation and allocation on large grids. Detail pages always
+		// receive the full set.
+		CompactThumbnails bool `env:"PIXIVFE_IMAGE_COMPACT_THUMBNAILS,overwrite" yaml:"compactThumbnails"`
+	} `yaml:"image"`
+
+	Artwork struct {
+		// RecentWorksLimit caps the recent-works list on artwork pages.
+		RecentWorksLimit int `env:"PIXIVFE_ARTWORK_RECENT_WORKS_LIMIT,overwrite" yaml:"recentWorksLimit"`
+		// RecentWorksOrder is "id" (default) or "date"; "date" orders the
+		// list by CreateDate descending instead of ID descending.
+		RecentWorksOrder string `env:"PIXIVFE_ARTWORK_RECENT_WORKS_ORDER,overwrite" yaml:"recentWorksOrder"`
+	} `yaml:"artwork"`
+
+	Novel struct {
+		// SeriesFetchConcurrency bounds how many novel-series title fetches
+		// run simultaneously across all requests.
+		SeriesFetchConcurrency int `env:"PIXIVFE_NOVEL_SERIES_FETCH_CONCURRENCY,overwrite" yaml:"seriesFetchConcurrency"`
+		// SeriesTitlesTTL is how long fetched series content titles are
+		// cached. Series contents only change when episodes are added, so a
+		// generous TTL is safe.
+		SeriesTitlesTTL time.Duration `env:"PIXIVFE_NOVEL_SERIES_TITLES_TTL,overwrite" yaml:"seriesTitlesTTL"`
+		// ReaderChapterCap limits how many chapters the continuous series
+		// reader stitches into one view, bounding the upstream fetches a
+		// single page can trigger for very long series.
+		ReaderChapterCap int `env:"PIXIVFE_NOVEL_READER_CHAPTER_CAP,overwrite" yaml:"readerChapterCap"`
+	} `yaml:"novel"`
+
+	Pixivision struct {
+		// ImageEnrichmentConcurrency bounds how many collected image URLs
+		// are enriched simultaneously per scraped page.
+		ImageEnrichmentConcurrency int `env:"PIXIVFE_PIXIVISION_IMAGE_ENRICHMENT_CONCURRENCY,overwrite" yaml:"imageEnrichmentConcurrency"`
+	} `yaml:"pixivision"`
+
+	ExternalLinks struct {
+		// AllowedHosts restricts /goto redirect targets to the listed hosts
+		// when non-empty.
+		AllowedHosts []string `env:"PIXIVFE_EXTERNAL_LINK_ALLOWED_HOSTS,overwrite" yaml:"allowedHosts"`
+
+		// DeniedHosts blocks /goto redirect targets on the listed hosts.
+		DeniedHosts []string `env:"PIXIVFE_EXTERNAL_LINK_DENIED_HOSTS,overwrite" yaml:"deniedHosts"`
+
+		// RouteDescriptionLinks rewrites recognized sketch.pixiv.net and
+		// fanbox.cc links in descriptions through the /goto warning page,
+		// labeling them clearly as external. When disabled they pass
+		// through as raw external links.
+		RouteDescriptionLinks bool `env:"PIXIVFE_EXTERNAL_LINK_ROUTE_DESCRIPTION_LINKS,overwrite" yaml:"routeDescriptionLinks"`
+	} `yaml:"externalLinks"`
+
+	Instance struct {
+		StartingTime      string `yaml:"-"`
+		FileServerCacheID string `yaml:"-"`
+		RepoURL           string `env:"PIXIVFE_REPO_URL,overwrite" yaml:"repoUrl"`
+	} `yaml:"instance"`
+
+	Development struct {
+		InDevelopment        bool   `env:"PIXIVFE_DEV" yaml:"inDevelopment"`
+		SaveResponses        bool   `env:"PIXIVFE_SAVE_RESPONSES,overwrite" yaml:"saveResponses"`
+		ResponseSaveLocation string `env:"PIXIVFE_RESPONSE_SAVE_LOCATION,overwrite" yaml:"responseSaveLocation"`
+
+		// Directory of .po files to watch for changes; empty disables watching.
+		// Only honored when InDevelopment is set.
+		LocaleWatchPath string `env:"PIXIVFE_LOCALE_WATCH_PATH,overwrite" yaml:"localeWatchPath"`
+		// Pseudolocalize wraps and lengthens every translated string for
+		// layout testing; see the i18n package for details.
+		Pseudolocalize bool `env:"PIXIVFE_PSEUDOLOCALIZE,overwrite" yaml:"pseudolocalize"`
+		// AnnotateFetchSource stamps each fetched work with the upstream
+		// call it came from, for diagnosing why a work appears or
+		// disappears. Only honored when InDevelopment is set.
+		AnnotateFetchSource bool `env:"PIXIVFE_ANNOTATE_FETCH_SOURCE,overwrite" yaml:"annotateFetchSource"`
+	} `yaml:"development"`
+
+	Log struct {
+		Level   string   `env:"PIXIVFE_LOG_LEVEL,overwrite" yaml:"logLevel"`
+		Outputs []string `env:"PIXIVFE_LOG_OUTPUTS,overwrite" yaml:"logOutputs"`
+		Format  string   `env:"PIXIVFE_LOG_FORMAT,overwrite" yaml:"logFormat"`
+	} `yaml:"log"`
+
+	Limiter struct {
+		Enabled       bool     `env:"PIXIVFE_LIMITER,overwrite" yaml:"enabled"`
+		StateFilepath string   `env:"PIXIVFE_LIMITER_STATE_FILEPATH,overwrite" yaml:"stateFilepath"`
+		PassIPs       []string `env:"PIXIVFE_LIMITER_PASS_IPS,overwrite" yaml:"passList"`
+		BlockIPs      []string `env:"PIXIVFE_LIMITER_BLOCK_IPS,overwrite" yaml:"blockList"`
+		FilterLocal   bool     `env:"PIXIVFE_LIMITER_FILTER_LOCAL,overwrite" yaml:"filterLocal"`
+		IPv4Prefix    int      `env:"PIXIVFE_LIMITER_IPV4_PREFIX,overwrite" yaml:"ipv4Prefix"`
+		IPv6Prefix    int      `env:"PIXIVFE_LIMITER_IPV6_PREFIX,overwrite" yaml:"ipv6Prefix"`
+		CheckHeaders  bool     `env:"PIXIVFE_LIMITER_CHECK_HEADERS,overwrite" yaml:"checkHeaders"`
+
+		// MaxConnsPerIP caps the number of simultaneously open TCP connections
+		// per client IP at the listener level. Zero disables the cap.
+		// IPs on the pass list are exempt.
+		MaxConnsPerIP int `env:"PIXIVFE_LIMITER_MAX_CONNS_PER_IP,overwrite" yaml:"maxConnsPerIP"`
+
+		DetectionMethod    LimiterDetectionMethod `env:"PIXIVFE_LIMITER_DETECTION_METHOD,overwrite" yaml:"detectionMethod"`
+		TurnstileSitekey   string                 `env:"PIXIVFE_LIMITER_TURNSTILE_SITEKEY" yaml:"turnstileSitekey"`
+		TurnstileSecretKey string                 `env:"PIXIVFE_LIMITER_TURNSTILE_SECRET_KEY" yaml:"turnstileSecretKey"`
+
+		// Dedicated bucket for the tag-completion endpoint, which is hit on
+		// every search keystroke. Rate is in tokens per minute.
+		TagCompletionRate  int `env:"PIXIVFE_LIMITER_TAG_COMPLETION_RATE,overwrite"  yaml:"tagCompletionRate"`
+		TagCompletionBurst int `env:"PIXIVFE_LIMITER_TAG_COMPLETION_BURST,overwrite" yaml:"tagCompletionBurst"`
+	} `yaml:"limiter"`
+
+	Internationalization struct {
+		// Strict mode for missing keys.
+		//
+		// When enabled, missing keys are logged (deduplicated per locale+key) and
+		// visibly wrapped using markers.
+		StrictMissingKeys bool `env:"PIXIVFE_STRICT_MISSING_KEYS" yaml:"strictMissingKeys"`
+
+		// LocaleAllowlist restricts the locales offered in the language
+		// switcher to these BCP 47 tags. Empty exposes every loaded locale.
+		LocaleAllowlist []string `env:"PIXIVFE_LOCALE_ALLOWLIST,overwrite" yaml:"localeAllowlist"`
+
+		// LocaleDenylist hides these BCP 47 tags from the language switcher.
+		LocaleDenylist []string `env:"PIXIVFE_LOCALE_DENYLIST,overwrite" yaml:"localeDenylist"`
+
+		// MinLocaleCoverage hides locales whose translation coverage (the
+		// fraction of template messages translated, between 0 and 1) falls
+		// below this threshold. Zero exposes incomplete locales.
+		MinLocaleCoverage float64 `env:"PIXIVFE_MIN_LOCALE_COVERAGE,overwrite" yaml:"minLocaleCoverage"`
+	}
+}
+
+// LoadConfig loads the configuration from various sources.
+func (cfg *ServerConfig) LoadConfig() error {
+	parsedConfigFlagValue := parseCommandLineArgs()
+
+	// Check if the -config flag was explicitly set by the user.
+	configFlagUserSet := false
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			configFlagUserSet = true
+		}
+	})
+
+	var configFilePath string
+
+	// Determine the config file path with the correct precedence:
+	// 1. Command-line flag (-config)
+	// 2. Environment variable (PIXIVFE_CONFIGFILE)
+	// 3. Default path with fallback check
+	if configFlagUserSet {
+		// Command-line flag has the highest precedence.
+		configFilePath = parsedConfigFlagValue
+	} else if envVar := os.Getenv("PIXIVFE_CONFIGFILE"); envVar != "" {
+		// Environment variable is next.
+		configFilePath = envVar
+	} else {
+		// If neither flag nor env var was provided, use the default value
+		// from the flag ("./config.yaml").
+		configFilePath = parsedConfigFlagValue
+		// Then, perform a fallback check for "./config.yml".
+		if _, err := os.Stat(configFilePath); os.IsNotExist(err) {
+			ymlPath := "./config.yml"
+			if _, statErr := os.Stat(ymlPath); statErr == nil {
+				configFilePath = ymlPath
+			}
+		}
+	}
+
+	cfg.SetDefaults()
+
+	cfg.Build.load()
+
+	cfg.Instance.FileServerCacheID = idgen.Make()
+	cfg.Instance.StartingTime = time.Now().UTC().Format("2006-01-02 15:04")
+
+	if err := cfg.readYAML(configFilePath); err != nil {
+		return fmt.Errorf("error loading YAML config: %w", err)
+	}
+
+	// @iacore: maybe this should be moved to the start of main()? there must be an existing library for this
+	if err := useDotEnv(); err != nil {
+		return fmt.Errorf("error using .env file: %w", err)
+	}
+
+	if err := readEnv(cfg); err != nil {
+		return fmt.Errorf("error loading environment variables: %w", err)
+	}
+
+	if err := cfg.validateAndSet(); err != nil {
+		return fmt.Errorf("configuration invalid: %w", err)
+	}
+
+	cfg.setupAudit()
+
+	tokenManager, err := tokenmanager.NewTokenManager(
+		cfg.Basic.Token,
+		cfg.TokenManager.MaxRetries,
+		cfg.TokenManager.BaseTimeout,
+		cfg.TokenManager.MaxBackoffTime,
+		cfg.TokenManager.LoadBalancing,
+		cfg.TokenManager.TokenWeights...,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating token manager: %w", err)
+	}
+
+	tokenmanager.DefaultTokenManager = tokenManager
+
+	cfg.print()
+
+	// Heuristically check for containerized environment and warn if host is not a wildcard address.
+	if isContainerized() && cfg.Basic.Host != "0.0.0.0" && cfg.Basic.Host != "::" {
+		log.Warn().
+			Str("host", cfg.Basic.Host).
+			Msg("Running in a containerized environment but host is not a wildcard address (e.g., '0.0.0.0' or '::'). This may prevent the service from being accessible outside the container.")
+	}
+
+	return nil
+}
+
+var (
+	staticSkippedPathPrefixes = []string{"/img/", "/css/", "/js/"}
+	devSkippedPathPrefixes    = []string{"/proxy/s.pximg.net/", "/proxy/i.pximg.net/"}
+)
+
+// ShouldSkipServerLogging determines if a request should bypass the logging middleware.
+func (cfg *ServerConfig) ShouldSkipServerLogging(path string) bool {
+	for _, prefix := range staticSkippedPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	if cfg.Development.InDevelopment {
+		for _, prefix := range devSkippedPathPrefixes {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isContainerized checks for common indicators of a containerized environment.
+//
+// This is a heuristic and may not be 100% accurate.
+func isContainerized() bool {
+	// Check for a Kubernetes-injected environment variable.
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+
+	// Check for existence of container-specific files.
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return true
+	}
+
+	if _, err := os.Stat("/.containerenv"); err == nil {
+		return true
+	}
+
+	// Check the cgroup of the current process.
+	// #nosec G304 -- We are checking for the existence and content of a well-known system file for heuristics.
+	cgroup, err := os.ReadFile("/proc/self/cgroup")
+	if err == nil {
+		content := string(cgroup)
+
+		// Check for keywords common in container cgroup paths.
+		return strings.Contains(content, "docker") ||
+			strings.Contains(content, "kubepods") ||
+			strings.Contains(content, "containerd") ||
+			strings.Contains(content, "lxc") ||
+			strings.Contains(content, "crio") ||
+			// systemd-nspawn containers
+			strings.Contains(content, ".machine")
+	}
+
+	return false
+}
+
+// GetDurationEncoderOption returns a YAML encoder option that marshals
+// time.Duration into a human-readable string format (e.g., "30m", "1h").
+func GetDurationEncoderOption() yaml.EncodeOption {
+	return yaml.CustomMarshaler[time.Duration](
+		func(d time.Duration) ([]byte, error) {
+			return yaml.Marshal(d.String())
+		},
+	)
+}
@@ -18,9 +18,10 @@ and *shouldn't* need exhaustive scenarios
 
 // TestLoadConfig is a test function that verifies the behavior of the LoadConfig function.
 func TestLoadConfig(t *testing.T) {
-	t.Parallel()
 	// Helper function to set environment variables
-	setEnv := func(env map[string]string) {
+	setEnv := func(t *testing.T, env map[string]string) {
+		t.Helper()
+
 		for k, v := range env {
 			t.Setenv(k, v)
 		}
@@ -80,9 +81,8 @@ func TestLoadConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
 			// Set up environment
-			setEnv(tt.env)
+			setEnv(t, tt.env)
 			defer unsetEnv(tt.env)
 
 			// Create a new ServerConfig instance
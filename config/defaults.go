@@ -0,0 +1,152 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package config
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// Default cache TTL in minutes.
+	defaultCacheTTLMinutes = 60
+	// Default HTTP cache max age in seconds.
+	defaultHTTPCacheMaxAgeSeconds = 30
+	// Default HTTP cache stale while revalidate in seconds.
+	defaultHTTPCacheStaleWhileRevalidateSeconds = 60
+	// Default cache TTL for user basic information in hours.
+	defaultCacheUserInfoTTLHours = 6
+	// Default grace window for serving stale cache entries in minutes.
+	defaultCacheStaleWhileRevalidateMinutes = 5
+
+	// Default token manager base timeout in milliseconds.
+	defaultTokenManagerBaseTimeoutMs = 1000
+	// Default token manager max backoff time in milliseconds.
+	defaultTokenManagerMaxBackoffTimeMs = 32000
+
+	// Default tokens per minute for the tag-completion limiter bucket.
+	defaultTagCompletionRatePerMinute = 60
+	// Default burst for the tag-completion limiter bucket.
+	defaultTagCompletionBurst = 15
+
+	// Default bounding box for master WebP conversions in pixels.
+	defaultImageWebpSize = 1200
+	// Default quality for master WebP conversions.
+	defaultImageWebpQuality = 80
+
+	// Default cap on the recent-works list on artwork pages.
+	defaultArtworkRecentWorksLimit = 20
+
+	// Default bound on concurrent pixivision image enrichment.
+	defaultPixivisionImageEnrichmentConcurrency = 8
+
+	// Default bound on concurrent novel-series title fetches.
+	defaultNovelSeriesFetchConcurrency = 4
+	// Default TTL for cached novel-series content titles in minutes.
+	defaultNovelSeriesTitlesTTLMinutes = 10
+	// Default cap on chapters stitched by the continuous series reader.
+	defaultNovelReaderChapterCap = 20
+
+	// defaultShutdownTimeout is how long shutdown waits for in-flight
+	// requests to drain by default.
+	defaultShutdownTimeout = 5 * time.Second
+
+	// Default number of retries for transient upstream GET failures.
+	defaultRequestMaxRetries = 2
+
+	// Default deadline for a single upstream pixiv call.
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// WebpConvertibleCategories lists every image path category that supports
+// master WebP conversion; it doubles as the default for Image.WebpCategories.
+var WebpConvertibleCategories = []string{
+	"img-master",
+	"custom-thumb",
+	"img-original",
+	"novel-cover-original",
+	"novel-cover-master",
+}
+
+// SetDefaults populates the configuration with default values.
+func (cfg *ServerConfig) SetDefaults() {
+	cfg.Basic.Host = "localhost"
+	cfg.Basic.Port = "8282"
+	cfg.Basic.ShutdownTimeout = defaultShutdownTimeout
+
+	cfg.ContentProxies.RawImage = BuiltInImageProxyPath
+	cfg.ContentProxies.RawStatic = BuiltInStaticProxyPath
+	cfg.ContentProxies.RawUgoira = BuiltInUgoiraProxyPath
+	cfg.ContentProxies.FallbackAvatar = "https://s.pximg.net/common/images/no_profile.png"
+	cfg.ContentProxies.FallbackThumbnail = "https://s.pximg.net/common/images/limit_unknown_360.png"
+
+	// Images, video/audio for ugoira, and CSS/fonts for static assets.
+	cfg.ContentProxies.AllowedContentTypes = []string{
+		"image/*",
+		"video/*",
+		"audio/*",
+		"font/*",
+		"text/css",
+	}
+
+	cfg.TokenManager.LoadBalancing = "round-robin"
+	cfg.TokenManager.MaxRetries = 5
+	cfg.TokenManager.BaseTimeout = defaultTokenManagerBaseTimeoutMs * time.Millisecond
+	cfg.TokenManager.MaxBackoffTime = defaultTokenManagerMaxBackoffTimeMs * time.Millisecond
+
+	cfg.Cache.Enabled = false
+	cfg.Cache.Size = 100
+	cfg.Cache.TTL = defaultCacheTTLMinutes * time.Minute
+	cfg.Cache.UserInfoTTL = defaultCacheUserInfoTTLHours * time.Hour
+	cfg.Cache.StaleWhileRevalidate = defaultCacheStaleWhileRevalidateMinutes * time.Minute
+
+	cfg.HTTPCache.MaxAge = defaultHTTPCacheMaxAgeSeconds * time.Second
+	cfg.HTTPCache.StaleWhileRevalidate = defaultHTTPCacheStaleWhileRevalidateSeconds * time.Second
+
+	cfg.Request.AcceptLanguage = "en-US,en;q=0.5"
+	cfg.Request.MaxRetries = defaultRequestMaxRetries
+	cfg.Request.Timeout = defaultRequestTimeout
+
+	cfg.Response.EarlyHintsResponses = false
+
+	cfg.Feature.PopularSearch = false
+	cfg.Feature.FastTagSuggestions = false
+	cfg.Feature.OpenAllButton = false
+	cfg.Feature.ProbeThumbnailDimensions = false
+	cfg.Feature.MergePixivMutes = false
+
+	cfg.Image.WebpSize = defaultImageWebpSize
+	cfg.Image.WebpQuality = defaultImageWebpQuality
+	cfg.Image.WebpCategories = slices.Clone(WebpConvertibleCategories)
+
+	cfg.Artwork.RecentWorksLimit = defaultArtworkRecentWorksLimit
+	cfg.Artwork.RecentWorksOrder = RecentWorksOrderID
+
+	cfg.Novel.SeriesFetchConcurrency = defaultNovelSeriesFetchConcurrency
+	cfg.Novel.SeriesTitlesTTL = defaultNovelSeriesTitlesTTLMinutes * time.Minute
+	cfg.Novel.ReaderChapterCap = defaultNovelReaderChapterCap
+
+	cfg.Pixivision.ImageEnrichmentConcurrency = defaultPixivisionImageEnrichmentConcurrency
+
+	cfg.Instance.RepoURL = "https://codeberg.org/PixivFE/PixivFE"
+
+	cfg.Development.SaveResponses = false
+	cfg.Development.ResponseSaveLocation = "/tmp/pixivfe/responses"
+
+	cfg.Log.Level = "info"
+	cfg.Log.Outputs = []string{"/dev/stderr"}
+	cfg.Log.Format = "console"
+
+	cfg.Limiter.Enabled = false
+	cfg.Limiter.StateFilepath = "./data/limiter_state.json"
+	cfg.Limiter.FilterLocal = false
+	cfg.Limiter.IPv4Prefix = 24
+	cfg.Limiter.IPv6Prefix = 48
+	cfg.Limiter.CheckHeaders = true
+	cfg.Limiter.DetectionMethod = ""
+	cfg.Limiter.TagCompletionRate = defaultTagCompletionRatePerMinute
+	cfg.Limiter.TagCompletionBurst = defaultTagCompletionBurst
+
+	cfg.Internationalization.StrictMissingKeys = false
+}
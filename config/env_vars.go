@@ -137,8 +137,9 @@ func setFieldValue(
 
 		field.SetBool(boolValue)
 	case reflect.Slice:
-		// All slices used in configuration are of strings
-		if field.Type().Elem().Kind() == reflect.String {
+		// Configuration slices are comma-separated lists of strings or ints.
+		switch field.Type().Elem().Kind() {
+		case reflect.String:
 			values := strings.Split(envValue, ",")
 			trimmedValues := make([]string, 0, len(values))
 
@@ -150,7 +151,28 @@ func setFieldValue(
 			}
 
 			field.Set(reflect.ValueOf(trimmedValues))
-		} else {
+		case reflect.Int:
+			values := strings.Split(envValue, ",")
+			intValues := make([]int, 0, len(values))
+
+			for _, value := range values {
+				trimmed := strings.TrimSpace(value)
+				if trimmed == "" {
+					continue
+				}
+
+				intValue, err := strconv.Atoi(trimmed)
+				if err != nil {
+					return fmt.Errorf(
+						"failed to parse int list for %s from env var %s (%s): %w",
+						fieldType.Name, envVarName, envValue, err)
+				}
+
+				intValues = append(intValues, intValue)
+			}
+
+			field.Set(reflect.ValueOf(intValues))
+		default:
 			return fmt.Errorf("%w for field %s", errUnsupportedSliceType, fieldType.Name)
 		}
 	case reflect.Struct:
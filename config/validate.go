@@ -3,11 +3,14 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/textproto"
 	"net/url"
 	"os"
 	"os/user"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 
@@ -31,6 +34,12 @@ var (
 	errTurnstileSecretKeyRequired    = errors.New("Limiter.TurnstileSecretKey is required")
 	errInvalidIPv4Prefix             = errors.New("IPv4 prefix must be between 0 and 32")
 	errInvalidIPv6Prefix             = errors.New("IPv6 prefix must be between 0 and 128")
+	errInvalidRecentWorksLimit       = errors.New("Artwork.RecentWorksLimit must be positive")
+	errInvalidRecentWorksOrder       = errors.New(`invalid Artwork.RecentWorksOrder value, must be "id" or "date"`)
+	errInvalidPreservedHeader        = errors.New("Request.PreserveForwardedHeaders entries must be X-Forwarded-*, Forwarded, or Via headers")
+	errInvalidEnrichmentConcurrency  = errors.New("Pixivision.ImageEnrichmentConcurrency must be positive")
+	errInvalidRequestMaxRetries      = errors.New("Request.MaxRetries cannot be negative")
+	errInvalidWebpCategory           = errors.New("invalid Image.WebpCategories entry")
 )
 
 var (
@@ -169,12 +178,48 @@ func (cfg *ServerConfig) validateAndSet() error {
 
 	// Validate TokenLoadBalancing
 	switch cfg.TokenManager.LoadBalancing {
-	case "round-robin", "random", "least-recently-used":
+	case "round-robin", "random", "least-recently-used", "weighted":
 		// valid
 	default:
 		return errInvalidTokenLoadBalancing
 	}
 
+	// Validate recent-works tuning
+	if cfg.Artwork.RecentWorksLimit <= 0 {
+		return errInvalidRecentWorksLimit
+	}
+
+	switch cfg.Artwork.RecentWorksOrder {
+	case RecentWorksOrderID, RecentWorksOrderDate:
+		// valid
+	default:
+		return errInvalidRecentWorksOrder
+	}
+
+	// Only known image path categories may be enabled for WebP conversion.
+	for _, category := range cfg.Image.WebpCategories {
+		if !slices.Contains(WebpConvertibleCategories, category) {
+			return fmt.Errorf("%w: %s", errInvalidWebpCategory, category)
+		}
+	}
+
+	if cfg.Pixivision.ImageEnrichmentConcurrency <= 0 {
+		return errInvalidEnrichmentConcurrency
+	}
+
+	if cfg.Request.MaxRetries < 0 {
+		return errInvalidRequestMaxRetries
+	}
+
+	// Only forwarding headers may be preserved on outbound requests.
+	for _, name := range cfg.Request.PreserveForwardedHeaders {
+		canonical := textproto.CanonicalMIMEHeaderKey(name)
+
+		if !strings.HasPrefix(canonical, "X-Forwarded-") && canonical != "Forwarded" && canonical != "Via" {
+			return fmt.Errorf("%w: %s", errInvalidPreservedHeader, name)
+		}
+	}
+
 	// Skip validating Limiter configuration if it's not enabled
 	if !cfg.Limiter.Enabled {
 		return nil
@@ -198,7 +243,11 @@ func (cfg *ServerConfig) validateAndSet() error {
 		if cfg.Basic.PasetoSecret == "" {
 			return errPasetoSecretRequired
 		}
+	}
 
+	// Load the key whenever one is provided; besides the limiter detection
+	// methods above, feed tokens are also signed with it.
+	if cfg.Basic.PasetoSecret != "" {
 		err := PasetoValidator.LoadSecretKeyFromHex(cfg.Basic.PasetoSecret)
 		if err != nil {
 			key := authenticated.NewSecretKeyHex()
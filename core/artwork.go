@@ -11,11 +11,11 @@ import (
 	"net/url"
 	"slices"
 	"sort"
-	"strings"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
+	"codeberg.org/pixivfe/pixivfe/v3/config"
 	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
 	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
 	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
@@ -23,11 +23,21 @@ import (
 )
 
 var (
-	ArtworkRecentLimit = 20 // Limit for recent artworks
+	ArtworkRecentLimit = 20 // Default limit for recent artworks
 
 	artworkRelatedLimit = 180 // Limit for related artworks
 )
 
+// RecentWorksLimit returns the operator-configured cap on the recent-works
+// list, falling back to ArtworkRecentLimit when the configuration is unset.
+func RecentWorksLimit() int {
+	if n := config.Global.Artwork.RecentWorksLimit; n > 0 {
+		return n
+	}
+
+	return ArtworkRecentLimit
+}
+
 // BookmarkData is a custom type to handle the following API response formats:
 //
 // Type 1, bookmarked:
@@ -64,9 +74,10 @@ type ArtworkItem struct {
 	SeriesID     string        `json:"seriesId"` // used by core/mangaseries
 	SeriesTitle  string        `json:"seriesTitle"`
 	Thumbnails   Thumbnails
-	Width        int
-	Height       int
-	Rank         int // Used for ranking data
+	Width        int          `json:"width"`  // Width of the original image; zero when the endpoint omits dimensions
+	Height       int          `json:"height"` // Height of the original image; zero when the endpoint omits dimensions
+	Rank         int          // Used for ranking data
+	FetchSource  *FetchSource `json:"-"` // Debug annotation; nil outside development builds
 }
 
 // ShouldHide reports whether the artwork should be hidden according to the
@@ -79,6 +90,11 @@ func (work *ArtworkItem) ShouldHide(cookies map[cookie.CookieName]string) bool {
 
 	profile := ReadFilterProfile(cookies[cookie.FilterProfileCookie])
 
+	// Optionally fold in the account's native pixiv mute list.
+	if config.Global.Feature.MergePixivMutes {
+		profile = applyNativeMutes(profile, cookies[cookie.TokenCookie])
+	}
+
 	// AI-generated works.
 	if profile.AI == FilterHide && work.AIType == AIGenerated {
 		return true
@@ -96,6 +112,11 @@ func (work *ArtworkItem) ShouldHide(cookies map[cookie.CookieName]string) bool {
 		}
 	}
 
+	// Sensitivity threshold over pixiv's sl buckets; SLUnreviewed disables it.
+	if profile.SanityThreshold > SLUnreviewed && work.SanityLevel >= profile.SanityThreshold {
+		return true
+	}
+
 	// Blacklisted user.
 	if len(profile.BlacklistedArtists) > 0 {
 		if slices.Contains(profile.BlacklistedArtists, work.UserID) {
@@ -103,17 +124,10 @@ func (work *ArtworkItem) ShouldHide(cookies map[cookie.CookieName]string) bool {
 		}
 	}
 
-	// Blacklisted tags.
-	if len(profile.BlacklistedTags) > 0 {
-		for _, workTag := range work.Tags {
-			if slices.ContainsFunc(
-				profile.BlacklistedTags,
-				func(tag string) bool {
-					return strings.EqualFold(tag, workTag)
-				},
-			) {
-				return true
-			}
+	// Blacklisted tags, including wildcard entries; see compileTagMatchers.
+	for _, workTag := range work.Tags {
+		if profile.matchesBlacklistedTag(workTag) {
+			return true
 		}
 	}
 
@@ -186,6 +200,12 @@ type Illust struct {
 	Height     int `json:"height"`
 }
 
+// CommentCounts reconciles the artwork's reported comment total with the
+// comments actually fetched into CommentsData.
+func (i *Illust) CommentCounts() CommentCounts {
+	return ReconcileCommentCounts(i.Comments, i.CommentsData)
+}
+
 // FastIllustParams encapsulates basic artwork data required
 // to call GetArtworkByIDFast, available through Artwork-* request headers.
 type FastIllustParams struct {
@@ -257,43 +277,81 @@ func GetBasicArtwork(r *http.Request, artworkID string, illust *Illust) error {
 	illust.Thumbnails = thumbnails
 
 	recentWorkIDs, _ := illust.RawRecentWorkIDs.ExtractIDs()
-	sort.Sort(sort.Reverse(sort.IntSlice(recentWorkIDs)))
+	illust.RecentWorkIDs = capRecentWorkIDs(recentWorkIDs, RecentWorksLimit())
 
-	if len(recentWorkIDs) > ArtworkRecentLimit {
-		recentWorkIDs = recentWorkIDs[:ArtworkRecentLimit]
-	}
+	return nil
+}
 
-	illust.RecentWorkIDs = recentWorkIDs
+// capRecentWorkIDs sorts ids descending and truncates the list to limit.
+func capRecentWorkIDs(ids []int, limit int) []int {
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
 
-	return nil
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	return ids
 }
 
+// GetArtworkRelated retrieves related artworks as one large batch.
 func GetArtworkRelated(r *http.Request, artworkID string) ([]ArtworkItem, error) {
+	related, _, err := GetArtworkRelatedPaged(r, artworkID, nil, artworkRelatedLimit)
+
+	return related, err
+}
+
+// GetArtworkRelatedPaged fetches one bounded batch of related artworks.
+//
+// The first call (empty nextIDs) hits the recommend/init endpoint with the
+// given limit; subsequent calls resolve up to limit IDs from the continuation
+// token returned by the previous call. The returned token holds the IDs still
+// to be resolved and is empty once the listing is exhausted.
+func GetArtworkRelatedPaged(r *http.Request, artworkID string, nextIDs []string, limit int) ([]ArtworkItem, []string, error) {
+	if limit < 1 {
+		limit = artworkRelatedLimit
+	}
+
+	endpoint := GetArtworkRelatedURL(artworkID, limit)
+
+	var remaining []string
+
+	if len(nextIDs) > 0 {
+		batch := nextIDs
+		if len(batch) > limit {
+			batch = batch[:limit]
+			remaining = nextIDs[limit:]
+		}
+
+		endpoint = GetArtworkRelatedContinueURL(batch)
+	}
+
 	var data artworkRelatedResponse
 
 	resp, err := requests.GetJSONBody(
 		r.Context(),
-		GetArtworkRelatedURL(artworkID, artworkRelatedLimit),
+		endpoint,
 		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
 		r.Header,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := json.Unmarshal(RewriteEscapedImageURLs(r, resp), &data); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	for i, artwork := range data.Illusts {
 		if err := artwork.PopulateThumbnails(); err != nil {
-			return nil, fmt.Errorf("failed to populate thumbnails for artwork ID %s: %w", artwork.ID, err)
+			return nil, nil, fmt.Errorf("failed to populate thumbnails for artwork ID %s: %w", artwork.ID, err)
 		}
 
 		data.Illusts[i] = artwork
 	}
 
-	return data.Illusts, nil
+	annotateArtworkFetchSource(data.Illusts, "related", endpoint)
+
+	return data.Illusts, slices.Concat(remaining, data.NextIDs), nil
 }
 
 func PopulateArtworkRecent(r *http.Request, userID string, recentWorkIDs []int) ([]ArtworkItem, error) {
@@ -320,11 +378,27 @@ func PopulateArtworkRecent(r *http.Request, userID string, recentWorkIDs []int)
 		recent[i] = artwork
 	}
 
+	sortRecentWorks(recent, config.Global.Artwork.RecentWorksOrder)
+
+	annotateArtworkFetchSource(recent, "recent", GetUserFullArtworkURL(userID, idsString))
+
+	return recent, nil
+}
+
+// sortRecentWorks orders the recent-works list: by CreateDate descending when
+// order is "date", by ID descending otherwise.
+func sortRecentWorks(recent []ArtworkItem, order string) {
+	if order == config.RecentWorksOrderDate {
+		sort.Slice(recent, func(i, j int) bool {
+			return recent[i].CreateDate.After(recent[j].CreateDate)
+		})
+
+		return
+	}
+
 	sort.Slice(recent, func(i, j int) bool {
 		return numberGreaterThan(recent[i].ID, recent[j].ID)
 	})
-
-	return recent, nil
 }
 
 // getAndProcessArtwork orchestrates fetching artwork data, handling both
@@ -408,8 +482,10 @@ func getAndProcessArtwork(w http.ResponseWriter, r *http.Request, artworkID stri
 		})
 	}
 
-	// Full (non-fast) path extras
-	if !isFastPath {
+	// Full (non-fast) path extras. Minimal mode skips them too, composing
+	// with the fast path: low-bandwidth clients get neither the optional
+	// fetches nor the markup that would render them.
+	if !isFastPath && !untrusted.IsMinimalMode(r) {
 		g.Go(func() error {
 			t0 := time.Now()
 
@@ -470,25 +546,9 @@ func getAndProcessArtwork(w http.ResponseWriter, r *http.Request, artworkID stri
 
 	// Post-processing
 	if illust.Images == nil {
-		illust.Images = make([]Thumbnails, illust.Pages)
-
-		thumbs, err := PopulateThumbnailsFor(illust.Urls.Small)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate thumbnails for image: %w", err)
+		if err := populateSingleImage(r, &illust); err != nil {
+			return nil, err
 		}
-
-		illust.Images[0] = thumbs
-		illust.Images[0].Width = illust.Width
-		illust.Images[0].Height = illust.Height
-		illust.Images[0].Original = illust.Urls.Original
-		illust.Images[0].IllustType = illust.IllustType
-
-		orig, err := url.Parse(illust.Urls.Original)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse original URL '%s': %w", illust.Urls.Original, err)
-		}
-
-		illust.Images[0].Download = utils.GetProxyBase(untrusted.GetUgoiraProxy(r)) + "/pximg" + orig.Path
 	}
 
 	if illust.IllustType == Ugoira && len(illust.Images) > 0 {
@@ -506,6 +566,57 @@ func getAndProcessArtwork(w http.ResponseWriter, r *http.Request, artworkID stri
 	return &illust, nil
 }
 
+// populateSingleImage builds illust.Images for works whose pages were not
+// fetched separately (single-page works and the fast path).
+//
+// The API occasionally reports zero pages or omits the image URLs entirely;
+// rather than building thumbnails from empty strings (or panicking on the
+// zero-length slice), such works fall back to the configured placeholder
+// thumbnail so they render with a clear "image unavailable" state.
+func populateSingleImage(r *http.Request, illust *Illust) error {
+	if illust.Urls.Small == "" {
+		fallback := FallbackThumbnailURL(r)
+
+		illust.Images = []Thumbnails{{
+			MasterWebp_1200: fallback,
+			Webp_1200:       fallback,
+			Original:        fallback,
+			Width:           illust.Width,
+			Height:          illust.Height,
+			IllustType:      illust.IllustType,
+		}}
+
+		return nil
+	}
+
+	pages := illust.Pages
+	if pages < 1 {
+		pages = 1
+	}
+
+	illust.Images = make([]Thumbnails, pages)
+
+	thumbs, err := PopulateThumbnailsFor(illust.Urls.Small)
+	if err != nil {
+		return fmt.Errorf("failed to generate thumbnails for image: %w", err)
+	}
+
+	illust.Images[0] = thumbs
+	illust.Images[0].Width = illust.Width
+	illust.Images[0].Height = illust.Height
+	illust.Images[0].Original = illust.Urls.Original
+	illust.Images[0].IllustType = illust.IllustType
+
+	orig, err := url.Parse(illust.Urls.Original)
+	if err != nil {
+		return fmt.Errorf("failed to parse original URL '%s': %w", illust.Urls.Original, err)
+	}
+
+	illust.Images[0].Download = utils.GetProxyBase(untrusted.GetUgoiraProxy(r)) + "/pximg" + orig.Path
+
+	return nil
+}
+
 // getArtworkImages retrieves the images for an artwork.
 func getArtworkImages(r *http.Request, workID string, illustType IllustType) ([]Thumbnails, error) {
 	resp, err := requests.GetJSONBody(
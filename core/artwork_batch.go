@@ -0,0 +1,146 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
+	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
+)
+
+const (
+	// artworksBatchChunkSize is the maximum number of illust IDs pixiv
+	// resolves in a single lookup call.
+	artworksBatchChunkSize = 48
+
+	// artworksBatchConcurrency bounds the number of chunk fetches in flight.
+	artworksBatchConcurrency = 4
+)
+
+// artworksBatchResponse represents the API response for GetArtworksBatchURL.
+type artworksBatchResponse struct {
+	Illusts []Illust `json:"illusts"`
+}
+
+// GetArtworksBatch resolves many illust IDs to full artwork data, chunking
+// them into as few upstream calls as the per-request ID cap allows and
+// fetching the chunks concurrently.
+//
+// The returned map is keyed by illust ID and every entry has its thumbnails
+// populated. Chunk failures are tolerated as long as at least one chunk
+// succeeds; works from failed chunks are simply absent from the map. An error
+// is returned only when every chunk failed.
+func GetArtworksBatch(r *http.Request, ids []string) (map[string]*Illust, error) {
+	unique := dedupeIDs(ids)
+
+	works := make(map[string]*Illust, len(unique))
+	if len(unique) == 0 {
+		return works, nil
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		g        errgroup.Group
+	)
+
+	g.SetLimit(artworksBatchConcurrency)
+
+	// Resolve the chunk URLs up front so they can be issued in a
+	// connection-reuse-friendly order.
+	var chunkURLs []string
+
+	for chunk := range slices.Chunk(unique, artworksBatchChunkSize) {
+		chunkURLs = append(chunkURLs, GetArtworksBatchURL(chunk))
+	}
+
+	for _, chunkURL := range requests.OrderByHost(chunkURLs) {
+		g.Go(func() error {
+			illusts, err := fetchArtworksChunk(r, chunkURL)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+
+				return nil
+			}
+
+			for i := range illusts {
+				works[illusts[i].ID] = &illusts[i]
+			}
+
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	if len(works) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+
+	return works, nil
+}
+
+// fetchArtworksChunk fetches one pre-built chunk URL and populates thumbnails
+// for every returned work.
+func fetchArtworksChunk(r *http.Request, chunkURL string) ([]Illust, error) {
+	resp, err := requests.GetJSONBody(
+		r.Context(),
+		chunkURL,
+		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+		r.Header,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var data artworksBatchResponse
+
+	if err := json.Unmarshal(RewriteEscapedImageURLs(r, resp), &data); err != nil {
+		return nil, err
+	}
+
+	for i := range data.Illusts {
+		thumbnails, err := PopulateThumbnailsFor(data.Illusts[i].Urls.Small)
+		if err != nil {
+			return nil, err
+		}
+
+		data.Illusts[i].Thumbnails = thumbnails
+	}
+
+	return data.Illusts, nil
+}
+
+// dedupeIDs returns ids with duplicates and empty entries removed, preserving
+// first-seen order.
+func dedupeIDs(ids []string) []string {
+	var (
+		seen   = make(map[string]bool, len(ids))
+		unique []string
+	)
+
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+
+		seen[id] = true
+
+		unique = append(unique, id)
+	}
+
+	return unique
+}
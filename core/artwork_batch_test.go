@@ -0,0 +1,192 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+var errBatchChunkUnavailable = errors.New("stubbed chunk failure")
+
+// batchStubTransport serves a fixture-backed illust for every ID requested
+// via illust_ids[], recording the chunks it saw. Chunks containing an ID in
+// failIDs error instead.
+type batchStubTransport struct {
+	mu      sync.Mutex
+	chunks  [][]string
+	failIDs map[string]bool
+}
+
+func (b *batchStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ids := req.URL.Query()["illust_ids[]"]
+
+	b.mu.Lock()
+	b.chunks = append(b.chunks, ids)
+	b.mu.Unlock()
+
+	entries := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if b.failIDs[id] {
+			return nil, errBatchChunkUnavailable
+		}
+
+		entries = append(entries, fmt.Sprintf(
+			`{"id":"%[1]s","title":"Work %[1]s","userId":"9",
+				"urls":{"small":"https://i.pximg.net/c/540x540_70/img-master/img/2024/01/01/00/00/00/%[1]s_p0_master1200.jpg"}}`,
+			id))
+	}
+
+	body := `{"error":false,"body":{"illusts":[` + strings.Join(entries, ",") + `]}}`
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func setupBatchTest(t *testing.T, failIDs ...string) *batchStubTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+
+	transport := &batchStubTransport{failIDs: make(map[string]bool)}
+	for _, id := range failIDs {
+		transport.failIDs[id] = true
+	}
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	return transport
+}
+
+// batchTestIDs returns n distinct IDs starting at base.
+func batchTestIDs(base, n int) []string {
+	ids := make([]string, n)
+	for i := range n {
+		ids[i] = fmt.Sprint(base + i)
+	}
+
+	return ids
+}
+
+func TestGetArtworksBatch_ChunksLargeRequests(t *testing.T) {
+	transport := setupBatchTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	ids := batchTestIDs(70000, artworksBatchChunkSize+2)
+
+	works, err := GetArtworksBatch(r, ids)
+	if err != nil {
+		t.Fatalf("GetArtworksBatch() error: %v", err)
+	}
+
+	if len(works) != len(ids) {
+		t.Errorf("Got %d works, want %d", len(works), len(ids))
+	}
+
+	transport.mu.Lock()
+	chunks := transport.chunks
+	transport.mu.Unlock()
+
+	if len(chunks) != 2 {
+		t.Fatalf("Upstream saw %d chunks, want 2", len(chunks))
+	}
+
+	for _, chunk := range chunks {
+		if len(chunk) > artworksBatchChunkSize {
+			t.Errorf("Chunk has %d IDs, exceeding the cap of %d", len(chunk), artworksBatchChunkSize)
+		}
+	}
+
+	work, ok := works[ids[0]]
+	if !ok {
+		t.Fatalf("Work %s missing from the result map", ids[0])
+	}
+
+	if work.Thumbnails.MasterWebp_1200 == "" {
+		t.Error("Thumbnails not populated for a batched work")
+	}
+}
+
+func TestGetArtworksBatch_DedupesIDs(t *testing.T) {
+	transport := setupBatchTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	works, err := GetArtworksBatch(r, []string{"71001", "71002", "71001", "", "71002"})
+	if err != nil {
+		t.Fatalf("GetArtworksBatch() error: %v", err)
+	}
+
+	if len(works) != 2 {
+		t.Errorf("Got %d works, want 2", len(works))
+	}
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.chunks) != 1 || len(transport.chunks[0]) != 2 {
+		t.Errorf("Upstream chunks = %v, want one chunk of two IDs", transport.chunks)
+	}
+}
+
+func TestGetArtworksBatch_PartialFailure(t *testing.T) {
+	// Fail the chunk that contains the last ID.
+	ids := batchTestIDs(72000, artworksBatchChunkSize+2)
+	setupBatchTest(t, ids[len(ids)-1])
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	works, err := GetArtworksBatch(r, ids)
+	if err != nil {
+		t.Fatalf("GetArtworksBatch() error on partial failure: %v", err)
+	}
+
+	if len(works) != artworksBatchChunkSize {
+		t.Errorf("Got %d works, want the %d from the healthy chunk", len(works), artworksBatchChunkSize)
+	}
+
+	if _, ok := works[ids[len(ids)-1]]; ok {
+		t.Error("Work from the failed chunk present in the result map")
+	}
+}
+
+func TestGetArtworksBatch_AllChunksFailed(t *testing.T) {
+	setupBatchTest(t, "73001")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if _, err := GetArtworksBatch(r, []string{"73001"}); err == nil {
+		t.Fatal("Expected an error when every chunk fails, got nil")
+	}
+}
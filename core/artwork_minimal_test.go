@@ -0,0 +1,214 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// Fixtures for the artwork page fetch paths. Artwork 77 by user 55 has two
+// recent works, comments enabled, and one related work.
+const (
+	minimalArtworkFixture = `{"error":false,"body":{
+		"id":"77","title":"Test work","userId":"55","pageCount":1,
+		"commentOff":0,"sl":2,
+		"userIllusts":{"5":null,"6":null},
+		"urls":{
+			"small":"https://i.pximg.net/c/540x540_70/img-master/img/2024/01/01/00/00/00/77_p0_master1200.jpg",
+			"original":"https://i.pximg.net/img-original/img/2024/01/01/00/00/00/77_p0.png"
+		}
+	}}`
+
+	minimalUserFixture = `{"error":false,"body":{"userId":"55","name":"Artist","imageBig":""}}`
+
+	minimalRelatedFixture = `{"error":false,"body":{
+		"illusts":[{"id":"88","title":"Related work","userId":"56",
+			"url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/01/00/00/00/88_p0_square1200.jpg"}],
+		"nextIds":[]
+	}}`
+
+	minimalRecentFixture = `{"error":false,"body":{"works":{
+		"5":{"id":"5","title":"Recent A","userId":"55",
+			"url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/01/00/00/00/5_p0_square1200.jpg"},
+		"6":{"id":"6","title":"Recent B","userId":"55",
+			"url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/01/00/00/00/6_p0_square1200.jpg"}
+	}}}`
+
+	minimalCommentsFixture = `{"error":false,"body":{"comments":[],"hasNext":false}}`
+)
+
+// artworkStubTransport serves the fixture matching the requested path and
+// records every upstream path for later inspection.
+type artworkStubTransport struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (a *artworkStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	a.mu.Lock()
+	a.paths = append(a.paths, req.URL.Path)
+	a.mu.Unlock()
+
+	var body string
+
+	switch {
+	case strings.Contains(req.URL.Path, "/recommend/init"):
+		body = minimalRelatedFixture
+	case strings.Contains(req.URL.Path, "/comments/roots"):
+		body = minimalCommentsFixture
+	case strings.Contains(req.URL.Path, "/profile/illusts"):
+		body = minimalRecentFixture
+	case strings.Contains(req.URL.Path, "/ajax/user/"):
+		body = minimalUserFixture
+	default:
+		body = minimalArtworkFixture
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// fetchedOptionalSections reports which of the optional artwork endpoints
+// were requested through the transport.
+func (a *artworkStubTransport) fetchedOptionalSections() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var fetched []string
+
+	for _, path := range a.paths {
+		if strings.Contains(path, "/recommend/init") ||
+			strings.Contains(path, "/comments/roots") ||
+			strings.Contains(path, "/profile/illusts") {
+			fetched = append(fetched, path)
+		}
+	}
+
+	return fetched
+}
+
+func setupArtworkTest(t *testing.T) *artworkStubTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+	config.Global.ContentProxies.Static = url.URL{Path: "/proxy/s.pximg.net"}
+
+	transport := &artworkStubTransport{}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	return transport
+}
+
+func TestGetArtwork_FetchesOptionalSections(t *testing.T) {
+	transport := setupArtworkTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/77", nil)
+
+	illust, err := GetArtwork(httptest.NewRecorder(), r, "77")
+	if err != nil {
+		t.Fatalf("GetArtwork() error: %v", err)
+	}
+
+	if len(illust.RelatedWorks) != 1 {
+		t.Errorf("RelatedWorks has %d entries, want 1", len(illust.RelatedWorks))
+	}
+
+	if len(illust.RecentWorks) != 2 {
+		t.Errorf("RecentWorks has %d entries, want 2", len(illust.RecentWorks))
+	}
+
+	if illust.CommentsData == nil {
+		t.Error("CommentsData is nil, want the fetched (empty) comment set")
+	}
+
+	if fetched := transport.fetchedOptionalSections(); len(fetched) != 3 {
+		t.Errorf("Optional endpoints fetched = %v, want related, recent, and comments", fetched)
+	}
+}
+
+func TestGetArtwork_MinimalModeSkipsOptionalSections(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(r *http.Request)
+	}{
+		{"Query parameter", func(r *http.Request) {
+			r.URL.RawQuery = "minimal=1"
+		}},
+		{"Cookie", func(r *http.Request) {
+			r.AddCookie(&http.Cookie{Name: string(cookie.MinimalModeCookie), Value: "true"})
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := setupArtworkTest(t)
+
+			r := httptest.NewRequest(http.MethodGet, "/artworks/77", nil)
+			tc.setup(r)
+
+			illust, err := GetArtwork(httptest.NewRecorder(), r, "77")
+			if err != nil {
+				t.Fatalf("GetArtwork() error: %v", err)
+			}
+
+			if len(illust.RelatedWorks) != 0 || len(illust.RecentWorks) != 0 || illust.CommentsData != nil {
+				t.Error("Optional sections were populated in minimal mode")
+			}
+
+			if fetched := transport.fetchedOptionalSections(); len(fetched) != 0 {
+				t.Errorf("Optional endpoints fetched in minimal mode: %v", fetched)
+			}
+		})
+	}
+}
+
+func TestGetArtwork_MinimalQueryOverridesCookie(t *testing.T) {
+	transport := setupArtworkTest(t)
+
+	// An explicit minimal=0 wins over the MinimalMode cookie.
+	r := httptest.NewRequest(http.MethodGet, "/artworks/77?minimal=0", nil)
+	r.AddCookie(&http.Cookie{Name: string(cookie.MinimalModeCookie), Value: "true"})
+
+	illust, err := GetArtwork(httptest.NewRecorder(), r, "77")
+	if err != nil {
+		t.Fatalf("GetArtwork() error: %v", err)
+	}
+
+	if len(illust.RelatedWorks) == 0 {
+		t.Error("RelatedWorks is empty, want the full page with minimal=0")
+	}
+
+	if fetched := transport.fetchedOptionalSections(); len(fetched) != 3 {
+		t.Errorf("Optional endpoints fetched = %v, want all three with minimal=0", fetched)
+	}
+}
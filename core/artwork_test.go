@@ -0,0 +1,255 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+func TestCapRecentWorkIDs(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		ids   []int
+		limit int
+		want  []int
+	}{
+		{"Caps to limit", []int{3, 10, 7, 1}, 2, []int{10, 7}},
+		{"Limit above length keeps all", []int{3, 10, 7}, 20, []int{10, 7, 3}},
+		{"Zero limit keeps all", []int{3, 10}, 0, []int{10, 3}},
+		{"Empty input", nil, 5, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := capRecentWorkIDs(slices.Clone(tc.ids), tc.limit)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("capRecentWorkIDs(%v, %d) = %v, want %v", tc.ids, tc.limit, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRecentWorksLimit(t *testing.T) {
+	orig := config.Global.Artwork.RecentWorksLimit
+
+	t.Cleanup(func() {
+		config.Global.Artwork.RecentWorksLimit = orig
+	})
+
+	config.Global.Artwork.RecentWorksLimit = 5
+	if got := RecentWorksLimit(); got != 5 {
+		t.Errorf("RecentWorksLimit() = %d, want 5", got)
+	}
+
+	// An unset limit falls back to the built-in default.
+	config.Global.Artwork.RecentWorksLimit = 0
+	if got := RecentWorksLimit(); got != ArtworkRecentLimit {
+		t.Errorf("RecentWorksLimit() = %d, want %d", got, ArtworkRecentLimit)
+	}
+}
+
+func TestSortRecentWorks(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2025, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// The numerically largest ID carries the oldest CreateDate so the two
+	// orderings disagree.
+	works := func() []ArtworkItem {
+		return []ArtworkItem{
+			{ID: "9", CreateDate: day(3)},
+			{ID: "100", CreateDate: day(1)},
+			{ID: "20", CreateDate: day(2)},
+		}
+	}
+
+	ids := func(items []ArtworkItem) []string {
+		out := make([]string, len(items))
+		for i, item := range items {
+			out[i] = item.ID
+		}
+
+		return out
+	}
+
+	byID := works()
+	sortRecentWorks(byID, config.RecentWorksOrderID)
+
+	if got, want := ids(byID), []string{"100", "20", "9"}; !slices.Equal(got, want) {
+		t.Errorf("sortRecentWorks(order=id) = %v, want %v", got, want)
+	}
+
+	byDate := works()
+	sortRecentWorks(byDate, config.RecentWorksOrderDate)
+
+	if got, want := ids(byDate), []string{"9", "20", "100"}; !slices.Equal(got, want) {
+		t.Errorf("sortRecentWorks(order=date) = %v, want %v", got, want)
+	}
+}
+
+func TestPopulateSingleImage_ZeroPages(t *testing.T) {
+	setupPlaceholderConfig(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/111", nil)
+
+	illust := Illust{ID: "111", Width: 800, Height: 600}
+	illust.Urls.Small = "/proxy/i.pximg.net/c/540x540_70/img-master/img/2024/01/01/00/00/00/111_p0_master1200.jpg"
+	illust.Urls.Original = "https://i.pximg.net/img-original/img/2024/01/01/00/00/00/111_p0.png"
+
+	// Pages is left at zero: a single entry is still reserved so views that
+	// index Images[0] do not panic.
+	if err := populateSingleImage(r, &illust); err != nil {
+		t.Fatalf("populateSingleImage() error: %v", err)
+	}
+
+	if len(illust.Images) != 1 {
+		t.Fatalf("len(Images) = %d, want 1", len(illust.Images))
+	}
+
+	first := illust.Images[0]
+	if first.Webp_1200 == "" || strings.Contains(first.Webp_1200, "540x540_70") {
+		t.Errorf("Webp_1200 = %q, want a rewritten thumbnail URL", first.Webp_1200)
+	}
+
+	if first.Original != illust.Urls.Original {
+		t.Errorf("Original = %q, want %q", first.Original, illust.Urls.Original)
+	}
+
+	if first.Width != 800 || first.Height != 600 {
+		t.Errorf("dimensions = %dx%d, want 800x600", first.Width, first.Height)
+	}
+}
+
+func TestPopulateSingleImage_EmptyURLs(t *testing.T) {
+	setupPlaceholderConfig(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/111", nil)
+
+	illust := Illust{ID: "111", Pages: 1, Width: 800, Height: 600}
+
+	if err := populateSingleImage(r, &illust); err != nil {
+		t.Fatalf("populateSingleImage() error: %v", err)
+	}
+
+	if len(illust.Images) != 1 {
+		t.Fatalf("len(Images) = %d, want 1", len(illust.Images))
+	}
+
+	want := "/proxy/s.pximg.net/common/images/limit_unknown_360.png"
+
+	first := illust.Images[0]
+	if first.MasterWebp_1200 != want || first.Webp_1200 != want || first.Original != want {
+		t.Errorf("Images[0] = %+v, want every variant set to the placeholder %q", first, want)
+	}
+
+	if first.Width != 800 || first.Height != 600 {
+		t.Errorf("dimensions = %dx%d, want 800x600", first.Width, first.Height)
+	}
+}
+
+// relatedStubTransport serves related-works payloads, recording each request
+// URL so tests can assert on the endpoint and parameters used.
+type relatedStubTransport struct {
+	urls []string
+}
+
+func (t *relatedStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, req.URL.String())
+
+	var body string
+	if strings.Contains(req.URL.Path, "/recommend/init") {
+		body = `{"error":false,"body":{"illusts":[
+			{"id":"1","url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/01/00/00/00/1_p0_square1200.jpg"}
+		],"nextIds":["2","3","4"]}}`
+	} else {
+		body = `{"error":false,"body":{"illusts":[
+			{"id":"2","url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/01/00/00/00/2_p0_square1200.jpg"},
+			{"id":"3","url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/01/00/00/00/3_p0_square1200.jpg"}
+		],"nextIds":[]}}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetArtworkRelatedPaged_Continuation(t *testing.T) {
+	setupPlaceholderConfig(t)
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	transport := &relatedStubTransport{}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/999", nil)
+
+	// The first batch uses the init endpoint with the requested limit.
+	works, next, err := GetArtworkRelatedPaged(r, "999", nil, 2)
+	if err != nil {
+		t.Fatalf("GetArtworkRelatedPaged() error: %v", err)
+	}
+
+	if len(works) != 1 || works[0].ID != "1" {
+		t.Fatalf("first batch = %+v, want the single init work", works)
+	}
+
+	if !slices.Equal(next, []string{"2", "3", "4"}) {
+		t.Fatalf("continuation token = %v, want [2 3 4]", next)
+	}
+
+	if !strings.Contains(transport.urls[0], "/recommend/init") || !strings.Contains(transport.urls[0], "limit=2") {
+		t.Errorf("first request URL = %q, want the init endpoint with limit=2", transport.urls[0])
+	}
+
+	// The second batch resolves only limit IDs from the token and carries the
+	// rest over.
+	works, next, err = GetArtworkRelatedPaged(r, "999", next, 2)
+	if err != nil {
+		t.Fatalf("GetArtworkRelatedPaged() continuation error: %v", err)
+	}
+
+	if len(works) != 2 || works[0].ID != "2" || works[1].ID != "3" {
+		t.Fatalf("second batch = %+v, want works 2 and 3", works)
+	}
+
+	if !slices.Equal(next, []string{"4"}) {
+		t.Errorf("continuation token = %v, want the unresolved remainder [4]", next)
+	}
+
+	continueURL := transport.urls[1]
+	if !strings.Contains(continueURL, "/recommend/illusts") {
+		t.Errorf("second request URL = %q, want the continuation endpoint", continueURL)
+	}
+
+	if strings.Contains(continueURL, "illust_ids%5B%5D=4") {
+		t.Errorf("second request URL = %q, resolved more IDs than the limit", continueURL)
+	}
+}
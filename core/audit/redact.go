@@ -0,0 +1,58 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package audit
+
+import (
+	"strings"
+	"sync"
+)
+
+// redactedFragmentPlaceholder replaces a sensitive URL fragment (such as a
+// work ID) when it appears inside an otherwise loggable URL.
+const redactedFragmentPlaceholder = "[redacted]"
+
+var (
+	redactMu sync.RWMutex
+
+	// redactedURLs maps exact sensitive URLs to their log placeholders.
+	redactedURLs = map[string]string{}
+
+	// redactedFragments holds sensitive substrings (e.g. work IDs) masked
+	// wherever they appear in logged URLs.
+	redactedFragments = map[string]struct{}{}
+)
+
+// RegisterSensitiveURL masks rawURL in span logs and Server-Timing names,
+// replacing it with placeholder. The trailing path segment — the work ID for
+// artwork URLs — is also masked wherever it appears inside other URLs.
+//
+// Registration is idempotent.
+func RegisterSensitiveURL(rawURL, placeholder string) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+
+	redactedURLs[rawURL] = placeholder
+
+	trimmed := strings.TrimRight(rawURL, "/")
+	if idx := strings.LastIndex(trimmed, "/"); idx >= 0 && idx+1 < len(trimmed) {
+		redactedFragments[trimmed[idx+1:]] = struct{}{}
+	}
+}
+
+// RedactURL returns rawURL with registered sensitive URLs replaced by their
+// placeholders and registered fragments masked.
+func RedactURL(rawURL string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+
+	if placeholder, ok := redactedURLs[rawURL]; ok {
+		return placeholder
+	}
+
+	for fragment := range redactedFragments {
+		rawURL = strings.ReplaceAll(rawURL, fragment, redactedFragmentPlaceholder)
+	}
+
+	return rawURL
+}
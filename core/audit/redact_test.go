@@ -0,0 +1,119 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package audit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// registerTestURL registers a sensitive URL and restores the registries when
+// the test completes.
+func registerTestURL(t *testing.T, rawURL, placeholder string) {
+	t.Helper()
+
+	redactMu.Lock()
+	origURLs := redactedURLs
+	origFragments := redactedFragments
+	redactedURLs = map[string]string{}
+	redactedFragments = map[string]struct{}{}
+	redactMu.Unlock()
+
+	t.Cleanup(func() {
+		redactMu.Lock()
+		redactedURLs = origURLs
+		redactedFragments = origFragments
+		redactMu.Unlock()
+	})
+
+	RegisterSensitiveURL(rawURL, placeholder)
+}
+
+func TestRedactURL(t *testing.T) {
+	registerTestURL(t, "https://www.pixiv.net/en/artworks/115365120", "[token-init artwork]")
+
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			"Exact URL replaced by the placeholder",
+			"https://www.pixiv.net/en/artworks/115365120",
+			"[token-init artwork]",
+		},
+		{
+			"ID masked inside other URLs",
+			"https://www.pixiv.net/ajax/illust/115365120",
+			"https://www.pixiv.net/ajax/illust/[redacted]",
+		},
+		{
+			"Unrelated URLs pass through",
+			"https://www.pixiv.net/ajax/illust/987654",
+			"https://www.pixiv.net/ajax/illust/987654",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := RedactURL(tc.url); got != tc.want {
+				t.Errorf("RedactURL(%q) = %q, want %q", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSpanLog_RedactsSensitiveURL(t *testing.T) {
+	registerTestURL(t, "https://www.pixiv.net/en/artworks/115365120", "[token-init artwork]")
+
+	var buf bytes.Buffer
+
+	origLogger := log.Logger
+	origLevel := zerolog.GlobalLevel()
+
+	t.Cleanup(func() {
+		log.Logger = origLogger
+		zerolog.SetGlobalLevel(origLevel)
+	})
+
+	log.Logger = zerolog.New(&buf)
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+
+	span := Span{
+		Destination: ToPixiv,
+		Method:      http.MethodGet,
+		URL:         "https://www.pixiv.net/en/artworks/115365120",
+		StatusCode:  http.StatusOK,
+	}
+	span.Log()
+
+	if got := buf.String(); strings.Contains(got, "115365120") {
+		t.Errorf("Span.Log() output exposes the sensitive URL: %s", got)
+	}
+
+	if got := buf.String(); !strings.Contains(got, "[token-init artwork]") {
+		t.Errorf("Span.Log() output is missing the placeholder: %s", got)
+	}
+}
+
+func TestServerTimingName_RedactsSensitiveURL(t *testing.T) {
+	registerTestURL(t, "https://www.pixiv.net/en/artworks/115365120", "[token-init artwork]")
+
+	span := Span{
+		Destination: ToPixiv,
+		Method:      http.MethodGet,
+		URL:         "https://www.pixiv.net/en/artworks/115365120",
+	}
+
+	encodedID := base64.RawURLEncoding.EncodeToString([]byte(span.URL))
+	if name := span.ServerTimingName(); strings.Contains(name, encodedID) {
+		t.Errorf("ServerTimingName() = %q, want the URL redacted before encoding", name)
+	}
+}
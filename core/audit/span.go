@@ -59,7 +59,7 @@ var (
 func (span Span) ServerTimingName() string {
 	// must obey naming in docs/dev/server-timing.md
 	// base64 without trailing '=' match the syntax
-	return string(span.Destination) + "$" + span.Method + "$" + base64.RawURLEncoding.EncodeToString([]byte(span.URL))
+	return string(span.Destination) + "$" + span.Method + "$" + base64.RawURLEncoding.EncodeToString([]byte(RedactURL(span.URL)))
 }
 
 func (span *Span) Begin(ctx context.Context) context.Context {
@@ -108,7 +108,7 @@ func (span Span) Log() {
 
 	event.Str("sys", "http")
 	event.Str("method", span.Method)
-	event.Str("url", span.URL)
+	event.Str("url", RedactURL(span.URL))
 	event.Int("status_code", span.StatusCode)
 	event.Str("len", humanizeSize(len(span.Body)))
 	event.Dur("dur", span.duration)
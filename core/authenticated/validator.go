@@ -14,6 +14,8 @@ func NewSecretKeyHex() string {
 // v4.public validator
 type Validator struct {
 	SecretKey paseto.V4AsymmetricSecretKey
+
+	loaded bool
 }
 
 func (psk *Validator) LoadSecretKeyFromHex(hex string) (err error) {
@@ -22,9 +24,17 @@ func (psk *Validator) LoadSecretKeyFromHex(hex string) (err error) {
 		return
 	}
 	// public key can be derived efficiently from SecretKey, so it's not calculated here
+	psk.loaded = true
+
 	return
 }
 
+// HasSecretKey reports whether a secret key has been loaded,
+// i.e. whether tokens can be signed and verified.
+func (psk *Validator) HasSecretKey() bool {
+	return psk.loaded
+}
+
 // TODO: more functions that isn't tied to a global state can be moved here.
 // hint: add a helper function to the package 'request_context' to quickly create a sign a token.
 
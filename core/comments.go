@@ -30,6 +30,13 @@ type ArtworkCommentsParams struct {
 
 	// SanityLevel determines the content filtering level for artworks.
 	SanityLevel SanityLevel
+
+	// Offset is the number of root comments to skip.
+	Offset int
+
+	// Limit caps the number of root comments fetched.
+	// A non-positive limit fetches every root comment.
+	Limit int
 }
 
 // NovelCommentsParams holds the parameters required to fetch novel comments.
@@ -42,6 +49,13 @@ type NovelCommentsParams struct {
 
 	// XRestrict determines the content filtering level for novels.
 	XRestrict XRestrict
+
+	// Offset is the number of root comments to skip.
+	Offset int
+
+	// Limit caps the number of root comments fetched.
+	// A non-positive limit fetches every root comment.
+	Limit int
 }
 
 // CommentsData is a container for the fetched comments and their total count.
@@ -49,8 +63,43 @@ type CommentsData struct {
 	// Comments is a slice of root-level comments, each potentially containing replies.
 	Comments []*Comment
 
-	// Count is the total number of comments, including replies.
+	// Count is the total number of comments fetched, including replies.
 	Count int
+
+	// HasNext indicates that more root comments exist past the fetched window.
+	HasNext bool
+}
+
+// CommentCounts reconciles the comment total a work reports with the
+// comments actually fetched for display.
+//
+// The two regularly disagree: pixiv's commentCount includes deleted and
+// hidden comments that the comments endpoints never return.
+type CommentCounts struct {
+	// Reported is the total claimed by the work's metadata.
+	Reported int
+
+	// Rendered is the number of fetched comments, including replies.
+	Rendered int
+
+	// Mismatch indicates the counts disagree, letting the UI render
+	// "showing N of M" instead of a misleading total.
+	Mismatch bool
+}
+
+// ReconcileCommentCounts compares a work's reported comment total against the
+// fetched comment data. Nil data counts as zero rendered comments.
+func ReconcileCommentCounts(reported int, data *CommentsData) CommentCounts {
+	var rendered int
+	if data != nil {
+		rendered = data.Count
+	}
+
+	return CommentCounts{
+		Reported: reported,
+		Rendered: rendered,
+		Mismatch: rendered != reported,
+	}
 }
 
 // Comment represents a single comment or reply on a work.
@@ -220,16 +269,23 @@ type commentsRootsResponse struct {
 	HasNext bool `json:"hasNext"`
 }
 
-// urlFunc is a function type that generates a URL for a specific page of comments or replies.
+// urlFunc is a function type that generates a URL for a specific page of replies.
 type urlFunc func(id string, page int) string
 
-// GetArtworkComments fetches and processes all comments for a given artwork.
+// rootsURLFunc is a function type that generates a URL for a window of root comments.
+type rootsURLFunc func(id string, offset, limit int) string
+
+// GetArtworkComments fetches and processes comments for a given artwork.
 //
 // It returns the structured comment data, performance timings, and any error encountered.
 func GetArtworkComments(r *http.Request, params ArtworkCommentsParams) (*CommentsData, []utils.Timing, error) {
 	noToken := params.SanityLevel <= SLSafe
 
-	comments, commentTimings, err := getComments(r, params.ID, noToken, params.UserID, GetArtworkCommentsURL, GetArtworkCommentRepliesURL)
+	comments, hasNext, commentTimings, err := getComments(
+		r, params.ID, noToken, params.UserID,
+		GetArtworkCommentsURL, GetArtworkCommentRepliesURL,
+		params.Offset, params.Limit,
+	)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -237,16 +293,21 @@ func GetArtworkComments(r *http.Request, params ArtworkCommentsParams) (*Comment
 	return &CommentsData{
 		Comments: comments,
 		Count:    countCommentsAndReplies(comments),
+		HasNext:  hasNext,
 	}, commentTimings, nil
 }
 
-// GetNovelComments fetches and processes all comments for a given novel.
+// GetNovelComments fetches and processes comments for a given novel.
 //
 // It returns the structured comment data, performance timings, and any error encountered.
 func GetNovelComments(r *http.Request, params NovelCommentsParams) (*CommentsData, []utils.Timing, error) {
 	noToken := params.XRestrict < 1
 
-	comments, commentTimings, err := getComments(r, params.ID, noToken, params.UserID, GetNovelCommentsURL, GetNovelCommentRepliesURL)
+	comments, hasNext, commentTimings, err := getComments(
+		r, params.ID, noToken, params.UserID,
+		GetNovelCommentsURL, GetNovelCommentRepliesURL,
+		params.Offset, params.Limit,
+	)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -254,21 +315,24 @@ func GetNovelComments(r *http.Request, params NovelCommentsParams) (*CommentsDat
 	return &CommentsData{
 		Comments: comments,
 		Count:    countCommentsAndReplies(comments),
+		HasNext:  hasNext,
 	}, commentTimings, nil
 }
 
 // getComments provides the generic logic for fetching and processing comments.
 //
-// It first fetches all pages of root comments. Then, for each root comment
-// that has replies, it concurrently fetches all pages of its replies.
+// It first fetches the requested window of root comments. Then, for each root
+// comment that has replies, it concurrently fetches all pages of its replies.
+// The second result reports whether more root comments exist past the window.
 func getComments(
 	r *http.Request,
 	workID string,
 	noToken bool,
 	workUserID string,
-	getCommentsURL urlFunc,
+	getCommentsURL rootsURLFunc,
 	getRepliesURL urlFunc,
-) ([]*Comment, []utils.Timing, error) {
+	offset, limit int,
+) ([]*Comment, bool, []utils.Timing, error) {
 	timings := make([]utils.Timing, 0)
 	start := time.Now()
 
@@ -282,12 +346,12 @@ func getComments(
 		})
 	}()
 
-	// Fetch all root comments.
+	// Fetch the requested window of root comments.
 	rootFetchStart := time.Now()
 
-	allComments, err := fetchPaginatedComments(r, workID, noToken, getCommentsURL, 0)
+	allComments, hasNext, err := fetchCommentRoots(r, workID, noToken, getCommentsURL, offset, limit)
 	if err != nil {
-		return nil, timings, err
+		return nil, false, timings, err
 	}
 
 	timings = append(timings, utils.Timing{
@@ -307,6 +371,10 @@ func getComments(
 			rootComment.Comment = parseEmojis(rootComment.Comment)
 			rootComment.processStamp(r)
 
+			if rootComment.Img == "" {
+				rootComment.Img = FallbackAvatarURL(r)
+			}
+
 			// If the root comment has replies, fetch and process them.
 			if !rootComment.HasReplies {
 				return nil
@@ -324,6 +392,10 @@ func getComments(
 				reply.WorkUserID = workUserID
 				reply.Comment = parseEmojis(reply.Comment)
 				reply.processStamp(r)
+
+				if reply.Img == "" {
+					reply.Img = FallbackAvatarURL(r)
+				}
 			}
 
 			// Reverse the replies for chronological display, as the API returns them newest-first.
@@ -336,7 +408,7 @@ func getComments(
 	}
 
 	if err := g.Wait(); err != nil {
-		return nil, timings, err
+		return nil, false, timings, err
 	}
 
 	timings = append(timings, utils.Timing{
@@ -345,10 +417,85 @@ func getComments(
 		Description: "Concurrent processing of all comments and replies",
 	})
 
-	return allComments, timings, nil
+	return allComments, hasNext, timings, nil
+}
+
+// fetchCommentsPage performs a single comments API request and decodes the response.
+func fetchCommentsPage(r *http.Request, url string, noToken bool) (commentsRootsResponse, error) {
+	var (
+		data    commentsRootsResponse
+		cookies map[string]string
+	)
+
+	if noToken {
+		cookies = map[string]string{"PHPSESSID": requests.NoToken}
+	} else {
+		cookies = map[string]string{"PHPSESSID": untrusted.GetUserToken(r)}
+	}
+
+	resp, err := requests.GetJSONBody(
+		r.Context(),
+		url,
+		cookies,
+		r.Header,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	if err := json.Unmarshal(RewriteEscapedImageURLs(r, resp), &data); err != nil {
+		return data, err
+	}
+
+	return data, nil
 }
 
-// fetchPaginatedComments fetches all pages of comments from a given endpoint.
+// fetchCommentRoots fetches root comments starting at offset.
+//
+// A positive limit bounds the fetch to a single batch of that size; a
+// non-positive limit keeps fetching page-sized batches until the API reports
+// no further pages. The second result reports whether more root comments
+// remain past the returned window.
+func fetchCommentRoots(
+	r *http.Request,
+	id string,
+	noToken bool,
+	urlFn rootsURLFunc,
+	offset, limit int,
+) ([]*Comment, bool, error) {
+	if limit > 0 {
+		data, err := fetchCommentsPage(r, urlFn(id, offset, limit), noToken)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return data.Comments, data.HasNext, nil
+	}
+
+	var comments []*Comment
+
+	hasNext := true
+
+	for hasNext {
+		data, err := fetchCommentsPage(r, urlFn(id, offset+len(comments), CommentsPageSize), noToken)
+		if err != nil {
+			return nil, false, err
+		}
+
+		comments = append(comments, data.Comments...)
+		hasNext = data.HasNext
+
+		// Guard against an API that keeps reporting more pages without
+		// returning any comments.
+		if len(data.Comments) == 0 {
+			break
+		}
+	}
+
+	return comments, false, nil
+}
+
+// fetchPaginatedComments fetches all pages of replies from a given endpoint.
 //
 // It starts from the page number specified by startPage and continues to fetch
 // subsequent pages until the API response indicates that no more pages are
@@ -366,28 +513,7 @@ func fetchPaginatedComments(
 	hasNext := true
 
 	for hasNext {
-		var (
-			data    commentsRootsResponse
-			cookies map[string]string
-		)
-
-		if noToken {
-			cookies = map[string]string{"PHPSESSID": requests.NoToken}
-		} else {
-			cookies = map[string]string{"PHPSESSID": untrusted.GetUserToken(r)}
-		}
-
-		resp, err := requests.GetJSONBody(
-			r.Context(),
-			urlFn(id, page),
-			cookies,
-			r.Header,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		err = json.Unmarshal(RewriteEscapedImageURLs(r, resp), &data)
+		data, err := fetchCommentsPage(r, urlFn(id, page), noToken)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,317 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// commentsStubTransport serves a fixed pool of root comments, honouring the
+// offset/limit query parameters up to pageCap entries per request.
+type commentsStubTransport struct {
+	requests []string
+	total    int
+	pageCap  int
+}
+
+func (t *commentsStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req.URL.String())
+
+	query := req.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	if t.pageCap > 0 && limit > t.pageCap {
+		limit = t.pageCap
+	}
+
+	end := offset + limit
+	if end > t.total {
+		end = t.total
+	}
+
+	items := make([]string, 0, end-offset)
+	for i := offset; i < end; i++ {
+		items = append(items,
+			fmt.Sprintf(`{"id":"%d","comment":"comment %d","commentDate":"2024-01-01 00:00"}`, i, i))
+	}
+
+	body := fmt.Sprintf(`{"error":false,"body":{"comments":[%s],"hasNext":%t}}`,
+		strings.Join(items, ","), end < t.total)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// setupCommentsTest installs a stub upstream and a token manager, restoring
+// both when the test completes.
+func setupCommentsTest(t *testing.T, transport *commentsStubTransport) {
+	t.Helper()
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+}
+
+func TestFetchCommentRoots_Paged(t *testing.T) {
+	transport := &commentsStubTransport{total: 5}
+	setupCommentsTest(t, transport)
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/123", nil)
+
+	comments, hasNext, err := fetchCommentRoots(r, "123", true, GetArtworkCommentsURL, 0, 2)
+	if err != nil {
+		t.Fatalf("fetchCommentRoots() error: %v", err)
+	}
+
+	if len(comments) != 2 || comments[0].ID != "0" || comments[1].ID != "1" {
+		t.Fatalf("fetchCommentRoots() = %+v, want comments 0 and 1", comments)
+	}
+
+	if !hasNext {
+		t.Error("hasNext = false, want true with comments remaining")
+	}
+
+	if len(transport.requests) != 1 || !strings.Contains(transport.requests[0], "offset=0&limit=2") {
+		t.Errorf("requests = %v, want a single fetch with offset=0&limit=2", transport.requests)
+	}
+
+	// The final window reports no further comments.
+	comments, hasNext, err = fetchCommentRoots(r, "123", true, GetArtworkCommentsURL, 4, 2)
+	if err != nil {
+		t.Fatalf("fetchCommentRoots() error: %v", err)
+	}
+
+	if len(comments) != 1 || comments[0].ID != "4" {
+		t.Fatalf("fetchCommentRoots() = %+v, want the final comment", comments)
+	}
+
+	if hasNext {
+		t.Error("hasNext = true, want false at the end of the comments")
+	}
+}
+
+func TestFetchCommentRoots_FetchAll(t *testing.T) {
+	// Serve at most two comments per request so fetching everything requires
+	// multiple pages.
+	transport := &commentsStubTransport{total: 5, pageCap: 2}
+	setupCommentsTest(t, transport)
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/123", nil)
+
+	comments, hasNext, err := fetchCommentRoots(r, "123", true, GetArtworkCommentsURL, 0, 0)
+	if err != nil {
+		t.Fatalf("fetchCommentRoots() error: %v", err)
+	}
+
+	if len(comments) != 5 {
+		t.Fatalf("fetchCommentRoots() returned %d comments, want all 5", len(comments))
+	}
+
+	if hasNext {
+		t.Error("hasNext = true, want false after fetching every comment")
+	}
+
+	if len(transport.requests) != 3 {
+		t.Errorf("upstream requests = %d, want 3 paged fetches:\n%v", len(transport.requests), transport.requests)
+	}
+}
+
+func TestGetArtworkComments_Paged(t *testing.T) {
+	transport := &commentsStubTransport{total: 5}
+	setupCommentsTest(t, transport)
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/123", nil)
+
+	data, _, err := GetArtworkComments(r, ArtworkCommentsParams{
+		ID:     "123",
+		UserID: "456",
+		Offset: 2,
+		Limit:  2,
+	})
+	if err != nil {
+		t.Fatalf("GetArtworkComments() error: %v", err)
+	}
+
+	if data.Count != 2 {
+		t.Errorf("Count = %d, want 2", data.Count)
+	}
+
+	if !data.HasNext {
+		t.Error("HasNext = false, want true with comments remaining")
+	}
+}
+
+func TestReconcileCommentCounts(t *testing.T) {
+	threeComments := &CommentsData{
+		Comments: []*Comment{
+			{ID: "1", Replies: []*Comment{{ID: "2"}}},
+			{ID: "3"},
+		},
+		Count: 3,
+	}
+
+	cases := []struct {
+		name     string
+		reported int
+		data     *CommentsData
+		want     CommentCounts
+	}{
+		{
+			"Counts agree",
+			3, threeComments,
+			CommentCounts{Reported: 3, Rendered: 3},
+		},
+		{
+			"Deleted comments inflate the reported total",
+			5, threeComments,
+			CommentCounts{Reported: 5, Rendered: 3, Mismatch: true},
+		},
+		{
+			"No fetched data with no reported comments",
+			0, nil,
+			CommentCounts{},
+		},
+		{
+			"No fetched data despite reported comments",
+			4, nil,
+			CommentCounts{Reported: 4, Mismatch: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ReconcileCommentCounts(tc.reported, tc.data); got != tc.want {
+				t.Errorf("ReconcileCommentCounts(%d, ...) = %+v, want %+v", tc.reported, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIllustCommentCounts(t *testing.T) {
+	illust := &Illust{
+		Comments: 5,
+		CommentsData: &CommentsData{
+			Comments: []*Comment{{ID: "1"}},
+			Count:    1,
+		},
+	}
+
+	got := illust.CommentCounts()
+	if got.Reported != 5 || got.Rendered != 1 || !got.Mismatch {
+		t.Errorf("CommentCounts() = %+v, want showing 1 of 5 with a mismatch", got)
+	}
+}
+
+// commentsRepliesStubTransport serves a single root comment that has replies,
+// with the replies spread over two pages and returned newest-first as pixiv
+// does.
+type commentsRepliesStubTransport struct{}
+
+func (commentsRepliesStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body string
+
+	switch {
+	case strings.Contains(req.URL.Path, "/comments/roots"):
+		body = `{"error":false,"body":{"comments":[
+			{"id":"10","comment":"root (happy)","commentDate":"2024-01-01 00:00","hasReplies":true}
+		],"hasNext":false}}`
+	case req.URL.Query().Get("page") == "1":
+		body = `{"error":false,"body":{"comments":[
+			{"id":"12","comment":"newer reply","commentDate":"2024-01-03 00:00","commentRootId":"10"}
+		],"hasNext":true}}`
+	default:
+		body = `{"error":false,"body":{"comments":[
+			{"id":"11","comment":"","stampId":"409","commentDate":"2024-01-02 00:00","commentRootId":"10"}
+		],"hasNext":false}}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetArtworkComments_RepliesThreaded(t *testing.T) {
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Static = url.URL{Path: "/proxy/s.pximg.net"}
+
+	utils.HTTPClient.Transport = commentsRepliesStubTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/123", nil)
+
+	data, _, err := GetArtworkComments(r, ArtworkCommentsParams{ID: "123", UserID: "456", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetArtworkComments() error: %v", err)
+	}
+
+	// One root plus its two replies.
+	if data.Count != 3 {
+		t.Errorf("Count = %d, want 3 including replies", data.Count)
+	}
+
+	if len(data.Comments) != 1 {
+		t.Fatalf("got %d root comments, want 1", len(data.Comments))
+	}
+
+	root := data.Comments[0]
+
+	// Emoji shortcodes in the root keep being rendered as image tags.
+	if !strings.Contains(root.Comment, "/common/images/emoji/105.png") {
+		t.Errorf("root.Comment = %q, want the (happy) emoji rendered", root.Comment)
+	}
+
+	// Replies span both pages and come back in chronological order.
+	if len(root.Replies) != 2 {
+		t.Fatalf("got %d replies, want 2 across both pages", len(root.Replies))
+	}
+
+	if root.Replies[0].ID != "11" || root.Replies[1].ID != "12" {
+		t.Errorf("reply order = %s, %s; want 11 then 12 (oldest first)",
+			root.Replies[0].ID, root.Replies[1].ID)
+	}
+
+	// Stamp replies keep being converted to proxied images.
+	if !strings.Contains(root.Replies[0].Comment, `/common/images/stamp/generated-stamps/409_s.jpg`) {
+		t.Errorf("stamp reply = %q, want a proxied stamp image tag", root.Replies[0].Comment)
+	}
+}
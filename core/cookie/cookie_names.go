@@ -32,6 +32,7 @@ const (
 	UgoiraProxyCookie            CookieName = "UgoiraProxy"
 	NovelFontTypeCookie          CookieName = "NovelFontType"
 	NovelViewModeCookie          CookieName = "NovelViewMode"
+	NovelCollapseBlanksCookie    CookieName = "NovelCollapseBlanks"
 	ThumbnailToNewTabCookie      CookieName = "ThumbnailToNewTab"
 	SeasonalEffectsEnabledCookie CookieName = "SeasonalEffectsEnabled"
 	VisibilityArtR18Cookie       CookieName = "VisibilityArtR18"
@@ -47,6 +48,8 @@ const (
 	DesktopSidebarHiddenCookie   CookieName = "DesktopSidebarHidden"
 	BookmarkDefaultPrivateCookie CookieName = "BookmarkDefaultPrivate"
 	FilterProfileCookie          CookieName = "FilterProfile"
+	MinimalModeCookie            CookieName = "MinimalMode"
+	OriginalImagesCookie         CookieName = "OriginalImages"
 )
 
 // AllCookieNames defines all cookies that can be set by the user.
@@ -65,6 +68,7 @@ var AllCookieNames = []CookieName{
 	UgoiraProxyCookie,
 	NovelFontTypeCookie,
 	NovelViewModeCookie,
+	NovelCollapseBlanksCookie,
 	ThumbnailToNewTabCookie,
 	SeasonalEffectsEnabledCookie,
 	VisibilityArtR18Cookie,
@@ -80,4 +84,6 @@ var AllCookieNames = []CookieName{
 	DesktopSidebarHiddenCookie,
 	BookmarkDefaultPrivateCookie,
 	FilterProfileCookie,
+	MinimalModeCookie,
+	OriginalImagesCookie,
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/tidwall/gjson"
 
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
 	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
 	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
 )
@@ -61,6 +62,62 @@ func GetDiscoveryArtworks(r *http.Request, mode string) ([]ArtworkItem, error) {
 	return artworks, nil
 }
 
+// GetDiscovery retrieves the discovery recommendation feed, dropping works
+// hidden by the request's filter profile.
+//
+// mode is normalised to the safe/all/r18 values shared with SearchFilterMode,
+// and limit is clamped to discoveryArtworksLimit when out of range. The
+// endpoint returns a changing random set, so the request layer never caches
+// its responses (see excludedCachePaths in core/requests).
+func GetDiscovery(r *http.Request, mode string, limit int) ([]ArtworkItem, error) {
+	switch SearchFilterMode(mode) {
+	case SearchFilterModeSafe, SearchFilterModeAll, SearchFilterModeR18:
+	default:
+		mode = SearchFilterModeSafe
+	}
+
+	if limit < 1 || limit > discoveryArtworksLimit {
+		limit = discoveryArtworksLimit
+	}
+
+	var artworks []ArtworkItem
+
+	resp, err := requests.GetJSONBody(
+		r.Context(),
+		GetDiscoveryURL(mode, limit),
+		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+		r.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	// We only want the "thumbnails.illust" field
+	err = json.Unmarshal([]byte(gjson.GetBytes(RewriteEscapedImageURLs(r, resp), "thumbnails.illust").Raw), &artworks)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies := map[cookie.CookieName]string{
+		cookie.FilterProfileCookie: untrusted.GetCookie(r, cookie.FilterProfileCookie),
+	}
+
+	visible := make([]ArtworkItem, 0, len(artworks))
+
+	for _, artwork := range artworks {
+		if artwork.ShouldHide(cookies) {
+			continue
+		}
+
+		if err := artwork.PopulateThumbnails(); err != nil {
+			return nil, fmt.Errorf("failed to populate thumbnails for artwork ID %s: %w", artwork.ID, err)
+		}
+
+		visible = append(visible, artwork)
+	}
+
+	return visible, nil
+}
+
 func GetDiscoveryNovels(r *http.Request, mode string) ([]*NovelBrief, error) {
 	var novels []*NovelBrief
 
@@ -0,0 +1,130 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// discoveryFixture carries two works by different artists so blacklist
+// filtering leaves exactly one.
+const discoveryFixture = `{"error":false,"body":{
+	"thumbnails":{"illust":[
+		{"id":"3001","title":"Kept work","userId":"100","xRestrict":0,
+			"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/03\/03\/00\/00\/00\/3001_p0_square1200.jpg"},
+		{"id":"3002","title":"Blacklisted work","userId":"666","xRestrict":0,
+			"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/03\/03\/00\/00\/00\/3002_p0_square1200.jpg"}
+	]}
+}}`
+
+// discoveryStubTransport serves the discovery fixture and records the last
+// requested URL.
+type discoveryStubTransport struct {
+	lastURL string
+}
+
+func (t *discoveryStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.lastURL = req.URL.String()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(discoveryFixture)),
+		Request:    req,
+	}, nil
+}
+
+func setupDiscoveryTest(t *testing.T) *discoveryStubTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+
+	transport := &discoveryStubTransport{}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	return transport
+}
+
+func TestGetDiscovery(t *testing.T) {
+	transport := setupDiscoveryTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/discovery", nil)
+
+	works, err := GetDiscovery(r, "safe", 30)
+	if err != nil {
+		t.Fatalf("GetDiscovery() error: %v", err)
+	}
+
+	if len(works) != 2 {
+		t.Fatalf("GetDiscovery() returned %d works, want 2 with the default profile", len(works))
+	}
+
+	for i, work := range works {
+		if !strings.HasPrefix(work.Thumbnails.Webp_1200, "/proxy/i.pximg.net/") {
+			t.Errorf("works[%d].Thumbnails.Webp_1200 = %q, want a proxied URL", i, work.Thumbnails.Webp_1200)
+		}
+	}
+
+	if !strings.Contains(transport.lastURL, "mode=safe") || !strings.Contains(transport.lastURL, "limit=30") {
+		t.Errorf("request URL = %q, want mode=safe and limit=30", transport.lastURL)
+	}
+
+	// An unknown mode falls back to safe, and an out-of-range limit is
+	// clamped to the discovery page size.
+	if _, err := GetDiscovery(r, "everything", 500); err != nil {
+		t.Fatalf("GetDiscovery() error: %v", err)
+	}
+
+	if !strings.Contains(transport.lastURL, "mode=safe") || !strings.Contains(transport.lastURL, "limit=60") {
+		t.Errorf("request URL = %q, want the normalised mode=safe and limit=60", transport.lastURL)
+	}
+}
+
+func TestGetDiscovery_BlacklistedArtist(t *testing.T) {
+	setupDiscoveryTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/discovery", nil)
+	r.AddCookie(&http.Cookie{
+		Name: string(cookie.FilterProfileCookie),
+		Value: url.QueryEscape(
+			`{"v":1,"r15":"show","r18":"hide","r18g":"hide","ai":"show","blacklisted_artists":["666"]}`),
+	})
+
+	works, err := GetDiscovery(r, "safe", 0)
+	if err != nil {
+		t.Fatalf("GetDiscovery() error: %v", err)
+	}
+
+	if len(works) != 1 {
+		t.Fatalf("GetDiscovery() returned %d works, want 1 with artist 666 blacklisted", len(works))
+	}
+
+	if works[0].ID != "3001" {
+		t.Errorf("remaining work = %s, want 3001", works[0].ID)
+	}
+}
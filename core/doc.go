@@ -18,7 +18,7 @@ You may use this package independently as follows:
 	)
 
 	func main() {
-		tokenmanager.DefaultTokenManager = tokenmanager.NewTokenManager(
+		tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
 			[]string{"YOUR_TOKEN_HERE"},
 			5,
 			1000*time.Millisecond,
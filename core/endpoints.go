@@ -13,15 +13,17 @@ import (
 )
 
 const (
-	BookmarksPageSize       = 48 // For both illustrations and novels
-	UserFollowersPageSize   = 100
-	ArtworkCommentsPageSize = 1000
-	NovelCommentsPageSize   = 1000
+	BookmarksPageSize     = 48 // For both illustrations and novels
+	UserFollowersPageSize = 100
+	CommentsPageSize      = 1000 // Batch size for both artwork and novel comments
 )
 
 var (
-	errSearchNameEmpty     = errors.New("search name cannot be empty")
-	errSearchCategoryEmpty = errors.New("search category cannot be empty")
+	errSearchNameEmpty            = errors.New("search name cannot be empty")
+	errSearchCategoryEmpty        = errors.New("search category cannot be empty")
+	errSearchInvalidSmode         = errors.New("invalid search match mode")
+	errSearchTitleMatchExclusions = errors.New("exclusion terms cannot be combined with title/caption matching")
+	errSearchInvalidAIType        = errors.New("invalid AI work filter")
 )
 
 // GET endpoints
@@ -56,6 +58,10 @@ func GetDiscoveryUserURL(limit int) string {
 	return fmt.Sprintf(base, limit)
 }
 
+func GetTrendingTagsURL() string {
+	return "https://www.pixiv.net/ajax/trending-tags/illust"
+}
+
 func GetRankingURL(mode, contentType, date, page string) string {
 	params := url.Values{}
 	params.Add("mode", mode)
@@ -109,10 +115,16 @@ func GetUserFullNovelURL(userID, novelIDs string) string {
 	return fmt.Sprintf(base, userID, novelIDs)
 }
 
-func GetUserIllustBookmarksURL(userID, mode string, page int) string {
-	base := "https://www.pixiv.net/ajax/user/%s/illusts/bookmarks?tag=&offset=%d&limit=48&rest=%s"
+func GetUserIllustBookmarksURL(userID, tag, mode string, page int) string {
+	base := "https://www.pixiv.net/ajax/user/%s/illusts/bookmarks?tag=%s&offset=%d&limit=48&rest=%s"
 
-	return fmt.Sprintf(base, userID, page*BookmarksPageSize, mode)
+	return fmt.Sprintf(base, userID, url.QueryEscape(tag), page*BookmarksPageSize, mode)
+}
+
+func GetUserIllustBookmarkTagsURL(userID string) string {
+	base := "https://www.pixiv.net/ajax/user/%s/illusts/bookmark/tags"
+
+	return fmt.Sprintf(base, userID)
 }
 
 func GetUserNovelBookmarksURL(userID, mode string, page int) string {
@@ -175,19 +187,50 @@ func GetArtworkImagesURL(illustID string) string {
 	return fmt.Sprintf(base, illustID)
 }
 
+func GetUgoiraMetaURL(illustID string) string {
+	base := "https://www.pixiv.net/ajax/illust/%s/ugoira_meta"
+
+	return fmt.Sprintf(base, illustID)
+}
+
 func GetArtworkRelatedURL(illustID string, limit int) string {
 	base := "https://www.pixiv.net/ajax/illust/%s/recommend/init?limit=%d"
 
 	return fmt.Sprintf(base, illustID, limit)
 }
 
+// GetArtworkRelatedContinueURL resolves a batch of recommendation IDs returned
+// as nextIds by a previous related-works response.
+func GetArtworkRelatedContinueURL(illustIDs []string) string {
+	params := url.Values{}
+
+	for _, id := range illustIDs {
+		params.Add("illust_ids[]", id)
+	}
+
+	return "https://www.pixiv.net/ajax/illust/recommend/illusts?" + params.Encode()
+}
+
+// GetArtworksBatchURL resolves a set of illust IDs to full artwork data in a
+// single call. The number of IDs per call is capped upstream; see
+// artworksBatchChunkSize.
+func GetArtworksBatchURL(illustIDs []string) string {
+	params := url.Values{}
+
+	for _, id := range illustIDs {
+		params.Add("illust_ids[]", id)
+	}
+
+	return "https://www.pixiv.net/ajax/illusts?" + params.Encode()
+}
+
 // Retrieves the comments for a given illustration ID.
 //
 // Unlike other endpoints, the limit parameter doesn't seem to have a maximum.
-func GetArtworkCommentsURL(illustID string, page int) string {
-	base := "https://www.pixiv.net/ajax/illusts/comments/roots?illust_id=%s&offset=%d&limit=1000"
+func GetArtworkCommentsURL(illustID string, offset, limit int) string {
+	base := "https://www.pixiv.net/ajax/illusts/comments/roots?illust_id=%s&offset=%d&limit=%d"
 
-	return fmt.Sprintf(base, illustID, page*ArtworkCommentsPageSize)
+	return fmt.Sprintf(base, illustID, offset, limit)
 }
 
 // Retrieves the replies for a given comment ID.
@@ -202,10 +245,10 @@ func GetArtworkCommentRepliesURL(illustID string, page int) string {
 // Retrieves the comments for a given novel ID.
 //
 // Unlike other endpoints, the limit parameter doesn't seem to have a maximum.
-func GetNovelCommentsURL(novelID string, page int) string {
-	base := "https://www.pixiv.net/ajax/novels/comments/roots?novel_id=%s&offset=%d&limit=1000"
+func GetNovelCommentsURL(novelID string, offset, limit int) string {
+	base := "https://www.pixiv.net/ajax/novels/comments/roots?novel_id=%s&offset=%d&limit=%d"
 
-	return fmt.Sprintf(base, novelID, page*NovelCommentsPageSize)
+	return fmt.Sprintf(base, novelID, offset, limit)
 }
 
 // Retrieves the replies for a given comment ID.
@@ -248,6 +291,29 @@ func GetArtworkSearchURL(settings WorkSearchSettings) (string, error) {
 		return "", errSearchCategoryEmpty
 	}
 
+	// Smode maps to pixiv's s_mode parameter: SearchSmodePartial ("s_tag"),
+	// SearchSmodeExact ("s_tag_full"), or SearchSmodeTitle ("s_tc").
+	switch settings.Smode {
+	case "", SearchSmodePartial, SearchSmodeExact, SearchSmodeTitle:
+	default:
+		return "", fmt.Errorf("%w: %q", errSearchInvalidSmode, settings.Smode)
+	}
+
+	// AIType maps to pixiv's ai_type parameter; SearchAITypeExclude ("1") is
+	// the only value the search API understands.
+	switch settings.AIType {
+	case "", SearchAITypeExclude:
+	default:
+		return "", fmt.Errorf("%w: %q", errSearchInvalidAIType, settings.AIType)
+	}
+
+	// Exclusions ("-keyword") only apply to tag matching; pixiv silently
+	// ignores them under title/caption search, so reject the combination
+	// instead of returning misleading results.
+	if _, excluded := SplitSearchTerms(settings.Name); len(excluded) > 0 && settings.Smode == SearchSmodeTitle {
+		return "", fmt.Errorf("%w: %q", errSearchTitleMatchExclusions, excluded)
+	}
+
 	u := &url.URL{
 		Scheme: "https",
 		Host:   "www.pixiv.net",
@@ -274,18 +340,19 @@ func GetArtworkSearchURL(settings WorkSearchSettings) (string, error) {
 
 	// Add all optional query parameters.
 	params := map[string]string{
-		"p":      settings.Page,
-		"order":  settings.Order,
-		"mode":   settings.Mode,
-		"ratio":  settings.Ratio,
-		"s_mode": settings.Smode,
-		"wlt":    settings.Wlt,
-		"wgt":    settings.Wgt,
-		"hlt":    settings.Hlt,
-		"hgt":    settings.Hgt,
-		"tool":   settings.Tool,
-		"scd":    settings.Scd,
-		"ecd":    settings.Ecd,
+		"p":       settings.Page,
+		"order":   settings.Order,
+		"mode":    settings.Mode,
+		"ratio":   settings.Ratio,
+		"s_mode":  settings.Smode,
+		"wlt":     settings.Wlt,
+		"wgt":     settings.Wgt,
+		"hlt":     settings.Hlt,
+		"hgt":     settings.Hgt,
+		"tool":    settings.Tool,
+		"scd":     settings.Scd,
+		"ecd":     settings.Ecd,
+		"ai_type": settings.AIType,
 	}
 
 	for key, value := range params {
@@ -168,6 +168,79 @@ func TestGetArtworkSearchURL(t *testing.T) {
 			want:    "https://www.pixiv.net/ajax/search/artworks/tag?mode=++&word=tag",
 			wantErr: false,
 		},
+		{
+			name: "exclusion terms are forwarded verbatim",
+			settings: core.WorkSearchSettings{
+				Name:     "cat -dog",
+				Category: "artworks",
+				Smode:    core.SearchSmodePartial,
+			},
+			// Exclusions stay inside the word parameter, prefix included;
+			// pixiv interprets the "-" itself.
+			want:    "https://www.pixiv.net/ajax/search/artworks/cat%20-dog?s_mode=s_tag&word=cat+-dog",
+			wantErr: false,
+		},
+		{
+			name: "exact tag match mode",
+			settings: core.WorkSearchSettings{
+				Name:     "cat",
+				Category: "artworks",
+				Smode:    core.SearchSmodeExact,
+			},
+			want:    "https://www.pixiv.net/ajax/search/artworks/cat?s_mode=s_tag_full&word=cat",
+			wantErr: false,
+		},
+		{
+			name: "title and caption match mode",
+			settings: core.WorkSearchSettings{
+				Name:     "cat",
+				Category: "artworks",
+				Smode:    core.SearchSmodeTitle,
+			},
+			want:    "https://www.pixiv.net/ajax/search/artworks/cat?s_mode=s_tc&word=cat",
+			wantErr: false,
+		},
+		{
+			name: "error on unknown match mode",
+			settings: core.WorkSearchSettings{
+				Name:     "cat",
+				Category: "artworks",
+				Smode:    "s_bogus",
+			},
+			// Only the documented s_mode values are forwarded.
+			wantErr: true,
+		},
+		{
+			name: "error on exclusions with title match",
+			settings: core.WorkSearchSettings{
+				Name:     "cat -dog",
+				Category: "artworks",
+				Smode:    core.SearchSmodeTitle,
+			},
+			// pixiv ignores exclusions under title/caption search, so the
+			// combination is rejected instead of silently misbehaving.
+			wantErr: true,
+		},
+		{
+			name: "AI works excluded at the source",
+			settings: core.WorkSearchSettings{
+				Name:     "cat",
+				Category: "artworks",
+				AIType:   core.SearchAITypeExclude,
+			},
+			want:    "https://www.pixiv.net/ajax/search/artworks/cat?ai_type=1&word=cat",
+			wantErr: false,
+		},
+		{
+			name: "error on unknown AI filter value",
+			settings: core.WorkSearchSettings{
+				Name:     "cat",
+				Category: "artworks",
+				AIType:   "2",
+			},
+			// Only pixiv's documented ai_type value is forwarded.
+			wantErr: true,
+		},
 		{
 			name: "search with unicode in category",
 			settings: core.WorkSearchSettings{
@@ -238,3 +311,28 @@ func TestGetArtworkSearchURL(t *testing.T) {
 		})
 	}
 }
+
+// TestSplitSearchTerms verifies the "-keyword" exclusion syntax parsing.
+func TestSplitSearchTerms(t *testing.T) {
+	t.Parallel()
+
+	included, excluded := core.SplitSearchTerms("東方 -r18 reimu -AI生成")
+
+	if want := []string{"東方", "reimu"}; !reflect.DeepEqual(included, want) {
+		t.Errorf("included = %v, want %v", included, want)
+	}
+
+	if want := []string{"r18", "AI生成"}; !reflect.DeepEqual(excluded, want) {
+		t.Errorf("excluded = %v, want %v", excluded, want)
+	}
+
+	// A bare "-" is not an exclusion, and an empty string yields nothing.
+	included, excluded = core.SplitSearchTerms("-")
+	if len(included) != 1 || included[0] != "-" || len(excluded) != 0 {
+		t.Errorf("SplitSearchTerms(\"-\") = %v, %v; want the bare dash included", included, excluded)
+	}
+
+	if included, excluded = core.SplitSearchTerms(""); included != nil || excluded != nil {
+		t.Errorf("SplitSearchTerms(\"\") = %v, %v; want nil slices", included, excluded)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"strings"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// FetchSource records which upstream fetch produced a work, for debugging
+// why a work appears or disappears between views. It is only populated when
+// Development.AnnotateFetchSource is enabled in a development build; in
+// production the field stays nil and annotation is a single flag check.
+type FetchSource struct {
+	Source string // logical origin, e.g. "search", "related", "recent"
+	URL    string // the upstream URL the work was fetched from
+}
+
+// HTMLComment renders the annotation as an HTML comment for embedding in
+// templates, e.g. "<!-- fetched via search: https://... -->". The comment
+// terminator is stripped from the URL so the output cannot break out of the
+// comment.
+func (f *FetchSource) HTMLComment() string {
+	if f == nil {
+		return ""
+	}
+
+	safeURL := strings.ReplaceAll(f.URL, "--", "")
+
+	return "<!-- fetched via " + f.Source + ": " + safeURL + " -->"
+}
+
+// fetchSourceEnabled reports whether works should carry fetch annotations.
+func fetchSourceEnabled() bool {
+	return config.Global.Development.InDevelopment && config.Global.Development.AnnotateFetchSource
+}
+
+// annotateArtworkFetchSource stamps every work in the slice with its fetch
+// origin. A no-op unless annotation is enabled.
+func annotateArtworkFetchSource(works []ArtworkItem, source, url string) {
+	if !fetchSourceEnabled() {
+		return
+	}
+
+	for i := range works {
+		works[i].FetchSource = &FetchSource{Source: source, URL: url}
+	}
+}
+
+// annotateNovelFetchSource is annotateArtworkFetchSource for novel briefs.
+func annotateNovelFetchSource(novels []*NovelBrief, source, url string) {
+	if !fetchSourceEnabled() {
+		return
+	}
+
+	for _, novel := range novels {
+		if novel != nil {
+			novel.FetchSource = &FetchSource{Source: source, URL: url}
+		}
+	}
+}
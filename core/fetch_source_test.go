@@ -0,0 +1,83 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// TestAnnotateFetchSource verifies that works are only stamped when both
+// development mode and the annotation flag are enabled. Deliberately not
+// parallel: it mutates config.Global.Development.
+func TestAnnotateFetchSource(t *testing.T) {
+	origConfig := config.Global
+	t.Cleanup(func() { config.Global = origConfig })
+
+	cases := []struct {
+		name          string
+		inDevelopment bool
+		annotate      bool
+		wantStamped   bool
+	}{
+		{"Disabled entirely", false, false, false},
+		{"Development only", true, false, false},
+		{"Flag without development", false, true, false},
+		{"Both enabled", true, true, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Global.Development.InDevelopment = tt.inDevelopment
+			config.Global.Development.AnnotateFetchSource = tt.annotate
+
+			works := []ArtworkItem{{ID: "1"}, {ID: "2"}}
+			annotateArtworkFetchSource(works, "search", "https://www.pixiv.net/ajax/search/artworks/x")
+
+			novels := []*NovelBrief{{ID: "3"}, nil}
+			annotateNovelFetchSource(novels, "search", "https://www.pixiv.net/ajax/search/novels/x")
+
+			for _, work := range works {
+				if got := work.FetchSource != nil; got != tt.wantStamped {
+					t.Errorf("Artwork %s stamped = %t, want %t", work.ID, got, tt.wantStamped)
+				}
+			}
+
+			if got := novels[0].FetchSource != nil; got != tt.wantStamped {
+				t.Errorf("Novel stamped = %t, want %t", got, tt.wantStamped)
+			}
+
+			if !tt.wantStamped {
+				return
+			}
+
+			if works[0].FetchSource.Source != "search" || works[0].FetchSource.URL == "" {
+				t.Errorf("Annotation = %+v, want source and URL recorded", works[0].FetchSource)
+			}
+		})
+	}
+}
+
+func TestFetchSourceHTMLComment(t *testing.T) {
+	t.Parallel()
+
+	var unset *FetchSource
+	if got := unset.HTMLComment(); got != "" {
+		t.Errorf("HTMLComment() on nil = %q, want empty", got)
+	}
+
+	source := &FetchSource{Source: "related", URL: "https://www.pixiv.net/ajax/illust/77/recommend/init?limit=180"}
+
+	want := "<!-- fetched via related: https://www.pixiv.net/ajax/illust/77/recommend/init?limit=180 -->"
+	if got := source.HTMLComment(); got != want {
+		t.Errorf("HTMLComment() = %q, want %q", got, want)
+	}
+
+	// A hostile URL must not be able to terminate the comment early.
+	hostile := &FetchSource{Source: "search", URL: "https://example.com/--><script>"}
+	if got := hostile.HTMLComment(); got != "<!-- fetched via search: https://example.com/><script> -->" {
+		t.Errorf("HTMLComment() with hostile URL = %q", got)
+	}
+}
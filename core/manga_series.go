@@ -33,6 +33,37 @@ type MangaSeriesData struct {
 	mangaSeriesResponse
 
 	Title string
+
+	// The fields below are populated by [GetMangaSeries], the single-call
+	// variant, and stay zero for [GetMangaSeriesByID].
+	Description string        // the series' own description, with URLs parsed
+	Author      *User         // the series author as carried by the response
+	Chapters    []ArtworkItem // the page's works in series order, thumbnails populated
+	CurrentPage int
+	LastPage    int
+}
+
+// AdjacentWorks returns the chapters immediately before and after workID in
+// the series ordering. Either result is nil at the corresponding end of the
+// series, as is both when workID is not part of this page.
+func (d *MangaSeriesData) AdjacentWorks(workID string) (prev, next *ArtworkItem) {
+	for i := range d.Chapters {
+		if d.Chapters[i].ID != workID {
+			continue
+		}
+
+		if i > 0 {
+			prev = &d.Chapters[i-1]
+		}
+
+		if i+1 < len(d.Chapters) {
+			next = &d.Chapters[i+1]
+		}
+
+		return prev, next
+	}
+
+	return nil, nil
 }
 
 // mangaSeriesResponse defines the API response structure for /ajax/series/{ seriesID }.
@@ -87,6 +118,111 @@ type seriesEntry struct {
 	Order  int    `json:"order"`
 }
 
+// orderedSeriesWorks returns the given works rearranged to match the order of
+// the page's series entries. Entries whose work is absent from works are
+// skipped, as are works without an entry.
+func orderedSeriesWorks(works []ArtworkItem, entries []seriesEntry) []ArtworkItem {
+	byID := make(map[string]ArtworkItem, len(works))
+	for _, work := range works {
+		byID[work.ID] = work
+	}
+
+	ordered := make([]ArtworkItem, 0, len(entries))
+
+	for _, entry := range entries {
+		if work, ok := byID[entry.WorkID]; ok {
+			ordered = append(ordered, work)
+		}
+	}
+
+	return ordered
+}
+
+// mangaSeriesLastPage returns the number of pages needed for total works at
+// [MangaSeriesPageSize] works per page, never less than one.
+func mangaSeriesLastPage(total int) int {
+	lastPage := (total + MangaSeriesPageSize - 1) / MangaSeriesPageSize
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	return lastPage
+}
+
+// GetMangaSeries retrieves a complete manga series page in a single API call:
+// series metadata, the ordered chapter list with thumbnails populated, and
+// pagination bounds. Adjacent-work navigation derives from the chapter order
+// via [MangaSeriesData.AdjacentWorks].
+//
+// Unlike [GetMangaSeriesByID] it performs no extra user lookup; the author
+// comes from the series response itself.
+func GetMangaSeries(r *http.Request, seriesID string, page int) (*MangaSeriesData, error) {
+	var data mangaSeriesResponse
+
+	resp, err := requests.GetJSONBody(
+		r.Context(),
+		GetMangaSeriesContentURL(seriesID, page),
+		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+		r.Header)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manga series content: %w", err)
+	}
+
+	if err := json.Unmarshal(RewriteEscapedImageURLs(r, resp), &data); err != nil {
+		return nil, fmt.Errorf("unmarshaling manga series content: %w", err)
+	}
+
+	// Populate thumbnails and collect the works belonging to this series;
+	// the response also carries works from the author's other series.
+	seriesWorks := make([]ArtworkItem, 0, len(data.Page.Series))
+
+	for i := range data.Thumbnails.Illust {
+		artwork := &data.Thumbnails.Illust[i]
+		if err := artwork.PopulateThumbnails(); err != nil {
+			return nil, fmt.Errorf("populating thumbnails for artwork ID %s: %w", artwork.ID, err)
+		}
+
+		if artwork.SeriesID == seriesID {
+			seriesWorks = append(seriesWorks, *artwork)
+		}
+	}
+
+	result := &MangaSeriesData{
+		mangaSeriesResponse: data,
+		Title:               mangaSeriesDefaultTitle,
+		Chapters:            orderedSeriesWorks(seriesWorks, data.Page.Series),
+		CurrentPage:         page,
+		LastPage:            mangaSeriesLastPage(data.Page.Total),
+	}
+
+	// Series metadata comes from the series' own illustSeries entry.
+	for i := range data.IllustSeries {
+		series := &data.IllustSeries[i]
+		if series.ID != seriesID {
+			continue
+		}
+
+		if series.Title != "" {
+			result.Title = series.Title
+		}
+
+		description := series.Description
+		if description == "" {
+			description = series.Caption
+		}
+
+		result.Description = parseDescriptionURLs(description)
+
+		break
+	}
+
+	if len(data.Users) > 0 {
+		result.Author = data.Users[0]
+	}
+
+	return result, nil
+}
+
 // GetMangaSeriesByID retrieves the content of a manga series by its ID and page number.
 func GetMangaSeriesByID(r *http.Request, userID, id string, page int) (*MangaSeriesData, error) {
 	var data mangaSeriesResponse
@@ -156,21 +292,7 @@ func GetMangaSeriesByID(r *http.Request, userID, id string, page int) (*MangaSer
 		// Order the artworks in the main series as specified by body.Page.Series.
 		mainSeriesIDStr := strconv.Itoa(data.Page.SeriesID)
 		if mainSeries, exists := illustSeriesMap[mainSeriesIDStr]; exists {
-			currentArtworksInMainSeries := make(map[string]ArtworkItem, len(mainSeries.List))
-			for _, art := range mainSeries.List {
-				currentArtworksInMainSeries[art.ID] = art
-			}
-
-			// Build the ordered list based on body.Page.Series.
-			orderedList := make([]ArtworkItem, 0, len(data.Page.Series))
-
-			for _, entry := range data.Page.Series {
-				if art, ok := currentArtworksInMainSeries[entry.WorkID]; ok {
-					orderedList = append(orderedList, art)
-				}
-			}
-
-			mainSeries.List = orderedList // Replace the main series' artwork list with the ordered one.
+			mainSeries.List = orderedSeriesWorks(mainSeries.List, data.Page.Series)
 		}
 
 		return nil
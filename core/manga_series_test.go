@@ -0,0 +1,171 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// mangaSeriesFixture is a trimmed /ajax/series/{id} response. The thumbnails
+// arrive out of series order and include a work from another series; the
+// page.series entries carry the authoritative ordering.
+const mangaSeriesFixture = `{"error":false,"body":{
+	"tagTranslation":{},
+	"thumbnails":{"illust":[
+		{"id":"302","title":"Chapter 2","userId":"77","seriesId":"2201",
+			"url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/02/00/00/00/302_p0_square1200.jpg"},
+		{"id":"999","title":"Other series work","userId":"77","seriesId":"9999",
+			"url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/09/00/00/00/999_p0_square1200.jpg"},
+		{"id":"303","title":"Chapter 3","userId":"77","seriesId":"2201",
+			"url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/03/00/00/00/303_p0_square1200.jpg"},
+		{"id":"301","title":"Chapter 1","userId":"77","seriesId":"2201",
+			"url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/01/00/00/00/301_p0_square1200.jpg"}
+	]},
+	"illustSeries":[
+		{"id":"2201","userId":"77","title":"My Series","description":"A series about cats.","total":30}
+	],
+	"users":[{"userId":"77","name":"Series Author"}],
+	"page":{
+		"series":[
+			{"workId":"301","order":1},
+			{"workId":"302","order":2},
+			{"workId":"303","order":3}
+		],
+		"seriesId":2201,
+		"total":30
+	}
+}}`
+
+// mangaSeriesStubTransport serves the series fixture for every request.
+type mangaSeriesStubTransport struct{}
+
+func (mangaSeriesStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(mangaSeriesFixture)),
+		Request:    req,
+	}, nil
+}
+
+func setupMangaSeriesTest(t *testing.T) {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+
+	utils.HTTPClient.Transport = mangaSeriesStubTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+}
+
+func TestGetMangaSeries(t *testing.T) {
+	setupMangaSeriesTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/users/77/series/2201", nil)
+
+	data, err := GetMangaSeries(r, "2201", 1)
+	if err != nil {
+		t.Fatalf("GetMangaSeries() error: %v", err)
+	}
+
+	// Chapters follow the page.series ordering, not the thumbnail order, and
+	// exclude the work belonging to the other series.
+	wantOrder := []string{"301", "302", "303"}
+	if len(data.Chapters) != len(wantOrder) {
+		t.Fatalf("Chapters = %d works, want %d", len(data.Chapters), len(wantOrder))
+	}
+
+	for i, want := range wantOrder {
+		if data.Chapters[i].ID != want {
+			t.Errorf("Chapters[%d].ID = %q, want %q", i, data.Chapters[i].ID, want)
+		}
+
+		if data.Chapters[i].Thumbnails.MasterWebp_1200 == "" {
+			t.Errorf("Chapters[%d] has no populated thumbnails", i)
+		}
+	}
+
+	if data.Title != "My Series" {
+		t.Errorf("Title = %q, want the series title", data.Title)
+	}
+
+	if data.Description != "A series about cats." {
+		t.Errorf("Description = %q, want the series description", data.Description)
+	}
+
+	if data.Author == nil || data.Author.Name != "Series Author" {
+		t.Errorf("Author = %+v, want the series author", data.Author)
+	}
+
+	// 30 works at 12 per page span 3 pages.
+	if data.CurrentPage != 1 || data.LastPage != 3 {
+		t.Errorf("Pagination = page %d of %d, want page 1 of 3", data.CurrentPage, data.LastPage)
+	}
+}
+
+func TestMangaSeriesLastPage(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		total int
+		want  int
+	}{
+		{0, 1},
+		{1, 1},
+		{MangaSeriesPageSize, 1},
+		{MangaSeriesPageSize + 1, 2},
+		{30, 3},
+	}
+
+	for _, tc := range cases {
+		if got := mangaSeriesLastPage(tc.total); got != tc.want {
+			t.Errorf("mangaSeriesLastPage(%d) = %d, want %d", tc.total, got, tc.want)
+		}
+	}
+}
+
+func TestMangaSeriesAdjacentWorks(t *testing.T) {
+	t.Parallel()
+
+	data := &MangaSeriesData{Chapters: []ArtworkItem{{ID: "301"}, {ID: "302"}, {ID: "303"}}}
+
+	prev, next := data.AdjacentWorks("302")
+	if prev == nil || prev.ID != "301" || next == nil || next.ID != "303" {
+		t.Errorf("AdjacentWorks(302) = %v, %v, want 301 and 303", prev, next)
+	}
+
+	prev, next = data.AdjacentWorks("301")
+	if prev != nil || next == nil || next.ID != "302" {
+		t.Errorf("AdjacentWorks(301) = %v, %v, want nil and 302", prev, next)
+	}
+
+	prev, next = data.AdjacentWorks("303")
+	if prev == nil || prev.ID != "302" || next != nil {
+		t.Errorf("AdjacentWorks(303) = %v, %v, want 302 and nil", prev, next)
+	}
+
+	prev, next = data.AdjacentWorks("999")
+	if prev != nil || next != nil {
+		t.Errorf("AdjacentWorks(999) = %v, %v, want nil for a work outside the series", prev, next)
+	}
+}
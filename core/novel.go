@@ -16,6 +16,8 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
 	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
 	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
 	"codeberg.org/pixivfe/pixivfe/v3/i18n"
@@ -29,12 +31,21 @@ const (
 	NovelTextLayoutDefault       NovelTextLayout = 2
 )
 
-func CalculateNovelTextLayout(mode NovelTextLayout, cookie string) NovelTextLayout {
+// CalculateNovelTextLayout resolves the text layout for a novel page.
+//
+// Resolution order: the user's NovelViewMode cookie wins when it expresses a
+// preference, then the author's suggested Settings.ViewMode, then the server
+// default of horizontal text.
+func CalculateNovelTextLayout(suggested NovelTextLayout, cookie string) NovelTextLayout {
 	if userViewMode := ParseNovelTextLayout(cookie); userViewMode != NovelTextLayoutUnknown {
-		mode = userViewMode
+		return userViewMode
+	}
+
+	if suggested != NovelTextLayoutUnknown {
+		return suggested
 	}
 
-	return mode
+	return NovelTextLayoutDefault
 }
 
 func ParseNovelTextLayout(s string) NovelTextLayout {
@@ -149,33 +160,75 @@ type NovelData struct {
 	NovelSeriesTitles        []string
 	User                     *User
 	Title                    string
+	Poll                     *NovelPoll // nil when the novel has no poll
+}
+
+// NovelPollChoice is one answer of a novel poll with its vote count.
+type NovelPollChoice struct {
+	ID    int    `json:"id"`
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// NovelPoll is the typed form of a novel's pollData payload.
+type NovelPoll struct {
+	Question string            `json:"question"`
+	Total    int               `json:"total"`
+	Choices  []NovelPollChoice `json:"choices"`
+}
+
+// parseNovelPoll converts the raw pollData value into a NovelPoll.
+//
+// pixiv sends a JSON object when the novel has a poll and an empty array (or
+// null) when it doesn't, so anything that isn't a non-empty object yields nil.
+func parseNovelPoll(raw any) *NovelPoll {
+	obj, ok := raw.(map[string]any)
+	if !ok || len(obj) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+
+	var poll NovelPoll
+	if err := json.Unmarshal(b, &poll); err != nil {
+		return nil
+	}
+
+	if poll.Question == "" {
+		return nil
+	}
+
+	return &poll
 }
 
 type Novel struct {
-	Bookmarks      int       `json:"bookmarkCount"`
-	CommentCount   int       `json:"commentCount"`
-	MarkerCount    int       `json:"markerCount"`
-	CreateDate     time.Time `json:"createDate"`
-	UploadDate     time.Time `json:"uploadDate"`
-	Description    string    `json:"description"`
-	ID             string    `json:"id"`
-	Title          string    `json:"title"`
-	Likes          int       `json:"likeCount"`
-	Pages          int       `json:"pageCount"`
-	UserID         string    `json:"userId"`
-	UserName       string    `json:"userName"`
-	Views          int       `json:"viewCount"`
-	IsOriginal     bool      `json:"isOriginal"`
-	IsBungei       bool      `json:"isBungei"`
-	XRestrict      XRestrict `json:"xRestrict"`
-	Restrict       int       `json:"restrict"`
-	Content        string    `json:"content"`
-	CoverURL       string    `json:"coverUrl"`
-	IsBookmarkable bool      `json:"isBookmarkable"`
-	BookmarkData   any       `json:"bookmarkData"`
-	LikeData       bool      `json:"likeData"`
-	PollData       any       `json:"pollData"`
-	Marker         any       `json:"marker"`
+	Bookmarks      int           `json:"bookmarkCount"`
+	CommentCount   int           `json:"commentCount"`
+	MarkerCount    int           `json:"markerCount"`
+	CreateDate     time.Time     `json:"createDate"`
+	UploadDate     time.Time     `json:"uploadDate"`
+	Description    string        `json:"description"`
+	ID             string        `json:"id"`
+	Title          string        `json:"title"`
+	Likes          int           `json:"likeCount"`
+	Pages          int           `json:"pageCount"`
+	UserID         string        `json:"userId"`
+	UserName       string        `json:"userName"`
+	Views          int           `json:"viewCount"`
+	IsOriginal     bool          `json:"isOriginal"`
+	IsBungei       bool          `json:"isBungei"`
+	XRestrict      XRestrict     `json:"xRestrict"`
+	Restrict       int           `json:"restrict"`
+	Content        string        `json:"content"`
+	CoverURL       string        `json:"coverUrl"`
+	IsBookmarkable bool          `json:"isBookmarkable"`
+	BookmarkData   *BookmarkData `json:"bookmarkData"`
+	LikeData       bool          `json:"likeData"`
+	PollData       any           `json:"pollData"`
+	Marker         any           `json:"marker"`
 	Tags           struct {
 		AuthorID string `json:"authorId"`
 		IsLocked bool   `json:"isLocked"`
@@ -225,6 +278,13 @@ type Novel struct {
 	CommentsData  *CommentsData
 	UserNovels    map[string]*NovelBrief `json:"userNovels"`
 	ContentBlocks []NovelContentBlock    `json:"-"` // Parsed content blocks for rendering
+	Poll          *NovelPoll             `json:"-"` // Typed PollData; nil when the novel has no poll
+}
+
+// CommentCounts reconciles the novel's reported comment total with the
+// comments actually fetched into CommentsData.
+func (n *Novel) CommentCounts() CommentCounts {
+	return ReconcileCommentCounts(n.CommentCount, n.CommentsData)
 }
 
 type NovelAdjacentInSeries struct {
@@ -256,11 +316,41 @@ type NovelBrief struct {
 	CreateDate     time.Time     `json:"createDate"`
 	UpdateDate     time.Time     `json:"updateDate"`
 	IsMasked       bool          `json:"isMasked"`
+	MaskReason     string        `json:"maskReason"` // why pixiv masked the work, when provided
 	SeriesID       string        `json:"seriesId"`
 	SeriesTitle    string        `json:"seriesTitle"`
 	IsUnlisted     bool          `json:"isUnlisted"`
 	AIType         AIType        `json:"aiType"`
 	Genre          string        `json:"genre"`
+	FetchSource    *FetchSource  `json:"-"` // Debug annotation; nil outside development builds
+}
+
+// maskReasons maps pixiv's maskReason values to user-facing explanations.
+var maskReasons = map[string]i18n.MsgKey{
+	"r18":     "Masked by pixiv: R-18 content",
+	"r18g":    "Masked by pixiv: R-18G content",
+	"mypixiv": "Masked by pixiv: limited to the author's My pixiv",
+}
+
+// MaskExplanation returns a user-facing explanation for why pixiv masked the
+// work, so the UI can give context instead of silently omitting it. Unknown
+// reason codes fall back to a generic message carrying the raw code; works
+// that are not masked yield an empty string.
+func (n *NovelBrief) MaskExplanation(ctx context.Context) string {
+	if !n.IsMasked {
+		return ""
+	}
+
+	if key, ok := maskReasons[n.MaskReason]; ok {
+		return key.Tr(ctx)
+	}
+
+	generic := i18n.Tr(ctx, "This work is masked by pixiv.")
+	if n.MaskReason != "" {
+		return fmt.Sprintf("%s (%s)", generic, n.MaskReason)
+	}
+
+	return generic
 }
 
 // insertIllustsResponse models the response from /ajax/novel/.../insert_illusts
@@ -396,6 +486,7 @@ func GetNovelPageData(r *http.Request, id string) (*NovelData, error) {
 		NovelSeriesIDs:           novelSeriesIDs,
 		NovelSeriesTitles:        novelSeriesTitles,
 		Title:                    title,
+		Poll:                     novel.Poll,
 	}, nil
 }
 
@@ -432,6 +523,9 @@ func getNovelByID(r *http.Request, id string) (*Novel, error) {
 	// Process the novel content into structured blocks
 	novel.ContentBlocks = processNovelContent(r, novel)
 
+	// Type the raw poll payload, if any.
+	novel.Poll = parseNovelPoll(novel.PollData)
+
 	return novel, nil
 }
 
@@ -462,7 +556,68 @@ func getNovelRelated(r *http.Request, id string) ([]*NovelBrief, error) {
 	return data.List, nil
 }
 
+// novelSeriesTitlesEntry holds one series' fetched content titles.
+type novelSeriesTitlesEntry struct {
+	titles    []*NovelSeriesContentTitle
+	fetchedAt time.Time
+}
+
+// novelSeriesTitlesCache memoizes content titles per series ID. Series
+// contents only change when an episode is added, so entries are reused for
+// config.Global.Novel.SeriesTitlesTTL.
+var novelSeriesTitlesCache struct {
+	sync.Mutex
+
+	entries map[int]*novelSeriesTitlesEntry
+}
+
+var (
+	novelSeriesFetchOnce  sync.Once
+	novelSeriesFetchSlots chan struct{}
+)
+
+// novelSeriesFetchSemaphore returns the shared semaphore bounding concurrent
+// series title fetches, sized from config on first use. A non-positive
+// configured limit falls back to 4.
+func novelSeriesFetchSemaphore() chan struct{} {
+	novelSeriesFetchOnce.Do(func() {
+		limit := config.Global.Novel.SeriesFetchConcurrency
+		if limit <= 0 {
+			limit = 4
+		}
+
+		novelSeriesFetchSlots = make(chan struct{}, limit)
+	})
+
+	return novelSeriesFetchSlots
+}
+
+// novelSeriesTitlesTTL returns the configured cache TTL, falling back to ten
+// minutes when unset.
+func novelSeriesTitlesTTL() time.Duration {
+	if ttl := config.Global.Novel.SeriesTitlesTTL; ttl > 0 {
+		return ttl
+	}
+
+	return 10 * time.Minute
+}
+
 func getNovelSeriesContentTitlesByID(r *http.Request, id int) ([]*NovelSeriesContentTitle, error) {
+	novelSeriesTitlesCache.Lock()
+	if entry := novelSeriesTitlesCache.entries[id]; entry != nil &&
+		time.Since(entry.fetchedAt) < novelSeriesTitlesTTL() {
+		novelSeriesTitlesCache.Unlock()
+
+		return entry.titles, nil
+	}
+	novelSeriesTitlesCache.Unlock()
+
+	// Bound the fan-out: every episode page of a series triggers this fetch.
+	sem := novelSeriesFetchSemaphore()
+	sem <- struct{}{}
+
+	defer func() { <-sem }()
+
 	var data []*NovelSeriesContentTitle
 
 	resp, err := requests.GetJSONBody(
@@ -479,6 +634,17 @@ func getNovelSeriesContentTitlesByID(r *http.Request, id int) ([]*NovelSeriesCon
 		return data, err
 	}
 
+	novelSeriesTitlesCache.Lock()
+	if novelSeriesTitlesCache.entries == nil {
+		novelSeriesTitlesCache.entries = make(map[int]*novelSeriesTitlesEntry)
+	}
+
+	novelSeriesTitlesCache.entries[id] = &novelSeriesTitlesEntry{
+		titles:    data,
+		fetchedAt: time.Now(),
+	}
+	novelSeriesTitlesCache.Unlock()
+
 	return data, nil
 }
 
@@ -567,8 +733,12 @@ func processNovelContent(r *http.Request, novel *Novel) []NovelContentBlock {
 	// Pre-compute data required by the parser.
 	imageData := prepareImageData(r, novel)
 
+	// Rendering is faithful to author spacing by default; the user can opt in
+	// to collapsing runs of blank lines via the NovelCollapseBlanks cookie.
+	collapseBlanks := untrusted.GetCookie(r, cookie.NovelCollapseBlanksCookie) == "true"
+
 	// Convert the text (with the help of the image map) into blocks.
-	return parseNovelContent(novel.Content, imageData)
+	return parseNovelContent(novel.Content, imageData, collapseBlanks)
 }
 
 // prepareImageData scans the novel text for image tags and returns a map that
@@ -674,7 +844,7 @@ var (
 
 // parseNovelContent splits the text on [newpage] tags and delegates each
 // segment to the line-oriented parser.
-func parseNovelContent(content string, imageData map[string]*novelImageData) []NovelContentBlock {
+func parseNovelContent(content string, imageData map[string]*novelImageData, collapseBlanks bool) []NovelContentBlock {
 	if content == "" {
 		return nil
 	}
@@ -683,7 +853,7 @@ func parseNovelContent(content string, imageData map[string]*novelImageData) []N
 
 	segments := newPageRegexp.Split(content, -1)
 	for pageIdx, segment := range segments {
-		segBlocks := parseSegmentIntoBlocks(segment, imageData)
+		segBlocks := parseSegmentIntoBlocks(segment, imageData, collapseBlanks)
 		if len(segBlocks) == 0 {
 			continue
 		}
@@ -772,7 +942,10 @@ func parseParagraph(paragraph string, imageData map[string]*novelImageData) []No
 
 // parseSegmentIntoBlocks converts a single page-segment into blocks, handling
 // paragraphs, blank runs, chapter headers and inline images.
-func parseSegmentIntoBlocks(segment string, imageData map[string]*novelImageData) []NovelContentBlock {
+//
+// When collapseBlanks is set, runs of blank lines produce a single paragraph
+// break instead of extra <br /> blocks; author spacing is otherwise preserved.
+func parseSegmentIntoBlocks(segment string, imageData map[string]*novelImageData, collapseBlanks bool) []NovelContentBlock {
 	// Nothing to do if there's no content.
 	if segment == "" {
 		return nil
@@ -827,8 +1000,11 @@ func parseSegmentIntoBlocks(segment string, imageData map[string]*novelImageData
 
 		// Blank line.
 		if inBlankRun {
-			// Second (or later) consecutive blank line ⇒ explicit <br>.
-			blocks = append(blocks, TextBlock{Content: "<br />"})
+			// Second (or later) consecutive blank line ⇒ explicit <br>,
+			// unless the user asked for blank runs to be collapsed.
+			if !collapseBlanks {
+				blocks = append(blocks, TextBlock{Content: "<br />"})
+			}
 
 			continue
 		}
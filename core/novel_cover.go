@@ -0,0 +1,69 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"net/http"
+	"strings"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// novelCoverThumbnailSpec is the size/quality segment for the small cover
+// variant used in lists.
+const novelCoverThumbnailSpec = "240x480_80_webp"
+
+// NovelCover exposes the proxied size variants of a novel cover image so
+// series and search UIs can pick an appropriate size.
+type NovelCover struct {
+	Thumbnail string // small crop for list views
+	Medium    string // master WebP bounded to the configured conversion box
+	Original  string // full-size original cover
+}
+
+// HasCover reports whether any variant is available.
+func (c NovelCover) HasCover() bool {
+	return c.Medium != ""
+}
+
+// Cover derives the proxied cover variants for the novel. The zero value is
+// returned when the novel has no cover.
+func (n *Novel) Cover(r *http.Request) NovelCover {
+	if n == nil {
+		return NovelCover{}
+	}
+
+	return NovelCoverVariants(r, n.CoverURL)
+}
+
+// NovelCoverVariants builds the proxied size variants for a novel cover URL.
+//
+// The input may be a raw i.pximg.net URL or one already routed through a
+// proxy; both the novel-cover-master and novel-cover-original path forms are
+// handled by the shared master WebP rewrite logic.
+func NovelCoverVariants(r *http.Request, coverURL string) NovelCover {
+	if coverURL == "" {
+		return NovelCover{}
+	}
+
+	proxyBase := utils.GetProxyBase(untrusted.GetImageProxy(r))
+
+	medium := generateMasterWebpURL(coverURL, proxyBase)
+
+	// The same master image with a small crop box for list views.
+	thumbnail := sizeQualityRe.ReplaceAllString(medium, "/c/"+novelCoverThumbnailSpec+"/")
+
+	// The un-cropped cover: drop the size segment, switch back to the
+	// original path form, and strip the master filename suffix.
+	original := sizeQualityRe.ReplaceAllString(medium, "/")
+	original = strings.Replace(original, "/novel-cover-master/", "/novel-cover-original/", 1)
+	original = baseFileRe.ReplaceAllString(original, "${1}.jpg")
+
+	return NovelCover{
+		Thumbnail: thumbnail,
+		Medium:    medium,
+		Original:  original,
+	}
+}
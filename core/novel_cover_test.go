@@ -0,0 +1,83 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// TestNovelCoverVariants verifies the derived cover sizes. Deliberately not
+// parallel: it mutates config.Global.
+func TestNovelCoverVariants(t *testing.T) {
+	origConfig := config.Global
+	t.Cleanup(func() { config.Global = origConfig })
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+
+	r := httptest.NewRequest(http.MethodGet, "/novel/55", nil)
+
+	tests := []struct {
+		name          string
+		coverURL      string
+		wantThumbnail string
+		wantMedium    string
+		wantOriginal  string
+	}{
+		{
+			name:          "Raw original cover URL",
+			coverURL:      "https://i.pximg.net/novel-cover-original/img/1970/01/01/00/00/00/deadbeef.jpg",
+			wantThumbnail: "/proxy/i.pximg.net/c/240x480_80_webp/novel-cover-master/img/1970/01/01/00/00/00/deadbeef_master1200.jpg",
+			wantMedium:    "/proxy/i.pximg.net/c/1200x1200_80_webp/novel-cover-master/img/1970/01/01/00/00/00/deadbeef_master1200.jpg",
+			wantOriginal:  "/proxy/i.pximg.net/novel-cover-original/img/1970/01/01/00/00/00/deadbeef.jpg",
+		},
+		{
+			name:          "Already proxied master cover URL",
+			coverURL:      "/proxy/i.pximg.net/c/600x600/novel-cover-master/img/1970/01/01/00/00/00/deadbeef_master1200.jpg",
+			wantThumbnail: "/proxy/i.pximg.net/c/240x480_80_webp/novel-cover-master/img/1970/01/01/00/00/00/deadbeef_master1200.jpg",
+			wantMedium:    "/proxy/i.pximg.net/c/1200x1200_80_webp/novel-cover-master/img/1970/01/01/00/00/00/deadbeef_master1200.jpg",
+			wantOriginal:  "/proxy/i.pximg.net/novel-cover-original/img/1970/01/01/00/00/00/deadbeef.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cover := NovelCoverVariants(r, tt.coverURL)
+
+			if !cover.HasCover() {
+				t.Fatal("HasCover() = false, want true")
+			}
+
+			if cover.Thumbnail != tt.wantThumbnail {
+				t.Errorf("Thumbnail = %q, want %q", cover.Thumbnail, tt.wantThumbnail)
+			}
+
+			if cover.Medium != tt.wantMedium {
+				t.Errorf("Medium = %q, want %q", cover.Medium, tt.wantMedium)
+			}
+
+			if cover.Original != tt.wantOriginal {
+				t.Errorf("Original = %q, want %q", cover.Original, tt.wantOriginal)
+			}
+		})
+	}
+}
+
+func TestNovelCoverVariants_NoCover(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/novel/55", nil)
+
+	if cover := NovelCoverVariants(r, ""); cover.HasCover() || cover != (NovelCover{}) {
+		t.Errorf("NovelCoverVariants(\"\") = %+v, want the zero value", cover)
+	}
+
+	var novel *Novel
+	if cover := novel.Cover(r); cover != (NovelCover{}) {
+		t.Errorf("nil novel Cover() = %+v, want the zero value", cover)
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// Supported values for the format argument of ExportNovel.
+const (
+	NovelExportFormatText = "text"
+	NovelExportFormatEPUB = "epub"
+)
+
+var errUnsupportedExportFormat = errors.New("unsupported novel export format")
+
+// NovelExport holds a serialized novel ready to be sent as a download.
+type NovelExport struct {
+	Data        []byte
+	Filename    string
+	ContentType string
+}
+
+// ExportNovel fetches a novel and serializes its parsed content blocks for
+// offline reading, either as plain text or as a minimal EPUB.
+func ExportNovel(r *http.Request, id, format string) (*NovelExport, error) {
+	switch format {
+	case NovelExportFormatText, "txt", NovelExportFormatEPUB:
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedExportFormat, format)
+	}
+
+	novel, err := getNovelByID(r, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == NovelExportFormatEPUB {
+		data, err := renderNovelEPUB(r, novel)
+		if err != nil {
+			return nil, err
+		}
+
+		return &NovelExport{
+			Data:        data,
+			Filename:    novelExportFilename(novel, "epub"),
+			ContentType: "application/epub+zip",
+		}, nil
+	}
+
+	return &NovelExport{
+		Data:        []byte(renderNovelPlainText(novel)),
+		Filename:    novelExportFilename(novel, "txt"),
+		ContentType: "text/plain; charset=utf-8",
+	}, nil
+}
+
+// HTML produced by processTextMarkup that the plain-text path flattens back
+// into text.
+var (
+	// novelRubyHTMLRegexp captures the base text of a rendered furigana tag.
+	novelRubyHTMLRegexp = regexp.MustCompile(`<ruby>(.*?)<rp>.*?</rp><rt>.*?</rt><rp>.*?</rp></ruby>`)
+
+	// novelBreakHTMLRegexp matches the line breaks inserted for raw newlines.
+	novelBreakHTMLRegexp = regexp.MustCompile(`<br\s*/?>`)
+
+	// novelAnchorHTMLRegexp captures the label of a rendered link.
+	novelAnchorHTMLRegexp = regexp.MustCompile(`(?s)<a\b[^>]*>(.*?)</a>`)
+
+	// novelTagHTMLRegexp matches any remaining HTML tag.
+	novelTagHTMLRegexp = regexp.MustCompile(`<[^>]+>`)
+)
+
+// renderNovelPlainText serializes the novel's content blocks to plain text,
+// stripping the HTML produced by processTextMarkup.
+func renderNovelPlainText(novel *Novel) string {
+	var b strings.Builder
+
+	b.WriteString(novel.Title + "\n")
+
+	if novel.UserName != "" {
+		b.WriteString("by " + novel.UserName + "\n")
+	}
+
+	b.WriteString("\n")
+
+	for _, block := range novel.ContentBlocks {
+		switch blk := block.(type) {
+		case TextBlock:
+			b.WriteString(flattenNovelHTML(blk.Content) + "\n\n")
+		case ChapterBlock:
+			b.WriteString("== " + blk.Title + " ==\n\n")
+		case PageBreakBlock:
+			b.WriteString(fmt.Sprintf("----- Page %d -----\n\n", blk.PageNumber))
+		case ImageBlock:
+			b.WriteString(novelImagePlaceholder(blk) + "\n\n")
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// flattenNovelHTML reduces the HTML of a TextBlock back to readable text:
+// furigana collapses to its base text, line breaks become newlines, and links
+// keep only their label.
+func flattenNovelHTML(s string) string {
+	s = novelRubyHTMLRegexp.ReplaceAllString(s, "$1")
+	s = novelBreakHTMLRegexp.ReplaceAllString(s, "\n")
+	s = novelAnchorHTMLRegexp.ReplaceAllString(s, "$1")
+	s = novelTagHTMLRegexp.ReplaceAllString(s, "")
+
+	return html.UnescapeString(s)
+}
+
+// novelImagePlaceholder renders an ImageBlock as a readable plain-text marker.
+func novelImagePlaceholder(blk ImageBlock) string {
+	switch {
+	case blk.ErrorMsg != "":
+		return "[" + blk.ErrorMsg + "]"
+	case blk.IllustID != "":
+		return "[Illustration: " + blk.IllustID + "]"
+	default:
+		return "[Image]"
+	}
+}
+
+// epubContainerXML points EPUB readers at the package document.
+const epubContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// renderNovelEPUB serializes the novel as a minimal EPUB: one XHTML document
+// per page-break segment, with embedded images referenced through the
+// instance's content proxy.
+func renderNovelEPUB(r *http.Request, novel *Novel) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	// The mimetype entry must come first and be stored uncompressed.
+	mimetype, err := w.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mimetype entry: %w", err)
+	}
+
+	if _, err := mimetype.Write([]byte("application/epub+zip")); err != nil {
+		return nil, fmt.Errorf("failed to write mimetype entry: %w", err)
+	}
+
+	if err := writeZipFile(w, "META-INF/container.xml", epubContainerXML); err != nil {
+		return nil, err
+	}
+
+	pages := splitBlocksAtPageBreaks(novel.ContentBlocks)
+	origin := utils.GetOriginFromRequest(r)
+
+	var manifest, spine strings.Builder
+
+	for i, page := range pages {
+		name := fmt.Sprintf("page_%d.xhtml", i+1)
+
+		manifest.WriteString(fmt.Sprintf(
+			`    <item id="page_%d" href="%s" media-type="application/xhtml+xml"/>`+"\n", i+1, name))
+		spine.WriteString(fmt.Sprintf(`    <itemref idref="page_%d"/>`+"\n", i+1))
+
+		if err := writeZipFile(w, "OEBPS/"+name, novelPageXHTML(novel.Title, origin, page)); err != nil {
+			return nil, err
+		}
+	}
+
+	language := novel.Language
+	if language == "" {
+		language = "und"
+	}
+
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" unique-identifier="id" version="2.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="id">urn:pixiv:novel:` + novel.ID + `</dc:identifier>
+    <dc:title>` + html.EscapeString(novel.Title) + `</dc:title>
+    <dc:creator>` + html.EscapeString(novel.UserName) + `</dc:creator>
+    <dc:language>` + html.EscapeString(language) + `</dc:language>
+  </metadata>
+  <manifest>
+` + manifest.String() + `  </manifest>
+  <spine>
+` + spine.String() + `  </spine>
+</package>
+`
+
+	if err := writeZipFile(w, "OEBPS/content.opf", opf); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize EPUB: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeZipFile adds a single compressed file to the archive.
+func writeZipFile(w *zip.Writer, name, content string) error {
+	f, err := w.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+
+	if _, err := f.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// splitBlocksAtPageBreaks groups content blocks into pages, dropping the
+// page-break markers themselves. A novel without page breaks yields a single
+// page.
+func splitBlocksAtPageBreaks(blocks []NovelContentBlock) [][]NovelContentBlock {
+	pages := [][]NovelContentBlock{nil}
+
+	for _, block := range blocks {
+		if _, ok := block.(PageBreakBlock); ok {
+			pages = append(pages, nil)
+
+			continue
+		}
+
+		pages[len(pages)-1] = append(pages[len(pages)-1], block)
+	}
+
+	return pages
+}
+
+// novelPageXHTML renders one page of content blocks as an XHTML document.
+//
+// TextBlock content is already XHTML-compatible markup from
+// processTextMarkup; relative proxied image URLs are made absolute so they
+// resolve outside the instance.
+func novelPageXHTML(title, origin string, blocks []NovelContentBlock) string {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>` + html.EscapeString(title) + `</title></head>
+<body>
+`)
+
+	for _, block := range blocks {
+		switch blk := block.(type) {
+		case TextBlock:
+			b.WriteString("<p>" + blk.Content + "</p>\n")
+		case ChapterBlock:
+			b.WriteString("<h2>" + html.EscapeString(blk.Title) + "</h2>\n")
+		case ImageBlock:
+			if blk.ErrorMsg != "" {
+				b.WriteString("<p>" + html.EscapeString(novelImagePlaceholder(blk)) + "</p>\n")
+
+				continue
+			}
+
+			src := blk.URL
+			if strings.HasPrefix(src, "/") {
+				src = origin + src
+			}
+
+			b.WriteString(`<div><img src="` + html.EscapeString(src) +
+				`" alt="` + html.EscapeString(blk.Alt) + `"/></div>` + "\n")
+		}
+	}
+
+	b.WriteString("</body>\n</html>\n")
+
+	return b.String()
+}
+
+// novelExportFilename derives a download filename from the novel title,
+// replacing characters that are unsafe in filenames.
+func novelExportFilename(novel *Novel, ext string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		default:
+			return r
+		}
+	}, strings.TrimSpace(novel.Title))
+
+	if name == "" {
+		name = "novel-" + novel.ID
+	}
+
+	return name + "." + ext
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+func TestRenderNovelPlainText(t *testing.T) {
+	t.Parallel()
+
+	imageData := map[string]*novelImageData{
+		"[uploadedimage:123]": {
+			URL: "https://example.com/uploaded_123.jpg",
+			Alt: "[uploadedimage:123]",
+		},
+		"[pixivimage:456-1]": {
+			URL:      "https://example.com/pixiv_456_p1.jpg",
+			Alt:      "[pixivimage:456-1]",
+			Link:     "/artworks/456",
+			IllustID: "456-1",
+		},
+		"[pixivimage:789]": {
+			ErrorMsg: "Cannot insert illust: 789",
+		},
+	}
+
+	content := "[chapter: Prologue]\n\n" +
+		"Text with [[rb: 漢字 > かんじ]].\nSecond line.\n\n" +
+		"[uploadedimage:123]\n" +
+		"[newpage]\n" +
+		"See [[jumpuri: my site > https://example.com]].\n\n" +
+		"[pixivimage:456-1]\n\n" +
+		"[pixivimage:789]"
+
+	novel := &Novel{Title: "My Novel", UserName: "Author"}
+	novel.ContentBlocks = parseNovelContent(content, imageData, false)
+
+	got := renderNovelPlainText(novel)
+
+	want := "My Novel\n" +
+		"by Author\n\n" +
+		"== Prologue ==\n\n" +
+		"Text with 漢字.\nSecond line.\n\n" +
+		"[Image]\n\n" +
+		"----- Page 2 -----\n\n" +
+		"See my site.\n\n" +
+		"[Illustration: 456-1]\n\n" +
+		"[Cannot insert illust: 789]\n"
+
+	if got != want {
+		t.Errorf("renderNovelPlainText() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// novelExportFixture is a minimal novel response without embedded images, so
+// no illust lookups are needed.
+const novelExportFixture = `{"error":false,"body":{
+	"id":"55","title":"My: Novel","userId":"1","userName":"Author","language":"ja",
+	"content":"Hello.\n[newpage]\nWorld."
+}}`
+
+type novelExportStubTransport struct{}
+
+func (novelExportStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(novelExportFixture)),
+		Request:    req,
+	}, nil
+}
+
+func setupNovelExportTest(t *testing.T) {
+	t.Helper()
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	utils.HTTPClient.Transport = novelExportStubTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+}
+
+func TestExportNovel_Text(t *testing.T) {
+	setupNovelExportTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "http://instance.test/novel/55", nil)
+
+	export, err := ExportNovel(r, "55", NovelExportFormatText)
+	if err != nil {
+		t.Fatalf("ExportNovel() error: %v", err)
+	}
+
+	if export.ContentType != "text/plain; charset=utf-8" {
+		t.Errorf("ContentType = %q, want plain text", export.ContentType)
+	}
+
+	if export.Filename != "My_ Novel.txt" {
+		t.Errorf("Filename = %q, want the sanitized title", export.Filename)
+	}
+
+	text := string(export.Data)
+	if !strings.Contains(text, "Hello.") || !strings.Contains(text, "----- Page 2 -----") {
+		t.Errorf("Export text = %q, want both pages separated by a page break", text)
+	}
+}
+
+func TestExportNovel_EPUB(t *testing.T) {
+	setupNovelExportTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "http://instance.test/novel/55", nil)
+
+	export, err := ExportNovel(r, "55", NovelExportFormatEPUB)
+	if err != nil {
+		t.Fatalf("ExportNovel() error: %v", err)
+	}
+
+	if export.ContentType != "application/epub+zip" {
+		t.Errorf("ContentType = %q, want EPUB", export.ContentType)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(export.Data), int64(len(export.Data)))
+	if err != nil {
+		t.Fatalf("Export is not a valid zip: %v", err)
+	}
+
+	if zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+		t.Errorf("First entry = %q (method %d), want an uncompressed mimetype entry",
+			zr.File[0].Name, zr.File[0].Method)
+	}
+
+	pages := 0
+
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "OEBPS/page_") {
+			pages++
+		}
+	}
+
+	if pages != 2 {
+		t.Errorf("EPUB has %d page documents, want one per page-break segment (2)", pages)
+	}
+
+	opf, err := zr.Open("OEBPS/content.opf")
+	if err != nil {
+		t.Fatalf("EPUB is missing the package document: %v", err)
+	}
+	defer opf.Close()
+
+	opfData, _ := io.ReadAll(opf)
+	if !strings.Contains(string(opfData), "urn:pixiv:novel:55") {
+		t.Errorf("content.opf = %q, want the novel identifier", opfData)
+	}
+}
+
+func TestExportNovel_UnsupportedFormat(t *testing.T) {
+	setupNovelExportTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "http://instance.test/novel/55", nil)
+
+	if _, err := ExportNovel(r, "55", "pdf"); !errors.Is(err, errUnsupportedExportFormat) {
+		t.Errorf("ExportNovel() error = %v, want errUnsupportedExportFormat", err)
+	}
+}
@@ -125,22 +125,22 @@ type novelSeriesMember struct {
 	Title             string `json:"title"`
 	CommentHTML       string `json:"commentHtml"`
 	Tags              Tags
-	RawTags           StringTags `json:"tags"`
-	Restrict          int        `json:"restrict"`
-	XRestrict         XRestrict  `json:"xRestrict"`
-	IsOriginal        bool       `json:"isOriginal"`
-	TextLength        int        `json:"textLength"`
-	CharacterCount    int        `json:"characterCount"`
-	WordCount         int        `json:"wordCount"`
-	UseWordCount      bool       `json:"useWordCount"`
-	ReadingTime       int        `json:"readingTime"`
-	Bookmarks         int        `json:"bookmarkCount"`
-	CoverURL          string     `json:"url"`
-	UploadTimestamp   int        `json:"uploadTimestamp"`
-	ReuploadTimestamp int        `json:"reuploadTimestamp"`
-	IsBookmarkable    bool       `json:"isBookmarkable"`
-	BookmarkData      any        `json:"bookmarkData"`
-	AIType            AIType     `json:"aiType"`
+	RawTags           StringTags    `json:"tags"`
+	Restrict          int           `json:"restrict"`
+	XRestrict         XRestrict     `json:"xRestrict"`
+	IsOriginal        bool          `json:"isOriginal"`
+	TextLength        int           `json:"textLength"`
+	CharacterCount    int           `json:"characterCount"`
+	WordCount         int           `json:"wordCount"`
+	UseWordCount      bool          `json:"useWordCount"`
+	ReadingTime       int           `json:"readingTime"`
+	Bookmarks         int           `json:"bookmarkCount"`
+	CoverURL          string        `json:"url"`
+	UploadTimestamp   int           `json:"uploadTimestamp"`
+	ReuploadTimestamp int           `json:"reuploadTimestamp"`
+	IsBookmarkable    bool          `json:"isBookmarkable"`
+	BookmarkData      *BookmarkData `json:"bookmarkData"`
+	AIType            AIType        `json:"aiType"`
 }
 
 // GetNovelSeries retrieves a novel series.
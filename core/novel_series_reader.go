@@ -0,0 +1,119 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/sync/errgroup"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// novelReaderFetchConcurrency bounds how many chapter fetches run
+// simultaneously while stitching a series into the continuous reader.
+const novelReaderFetchConcurrency = 4
+
+// NovelSeriesReaderData holds a short novel series stitched into one
+// continuous view: the chapters' parsed content blocks concatenated in
+// series order, with a [ChapterBlock] boundary in front of each chapter and
+// per-chapter page breaks preserved.
+type NovelSeriesReaderData struct {
+	SeriesID      string
+	Chapters      []*NovelSeriesContentTitle // the chapters included, in series order
+	ContentBlocks []NovelContentBlock
+	TotalChapters int  // available chapters in the series, including any beyond the cap
+	Truncated     bool // whether the chapter cap cut the series short
+}
+
+// novelReaderChapterCap returns the configured cap on stitched chapters,
+// falling back to twenty when unset.
+func novelReaderChapterCap() int {
+	if chapterCap := config.Global.Novel.ReaderChapterCap; chapterCap > 0 {
+		return chapterCap
+	}
+
+	return 20
+}
+
+// GetNovelSeriesReader fetches a whole novel series for continuous reading.
+// It resolves the series' content list, fetches each available chapter, and
+// concatenates their parsed content blocks in series order. Embedded images
+// are resolved per chapter, exactly as on single-chapter pages.
+//
+// Very long series are truncated at the configured chapter cap; the result
+// reports the truncation so the reader can link to the remaining chapters.
+func GetNovelSeriesReader(r *http.Request, seriesID string) (*NovelSeriesReaderData, error) {
+	id, err := strconv.Atoi(seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid series ID: %s", seriesID)
+	}
+
+	titles, err := getNovelSeriesContentTitlesByID(r, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetching series content titles: %w", err)
+	}
+
+	// Unavailable chapters (deleted or restricted) cannot be fetched.
+	chapters := make([]*NovelSeriesContentTitle, 0, len(titles))
+
+	for _, title := range titles {
+		if title.Available {
+			chapters = append(chapters, title)
+		}
+	}
+
+	data := &NovelSeriesReaderData{
+		SeriesID:      seriesID,
+		TotalChapters: len(chapters),
+	}
+
+	if chapterCap := novelReaderChapterCap(); len(chapters) > chapterCap {
+		chapters = chapters[:chapterCap]
+		data.Truncated = true
+	}
+
+	data.Chapters = chapters
+
+	// Fetch the chapters concurrently, keeping results indexed so the
+	// stitched output preserves series order.
+	novels := make([]*Novel, len(chapters))
+
+	var g errgroup.Group
+
+	g.SetLimit(novelReaderFetchConcurrency)
+
+	for i, chapter := range chapters {
+		g.Go(func() error {
+			novel, err := getNovelByID(r, chapter.ID)
+			if err != nil {
+				return fmt.Errorf("fetching chapter %s: %w", chapter.ID, err)
+			}
+
+			novels[i] = novel
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Stitch: a chapter boundary, then the chapter's own blocks with their
+	// page breaks intact.
+	for i, novel := range novels {
+		title := chapters[i].Title
+		if title == "" {
+			title = novel.Title
+		}
+
+		data.ContentBlocks = append(data.ContentBlocks, ChapterBlock{Title: title})
+		data.ContentBlocks = append(data.ContentBlocks, novel.ContentBlocks...)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// readerTitlesFixture lists two readable chapters and one that is gone.
+const readerTitlesFixture = `{"error":false,"body":[
+	{"id":"201","title":"Part 1","available":true},
+	{"id":"202","title":"Part 2","available":true},
+	{"id":"203","title":"Deleted part","available":false}
+]}`
+
+// readerChapterContents maps chapter IDs to their raw novel text. Part 1
+// carries a [newpage] tag so page breaks inside a chapter are exercised.
+var readerChapterContents = map[string]string{
+	"201": "First page of part one.[newpage]Second page of part one.",
+	"202": "All of part two.",
+}
+
+// readerStubTransport serves the titles fixture and per-chapter novel bodies.
+type readerStubTransport struct{}
+
+func (readerStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body string
+
+	switch {
+	case strings.Contains(req.URL.Path, "/content_titles"):
+		body = readerTitlesFixture
+	default:
+		id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+		content, ok := readerChapterContents[id]
+		if !ok {
+			return nil, fmt.Errorf("unexpected chapter fetch for %q", id)
+		}
+
+		body = fmt.Sprintf(`{"error":false,"body":{"id":%q,"title":"Chapter %s","content":%q}}`,
+			id, id, content)
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func setupReaderTest(t *testing.T) {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+
+		resetSeriesTitlesState()
+	})
+
+	config.Global.SetDefaults()
+
+	utils.HTTPClient.Transport = readerStubTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	resetSeriesTitlesState()
+}
+
+// collectReaderOutline reduces stitched blocks to a comparable outline:
+// chapter boundaries, page breaks, and text contents in order.
+func collectReaderOutline(blocks []NovelContentBlock) []string {
+	outline := make([]string, 0, len(blocks))
+
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case ChapterBlock:
+			outline = append(outline, "chapter:"+b.Title)
+		case PageBreakBlock:
+			outline = append(outline, fmt.Sprintf("pagebreak:%d", b.PageNumber))
+		case TextBlock:
+			outline = append(outline, "text:"+b.Content)
+		}
+	}
+
+	return outline
+}
+
+func TestGetNovelSeriesReader(t *testing.T) {
+	setupReaderTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/novel/series/77", nil)
+
+	data, err := GetNovelSeriesReader(r, "77")
+	if err != nil {
+		t.Fatalf("GetNovelSeriesReader() error: %v", err)
+	}
+
+	if data.Truncated || data.TotalChapters != 2 || len(data.Chapters) != 2 {
+		t.Fatalf("Reader = %+v, want both available chapters without truncation", data)
+	}
+
+	want := []string{
+		"chapter:Part 1",
+		"text:First page of part one.",
+		"pagebreak:2",
+		"text:Second page of part one.",
+		"chapter:Part 2",
+		"text:All of part two.",
+	}
+
+	got := collectReaderOutline(data.ContentBlocks)
+	if len(got) != len(want) {
+		t.Fatalf("Outline = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Outline[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGetNovelSeriesReader_ChapterCap(t *testing.T) {
+	setupReaderTest(t)
+
+	config.Global.Novel.ReaderChapterCap = 1
+
+	r := httptest.NewRequest(http.MethodGet, "/novel/series/78", nil)
+
+	data, err := GetNovelSeriesReader(r, "78")
+	if err != nil {
+		t.Fatalf("GetNovelSeriesReader() error: %v", err)
+	}
+
+	if !data.Truncated {
+		t.Error("Truncated = false, want true with the cap below the chapter count")
+	}
+
+	if data.TotalChapters != 2 || len(data.Chapters) != 1 || data.Chapters[0].ID != "201" {
+		t.Fatalf("Reader = %+v, want only the first chapter kept of 2", data)
+	}
+
+	outline := collectReaderOutline(data.ContentBlocks)
+	if len(outline) == 0 || outline[0] != "chapter:Part 1" {
+		t.Errorf("Outline = %v, want it to start with the first chapter", outline)
+	}
+
+	for _, entry := range outline {
+		if strings.Contains(entry, "part two") {
+			t.Errorf("Outline contains the capped chapter: %v", outline)
+		}
+	}
+}
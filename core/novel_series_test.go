@@ -0,0 +1,180 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// seriesTitlesFixture is a captured content_titles response.
+const seriesTitlesFixture = `{"error":false,"body":[
+	{"id":"101","title":"Episode 1","available":true},
+	{"id":"102","title":"Episode 2","available":true}
+]}`
+
+// seriesTitlesStubTransport serves the titles fixture, counting requests and
+// tracking how many are in flight simultaneously.
+type seriesTitlesStubTransport struct {
+	mu          sync.Mutex
+	requests    int
+	inFlight    int
+	maxInFlight int
+}
+
+func (t *seriesTitlesStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests++
+	t.inFlight++
+
+	if t.inFlight > t.maxInFlight {
+		t.maxInFlight = t.inFlight
+	}
+	t.mu.Unlock()
+
+	// Hold the request open briefly so concurrent fetches overlap.
+	time.Sleep(20 * time.Millisecond)
+
+	t.mu.Lock()
+	t.inFlight--
+	t.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(seriesTitlesFixture)),
+		Request:    req,
+	}, nil
+}
+
+func (t *seriesTitlesStubTransport) stats() (requests, maxInFlight int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.requests, t.maxInFlight
+}
+
+func setupSeriesTitlesTest(t *testing.T) *seriesTitlesStubTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+
+		resetSeriesTitlesState()
+	})
+
+	config.Global.SetDefaults()
+
+	transport := &seriesTitlesStubTransport{}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	resetSeriesTitlesState()
+
+	return transport
+}
+
+// resetSeriesTitlesState clears the titles cache and lets the semaphore be
+// rebuilt from the current config.
+func resetSeriesTitlesState() {
+	novelSeriesTitlesCache.Lock()
+	novelSeriesTitlesCache.entries = nil
+	novelSeriesTitlesCache.Unlock()
+
+	novelSeriesFetchOnce = sync.Once{}
+	novelSeriesFetchSlots = nil
+}
+
+func TestGetNovelSeriesContentTitles_Cached(t *testing.T) {
+	transport := setupSeriesTitlesTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/novel/1", nil)
+
+	titles, err := getNovelSeriesContentTitlesByID(r, 55)
+	if err != nil {
+		t.Fatalf("getNovelSeriesContentTitlesByID() error: %v", err)
+	}
+
+	if len(titles) != 2 || titles[0].Title != "Episode 1" {
+		t.Fatalf("getNovelSeriesContentTitlesByID() = %+v, want the fixture titles", titles)
+	}
+
+	// A second read within the TTL is served from the cache.
+	if _, err := getNovelSeriesContentTitlesByID(r, 55); err != nil {
+		t.Fatalf("cached read error: %v", err)
+	}
+
+	// A different series still fetches.
+	if _, err := getNovelSeriesContentTitlesByID(r, 56); err != nil {
+		t.Fatalf("second series read error: %v", err)
+	}
+
+	if requests, _ := transport.stats(); requests != 2 {
+		t.Errorf("content_titles fetched %d times, want 2", requests)
+	}
+
+	// Age series 55 past the TTL; the next read must refresh.
+	novelSeriesTitlesCache.Lock()
+	novelSeriesTitlesCache.entries[55].fetchedAt =
+		time.Now().Add(-config.Global.Novel.SeriesTitlesTTL - time.Minute)
+	novelSeriesTitlesCache.Unlock()
+
+	if _, err := getNovelSeriesContentTitlesByID(r, 55); err != nil {
+		t.Fatalf("refresh read error: %v", err)
+	}
+
+	if requests, _ := transport.stats(); requests != 3 {
+		t.Errorf("content_titles fetched %d times, want 3 after TTL expiry", requests)
+	}
+}
+
+func TestGetNovelSeriesContentTitles_ConcurrencyBound(t *testing.T) {
+	transport := setupSeriesTitlesTest(t)
+
+	config.Global.Novel.SeriesFetchConcurrency = 2
+
+	var wg sync.WaitGroup
+
+	for i := range 6 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/novel/%d", i), nil)
+
+			if _, err := getNovelSeriesContentTitlesByID(r, 100+i); err != nil {
+				t.Errorf("getNovelSeriesContentTitlesByID() error: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	requests, maxInFlight := transport.stats()
+	if requests != 6 {
+		t.Errorf("content_titles fetched %d times, want 6 distinct series", requests)
+	}
+
+	if maxInFlight > 2 {
+		t.Errorf("max in-flight fetches = %d, want at most the configured limit of 2", maxInFlight)
+	}
+}
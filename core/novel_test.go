@@ -4,6 +4,7 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -261,10 +262,222 @@ func TestParseNovelContent(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := renderBlocksToHTML(parseNovelContent(tc.input, mockImageData))
+			result := renderBlocksToHTML(parseNovelContent(tc.input, mockImageData, false))
 			if !reflect.DeepEqual(result, tc.expected) {
 				t.Errorf("parseNovelContent() test case failed: %q\nInput:\n`%s`\n\nGot:\n`%s`\n\nWant:\n`%s`", tc.name, tc.input, result, tc.expected)
 			}
 		})
 	}
 }
+
+// TestParseNovelContent_CollapseBlanks compares faithful rendering against the
+// opt-in collapsing of consecutive blank lines.
+func TestParseNovelContent_CollapseBlanks(t *testing.T) {
+	t.Parallel()
+
+	input := "Paragraph 1.\n\n\n\n\nParagraph 2.\n\nParagraph 3."
+
+	// Faithful rendering keeps one <br /> block per extra blank line.
+	faithful := renderBlocksToHTML(parseNovelContent(input, nil, false))
+
+	wantFaithful := "<p>Paragraph 1.</p>\n" +
+		"<p><br /></p>\n" +
+		"<p><br /></p>\n" +
+		"<p><br /></p>\n" +
+		"<p>Paragraph 2.</p>\n" +
+		"<p>Paragraph 3.</p>"
+	if faithful != wantFaithful {
+		t.Errorf("faithful rendering:\ngot:  %q\nwant: %q", faithful, wantFaithful)
+	}
+
+	// Collapsed rendering reduces every blank run to a single paragraph break.
+	collapsed := renderBlocksToHTML(parseNovelContent(input, nil, true))
+
+	wantCollapsed := "<p>Paragraph 1.</p>\n" +
+		"<p>Paragraph 2.</p>\n" +
+		"<p>Paragraph 3.</p>"
+	if collapsed != wantCollapsed {
+		t.Errorf("collapsed rendering:\ngot:  %q\nwant: %q", collapsed, wantCollapsed)
+	}
+}
+
+func TestCalculateNovelTextLayout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		suggested NovelTextLayout
+		cookie    string
+		expected  NovelTextLayout
+	}{
+		{
+			name:      "User cookie overrides author suggestion",
+			suggested: NovelTextLayoutForceVertical,
+			cookie:    NovelViewModeHorizontal,
+			expected:  NovelTextLayoutDefault,
+		},
+		{
+			name:      "User cookie applies without author suggestion",
+			suggested: NovelTextLayoutUnknown,
+			cookie:    NovelViewModeVertical,
+			expected:  NovelTextLayoutForceVertical,
+		},
+		{
+			name:      "Author suggestion applies without user cookie",
+			suggested: NovelTextLayoutForceVertical,
+			cookie:    NovelViewModeNone,
+			expected:  NovelTextLayoutForceVertical,
+		},
+		{
+			name:      "Unparseable cookie falls back to author suggestion",
+			suggested: NovelTextLayoutForceVertical,
+			cookie:    "sideways",
+			expected:  NovelTextLayoutForceVertical,
+		},
+		{
+			name:      "Server default without cookie or suggestion",
+			suggested: NovelTextLayoutUnknown,
+			cookie:    NovelViewModeNone,
+			expected:  NovelTextLayoutDefault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := CalculateNovelTextLayout(tt.suggested, tt.cookie); got != tt.expected {
+				t.Errorf("CalculateNovelTextLayout(%d, %q) = %d, expected %d",
+					tt.suggested, tt.cookie, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseNovelPoll(t *testing.T) {
+	t.Parallel()
+
+	// pixiv sends an object when a poll exists and an empty array otherwise.
+	withPoll := `{"id":"55","pollData":{
+		"question":"Which route?",
+		"total":12,
+		"choices":[
+			{"id":1,"text":"Route A","count":8},
+			{"id":2,"text":"Route B","count":4}
+		]
+	}}`
+	withoutPoll := `{"id":"56","pollData":[]}`
+	nullPoll := `{"id":"57","pollData":null}`
+
+	var novel Novel
+	if err := json.Unmarshal([]byte(withPoll), &novel); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	poll := parseNovelPoll(novel.PollData)
+	if poll == nil {
+		t.Fatal("parseNovelPoll() = nil, want a typed poll")
+	}
+
+	if poll.Question != "Which route?" || poll.Total != 12 {
+		t.Errorf("parseNovelPoll() = %+v, want question and 12 total votes", poll)
+	}
+
+	if len(poll.Choices) != 2 || poll.Choices[0].Count != 8 || poll.Choices[1].Text != "Route B" {
+		t.Errorf("parseNovelPoll() choices = %+v, want the fixture choices", poll.Choices)
+	}
+
+	for _, raw := range []string{withoutPoll, nullPoll} {
+		var novel Novel
+		if err := json.Unmarshal([]byte(raw), &novel); err != nil {
+			t.Fatalf("Unmarshal() error: %v", err)
+		}
+
+		if got := parseNovelPoll(novel.PollData); got != nil {
+			t.Errorf("parseNovelPoll() for %s = %+v, want nil", raw, got)
+		}
+	}
+}
+
+// TestNovelBookmarkData verifies that both bookmarkData shapes pixiv returns
+// decode into the typed struct, so bookmark actions have the ID and privacy
+// flag available.
+func TestNovelBookmarkData(t *testing.T) {
+	t.Parallel()
+
+	var bookmarked Novel
+	if err := json.Unmarshal([]byte(`{"id":"11","bookmarkData":{"id":"5678","private":true}}`), &bookmarked); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	if bookmarked.BookmarkData == nil ||
+		bookmarked.BookmarkData.ID != "5678" || !bookmarked.BookmarkData.Private {
+		t.Errorf("BookmarkData = %+v, want ID 5678 and private", bookmarked.BookmarkData)
+	}
+
+	var plain Novel
+	if err := json.Unmarshal([]byte(`{"id":"12","bookmarkData":null}`), &plain); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	if plain.BookmarkData != nil {
+		t.Errorf("BookmarkData = %+v, want nil for a not-bookmarked novel", plain.BookmarkData)
+	}
+
+	var member novelSeriesMember
+	if err := json.Unmarshal([]byte(`{"id":"13","bookmarkData":{"id":"91","private":false}}`), &member); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	if member.BookmarkData == nil || member.BookmarkData.ID != "91" || member.BookmarkData.Private {
+		t.Errorf("Series member BookmarkData = %+v, want ID 91 and public", member.BookmarkData)
+	}
+}
+
+// TestNovelBriefMaskExplanation covers masked works with a known reason,
+// an unknown reason code, no reason at all, and unmasked works.
+func TestNovelBriefMaskExplanation(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		json string
+		want string
+	}{
+		{
+			"known reason",
+			`{"id":"1","isMasked":true,"maskReason":"r18"}`,
+			"Masked by pixiv: R-18 content",
+		},
+		{
+			"unknown reason keeps the raw code",
+			`{"id":"2","isMasked":true,"maskReason":"guideline"}`,
+			"This work is masked by pixiv. (guideline)",
+		},
+		{
+			"masked without a reason",
+			`{"id":"3","isMasked":true}`,
+			"This work is masked by pixiv.",
+		},
+		{
+			"not masked",
+			`{"id":"4","isMasked":false,"maskReason":"r18"}`,
+			"",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var brief NovelBrief
+			if err := json.Unmarshal([]byte(tc.json), &brief); err != nil {
+				t.Fatalf("Unmarshal() error: %v", err)
+			}
+
+			if got := brief.MaskExplanation(t.Context()); got != tc.want {
+				t.Errorf("MaskExplanation() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+// PageEllipsis is the sentinel value emitted by PageWindow where a run of
+// page numbers has been elided.
+const PageEllipsis = -1
+
+// PageWindow computes the list of page numbers for a pagination control,
+// e.g. 1 … 5 6 [7] 8 9 … 50 for PageWindow(7, 50, 2).
+//
+// The window spans up to span pages on either side of current, and the first
+// and last pages are always included. Gaps are marked with PageEllipsis,
+// except where the gap is a single page, which is shown directly instead.
+//
+// current is clamped to [1, last]. A non-positive last returns nil.
+func PageWindow(current, last, span int) []int {
+	if last < 1 {
+		return nil
+	}
+
+	if current < 1 {
+		current = 1
+	} else if current > last {
+		current = last
+	}
+
+	if span < 0 {
+		span = 0
+	}
+
+	start := current - span
+	if start < 1 {
+		start = 1
+	}
+
+	end := current + span
+	if end > last {
+		end = last
+	}
+
+	// Window plus, at most, the first page, the last page, and two ellipses.
+	window := make([]int, 0, end-start+5)
+
+	switch {
+	case start == 2:
+		window = append(window, 1)
+	case start == 3:
+		// An ellipsis hiding a single page is pointless; show the page.
+		window = append(window, 1, 2)
+	case start > 3:
+		window = append(window, 1, PageEllipsis)
+	}
+
+	for page := start; page <= end; page++ {
+		window = append(window, page)
+	}
+
+	switch {
+	case end == last-1:
+		window = append(window, last)
+	case end == last-2:
+		window = append(window, last-1, last)
+	case end < last-2:
+		window = append(window, PageEllipsis, last)
+	}
+
+	return window
+}
+
+// Pagination describes the page navigation for a paginated listing, so
+// templates don't each recompute prev/next links and page windows.
+//
+// Pages is a plain run of page numbers; use PageWindow directly for a
+// control with first/last pages and ellipses.
+type Pagination struct {
+	CurrentPage int
+	FirstPage   int
+	LastPage    int
+
+	// PrevPage and NextPage are zero when there is no page in that direction.
+	PrevPage int
+	NextPage int
+
+	HasPrev bool
+	HasNext bool
+
+	// Pages holds up to window page numbers centered on CurrentPage, clamped
+	// to the valid page range.
+	Pages []int
+}
+
+// NewPagination computes navigation metadata for page current out of last
+// pages. Out-of-range inputs are clamped: last is at least one page, and
+// current is kept within [1, last].
+func NewPagination(current, last, window int) Pagination {
+	if last < 1 {
+		last = 1
+	}
+
+	if current < 1 {
+		current = 1
+	} else if current > last {
+		current = last
+	}
+
+	p := Pagination{
+		CurrentPage: current,
+		FirstPage:   1,
+		LastPage:    last,
+		HasPrev:     current > 1,
+		HasNext:     current < last,
+	}
+
+	if p.HasPrev {
+		p.PrevPage = current - 1
+	}
+
+	if p.HasNext {
+		p.NextPage = current + 1
+	}
+
+	if window < 1 {
+		return p
+	}
+
+	// Center the window on the current page, shifting it back into range at
+	// either end.
+	start := current - (window-1)/2
+	if start < 1 {
+		start = 1
+	}
+
+	end := start + window - 1
+	if end > last {
+		end = last
+
+		if start = end - window + 1; start < 1 {
+			start = 1
+		}
+	}
+
+	p.Pages = make([]int, 0, end-start+1)
+	for page := start; page <= end; page++ {
+		p.Pages = append(p.Pages, page)
+	}
+
+	return p
+}
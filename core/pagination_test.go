@@ -0,0 +1,199 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core_test
+
+import (
+	"slices"
+	"testing"
+
+	. "codeberg.org/pixivfe/pixivfe/v3/core"
+)
+
+func TestPageWindow(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		current int
+		last    int
+		span    int
+		want    []int
+	}{
+		{
+			name:    "Middle of a large set",
+			current: 7, last: 50, span: 2,
+			want: []int{1, PageEllipsis, 5, 6, 7, 8, 9, PageEllipsis, 50},
+		},
+		{
+			name:    "Near the start",
+			current: 2, last: 50, span: 2,
+			want: []int{1, 2, 3, 4, PageEllipsis, 50},
+		},
+		{
+			name:    "Near the end",
+			current: 49, last: 50, span: 2,
+			want: []int{1, PageEllipsis, 47, 48, 49, 50},
+		},
+		{
+			name:    "First page",
+			current: 1, last: 50, span: 2,
+			want: []int{1, 2, 3, PageEllipsis, 50},
+		},
+		{
+			name:    "Last page",
+			current: 50, last: 50, span: 2,
+			want: []int{1, PageEllipsis, 48, 49, 50},
+		},
+		{
+			name:    "Window covers the whole set",
+			current: 3, last: 5, span: 2,
+			want: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:    "Single-page gap is shown instead of an ellipsis",
+			current: 4, last: 7, span: 1,
+			want: []int{1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			name:    "Single page",
+			current: 1, last: 1, span: 2,
+			want: []int{1},
+		},
+		{
+			name:    "Zero span",
+			current: 7, last: 50, span: 0,
+			want: []int{1, PageEllipsis, 7, PageEllipsis, 50},
+		},
+		{
+			name:    "Current clamped below range",
+			current: 0, last: 10, span: 1,
+			want: []int{1, 2, PageEllipsis, 10},
+		},
+		{
+			name:    "Current clamped above range",
+			current: 15, last: 10, span: 1,
+			want: []int{1, PageEllipsis, 9, 10},
+		},
+		{
+			name:    "No pages",
+			current: 1, last: 0, span: 2,
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := PageWindow(tc.current, tc.last, tc.span); !slices.Equal(got, tc.want) {
+				t.Errorf("PageWindow(%d, %d, %d) = %v, want %v",
+					tc.current, tc.last, tc.span, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewPagination(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		current int
+		last    int
+		window  int
+		want    Pagination
+	}{
+		{
+			name:    "First page",
+			current: 1, last: 10, window: 5,
+			want: Pagination{
+				CurrentPage: 1, FirstPage: 1, LastPage: 10,
+				NextPage: 2, HasNext: true,
+				Pages: []int{1, 2, 3, 4, 5},
+			},
+		},
+		{
+			name:    "Last page",
+			current: 10, last: 10, window: 5,
+			want: Pagination{
+				CurrentPage: 10, FirstPage: 1, LastPage: 10,
+				PrevPage: 9, HasPrev: true,
+				Pages: []int{6, 7, 8, 9, 10},
+			},
+		},
+		{
+			name:    "Middle page",
+			current: 5, last: 10, window: 5,
+			want: Pagination{
+				CurrentPage: 5, FirstPage: 1, LastPage: 10,
+				PrevPage: 4, NextPage: 6, HasPrev: true, HasNext: true,
+				Pages: []int{3, 4, 5, 6, 7},
+			},
+		},
+		{
+			name:    "Single page",
+			current: 1, last: 1, window: 5,
+			want: Pagination{
+				CurrentPage: 1, FirstPage: 1, LastPage: 1,
+				Pages: []int{1},
+			},
+		},
+		{
+			name:    "Window larger than the page count",
+			current: 2, last: 3, window: 10,
+			want: Pagination{
+				CurrentPage: 2, FirstPage: 1, LastPage: 3,
+				PrevPage: 1, NextPage: 3, HasPrev: true, HasNext: true,
+				Pages: []int{1, 2, 3},
+			},
+		},
+		{
+			name:    "Current page clamped into range",
+			current: 12, last: 10, window: 3,
+			want: Pagination{
+				CurrentPage: 10, FirstPage: 1, LastPage: 10,
+				PrevPage: 9, HasPrev: true,
+				Pages: []int{8, 9, 10},
+			},
+		},
+		{
+			name:    "Non-positive window omits the page list",
+			current: 2, last: 4, window: 0,
+			want: Pagination{
+				CurrentPage: 2, FirstPage: 1, LastPage: 4,
+				PrevPage: 1, NextPage: 3, HasPrev: true, HasNext: true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := NewPagination(tc.current, tc.last, tc.window)
+			if got.CurrentPage != tc.want.CurrentPage ||
+				got.FirstPage != tc.want.FirstPage ||
+				got.LastPage != tc.want.LastPage ||
+				got.PrevPage != tc.want.PrevPage ||
+				got.NextPage != tc.want.NextPage ||
+				got.HasPrev != tc.want.HasPrev ||
+				got.HasNext != tc.want.HasNext ||
+				!slices.Equal(got.Pages, tc.want.Pages) {
+				t.Errorf("NewPagination(%d, %d, %d) = %+v, want %+v",
+					tc.current, tc.last, tc.window, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSearchDataPagination(t *testing.T) {
+	t.Parallel()
+
+	data := &SearchData{CurrentPage: 4, LastPage: 9}
+
+	got := data.Pagination(3)
+	if got.PrevPage != 3 || got.NextPage != 5 || !slices.Equal(got.Pages, []int{3, 4, 5}) {
+		t.Errorf("Pagination(3) = %+v, want pages 3-5 around page 4", got)
+	}
+}
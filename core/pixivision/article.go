@@ -425,27 +425,40 @@ func parseArticleTags(doc *goquery.Document) []EmbedTag {
 }
 
 // parseAllRelatedArticles finds and parses all "related articles" sections on the page.
+//
+// Sections are parsed with raw image URLs first; a single batched
+// enrichment pass then rewrites every collected thumbnail.
 func parseAllRelatedArticles(doc *goquery.Document, r *http.Request) (RelatedArticleGroup, RelatedArticleGroup, RelatedArticleGroup) {
 	var newestTagged, popularTagged, newestCategory RelatedArticleGroup
 
 	doc.Find("div._related-articles[data-gtm-category='Related Article Latest']").Each(func(i int, section *goquery.Selection) {
 		if section.Find("ul.rla__list-group").Length() > 0 {
-			newestTagged = parseRelatedArticleSection(section, r)
+			newestTagged = parseRelatedArticleSection(section)
 		}
 	})
 
 	doc.Find("div._related-articles[data-gtm-category='Related Article Popular']").Each(func(i int, section *goquery.Selection) {
 		if section.Find("ul.rla__list-group").Length() > 0 {
-			popularTagged = parseRelatedArticleSection(section, r)
+			popularTagged = parseRelatedArticleSection(section)
 		}
 	})
 
 	doc.Find("div._related-articles[data-gtm-category='Article Latest']").Each(func(i int, section *goquery.Selection) {
 		if section.Find("ul.rla__list-group").Length() > 0 {
-			newestCategory = parseRelatedArticleSection(section, r)
+			newestCategory = parseRelatedArticleSection(section)
 		}
 	})
 
+	var thumbnails []*string
+
+	for _, group := range []*RelatedArticleGroup{&newestTagged, &popularTagged, &newestCategory} {
+		for i := range group.Articles {
+			thumbnails = append(thumbnails, &group.Articles[i].Thumbnail)
+		}
+	}
+
+	enrichImageURLs(r, thumbnails)
+
 	return newestTagged, popularTagged, newestCategory
 }
 
@@ -475,7 +488,10 @@ func fetchArticle(r *http.Request, id string, lang []string) (*goquery.Document,
 }
 
 // parseRelatedArticleSection parses a list of related articles from a div._related-articles selection.
-func parseRelatedArticleSection(sectionSelection *goquery.Selection, r *http.Request) RelatedArticleGroup {
+//
+// Thumbnails are left as raw pixiv URLs; the caller enriches them in a
+// batched pass.
+func parseRelatedArticleSection(sectionSelection *goquery.Selection) RelatedArticleGroup {
 	var group RelatedArticleGroup
 
 	group.HeadingLink = normalizeHeadingLink(sectionSelection.Find("h3.rla__heading a").AttrOr("href", ""))
@@ -488,9 +504,8 @@ func parseRelatedArticleSection(sectionSelection *goquery.Selection, r *http.Req
 		item.ID = thumbLinkSelection.AttrOr("data-gtm-label", "")
 
 		styleAttr := thumbLinkSelection.Find("div._thumbnail").AttrOr("style", "")
-		rawThumbnailURL := parseBackgroundImage(styleAttr)
 
-		item.Thumbnail = core.RewriteImageURLs(r, rawThumbnailURL)
+		item.Thumbnail = parseBackgroundImage(styleAttr)
 
 		item.Category = s.Find("div.ascr__category-pr a span._category-label").Text()
 
@@ -0,0 +1,48 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package pixivision
+
+import (
+	"net/http"
+	"sync"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core"
+)
+
+// enrichImageURLs rewrites the collected image URLs in place, fanning the
+// work out over a bounded number of goroutines.
+//
+// Today enrichment only routes URLs through the content proxies, but the
+// bounded stage means network-dependent processing (dimension probes,
+// transcoding) can be added here later without fanning out unboundedly.
+func enrichImageURLs(r *http.Request, urls []*string) {
+	limit := config.Global.Pixivision.ImageEnrichmentConcurrency
+	if limit <= 0 {
+		limit = 1
+	}
+
+	var (
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, limit)
+	)
+
+	for _, u := range urls {
+		if u == nil || *u == "" {
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			*u = core.RewriteImageURLs(r, *u)
+		}()
+	}
+
+	wg.Wait()
+}
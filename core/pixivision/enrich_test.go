@@ -0,0 +1,92 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package pixivision
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core"
+)
+
+// TestEnrichImageURLs verifies the batched pass produces the same output as
+// rewriting each URL directly.
+func TestEnrichImageURLs(t *testing.T) {
+	origConfig := config.Global
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+	config.Global.ContentProxies.Static = url.URL{Path: "/proxy/s.pximg.net"}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create test request: %v", err)
+	}
+
+	raw := []string{
+		"https://i.pximg.net/c/540x540_70/img-master/img/2024/01/01/00/00/00/111_p0_master1200.jpg",
+		"https://s.pximg.net/common/images/no_profile.png",
+		"https://example.com/unrelated.png",
+		"",
+	}
+
+	got := make([]string, len(raw))
+	copy(got, raw)
+
+	urls := make([]*string, 0, len(got)+1)
+	for i := range got {
+		urls = append(urls, &got[i])
+	}
+
+	// nil entries are tolerated so callers can append unconditionally.
+	urls = append(urls, nil)
+
+	enrichImageURLs(req, urls)
+
+	for i, rawURL := range raw {
+		want := rawURL
+		if want != "" {
+			want = core.RewriteImageURLs(req, rawURL)
+		}
+
+		if got[i] != want {
+			t.Errorf("urls[%d] = %q, want %q", i, got[i], want)
+		}
+	}
+}
+
+// TestEnrichImageURLs_ZeroConcurrency confirms an unset bound still processes
+// every URL instead of deadlocking.
+func TestEnrichImageURLs_ZeroConcurrency(t *testing.T) {
+	origConfig := config.Global
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+	config.Global.Pixivision.ImageEnrichmentConcurrency = 0
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create test request: %v", err)
+	}
+
+	thumb := "https://i.pximg.net/c/540x540_70/img-master/img/2024/01/01/00/00/00/222_p0_master1200.jpg"
+	want := core.RewriteImageURLs(req, thumb)
+
+	enrichImageURLs(req, []*string{&thumb})
+
+	if thumb != want {
+		t.Errorf("thumb = %q, want %q", thumb, want)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"net/http"
+	"strings"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// FallbackAvatarURL returns the configured placeholder for users whose avatar
+// is missing or deleted. Absolute pixiv URLs are routed through the user's
+// proxy; relative paths are served as local assets.
+func FallbackAvatarURL(r *http.Request) string {
+	return fallbackImageURL(r, config.Global.ContentProxies.FallbackAvatar)
+}
+
+// FallbackThumbnailURL returns the configured placeholder for works whose
+// thumbnail is missing or deleted. Absolute pixiv URLs are routed through the
+// user's proxy; relative paths are served as local assets.
+func FallbackThumbnailURL(r *http.Request) string {
+	return fallbackImageURL(r, config.Global.ContentProxies.FallbackThumbnail)
+}
+
+// fallbackImageURL proxies absolute pixiv URLs and leaves relative paths
+// (local assets) untouched.
+func fallbackImageURL(r *http.Request, raw string) string {
+	if raw == "" || strings.HasPrefix(raw, "/") {
+		return raw
+	}
+
+	return RewriteImageURLs(r, raw)
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// setupPlaceholderConfig installs the default fallback URLs with a known
+// static proxy, restoring the previous config when the test completes.
+func setupPlaceholderConfig(t *testing.T) {
+	t.Helper()
+
+	origConfig := config.Global
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Static = url.URL{Path: "/proxy/s.pximg.net"}
+}
+
+func TestFallbackImageURL(t *testing.T) {
+	setupPlaceholderConfig(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+
+	// Absolute pixiv URLs are routed through the static proxy.
+	want := "/proxy/s.pximg.net/common/images/limit_unknown_360.png"
+	if got := FallbackThumbnailURL(r); got != want {
+		t.Errorf("FallbackThumbnailURL() = %q, want %q", got, want)
+	}
+
+	// Relative paths are served as local assets, untouched.
+	config.Global.ContentProxies.FallbackAvatar = "/img/no-avatar.png"
+
+	if got := FallbackAvatarURL(r); got != "/img/no-avatar.png" {
+		t.Errorf("FallbackAvatarURL() = %q, want the local asset path", got)
+	}
+}
+
+// bookmarksStubTransport serves a bookmarks payload with one valid work and
+// one deleted work (which fails to unmarshal, as pixiv returns for removed
+// bookmarks).
+type bookmarksStubTransport struct{}
+
+func (bookmarksStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	const body = `{"error":false,"body":{"works":[
+		{"id":"111","title":"A work","url":"https://i.pximg.net/img-original/img/2024/01/01/00/00/00/111_p0.png"},
+		{"id":222,"title":"gone"}
+	],"total":2}}`
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestPopulateIllustBookmarks_DeletedWorkPlaceholder(t *testing.T) {
+	setupPlaceholderConfig(t)
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	utils.HTTPClient.Transport = bookmarksStubTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	r := httptest.NewRequest(http.MethodGet, "/users/123/bookmarks", nil)
+
+	artworks, total, err := populateIllustBookmarks(r, "123", "all", "", 1)
+	if err != nil {
+		t.Fatalf("populateIllustBookmarks() error: %v", err)
+	}
+
+	if total != 2 || len(artworks) != 2 {
+		t.Fatalf("populateIllustBookmarks() returned %d/%d works, want 2/2", len(artworks), total)
+	}
+
+	if artworks[0].ID != "111" {
+		t.Errorf("artworks[0].ID = %q, want the intact work", artworks[0].ID)
+	}
+
+	deleted := artworks[1]
+	if deleted.Title != "Deleted or private" {
+		t.Errorf("deleted.Title = %q, want %q", deleted.Title, "Deleted or private")
+	}
+
+	want := "/proxy/s.pximg.net/common/images/limit_unknown_360.png"
+	if deleted.Thumbnail != want {
+		t.Errorf("deleted.Thumbnail = %q, want the configured placeholder %q", deleted.Thumbnail, want)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"context"
+	"strings"
+
+	"codeberg.org/pixivfe/pixivfe/v3/i18n"
+)
+
+// ProvenanceToolLabels maps normalized (lowercase) tag names to the display
+// label of the tool or source they indicate.
+//
+// The map is data-driven on purpose: operators or future code can extend it
+// without touching the parsing logic. All keys must be lowercase.
+var ProvenanceToolLabels = map[string]string{
+	"novelai":          "NovelAI",
+	"novelaidiffusion": "NovelAI",
+	"stablediffusion":  "Stable Diffusion",
+	"midjourney":       "Midjourney",
+	"dall-e":           "DALL-E",
+	"dalle":            "DALL-E",
+	"niji・journey":     "niji journey",
+	"nijijourney":      "niji journey",
+	"waifudiffusion":   "Waifu Diffusion",
+}
+
+// Provenance describes where a work (likely) came from: whether it is
+// AI-generated according to pixiv's aiType field or our tag heuristics,
+// and which tools were recognized from its tags.
+type Provenance struct {
+	AIType AIType
+
+	// TaggedAI is true when at least one of the work's tags is a known
+	// AI-related tag, regardless of what aiType claims.
+	TaggedAI bool
+
+	// Tools holds the display labels of recognized tools/sources, in tag
+	// order and without duplicates.
+	Tools []string
+}
+
+// IsAI reports whether the work should be presented as AI-generated,
+// combining the API's aiType with the tag heuristics.
+func (p Provenance) IsAI() bool {
+	return p.AIType == AIGenerated || p.TaggedAI
+}
+
+// Badge returns a short, translated provenance badge such as
+// "AI-generated (NovelAI)", or an empty string when there is nothing
+// noteworthy to show.
+func (p Provenance) Badge(ctx context.Context) string {
+	if !p.IsAI() {
+		return ""
+	}
+
+	label := i18n.Tr(ctx, "AI-generated")
+	if p.AIType != AIGenerated {
+		// aiType did not claim AI; only the tags did.
+		label = i18n.Tr(ctx, "AI-assisted")
+	}
+
+	if len(p.Tools) > 0 {
+		label += " (" + strings.Join(p.Tools, ", ") + ")"
+	}
+
+	return label
+}
+
+// ParseProvenance extracts provenance information from an aiType value and a
+// set of tag names. Tag matching is case-insensitive; unrecognized tags are
+// ignored.
+func ParseProvenance(aiType AIType, tagNames []string) Provenance {
+	p := Provenance{AIType: aiType}
+
+	seen := make(map[string]bool, len(ProvenanceToolLabels))
+
+	for _, name := range tagNames {
+		normalized := strings.ToLower(name)
+
+		if IsKnownAITag(normalized) {
+			p.TaggedAI = true
+		}
+
+		if label, ok := ProvenanceToolLabels[normalized]; ok && !seen[label] {
+			seen[label] = true
+			p.Tools = append(p.Tools, label)
+		}
+	}
+
+	return p
+}
+
+// Provenance returns the provenance information for the illustration,
+// derived from its aiType and tags.
+func (i *Illust) Provenance() Provenance {
+	tagNames := make([]string, len(i.Tags.Tags))
+	for n, tag := range i.Tags.Tags {
+		tagNames[n] = tag.Name
+	}
+
+	return ParseProvenance(i.AIType, tagNames)
+}
+
+// Provenance returns the provenance information for the artwork item,
+// derived from its aiType and tags.
+func (work *ArtworkItem) Provenance() Provenance {
+	return ParseProvenance(work.AIType, work.Tags)
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core_test
+
+import (
+	"slices"
+	"testing"
+
+	. "codeberg.org/pixivfe/pixivfe/v3/core"
+)
+
+func TestParseProvenance(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name         string
+		aiType       AIType
+		tags         []string
+		wantTaggedAI bool
+		wantTools    []string
+	}{
+		{
+			name:         "Recognized tool tag",
+			aiType:       AIGenerated,
+			tags:         []string{"NovelAI", "風景"},
+			wantTaggedAI: true,
+			wantTools:    []string{"NovelAI"},
+		},
+		{
+			name:         "Recognized tool tag case-insensitive",
+			aiType:       AIGenerated,
+			tags:         []string{"StableDiffusion"},
+			wantTaggedAI: true,
+			wantTools:    []string{"Stable Diffusion"},
+		},
+		{
+			name:         "Duplicate tool labels deduplicated",
+			aiType:       AIGenerated,
+			tags:         []string{"novelai", "NovelAIDiffusion"},
+			wantTaggedAI: true,
+			wantTools:    []string{"NovelAI"},
+		},
+		{
+			name:         "AI tag without a recognized tool",
+			aiType:       Unrated,
+			tags:         []string{"AIイラスト"},
+			wantTaggedAI: true,
+			wantTools:    nil,
+		},
+		{
+			name:         "Unrecognized tags only",
+			aiType:       NotAIGenerated,
+			tags:         []string{"オリジナル", "女の子"},
+			wantTaggedAI: false,
+			wantTools:    nil,
+		},
+		{
+			name:         "No tags",
+			aiType:       AIGenerated,
+			tags:         nil,
+			wantTaggedAI: false,
+			wantTools:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := ParseProvenance(tc.aiType, tc.tags)
+
+			if got.AIType != tc.aiType {
+				t.Errorf("ParseProvenance() AIType = %d, want %d", got.AIType, tc.aiType)
+			}
+
+			if got.TaggedAI != tc.wantTaggedAI {
+				t.Errorf("ParseProvenance() TaggedAI = %v, want %v", got.TaggedAI, tc.wantTaggedAI)
+			}
+
+			if !slices.Equal(got.Tools, tc.wantTools) {
+				t.Errorf("ParseProvenance() Tools = %v, want %v", got.Tools, tc.wantTools)
+			}
+		})
+	}
+}
+
+func TestProvenance_Badge(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		p    Provenance
+		want string
+	}{
+		{
+			name: "AI-generated with tool",
+			p:    Provenance{AIType: AIGenerated, Tools: []string{"NovelAI"}},
+			want: "AI-generated (NovelAI)",
+		},
+		{
+			name: "AI-assisted via tags only",
+			p:    Provenance{AIType: NotAIGenerated, TaggedAI: true, Tools: []string{"Stable Diffusion"}},
+			want: "AI-assisted (Stable Diffusion)",
+		},
+		{
+			name: "AI-generated without tools",
+			p:    Provenance{AIType: AIGenerated},
+			want: "AI-generated",
+		},
+		{
+			name: "Not AI",
+			p:    Provenance{AIType: NotAIGenerated},
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.p.Badge(t.Context()); got != tc.want {
+				t.Errorf("Badge() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"time"
 
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
 	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
 	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
 )
@@ -26,6 +27,7 @@ const (
 )
 
 var (
+	errUnknownRankingMode   = errors.New("unknown ranking mode")
 	errParseRankingDate     = errors.New("failed to parse ranking date")
 	errNoIllustrationsFound = errors.New("no illustrations were found for the given date and mode")
 	errFetchDetails         = errors.New("failed to fetch details")
@@ -89,6 +91,11 @@ func GetRanking(r *http.Request, mode, contentType, date, page string) (RankingD
 		currentDate, prevDate, nextDate, rankingURL string
 	)
 
+	// modeDisplay doubles as the set of supported modes.
+	if _, ok := modeDisplay[mode]; !ok {
+		return data, fmt.Errorf("%w: %s", errUnknownRankingMode, mode)
+	}
+
 	pageInt, _ := strconv.Atoi(page)
 
 	if date == "" {
@@ -161,7 +168,12 @@ func GetRanking(r *http.Request, mode, contentType, date, page string) (RankingD
 		detailsMap[detail.ID] = detail
 	}
 
-	// Combine ranking and details.
+	// Combine ranking and details, applying the viewer's filter profile so
+	// R-18 modes respect the same visibility settings as everywhere else.
+	filterCookies := map[cookie.CookieName]string{
+		cookie.FilterProfileCookie: untrusted.GetCookie(r, cookie.FilterProfileCookie),
+	}
+
 	var orderedContents []ArtworkItem
 
 	for _, rankingItem := range rankingResp.Ranking {
@@ -180,6 +192,10 @@ func GetRanking(r *http.Request, mode, contentType, date, page string) (RankingD
 			// Convert TouchArtwork to ArtworkBrief
 			artworkBrief := convertTouchArtworkToArtworkBrief(detail)
 
+			if artworkBrief.ShouldHide(filterCookies) {
+				continue
+			}
+
 			orderedContents = append(orderedContents, artworkBrief)
 		}
 	}
@@ -228,7 +244,11 @@ func getDateRange(date string) (string, string, string, error) {
 
 	parsedDate, err := time.Parse(dateFormat, date)
 	if err != nil {
-		return "", "", "", fmt.Errorf("parse date: %w", err)
+		// pixiv's own ranking URLs use a compact YYYYMMDD date; accept it too.
+		parsedDate, err = time.Parse("20060102", date)
+		if err != nil {
+			return "", "", "", fmt.Errorf("parse date: %w", err)
+		}
 	}
 
 	parsedDate = parsedDate.Add(PixivTimeOffset)
@@ -0,0 +1,187 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// rankingFixture is a captured /touch/ajax/ranking/illust response with three
+// ranked entries.
+const rankingFixture = `{"error":false,"body":{
+	"rankingDate":"2024-03-01",
+	"ranking":[
+		{"illustId":"9001","rank":1},
+		{"illustId":"9002","rank":2},
+		{"illustId":"9003","rank":3}
+	]
+}}`
+
+// rankingDetailsFixture is the matching /touch/ajax/illust/details/many
+// response. Entry 9003 is R-18. Numeric fields arrive as strings, matching
+// the touch API.
+const rankingDetailsFixture = `{"error":false,"body":{"illust_details":[
+	{"id":"9001","user_id":"11","title":"First place","tags":["fanart"],
+		"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/03\/01\/00\/00\/00\/9001_p0_square1200.jpg",
+		"width":"800","height":"600","restrict":"0","x_restrict":"0","type":"0","page_count":"1","sl":2,"ai_type":0,
+		"author_details":{"user_id":"11","user_name":"Artist A"}},
+	{"id":"9002","user_id":"22","title":"Second place","tags":["fanart"],
+		"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/03\/01\/00\/00\/00\/9002_p0_square1200.jpg",
+		"width":"800","height":"600","restrict":"0","x_restrict":"0","type":"0","page_count":"1","sl":2,"ai_type":0,
+		"author_details":{"user_id":"22","user_name":"Artist B"}},
+	{"id":"9003","user_id":"33","title":"Third place","tags":["fanart"],
+		"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/03\/01\/00\/00\/00\/9003_p0_square1200.jpg",
+		"width":"800","height":"600","restrict":"0","x_restrict":"1","type":"0","page_count":"1","sl":6,"ai_type":0,
+		"author_details":{"user_id":"33","user_name":"Artist C"}}
+]}}`
+
+// rankingStubTransport serves the ranking and details fixtures and records
+// the requested URLs.
+type rankingStubTransport struct {
+	rankingURL string
+	detailsURL string
+}
+
+func (t *rankingStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body string
+
+	switch {
+	case strings.Contains(req.URL.Path, "/illust/details/many"):
+		t.detailsURL = req.URL.String()
+		body = rankingDetailsFixture
+	default:
+		t.rankingURL = req.URL.String()
+		body = rankingFixture
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func setupRankingTest(t *testing.T) *rankingStubTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+
+	transport := &rankingStubTransport{}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	return transport
+}
+
+func TestGetRanking_SequentialRanksAndThumbnails(t *testing.T) {
+	setupRankingTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/ranking", nil)
+
+	data, err := GetRanking(r, "daily", "all", "2024-03-01", "1")
+	if err != nil {
+		t.Fatalf("GetRanking() error: %v", err)
+	}
+
+	if len(data.Contents) != 3 {
+		t.Fatalf("GetRanking() returned %d works, want 3", len(data.Contents))
+	}
+
+	for i, work := range data.Contents {
+		if work.Rank != i+1 {
+			t.Errorf("Contents[%d].Rank = %d, want %d", i, work.Rank, i+1)
+		}
+
+		if !strings.Contains(work.Thumbnails.Webp_1200, "/proxy/i.pximg.net/") {
+			t.Errorf("Contents[%d].Thumbnails.Webp_1200 = %q, want a proxied thumbnail", i, work.Thumbnails.Webp_1200)
+		}
+	}
+
+	if data.CurrentDate != "2024-03-01" {
+		t.Errorf("CurrentDate = %q, want %q", data.CurrentDate, "2024-03-01")
+	}
+
+	if data.PrevDate != "2024-02-29" || data.NextDate != "2024-03-02" {
+		t.Errorf("PrevDate, NextDate = %q, %q, want the adjacent days", data.PrevDate, data.NextDate)
+	}
+}
+
+func TestGetRanking_FilterProfileHidesR18(t *testing.T) {
+	setupRankingTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/ranking", nil)
+	r.AddCookie(&http.Cookie{
+		Name:  string(cookie.FilterProfileCookie),
+		Value: url.QueryEscape(`{"v":1,"r15":"show","r18":"hide","r18g":"hide","ai":"show"}`),
+	})
+
+	data, err := GetRanking(r, "daily", "all", "2024-03-01", "1")
+	if err != nil {
+		t.Fatalf("GetRanking() error: %v", err)
+	}
+
+	if len(data.Contents) != 2 {
+		t.Fatalf("GetRanking() returned %d works, want the R-18 entry hidden", len(data.Contents))
+	}
+
+	for _, work := range data.Contents {
+		if work.ID == "9003" {
+			t.Error("Contents includes the R-18 work, want it hidden by the filter profile")
+		}
+	}
+}
+
+func TestGetRanking_CompactDate(t *testing.T) {
+	transport := setupRankingTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/ranking", nil)
+
+	data, err := GetRanking(r, "daily", "all", "20240301", "1")
+	if err != nil {
+		t.Fatalf("GetRanking() error: %v", err)
+	}
+
+	if !strings.Contains(transport.rankingURL, "date=2024-03-01") {
+		t.Errorf("Ranking URL = %q, want the compact date normalized", transport.rankingURL)
+	}
+
+	if data.CurrentDate != "2024-03-01" {
+		t.Errorf("CurrentDate = %q, want %q", data.CurrentDate, "2024-03-01")
+	}
+}
+
+func TestGetRanking_UnknownMode(t *testing.T) {
+	setupRankingTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/ranking", nil)
+
+	if _, err := GetRanking(r, "hourly", "all", "", "1"); !errors.Is(err, errUnknownRankingMode) {
+		t.Errorf("GetRanking() error = %v, want errUnknownRankingMode", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tidwall/gjson"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
+	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
+)
+
+const (
+	// recommendedUsersLimit matches the discovery users page size.
+	recommendedUsersLimit = 12
+
+	// recommendedSampleWorksLimit caps the sample works shown per suggested user.
+	recommendedSampleWorksLimit = 3
+)
+
+// RecommendedUser is a follow suggestion on the discovery surface,
+// carrying a few sample works.
+type RecommendedUser struct {
+	ID          string
+	Name        string
+	Avatar      string
+	Comment     string
+	SampleWorks []ArtworkItem
+}
+
+// RecommendedTag is a tag suggestion with its translation and a cover work.
+type RecommendedTag struct {
+	Name        string `json:"tag"`
+	Translation string `json:"trendTranslation"`
+	IllustID    string `json:"illustId"`
+	Thumbnail   string `json:"url"`
+}
+
+// GetRecommendedUsers retrieves users suggested for the requesting user to
+// follow. Sample works respect the filter profile, so hidden categories and
+// blacklisted entries never surface as suggestions.
+func GetRecommendedUsers(r *http.Request) ([]RecommendedUser, error) {
+	var (
+		users    []*User
+		artworks []ArtworkItem
+	)
+
+	resp, err := requests.GetJSONBody(
+		r.Context(),
+		GetDiscoveryUserURL(recommendedUsersLimit),
+		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+		r.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = RewriteEscapedImageURLs(r, resp)
+
+	if err = json.Unmarshal([]byte(gjson.GetBytes(resp, "users").Raw), &users); err != nil {
+		return nil, fmt.Errorf("error unmarshalling users in GetRecommendedUsers: %w", err)
+	}
+
+	if err = json.Unmarshal([]byte(gjson.GetBytes(resp, "thumbnails.illust").Raw), &artworks); err != nil {
+		return nil, fmt.Errorf("error unmarshalling artworks in GetRecommendedUsers: %w", err)
+	}
+
+	associateContentWithUsers(users, artworks, nil)
+
+	cookies := map[cookie.CookieName]string{
+		cookie.FilterProfileCookie: untrusted.GetCookie(r, cookie.FilterProfileCookie),
+	}
+
+	recommended := make([]RecommendedUser, 0, len(users))
+
+	for _, user := range users {
+		rec := RecommendedUser{
+			ID:      user.ID,
+			Name:    user.Name,
+			Avatar:  user.Avatar,
+			Comment: user.Comment,
+		}
+
+		if rec.Avatar == "" {
+			rec.Avatar = FallbackAvatarURL(r)
+		}
+
+		for _, work := range user.Artworks {
+			if len(rec.SampleWorks) == recommendedSampleWorksLimit {
+				break
+			}
+
+			if work.ShouldHide(cookies) {
+				continue
+			}
+
+			if err := work.PopulateThumbnails(); err != nil {
+				return nil, fmt.Errorf("failed to populate thumbnails for artwork ID %s: %w", work.ID, err)
+			}
+
+			rec.SampleWorks = append(rec.SampleWorks, work)
+		}
+
+		recommended = append(recommended, rec)
+	}
+
+	return recommended, nil
+}
+
+// GetRecommendedTags retrieves the tags pixiv currently suggests exploring,
+// with translations and proxied cover thumbnails.
+func GetRecommendedTags(r *http.Request) ([]RecommendedTag, error) {
+	var tags []RecommendedTag
+
+	resp, err := requests.GetJSONBody(
+		r.Context(),
+		GetTrendingTagsURL(),
+		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+		r.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = RewriteEscapedImageURLs(r, resp)
+
+	if err = json.Unmarshal([]byte(gjson.GetBytes(resp, "trendTags").Raw), &tags); err != nil {
+		return nil, fmt.Errorf("error unmarshalling tags in GetRecommendedTags: %w", err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// recommendedUsersFixture carries one user with a safe and an R-18 sample
+// work, and one user without works.
+const recommendedUsersFixture = `{"error":false,"body":{
+	"users":[
+		{"userId":"100","name":"Artist A","imageBig":"https://i.pximg.net/user-profile/img/2024/01/01/00/00/00/100_abc.png","comment":"hello"},
+		{"userId":"200","name":"Artist B","imageBig":""}
+	],
+	"thumbnails":{"illust":[
+		{"id":"1001","title":"Safe work","userId":"100","xRestrict":0,
+			"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/01\/01\/00\/00\/00\/1001_p0_square1200.jpg"},
+		{"id":"1002","title":"R18 work","userId":"100","xRestrict":1,
+			"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/01\/01\/00\/00\/00\/1002_p0_square1200.jpg"}
+	]}
+}}`
+
+const recommendedTagsFixture = `{"error":false,"body":{
+	"trendTags":[
+		{"tag":"オリジナル","trendTranslation":"original","illustId":"2001",
+			"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/02\/02\/00\/00\/00\/2001_p0_square1200.jpg"},
+		{"tag":"風景","trendTranslation":"scenery","illustId":"2002",
+			"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/02\/02\/00\/00\/00\/2002_p0_square1200.jpg"}
+	]
+}}`
+
+// recommendedStubTransport serves the fixture matching the requested path.
+type recommendedStubTransport struct{}
+
+func (recommendedStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := recommendedUsersFixture
+	if strings.Contains(req.URL.Path, "trending-tags") {
+		body = recommendedTagsFixture
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func setupRecommendedTest(t *testing.T) {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+	config.Global.ContentProxies.Static = url.URL{Path: "/proxy/s.pximg.net"}
+
+	utils.HTTPClient.Transport = recommendedStubTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+}
+
+func TestGetRecommendedUsers(t *testing.T) {
+	setupRecommendedTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/discovery/users", nil)
+
+	users, err := GetRecommendedUsers(r)
+	if err != nil {
+		t.Fatalf("GetRecommendedUsers() error: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("GetRecommendedUsers() returned %d users, want 2", len(users))
+	}
+
+	first := users[0]
+	if first.ID != "100" || first.Name != "Artist A" {
+		t.Errorf("users[0] = %s/%s, want 100/Artist A", first.ID, first.Name)
+	}
+
+	if !strings.HasPrefix(first.Avatar, "/proxy/i.pximg.net/") {
+		t.Errorf("users[0].Avatar = %q, want a proxied URL", first.Avatar)
+	}
+
+	if len(first.SampleWorks) != 2 {
+		t.Fatalf("users[0] has %d sample works, want 2 with the default profile", len(first.SampleWorks))
+	}
+
+	if first.SampleWorks[0].Thumbnails.Webp_1200 == "" {
+		t.Error("sample work thumbnails were not populated")
+	}
+
+	// A user without works still surfaces, with the fallback avatar.
+	second := users[1]
+	if len(second.SampleWorks) != 0 {
+		t.Errorf("users[1] has %d sample works, want 0", len(second.SampleWorks))
+	}
+
+	if second.Avatar == "" {
+		t.Error("users[1].Avatar is empty, want the fallback avatar")
+	}
+}
+
+func TestGetRecommendedUsers_FilterProfile(t *testing.T) {
+	setupRecommendedTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/discovery/users", nil)
+	r.AddCookie(&http.Cookie{
+		Name:  string(cookie.FilterProfileCookie),
+		Value: url.QueryEscape(`{"v":1,"r15":"show","r18":"hide","r18g":"hide","ai":"show"}`),
+	})
+
+	users, err := GetRecommendedUsers(r)
+	if err != nil {
+		t.Fatalf("GetRecommendedUsers() error: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("GetRecommendedUsers() returned %d users, want 2", len(users))
+	}
+
+	works := users[0].SampleWorks
+	if len(works) != 1 {
+		t.Fatalf("users[0] has %d sample works, want 1 with R-18 hidden", len(works))
+	}
+
+	if works[0].ID != "1001" {
+		t.Errorf("remaining sample work = %s, want the safe work 1001", works[0].ID)
+	}
+}
+
+func TestGetRecommendedTags(t *testing.T) {
+	setupRecommendedTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/discovery", nil)
+
+	tags, err := GetRecommendedTags(r)
+	if err != nil {
+		t.Fatalf("GetRecommendedTags() error: %v", err)
+	}
+
+	if len(tags) != 2 {
+		t.Fatalf("GetRecommendedTags() returned %d tags, want 2", len(tags))
+	}
+
+	if tags[0].Name != "オリジナル" || tags[0].Translation != "original" {
+		t.Errorf("tags[0] = %s/%s, want オリジナル/original", tags[0].Name, tags[0].Translation)
+	}
+
+	for i, tag := range tags {
+		if !strings.HasPrefix(tag.Thumbnail, "/proxy/i.pximg.net/") {
+			t.Errorf("tags[%d].Thumbnail = %q, want a proxied URL", i, tag.Thumbnail)
+		}
+	}
+}
@@ -5,14 +5,18 @@ package requests
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"fmt"
 	"hash/fnv"
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -33,6 +37,84 @@ var (
 	}
 )
 
+// userInfoPathRe matches the user basic information endpoint (/ajax/user/{id}),
+// excluding deeper user endpoints such as /ajax/user/{id}/profile/all.
+var userInfoPathRe = regexp.MustCompile(`^/ajax/user/\d+$`)
+
+// cacheTTLOverride pairs a URL prefix with the TTL applied to matching responses.
+type cacheTTLOverride struct {
+	prefix string
+	ttl    time.Duration
+}
+
+var (
+	cacheTTLOverrideMu sync.RWMutex
+
+	// cacheTTLOverrides is kept sorted by decreasing prefix length so the
+	// most specific override wins.
+	cacheTTLOverrides []cacheTTLOverride
+)
+
+// RegisterCacheTTL registers a TTL override for cached responses whose URL
+// starts with prefix, letting individual pixiv endpoints get a freshness that
+// matches how often they change. The GetXxxURL helpers produce predictable
+// prefixes to match against. Registering a prefix again replaces its TTL;
+// unmatched URLs keep the global Cache.TTL.
+func RegisterCacheTTL(prefix string, ttl time.Duration) {
+	cacheTTLOverrideMu.Lock()
+	defer cacheTTLOverrideMu.Unlock()
+
+	for i, override := range cacheTTLOverrides {
+		if override.prefix == prefix {
+			cacheTTLOverrides[i].ttl = ttl
+
+			return
+		}
+	}
+
+	cacheTTLOverrides = append(cacheTTLOverrides, cacheTTLOverride{prefix: prefix, ttl: ttl})
+
+	sort.SliceStable(cacheTTLOverrides, func(i, j int) bool {
+		return len(cacheTTLOverrides[i].prefix) > len(cacheTTLOverrides[j].prefix)
+	})
+}
+
+// lookupCacheTTLOverride returns the registered override for rawURL, if any.
+func lookupCacheTTLOverride(rawURL string) (time.Duration, bool) {
+	cacheTTLOverrideMu.RLock()
+	defer cacheTTLOverrideMu.RUnlock()
+
+	for _, override := range cacheTTLOverrides {
+		if strings.HasPrefix(rawURL, override.prefix) {
+			return override.ttl, true
+		}
+	}
+
+	return 0, false
+}
+
+// cacheTTLForURL returns the TTL to apply when storing a response for rawURL.
+//
+// Registered per-endpoint overrides take precedence. User basic information
+// (name, avatar) is fetched on nearly every page but changes rarely, so it
+// gets its own, longer TTL than the global Cache.TTL.
+func cacheTTLForURL(rawURL string) time.Duration {
+	if ttl, ok := lookupCacheTTLOverride(rawURL); ok {
+		return ttl
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return config.Global.Cache.TTL
+	}
+
+	if userInfoPathRe.MatchString(path.Clean(parsedURL.Path)) && config.Global.Cache.UserInfoTTL > 0 {
+		return config.Global.Cache.UserInfoTTL
+	}
+
+	return config.Global.Cache.TTL
+}
+
 // cachedItem represents a cached HTTP response's components along with its expiration time and original URL.
 type cachedItem struct {
 	StatusCode int
@@ -40,6 +122,12 @@ type cachedItem struct {
 	Body       []byte
 	ExpiresAt  time.Time
 	URL        string
+
+	// ETag and LastModified hold the validators from the original response
+	// so an expired entry can be refetched conditionally; a 304 then reuses
+	// Body instead of re-downloading it.
+	ETag         string
+	LastModified string
 }
 
 // cachePolicy defines the caching behavior for a request.
@@ -50,6 +138,15 @@ type cachePolicy struct {
 
 	// The cached item if available and valid.
 	cachedItem *cachedItem
+
+	// Whether cachedItem is past its ExpiresAt but within the
+	// stale-while-revalidate grace window; the caller should serve it and
+	// kick off a background refresh.
+	stale bool
+
+	// An expired item that carried validators; the upstream fetch should be
+	// made conditional and reuse its body on a 304.
+	revalidate *cachedItem
 }
 
 // Setup initializes the API response cache based on parameters in GlobalConfig.
@@ -58,8 +155,8 @@ type cachePolicy struct {
 // If caching is disabled in the configuration, it skips initialization.
 func Setup() {
 	if !config.Global.Cache.Enabled {
-		log.Info().
-			Msg("Cache is disabled, skipping cache initialization")
+		log.Warn().
+			Msg("API response cache is disabled; every request hits pixiv directly")
 
 		return
 	}
@@ -74,6 +171,99 @@ func Setup() {
 	log.Info().
 		Int("size", config.Global.Cache.Size).
 		Msg("Initialized API response cache")
+
+	if diskCacheEnabled() {
+		setupDiskCache()
+	}
+
+	go logCacheStats()
+}
+
+// cacheStatsLogInterval is how often cache effectiveness counters are logged.
+const cacheStatsLogInterval = time.Hour
+
+// cacheStatsWarnMinLookups is the minimum number of lookups within one stats
+// interval before a 0% hit rate is treated as a misconfiguration signal
+// rather than an idle instance.
+const cacheStatsWarnMinLookups = 100
+
+// logCacheStats periodically logs the cache effectiveness counters so
+// operators can tell whether the response cache is actually helping.
+func logCacheStats() {
+	ticker := time.NewTicker(cacheStatsLogInterval)
+	defer ticker.Stop()
+
+	var prev lrucache.Stats
+
+	for range ticker.C {
+		stats := cache.Stats()
+
+		log.Info().
+			Int64("hits", stats.Hits).
+			Int64("misses", stats.Misses).
+			Int64("evictions", stats.Evictions).
+			Int64("compressionSavedBytes", stats.CompressionSaved).
+			Int("entries", cache.Len()).
+			Msg("API response cache stats")
+
+		if zeroHitRateUnderTraffic(stats.Hits-prev.Hits, stats.Misses-prev.Misses) {
+			log.Warn().
+				Int64("lookups", stats.Misses-prev.Misses).
+				Msg("API response cache served no hits despite traffic; check the cache configuration")
+		}
+
+		prev = stats
+	}
+}
+
+// zeroHitRateUnderTraffic reports whether an interval saw enough lookups to
+// expect at least one hit yet produced none.
+func zeroHitRateUnderTraffic(hits, misses int64) bool {
+	return hits == 0 && misses >= cacheStatsWarnMinLookups
+}
+
+// CacheStatus is a point-in-time summary of the API response cache, exposed
+// through the health endpoint so silent cache misconfiguration is visible to
+// operators.
+type CacheStatus struct {
+	Enabled bool    `json:"enabled"`
+	Size    int     `json:"size"`    // configured capacity, in entries
+	Entries int     `json:"entries"` // entries currently stored
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hitRate"` // hits over all lookups; 0 before any lookup
+}
+
+// GetCacheStatus reports whether the response cache is running and how
+// effective it has been since startup.
+func GetCacheStatus() CacheStatus {
+	status := CacheStatus{
+		Enabled: config.Global.Cache.Enabled && cache != nil,
+		Size:    config.Global.Cache.Size,
+	}
+
+	if !status.Enabled {
+		return status
+	}
+
+	stats := cache.Stats()
+	status.Entries = cache.Len()
+	status.Hits = stats.Hits
+	status.Misses = stats.Misses
+	status.HitRate = cacheHitRate(stats.Hits, stats.Misses)
+
+	return status
+}
+
+// cacheHitRate returns hits as a fraction of all lookups, or 0 when there
+// have been none.
+func cacheHitRate(hits, misses int64) float64 {
+	total := hits + misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(total)
 }
 
 // The `generateCacheKey` function securely binds cached responses to both the request URL and the full authenticated
@@ -144,6 +334,22 @@ func determineCachePolicy(rawURL, userToken string, headers http.Header) cachePo
 					shouldUseCache: true, // We are using the cache.
 					cachedItem:     &item,
 				}
+			} else if grace := config.Global.Cache.StaleWhileRevalidate; grace > 0 &&
+				time.Now().Before(item.ExpiresAt.Add(grace)) {
+				// Expired but within the grace window: serve the stale item
+				// while a background refresh replaces it.
+				return cachePolicy{
+					shouldUseCache: true,
+					cachedItem:     &item,
+					stale:          true,
+				}
+			} else if item.ETag != "" || item.LastModified != "" {
+				// Expired, but the original response carried validators:
+				// keep the body around for a conditional refetch.
+				return cachePolicy{
+					shouldUseCache: true,
+					revalidate:     &item,
+				}
 			} else {
 				// Item has expired.
 				cache.Remove(cacheKey)
@@ -151,12 +357,59 @@ func determineCachePolicy(rawURL, userToken string, headers http.Header) cachePo
 		}
 	}
 
+	// On a memory miss, try the persistent tier before hitting pixiv.
+	if diskCacheEnabled() {
+		if item, encoded, ok := readDiskCache(cacheKey); ok {
+			// Promote the entry so subsequent lookups stay in memory.
+			cache.Add(cacheKey, encoded)
+
+			return cachePolicy{
+				shouldUseCache: true,
+				cachedItem:     item,
+			}
+		}
+	}
+
 	// No valid cached item was found. Decide whether to store the next response.
 	return cachePolicy{
 		shouldUseCache: !strings.Contains(lowerCacheControl, "no-store"),
 	}
 }
 
+// backgroundRefreshTimeout bounds a stale-while-revalidate upstream fetch.
+const backgroundRefreshTimeout = 30 * time.Second
+
+// refreshInFlight tracks cache keys with a background refresh running so a
+// thundering herd of stale hits spawns exactly one upstream fetch per key.
+var refreshInFlight sync.Map
+
+// refreshInBackground re-fetches opts.URL in a goroutine and stores the fresh
+// response under the stale entry's cache key. Concurrent calls for the same
+// key are deduplicated; only the first starts a fetch.
+func refreshInBackground(opts RequestOptions, userToken string) {
+	cacheKey := generateCacheKey(opts.URL, userToken)
+
+	if _, loaded := refreshInFlight.LoadOrStore(cacheKey, struct{}{}); loaded {
+		return
+	}
+
+	go func() {
+		defer refreshInFlight.Delete(cacheKey)
+
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundRefreshTimeout)
+		defer cancel()
+
+		refreshOpts := opts
+		refreshOpts.forceRefresh = true
+		// The refresh outlives the request that triggered it.
+		refreshOpts.IncomingHeaders = nil
+
+		if _, _, err := Do(ctx, refreshOpts); err != nil {
+			log.Warn().Err(err).Str("url", opts.URL).Msg("Background cache refresh failed")
+		}
+	}()
+}
+
 // InvalidateURLs removes all cached items where the cached URL starts with any of the provided URL prefixes.
 //
 // Takes a slice of URL prefixes to invalidate and returns the number of cache entries removed and their full URLs.
@@ -0,0 +1,614 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests/lrucache"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// setupCacheTest enables the cache with a fresh LRU store and restores the
+// previous package and config state when the test completes.
+func setupCacheTest(t *testing.T) {
+	t.Helper()
+
+	origConfig := config.Global
+	origCache := cache
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		cache = origCache
+	})
+
+	config.Global.Cache.Enabled = true
+	config.Global.Cache.TTL = time.Hour
+	config.Global.Cache.UserInfoTTL = 6 * time.Hour
+
+	var err error
+
+	cache, err = lrucache.NewLRUCache(10, false)
+	if err != nil {
+		t.Fatalf("NewLRUCache() error: %v", err)
+	}
+}
+
+func TestCacheTTLForURL(t *testing.T) {
+	setupCacheTest(t)
+
+	cases := []struct {
+		name string
+		url  string
+		want time.Duration
+	}{
+		{"User basic information", "https://www.pixiv.net/ajax/user/123456?full=1", 6 * time.Hour},
+		{"User profile works", "https://www.pixiv.net/ajax/user/123456/profile/all", time.Hour},
+		{"Artwork endpoint", "https://www.pixiv.net/ajax/illust/987654", time.Hour},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cacheTTLForURL(tc.url); got != tc.want {
+				t.Errorf("cacheTTLForURL(%q) = %v, want %v", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCacheTTLForURL_Unconfigured(t *testing.T) {
+	setupCacheTest(t)
+
+	// A non-positive UserInfoTTL falls back to the global TTL.
+	config.Global.Cache.UserInfoTTL = 0
+
+	if got := cacheTTLForURL("https://www.pixiv.net/ajax/user/123456?full=1"); got != time.Hour {
+		t.Errorf("cacheTTLForURL() = %v, want %v", got, time.Hour)
+	}
+}
+
+func TestDetermineCachePolicy_RepeatedUserInfoLookups(t *testing.T) {
+	setupCacheTest(t)
+
+	const (
+		rawURL    = "https://www.pixiv.net/ajax/user/123456?full=1"
+		userToken = "123456_sessionsecret"
+	)
+
+	// First lookup misses but allows storing the response.
+	policy := determineCachePolicy(rawURL, userToken, http.Header{})
+	if !policy.shouldUseCache || policy.cachedItem != nil {
+		t.Fatalf("Expected a cache miss with store permission, got %+v", policy)
+	}
+
+	// Store a response the way Do does.
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cachedItem{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       []byte(`{"name":"test"}`),
+		ExpiresAt:  time.Now().Add(cacheTTLForURL(rawURL)),
+		URL:        rawURL,
+	}); err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+
+	cache.Add(generateCacheKey(rawURL, userToken), buf.Bytes())
+
+	// Repeated lookups within the TTL must hit the cache.
+	policy = determineCachePolicy(rawURL, userToken, http.Header{})
+	if policy.cachedItem == nil {
+		t.Fatal("Expected a cache hit for a repeated lookup within the TTL")
+	}
+
+	if got := string(policy.cachedItem.Body); got != `{"name":"test"}` {
+		t.Errorf("Cached body = %q, want %q", got, `{"name":"test"}`)
+	}
+
+	// A different session token must not see the cached entry.
+	policy = determineCachePolicy(rawURL, "654321_othersecret", http.Header{})
+	if policy.cachedItem != nil {
+		t.Error("Expected a cache miss for a different session token")
+	}
+}
+
+// storeCachedItem inserts an already-encoded cachedItem the way Do does.
+func storeCachedItem(t *testing.T, rawURL, userToken string, item cachedItem) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(item); err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+
+	cache.Add(generateCacheKey(rawURL, userToken), buf.Bytes())
+}
+
+func TestDetermineCachePolicy_StaleWhileRevalidate(t *testing.T) {
+	setupCacheTest(t)
+
+	config.Global.Cache.StaleWhileRevalidate = time.Minute
+
+	const (
+		rawURL    = "https://www.pixiv.net/ajax/illust/987654"
+		userToken = "123456_sessionsecret"
+	)
+
+	// Expired ten seconds ago: still within the one-minute grace window.
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"stale":true}`),
+		ExpiresAt:  time.Now().Add(-10 * time.Second),
+		URL:        rawURL,
+	})
+
+	policy := determineCachePolicy(rawURL, userToken, http.Header{})
+	if policy.cachedItem == nil || !policy.stale {
+		t.Fatalf("Expected a stale cache hit within the grace window, got %+v", policy)
+	}
+
+	// Expired beyond the grace window: treated as a miss and removed.
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"stale":true}`),
+		ExpiresAt:  time.Now().Add(-2 * time.Minute),
+		URL:        rawURL,
+	})
+
+	policy = determineCachePolicy(rawURL, userToken, http.Header{})
+	if policy.cachedItem != nil {
+		t.Fatalf("Expected a miss past the grace window, got %+v", policy)
+	}
+
+	// With the grace window disabled, a freshly-expired item is also a miss.
+	config.Global.Cache.StaleWhileRevalidate = 0
+
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"stale":true}`),
+		ExpiresAt:  time.Now().Add(-10 * time.Second),
+		URL:        rawURL,
+	})
+
+	if policy = determineCachePolicy(rawURL, userToken, http.Header{}); policy.cachedItem != nil {
+		t.Fatalf("Expected a miss with stale serving disabled, got %+v", policy)
+	}
+}
+
+// gatedTransport counts upstream fetches and holds them until released.
+type gatedTransport struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (g *gatedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	g.calls.Add(1)
+	<-g.release
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"fresh":true}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestDo_StaleWhileRevalidate_SingleRefresh(t *testing.T) {
+	setupCacheTest(t)
+
+	config.Global.Cache.StaleWhileRevalidate = time.Minute
+
+	const (
+		rawURL    = "https://www.pixiv.net/ajax/illust/987654"
+		userToken = "123456_sessionsecret"
+	)
+
+	transport := &gatedTransport{release: make(chan struct{})}
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"stale":true}`),
+		ExpiresAt:  time.Now().Add(-10 * time.Second),
+		URL:        rawURL,
+	})
+
+	opts := RequestOptions{
+		Method:  http.MethodGet,
+		URL:     rawURL,
+		Cookies: map[string]string{"PHPSESSID": userToken},
+	}
+
+	// Every request during the refresh is served the stale body immediately.
+	for range 3 {
+		resp, body, err := Do(t.Context(), opts)
+		if err != nil {
+			t.Fatalf("Do() error: %v", err)
+		}
+
+		if got := string(body); got != `{"stale":true}` {
+			t.Fatalf("Do() body = %q, want the stale body", got)
+		}
+
+		if got := resp.Header.Get("X-Pixivfe-Cache"); got != "stale" {
+			t.Errorf("X-Pixivfe-Cache = %q, want %q", got, "stale")
+		}
+	}
+
+	close(transport.release)
+
+	// Wait for the background refresh to store the fresh response.
+	deadline := time.Now().Add(2 * time.Second)
+
+	for {
+		if _, body, err := Do(t.Context(), opts); err == nil && string(body) == `{"fresh":true}` {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Background refresh never replaced the stale entry")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := transport.calls.Load(); got != 1 {
+		t.Errorf("Upstream fetches = %d, want exactly 1", got)
+	}
+
+	// Wait for the refresh goroutine to fully exit so its trailing reads of
+	// package globals cannot race with the cleanup restoring them.
+	for {
+		if _, inFlight := refreshInFlight.Load(generateCacheKey(rawURL, userToken)); !inFlight {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Background refresh goroutine never exited")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRegisterCacheTTL(t *testing.T) {
+	setupCacheTest(t)
+
+	origOverrides := cacheTTLOverrides
+
+	t.Cleanup(func() {
+		cacheTTLOverrideMu.Lock()
+		cacheTTLOverrides = origOverrides
+		cacheTTLOverrideMu.Unlock()
+	})
+
+	RegisterCacheTTL("https://www.pixiv.net/ajax/search/", 5*time.Minute)
+	RegisterCacheTTL("https://www.pixiv.net/ajax/search/tags/", time.Minute)
+
+	cases := []struct {
+		name string
+		url  string
+		want time.Duration
+	}{
+		{"Matched prefix", "https://www.pixiv.net/ajax/search/artworks/fanart?p=1", 5 * time.Minute},
+		{"Most specific prefix wins", "https://www.pixiv.net/ajax/search/tags/fanart", time.Minute},
+		{"Unmatched URL keeps the global TTL", "https://www.pixiv.net/ajax/illust/987654", time.Hour},
+		{"Unmatched URL keeps dedicated TTLs", "https://www.pixiv.net/ajax/user/123456?full=1", 6 * time.Hour},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cacheTTLForURL(tc.url); got != tc.want {
+				t.Errorf("cacheTTLForURL(%q) = %v, want %v", tc.url, got, tc.want)
+			}
+		})
+	}
+
+	// Re-registering a prefix replaces its TTL.
+	RegisterCacheTTL("https://www.pixiv.net/ajax/search/tags/", 30*time.Second)
+
+	if got := cacheTTLForURL("https://www.pixiv.net/ajax/search/tags/fanart"); got != 30*time.Second {
+		t.Errorf("cacheTTLForURL() after re-registration = %v, want %v", got, 30*time.Second)
+	}
+}
+
+// conditionalTransport answers 304 when the request presents the expected
+// validator and a fresh 200 response otherwise, recording what it was sent.
+type conditionalTransport struct {
+	etag            string
+	freshBody       string
+	calls           atomic.Int32
+	gotIfNoneMatch  string
+	gotIfModifiedAt string
+}
+
+func (c *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+	c.gotIfNoneMatch = req.Header.Get("If-None-Match")
+	c.gotIfModifiedAt = req.Header.Get("If-Modified-Since")
+
+	if c.gotIfNoneMatch == c.etag {
+		return &http.Response{
+			StatusCode: http.StatusNotModified,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type": {"application/json"},
+			"Etag":         {c.etag},
+		},
+		Body:    io.NopCloser(strings.NewReader(c.freshBody)),
+		Request: req,
+	}, nil
+}
+
+// setupConditionalTest wires a conditionalTransport behind the cache setup.
+func setupConditionalTest(t *testing.T, transport *conditionalTransport) {
+	t.Helper()
+
+	setupCacheTest(t)
+
+	config.Global.Cache.StaleWhileRevalidate = 0
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+}
+
+// decodeCachedItem fetches and decodes the cache entry for the given request.
+func decodeCachedItem(t *testing.T, rawURL, userToken string) cachedItem {
+	t.Helper()
+
+	cached, found := cache.Get(generateCacheKey(rawURL, userToken))
+	if !found {
+		t.Fatal("No cache entry found")
+	}
+
+	var item cachedItem
+	if err := gob.NewDecoder(bytes.NewReader(cached.([]byte))).Decode(&item); err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+
+	return item
+}
+
+func TestDo_Revalidation304ReusesStoredBody(t *testing.T) {
+	const (
+		rawURL    = "https://www.pixiv.net/ajax/illust/987654"
+		userToken = "123456_sessionsecret"
+		etag      = `W/"abc123"`
+		modified  = "Mon, 02 Jan 2006 15:04:05 GMT"
+	)
+
+	transport := &conditionalTransport{etag: etag, freshBody: `{"fresh":true}`}
+	setupConditionalTest(t, transport)
+
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode:   http.StatusOK,
+		Body:         []byte(`{"cached":true}`),
+		ExpiresAt:    time.Now().Add(-10 * time.Minute),
+		URL:          rawURL,
+		ETag:         etag,
+		LastModified: modified,
+	})
+
+	opts := RequestOptions{
+		Method:  http.MethodGet,
+		URL:     rawURL,
+		Cookies: map[string]string{"PHPSESSID": userToken},
+	}
+
+	resp, body, err := Do(t.Context(), opts)
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+
+	if got := string(body); got != `{"cached":true}` {
+		t.Errorf("Do() body = %q, want the stored body after a 304", got)
+	}
+
+	if got := resp.Header.Get("X-Pixivfe-Cache"); got != "revalidated" {
+		t.Errorf("X-Pixivfe-Cache = %q, want %q", got, "revalidated")
+	}
+
+	if transport.gotIfNoneMatch != etag || transport.gotIfModifiedAt != modified {
+		t.Errorf("Conditional headers = (%q, %q), want (%q, %q)",
+			transport.gotIfNoneMatch, transport.gotIfModifiedAt, etag, modified)
+	}
+
+	// The stored entry got a fresh expiry, so the next call is a cache hit.
+	if item := decodeCachedItem(t, rawURL, userToken); !item.ExpiresAt.After(time.Now()) {
+		t.Errorf("Cached ExpiresAt = %v, want a bumped expiry in the future", item.ExpiresAt)
+	}
+
+	if _, body, err := Do(t.Context(), opts); err != nil || string(body) != `{"cached":true}` {
+		t.Fatalf("Do() after revalidation = (%q, %v), want a cache hit on the stored body", body, err)
+	}
+
+	if got := transport.calls.Load(); got != 1 {
+		t.Errorf("Upstream fetches = %d, want exactly 1", got)
+	}
+}
+
+func TestDo_RevalidationReplacesChangedEntry(t *testing.T) {
+	const (
+		rawURL    = "https://www.pixiv.net/ajax/illust/987654"
+		userToken = "123456_sessionsecret"
+	)
+
+	transport := &conditionalTransport{etag: `W/"v2"`, freshBody: `{"fresh":true}`}
+	setupConditionalTest(t, transport)
+
+	// The stored validator no longer matches, so the server sends a full
+	// response that must replace the entry.
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"cached":true}`),
+		ExpiresAt:  time.Now().Add(-10 * time.Minute),
+		URL:        rawURL,
+		ETag:       `W/"v1"`,
+	})
+
+	_, body, err := Do(t.Context(), RequestOptions{
+		Method:  http.MethodGet,
+		URL:     rawURL,
+		Cookies: map[string]string{"PHPSESSID": userToken},
+	})
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+
+	if got := string(body); got != `{"fresh":true}` {
+		t.Errorf("Do() body = %q, want the fresh body", got)
+	}
+
+	if item := decodeCachedItem(t, rawURL, userToken); item.ETag != `W/"v2"` {
+		t.Errorf("Cached ETag = %q, want the replacement validator", item.ETag)
+	}
+}
+
+func TestDetermineCachePolicy_ExpiredValidators(t *testing.T) {
+	setupCacheTest(t)
+
+	config.Global.Cache.StaleWhileRevalidate = 0
+
+	const (
+		rawURL    = "https://www.pixiv.net/ajax/illust/987654"
+		userToken = "123456_sessionsecret"
+	)
+
+	// An expired entry with validators is kept for a conditional refetch.
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"cached":true}`),
+		ExpiresAt:  time.Now().Add(-10 * time.Minute),
+		URL:        rawURL,
+		ETag:       `W/"abc"`,
+	})
+
+	policy := determineCachePolicy(rawURL, userToken, http.Header{})
+	if policy.cachedItem != nil || policy.revalidate == nil || !policy.shouldUseCache {
+		t.Fatalf("Expected a revalidation policy for an expired entry with validators, got %+v", policy)
+	}
+
+	// Without validators the expired entry is simply removed.
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"cached":true}`),
+		ExpiresAt:  time.Now().Add(-10 * time.Minute),
+		URL:        rawURL,
+	})
+
+	policy = determineCachePolicy(rawURL, userToken, http.Header{})
+	if policy.cachedItem != nil || policy.revalidate != nil {
+		t.Fatalf("Expected a plain miss for an expired entry without validators, got %+v", policy)
+	}
+}
+
+func TestGetCacheStatus(t *testing.T) {
+	setupCacheTest(t)
+
+	config.Global.Cache.Size = 10
+
+	// A fresh cache reports itself enabled but empty and untouched.
+	status := GetCacheStatus()
+	if !status.Enabled || status.Size != 10 || status.Entries != 0 || status.HitRate != 0 {
+		t.Fatalf("GetCacheStatus() = %+v, want an enabled, empty cache", status)
+	}
+
+	// One stored entry, one hit, one miss.
+	cache.Add("key", []byte("value"))
+
+	if _, ok := cache.Get("key"); !ok {
+		t.Fatal("Get() on a stored key missed")
+	}
+
+	if _, ok := cache.Get("absent"); ok {
+		t.Fatal("Get() on an absent key hit")
+	}
+
+	status = GetCacheStatus()
+	if status.Entries != 1 || status.Hits != 1 || status.Misses != 1 {
+		t.Errorf("GetCacheStatus() = %+v, want 1 entry, 1 hit, 1 miss", status)
+	}
+
+	if status.HitRate != 0.5 {
+		t.Errorf("HitRate = %v, want 0.5", status.HitRate)
+	}
+}
+
+func TestGetCacheStatus_Disabled(t *testing.T) {
+	setupCacheTest(t)
+
+	config.Global.Cache.Enabled = false
+
+	status := GetCacheStatus()
+	if status.Enabled {
+		t.Errorf("GetCacheStatus() = %+v, want Enabled false when caching is off", status)
+	}
+
+	// A nil cache store also reports disabled, even with the flag set.
+	config.Global.Cache.Enabled = true
+	cache = nil
+
+	if status := GetCacheStatus(); status.Enabled {
+		t.Errorf("GetCacheStatus() = %+v, want Enabled false with no cache store", status)
+	}
+}
+
+func TestZeroHitRateUnderTraffic(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		hits   int64
+		misses int64
+		want   bool
+	}{
+		{"idle instance", 0, 0, false},
+		{"light traffic without hits", 0, cacheStatsWarnMinLookups - 1, false},
+		{"sustained traffic without hits", 0, cacheStatsWarnMinLookups, true},
+		{"traffic with hits", 1, 10 * cacheStatsWarnMinLookups, false},
+	}
+
+	for _, tc := range cases {
+		if got := zeroHitRateUnderTraffic(tc.hits, tc.misses); got != tc.want {
+			t.Errorf("zeroHitRateUnderTraffic(%d, %d) = %t, want %t (%s)",
+				tc.hits, tc.misses, got, tc.want, tc.name)
+		}
+	}
+}
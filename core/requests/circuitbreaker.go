@@ -0,0 +1,147 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Circuit breaker tuning for the pixiv upstream.
+const (
+	// breakerMinSamples is how many outcomes must be observed before the
+	// failure ratio is evaluated.
+	breakerMinSamples = 10
+
+	// breakerFailureRatio is the failure fraction that trips the breaker.
+	breakerFailureRatio = 0.6
+
+	// breakerCooldown is how long the breaker stays open before allowing a
+	// trial request.
+	breakerCooldown = 30 * time.Second
+
+	// breakerSampleWindow caps the counted outcomes so the ratio tracks
+	// recent behavior rather than the whole process lifetime.
+	breakerSampleWindow = 2 * breakerMinSamples
+)
+
+// ErrUpstreamUnavailable is returned (wrapped in an [APIError]) when the
+// circuit breaker is open and upstream requests are being short-circuited.
+var ErrUpstreamUnavailable = errors.New("upstream temporarily unavailable (circuit open)")
+
+// breakerState is the position of the circuit breaker.
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // requests flow normally
+	breakerOpen                         // requests are short-circuited
+	breakerHalfOpen                     // a single trial request is allowed
+)
+
+// circuitBreaker tracks the recent upstream failure ratio and short-circuits
+// requests once it crosses [breakerFailureRatio], recovering via a single
+// half-open trial request after [breakerCooldown].
+type circuitBreaker struct {
+	mu               sync.Mutex
+	state            breakerState
+	failures         int
+	successes        int
+	openedAt         time.Time
+	halfOpenInFlight bool
+
+	// now is stubbed in tests to drive cooldown expiry.
+	now func() time.Time
+}
+
+// newCircuitBreaker returns a closed breaker.
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{now: time.Now}
+}
+
+// upstreamBreaker guards requests to the pixiv API.
+var upstreamBreaker = newCircuitBreaker()
+
+// allow reports whether a new upstream request may proceed. In the open
+// state it flips to half-open once the cooldown has elapsed, admitting
+// exactly one trial request.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < breakerCooldown {
+			return false
+		}
+
+		b.state = breakerHalfOpen
+		b.halfOpenInFlight = true
+
+		return true
+	case breakerHalfOpen:
+		if b.halfOpenInFlight {
+			return false
+		}
+
+		b.halfOpenInFlight = true
+
+		return true
+	default:
+		return true
+	}
+}
+
+// record feeds one upstream outcome into the breaker.
+func (b *circuitBreaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerHalfOpen:
+		b.halfOpenInFlight = false
+
+		if success {
+			b.reset()
+		} else {
+			b.trip()
+		}
+	case breakerClosed:
+		if success {
+			b.successes++
+		} else {
+			b.failures++
+		}
+
+		total := b.successes + b.failures
+		if total < breakerMinSamples {
+			return
+		}
+
+		if float64(b.failures)/float64(total) >= breakerFailureRatio {
+			b.trip()
+		} else if total >= breakerSampleWindow {
+			// Start a fresh window so old successes cannot mask a new outage.
+			b.failures = 0
+			b.successes = 0
+		}
+	case breakerOpen:
+		// Late results from requests admitted before the trip are ignored.
+	}
+}
+
+// trip opens the breaker and starts the cooldown. Callers must hold b.mu.
+func (b *circuitBreaker) trip() {
+	b.state = breakerOpen
+	b.openedAt = b.now()
+	b.failures = 0
+	b.successes = 0
+}
+
+// reset closes the breaker and clears the counters. Callers must hold b.mu.
+func (b *circuitBreaker) reset() {
+	b.state = breakerClosed
+	b.failures = 0
+	b.successes = 0
+}
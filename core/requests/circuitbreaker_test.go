@@ -0,0 +1,194 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// tripBreaker feeds enough failures to open a closed breaker.
+func tripBreaker(b *circuitBreaker) {
+	for range breakerMinSamples {
+		b.record(false)
+	}
+}
+
+func TestCircuitBreaker_OpensOnFailureRatio(t *testing.T) {
+	t.Parallel()
+
+	b := newCircuitBreaker()
+
+	// A healthy mix below the ratio keeps the breaker closed.
+	for range breakerMinSamples {
+		b.record(true)
+	}
+
+	if !b.allow() {
+		t.Fatal("breaker opened despite all-success outcomes")
+	}
+
+	// Sustained failures trip it even though the earlier successes filled a
+	// full sample window first.
+	tripBreaker(b)
+	tripBreaker(b)
+
+	if b.allow() {
+		t.Fatal("breaker still closed after sustained failures")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenRecovery(t *testing.T) {
+	t.Parallel()
+
+	current := time.Now()
+
+	b := newCircuitBreaker()
+	b.now = func() time.Time { return current }
+
+	tripBreaker(b)
+
+	if b.allow() {
+		t.Fatal("breaker admitted a request during the cooldown")
+	}
+
+	// After the cooldown exactly one trial request is admitted.
+	current = current.Add(breakerCooldown)
+
+	if !b.allow() {
+		t.Fatal("breaker did not admit a trial request after the cooldown")
+	}
+
+	if b.allow() {
+		t.Fatal("breaker admitted a second concurrent trial request")
+	}
+
+	// A successful trial closes the breaker again.
+	b.record(true)
+
+	if !b.allow() || !b.allow() {
+		t.Fatal("breaker did not close after a successful trial")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	t.Parallel()
+
+	current := time.Now()
+
+	b := newCircuitBreaker()
+	b.now = func() time.Time { return current }
+
+	tripBreaker(b)
+
+	current = current.Add(breakerCooldown)
+
+	if !b.allow() {
+		t.Fatal("breaker did not admit a trial request after the cooldown")
+	}
+
+	// A failed trial restarts the cooldown.
+	b.record(false)
+
+	if b.allow() {
+		t.Fatal("breaker closed after a failed trial request")
+	}
+
+	current = current.Add(breakerCooldown)
+
+	if !b.allow() {
+		t.Fatal("breaker did not re-enter half-open after another cooldown")
+	}
+}
+
+func TestDo_OpenBreakerShortCircuits(t *testing.T) {
+	transport := &failingTransport{status: http.StatusBadGateway}
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+	origBreaker := upstreamBreaker
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+		upstreamBreaker = origBreaker
+	})
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	upstreamBreaker = newCircuitBreaker()
+	tripBreaker(upstreamBreaker)
+
+	_, _, err := Do(t.Context(), RequestOptions{
+		Method: http.MethodGet,
+		URL:    "https://www.pixiv.net/ajax/illust/333333",
+	})
+	if !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("Do() error = %v, want ErrUpstreamUnavailable", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Do() error = %v, want an APIError with status 503", err)
+	}
+
+	if got := transport.calls.Load(); got != 0 {
+		t.Errorf("upstream attempts = %d, want 0 while the circuit is open", got)
+	}
+}
+
+func TestDo_OpenBreakerServesCache(t *testing.T) {
+	setupCacheTest(t)
+
+	transport := &failingTransport{status: http.StatusBadGateway}
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+	origBreaker := upstreamBreaker
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+		upstreamBreaker = origBreaker
+	})
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	upstreamBreaker = newCircuitBreaker()
+	tripBreaker(upstreamBreaker)
+
+	const (
+		rawURL    = "https://www.pixiv.net/ajax/illust/444444"
+		userToken = "123456_sessionsecret"
+	)
+
+	storeCachedItem(t, rawURL, userToken, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"cached":true}`),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		URL:        rawURL,
+	})
+
+	_, body, err := Do(t.Context(), RequestOptions{
+		Method:  http.MethodGet,
+		URL:     rawURL,
+		Cookies: map[string]string{"PHPSESSID": userToken},
+	})
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+
+	if string(body) != `{"cached":true}` {
+		t.Errorf("Do() body = %q, want the cached response", body)
+	}
+}
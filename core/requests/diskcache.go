@@ -0,0 +1,133 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+const (
+	// diskCachePruneInterval is how often expired disk entries are removed.
+	diskCachePruneInterval = 10 * time.Minute
+
+	diskCacheDirPermissions  = 0o700
+	diskCacheFilePermissions = 0o600
+)
+
+// diskCacheEnabled reports whether the persistent cache tier is configured.
+func diskCacheEnabled() bool {
+	return config.Global.Cache.Enabled && config.Global.Cache.DiskPath != ""
+}
+
+// diskCachePath returns the file holding the gob-encoded cachedItem for a
+// cache key. Keys are short hex strings from generateCacheKey, so they are
+// safe to use as file names directly.
+func diskCachePath(cacheKey string) string {
+	return filepath.Join(config.Global.Cache.DiskPath, cacheKey+".gob")
+}
+
+// setupDiskCache creates the cache directory and starts the prune timer.
+func setupDiskCache() {
+	if err := os.MkdirAll(config.Global.Cache.DiskPath, diskCacheDirPermissions); err != nil {
+		log.Warn().Err(err).Str("path", config.Global.Cache.DiskPath).
+			Msg("Failed to create disk cache directory; disk tier unavailable")
+
+		return
+	}
+
+	log.Info().
+		Str("path", config.Global.Cache.DiskPath).
+		Msg("Initialized disk cache tier")
+
+	go func() {
+		ticker := time.NewTicker(diskCachePruneInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			pruneExpiredDiskCache()
+		}
+	}()
+}
+
+// writeDiskCache persists an encoded cachedItem for a cache key. Failures are
+// logged and otherwise ignored; the disk tier is best-effort.
+func writeDiskCache(cacheKey string, encoded []byte) {
+	if err := os.WriteFile(diskCachePath(cacheKey), encoded, diskCacheFilePermissions); err != nil {
+		log.Warn().Err(err).Str("key", cacheKey).Msg("Failed to write disk cache entry")
+	}
+}
+
+// readDiskCache loads the cachedItem for a cache key, returning the raw
+// encoded bytes alongside it so callers can promote the entry to the memory
+// tier without re-encoding. Expired or corrupt entries are removed.
+func readDiskCache(cacheKey string) (*cachedItem, []byte, bool) {
+	encoded, err := os.ReadFile(diskCachePath(cacheKey))
+	if err != nil {
+		return nil, nil, false
+	}
+
+	var item cachedItem
+	if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&item); err != nil {
+		log.Warn().Err(err).Str("key", cacheKey).Msg("Failed to decode disk cache entry; removing")
+		_ = os.Remove(diskCachePath(cacheKey))
+
+		return nil, nil, false
+	}
+
+	if !time.Now().Before(item.ExpiresAt) {
+		_ = os.Remove(diskCachePath(cacheKey))
+
+		return nil, nil, false
+	}
+
+	return &item, encoded, true
+}
+
+// pruneExpiredDiskCache removes every expired or unreadable entry from the
+// disk cache directory, reporting how many files were removed.
+func pruneExpiredDiskCache() int {
+	entries, err := os.ReadDir(config.Global.Cache.DiskPath)
+	if err != nil {
+		return 0
+	}
+
+	removed := 0
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".gob" {
+			continue
+		}
+
+		fullPath := filepath.Join(config.Global.Cache.DiskPath, entry.Name())
+
+		encoded, err := os.ReadFile(fullPath)
+		if err != nil {
+			continue
+		}
+
+		var item cachedItem
+		if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&item); err == nil &&
+			time.Now().Before(item.ExpiresAt) {
+			continue
+		}
+
+		if os.Remove(fullPath) == nil {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		log.Info().Int("count", removed).Msg("Pruned expired disk cache entries")
+	}
+
+	return removed
+}
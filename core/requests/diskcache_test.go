@@ -0,0 +1,148 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"bytes"
+	"encoding/gob"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests/lrucache"
+)
+
+// setupDiskCacheTest enables both cache tiers with a temporary directory.
+func setupDiskCacheTest(t *testing.T) {
+	t.Helper()
+
+	setupCacheTest(t)
+
+	config.Global.Cache.DiskPath = t.TempDir()
+}
+
+// encodeCachedItem gob-encodes a cachedItem the way Do does before storing.
+func encodeCachedItem(t *testing.T, item cachedItem) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(item); err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDiskCache_WriteAndRead(t *testing.T) {
+	setupDiskCacheTest(t)
+
+	const key = "abcd1234"
+
+	encoded := encodeCachedItem(t, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"name":"test"}`),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		URL:        "https://www.pixiv.net/ajax/illust/987654",
+	})
+
+	writeDiskCache(key, encoded)
+
+	item, gotEncoded, ok := readDiskCache(key)
+	if !ok {
+		t.Fatal("readDiskCache() missed a freshly written entry")
+	}
+
+	if got := string(item.Body); got != `{"name":"test"}` {
+		t.Errorf("Body = %q, want %q", got, `{"name":"test"}`)
+	}
+
+	if !bytes.Equal(gotEncoded, encoded) {
+		t.Error("readDiskCache() returned different encoded bytes than written")
+	}
+
+	// An expired entry is treated as a miss and removed.
+	writeDiskCache(key, encodeCachedItem(t, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"name":"old"}`),
+		ExpiresAt:  time.Now().Add(-time.Minute),
+		URL:        "https://www.pixiv.net/ajax/illust/987654",
+	}))
+
+	if _, _, ok := readDiskCache(key); ok {
+		t.Error("readDiskCache() returned an expired entry")
+	}
+
+	if _, err := os.Stat(diskCachePath(key)); !os.IsNotExist(err) {
+		t.Error("Expected the expired entry to be removed from disk")
+	}
+}
+
+func TestDiskCache_SurvivesRestart(t *testing.T) {
+	setupDiskCacheTest(t)
+
+	const (
+		rawURL    = "https://www.pixiv.net/ajax/illust/987654"
+		userToken = "123456_sessionsecret"
+	)
+
+	cacheKey := generateCacheKey(rawURL, userToken)
+
+	writeDiskCache(cacheKey, encodeCachedItem(t, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"name":"persisted"}`),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		URL:        rawURL,
+	}))
+
+	// Simulate a restart: the memory tier starts cold.
+	var err error
+
+	cache, err = lrucache.NewLRUCache(10, false)
+	if err != nil {
+		t.Fatalf("NewLRUCache() error: %v", err)
+	}
+
+	policy := determineCachePolicy(rawURL, userToken, http.Header{})
+	if policy.cachedItem == nil {
+		t.Fatal("Expected a hit from the disk tier after a cold start")
+	}
+
+	if got := string(policy.cachedItem.Body); got != `{"name":"persisted"}` {
+		t.Errorf("Body = %q, want the persisted body", got)
+	}
+
+	// The entry is promoted back into the memory tier.
+	if cache.Len() != 1 {
+		t.Errorf("Memory cache holds %d entries after promotion, want 1", cache.Len())
+	}
+}
+
+func TestDiskCache_PruneExpired(t *testing.T) {
+	setupDiskCacheTest(t)
+
+	writeDiskCache("fresh", encodeCachedItem(t, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{}`),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}))
+	writeDiskCache("expired", encodeCachedItem(t, cachedItem{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{}`),
+		ExpiresAt:  time.Now().Add(-time.Minute),
+	}))
+
+	if removed := pruneExpiredDiskCache(); removed != 1 {
+		t.Errorf("pruneExpiredDiskCache() = %d, want 1", removed)
+	}
+
+	if _, err := os.Stat(diskCachePath("fresh")); err != nil {
+		t.Error("Expected the fresh entry to survive pruning")
+	}
+
+	if _, err := os.Stat(diskCachePath("expired")); !os.IsNotExist(err) {
+		t.Error("Expected the expired entry to be pruned")
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// isForwardedHeader reports whether name is a hop header that identifies the
+// client or intermediate proxies: any X-Forwarded-* header, or the standard
+// Forwarded and Via headers.
+func isForwardedHeader(name string) bool {
+	canonical := textproto.CanonicalMIMEHeaderKey(name)
+
+	return strings.HasPrefix(canonical, "X-Forwarded-") ||
+		canonical == "Forwarded" ||
+		canonical == "Via"
+}
+
+// sanitizeForwardedHeaders strips forwarding headers from an outbound request
+// so that client network details carried by the incoming request are never
+// leaked upstream to pixiv. Forwarding headers listed in
+// Request.PreserveForwardedHeaders are instead copied over from the incoming
+// request.
+func sanitizeForwardedHeaders(outbound, incoming http.Header) {
+	for name := range outbound {
+		if isForwardedHeader(name) {
+			outbound.Del(name)
+		}
+	}
+
+	for _, name := range config.Global.Request.PreserveForwardedHeaders {
+		canonical := textproto.CanonicalMIMEHeaderKey(name)
+
+		if values, ok := incoming[canonical]; ok {
+			outbound[canonical] = slices.Clone(values)
+		}
+	}
+}
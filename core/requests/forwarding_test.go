@@ -0,0 +1,111 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+)
+
+func TestIsForwardedHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"X-Forwarded-For", true},
+		{"x-forwarded-proto", true},
+		{"X-Forwarded-Host", true},
+		{"Forwarded", true},
+		{"via", true},
+		{"User-Agent", false},
+		{"X-Real-Ip", false},
+		{"Cookie", false},
+	}
+
+	for _, tt := range tests {
+		if got := isForwardedHeader(tt.name); got != tt.want {
+			t.Errorf("isForwardedHeader(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeForwardedHeaders_Strips(t *testing.T) {
+	outbound := http.Header{}
+	outbound.Set("User-Agent", "Mozilla/5.0")
+	outbound.Set("X-Forwarded-For", "198.51.100.7")
+	outbound.Set("X-Forwarded-Proto", "https")
+	outbound.Set("Forwarded", "for=198.51.100.7")
+	outbound.Set("Via", "1.1 proxy")
+
+	sanitizeForwardedHeaders(outbound, http.Header{})
+
+	for _, name := range []string{"X-Forwarded-For", "X-Forwarded-Proto", "Forwarded", "Via"} {
+		if outbound.Get(name) != "" {
+			t.Errorf("sanitizeForwardedHeaders() left %s = %q, want it stripped", name, outbound.Get(name))
+		}
+	}
+
+	if outbound.Get("User-Agent") != "Mozilla/5.0" {
+		t.Errorf("sanitizeForwardedHeaders() touched User-Agent = %q", outbound.Get("User-Agent"))
+	}
+}
+
+func TestSanitizeForwardedHeaders_Preserve(t *testing.T) {
+	origConfig := config.Global
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+	})
+
+	config.Global.Request.PreserveForwardedHeaders = []string{"x-forwarded-proto"}
+
+	incoming := http.Header{}
+	incoming.Set("X-Forwarded-For", "198.51.100.7")
+	incoming.Set("X-Forwarded-Proto", "https")
+
+	outbound := http.Header{}
+	outbound.Set("X-Forwarded-For", "198.51.100.7")
+
+	sanitizeForwardedHeaders(outbound, incoming)
+
+	if got := outbound.Get("X-Forwarded-Proto"); got != "https" {
+		t.Errorf("preserved X-Forwarded-Proto = %q, want %q", got, "https")
+	}
+
+	if got := outbound.Get("X-Forwarded-For"); got != "" {
+		t.Errorf("X-Forwarded-For = %q, want it stripped", got)
+	}
+}
+
+// TestNewRequest_StripsForwardedHeaders guards against IncomingHeaders ever
+// leaking forwarding headers onto outbound pixiv requests.
+func TestNewRequest_StripsForwardedHeaders(t *testing.T) {
+	incoming := http.Header{}
+	incoming.Set("X-Forwarded-For", "198.51.100.7")
+	incoming.Set("Forwarded", "for=198.51.100.7")
+	incoming.Set("Via", "1.1 proxy")
+
+	opts := RequestOptions{
+		Method:          http.MethodGet,
+		URL:             "https://www.pixiv.net/ajax/top/illust",
+		IncomingHeaders: incoming,
+	}
+
+	req, err := newRequest(context.Background(), opts, &tokenmanager.Token{Value: NoToken})
+	if err != nil {
+		t.Fatalf("newRequest() error: %v", err)
+	}
+
+	for _, name := range []string{"X-Forwarded-For", "Forwarded", "Via"} {
+		if req.Header.Get(name) != "" {
+			t.Errorf("outbound request carries %s = %q, want it absent", name, req.Header.Get(name))
+		}
+	}
+}
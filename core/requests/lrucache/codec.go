@@ -0,0 +1,119 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package lrucache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// codec is an encoder/decoder pair used for transparent value compression.
+// Implementations must be safe for concurrent use.
+type codec interface {
+	Encode(src []byte) ([]byte, error)
+	Decode(src []byte) ([]byte, error)
+}
+
+// newCodec builds the codec named by [Options.Codec]; an empty name or
+// [CodecNone] disables compression and returns a nil codec.
+func newCodec(name string, level int) (codec, error) {
+	switch name {
+	case "", CodecNone:
+		return nil, nil
+	case CodecZstd:
+		return newZstdCodec(level)
+	case CodecGzip:
+		return newGzipCodec(level)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, name)
+	}
+}
+
+// zstdCodec wraps reusable zstd block encoder/decoder instances.
+type zstdCodec struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+// newZstdCodec creates a zstd codec; a zero level selects the library default.
+func newZstdCodec(level int) (*zstdCodec, error) {
+	// A nil writer/reader lets us use EncodeAll/DecodeAll without streams.
+	var encOpts []zstd.EOption
+	if level != 0 {
+		encOpts = append(encOpts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+
+	enc, err := zstd.NewWriter(nil, encOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	if err != nil {
+		return nil, err
+	}
+
+	return &zstdCodec{enc: enc, dec: dec}, nil
+}
+
+func (c *zstdCodec) Encode(src []byte) ([]byte, error) {
+	return c.enc.EncodeAll(src, nil), nil
+}
+
+func (c *zstdCodec) Decode(src []byte) ([]byte, error) {
+	return c.dec.DecodeAll(src, nil)
+}
+
+// gzipCodec compresses with gzip at a fixed level. Writers are cheap enough
+// to construct per call, which keeps the codec trivially concurrency-safe.
+type gzipCodec struct {
+	level int
+}
+
+// newGzipCodec creates a gzip codec; a zero level selects the library default.
+func newGzipCodec(level int) (*gzipCodec, error) {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	// Validate the level up front so Encode cannot fail on construction.
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, err
+	}
+
+	return &gzipCodec{level: level}, nil
+}
+
+func (c *gzipCodec) Encode(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *gzipCodec) Decode(src []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
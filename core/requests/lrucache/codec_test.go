@@ -0,0 +1,186 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package lrucache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// codecCacheNames lists the codec names exercised by the round-trip tests.
+var codecCacheNames = []string{CodecZstd, CodecGzip, CodecNone}
+
+// repetitiveJSON builds a compressible payload shaped like a pixiv API
+// response list.
+func repetitiveJSON(entries int) string {
+	var b strings.Builder
+
+	b.WriteString(`{"error":false,"body":{"works":[`)
+
+	for i := range entries {
+		if i > 0 {
+			b.WriteString(",")
+		}
+
+		fmt.Fprintf(&b,
+			`{"id":"%d","title":"A fairly ordinary artwork title","userId":"123456",`+
+				`"url":"https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/01/01/00/00/00/%d_p0_square1200.jpg"}`,
+			i, i)
+	}
+
+	b.WriteString(`]}}`)
+
+	return b.String()
+}
+
+func TestNewLRUCacheWithOptions(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewLRUCacheWithOptions(Options{Codec: CodecZstd}); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("no cap: error = %v, want ErrInvalidSize", err)
+	}
+
+	if _, err := NewLRUCacheWithOptions(Options{Size: 4, Codec: "lz4"}); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("unknown codec: error = %v, want ErrUnknownCodec", err)
+	}
+
+	if _, err := NewLRUCacheWithOptions(Options{Size: 4, Codec: CodecGzip, Level: 42}); err == nil {
+		t.Error("out-of-range gzip level: error = nil, want non-nil")
+	}
+
+	// An empty codec name behaves like "none".
+	cache, err := NewLRUCacheWithOptions(Options{Size: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cache.compressEnabled {
+		t.Error("empty codec name enabled compression")
+	}
+}
+
+// TestLRUCache_Codec_RoundTrip verifies string and []byte values survive a
+// store/retrieve cycle under every codec.
+func TestLRUCache_Codec_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	payload := repetitiveJSON(50)
+
+	for _, name := range codecCacheNames {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cache, err := NewLRUCacheWithOptions(Options{Size: 4, Codec: name})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cache.Add("str", payload)
+			cache.Add("bytes", []byte(payload))
+
+			got, ok := cache.Get("str")
+			if !ok || got.(string) != payload {
+				t.Error("string value did not survive the round trip")
+			}
+
+			gotBytes, ok := cache.Get("bytes")
+			if !ok || !bytes.Equal(gotBytes.([]byte), []byte(payload)) {
+				t.Error("[]byte value did not survive the round trip")
+			}
+
+			// Compressing codecs should have stored the repetitive payload
+			// compressed; "none" must not.
+			cache.lock.RLock()
+			ce := cache.items["bytes"].Value.(*cacheEntry)
+			cache.lock.RUnlock()
+
+			if wantCompressed := name != CodecNone; ce.compressed != wantCompressed {
+				t.Errorf("compressed = %v, want %v", ce.compressed, wantCompressed)
+			}
+		})
+	}
+}
+
+// TestLRUCache_Codec_UncompressibleFallback verifies that every compressing
+// codec stores incompressible data raw.
+func TestLRUCache_Codec_UncompressibleFallback(t *testing.T) {
+	t.Parallel()
+
+	// Pseudorandom bytes are unlikely to compress.
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	b := make([]byte, 64*1024)
+
+	_, _ = r.Read(b)
+
+	for _, name := range []string{CodecZstd, CodecGzip} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cache, err := NewLRUCacheWithOptions(Options{Size: 2, Codec: name})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cache.Add("rnd", b)
+
+			cache.lock.RLock()
+			ce := cache.items["rnd"].Value.(*cacheEntry)
+			cache.lock.RUnlock()
+
+			if ce.compressed {
+				t.Fatal("expected uncompressible data to be stored uncompressed")
+			}
+
+			got, ok := cache.Get("rnd")
+			if !ok || !bytes.Equal(got.([]byte), b) {
+				t.Fatal("uncompressible value did not survive the round trip")
+			}
+		})
+	}
+}
+
+// BenchmarkCodecs compares the codecs on a representative JSON payload.
+// Run with -benchmem to see the allocation cost alongside throughput.
+func BenchmarkCodecs(b *testing.B) {
+	payload := []byte(repetitiveJSON(200))
+
+	for _, bench := range []struct {
+		name  string
+		codec string
+		level int
+	}{
+		{"zstd-default", CodecZstd, 0},
+		{"zstd-best", CodecZstd, 19},
+		{"gzip-default", CodecGzip, 0},
+		{"gzip-fastest", CodecGzip, 1},
+		{"none", CodecNone, 0},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			cache, err := NewLRUCacheWithOptions(Options{
+				Size:  16,
+				Codec: bench.codec,
+				Level: bench.level,
+			})
+			if err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+
+			b.SetBytes(int64(len(payload)))
+			b.ResetTimer()
+
+			for b.Loop() {
+				cache.Add("key", payload)
+
+				if _, ok := cache.Get("key"); !ok {
+					b.Fatal("value missing after Add")
+				}
+			}
+		})
+	}
+}
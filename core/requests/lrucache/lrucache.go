@@ -3,7 +3,9 @@
 
 /*
 Package lrucache provides a thread-safe, fixed-capacity least-recently-used (LRU) cache.
-Keys are strings. The cache evicts the least recently used entry when it reaches capacity.
+Keys are strings. The cache evicts the least recently used entry when it reaches capacity;
+caches built with [NewLRUCacheBytes] are instead bounded by the total stored byte length
+of their values.
 When created with compression enabled via [NewLRUCache], string and []byte values may be
 stored in compressed form and are transparently decompressed by [LRUCache.Get] and [LRUCache.Peek].
 */
@@ -13,11 +15,31 @@ import (
 	"container/list"
 	"errors"
 	"sync"
+	"sync/atomic"
+)
 
-	"github.com/klauspost/compress/zstd"
+var (
+	ErrInvalidSize  = errors.New("must provide a positive size")
+	ErrUnknownCodec = errors.New("unknown compression codec")
 )
 
-var ErrInvalidSize = errors.New("must provide a positive size")
+// Supported values for [Options.Codec].
+const (
+	CodecZstd = "zstd"
+	CodecGzip = "gzip"
+	CodecNone = "none"
+)
+
+// Options configures a cache built with [NewLRUCacheWithOptions].
+//
+// At least one of Size and MaxBytes must be positive; a zero value disables
+// the corresponding cap.
+type Options struct {
+	Size     int    // Maximum number of entries (0 = no count cap)
+	MaxBytes int64  // Maximum total stored bytes (0 = no byte budget)
+	Codec    string // Compression codec: "zstd", "gzip", or "none" (default "none")
+	Level    int    // Codec-specific compression level; 0 selects the codec default
+}
 
 // valueType describes what kind of value we store for transparent compression/decompression.
 type valueType int
@@ -33,19 +55,47 @@ const (
 // Keys are strings. When created with compression enabled, string and []byte values may be
 // stored in compressed form and are transparently decompressed by [LRUCache.Get] and [LRUCache.Peek].
 type LRUCache struct {
-	size            int                      // Maximum capacity of the cache (number of entries)
+	size            int                      // Maximum capacity of the cache (number of entries, 0 = no count cap)
+	maxBytes        int64                    // Maximum total stored bytes (0 = no byte budget)
+	bytes           int64                    // Current total stored bytes
 	evictList       *list.List               // A doubly-linked list to manage the eviction order
 	items           map[string]*list.Element // Maps string keys to their corresponding linked-list elements
 	lock            sync.RWMutex             // For thread-safe operations
 	compressEnabled bool                     // Whether transparent compression is enabled
-	zstdEnc         *zstd.Encoder            // Reusable zstd encoder for block operations
-	zstdDec         *zstd.Decoder            // Reusable zstd decoder for block operations
+	codec           codec                    // Encoder/decoder pair used when compression is enabled
+
+	// Effectiveness counters. These use atomics rather than the RWMutex so
+	// bumping them never adds contention on the lookup hot path.
+	hits             atomic.Int64
+	misses           atomic.Int64
+	evictions        atomic.Int64
+	compressionSaved atomic.Int64
+}
+
+// Stats is a point-in-time snapshot of the cache effectiveness counters.
+type Stats struct {
+	Hits             int64 // Lookups (Get or Peek) that found an entry
+	Misses           int64 // Lookups that found nothing
+	Evictions        int64 // Entries evicted to satisfy the size or byte limits
+	CompressionSaved int64 // Total bytes saved by entries stored compressed
+}
+
+// Stats returns a snapshot of the cache effectiveness counters. The counters
+// are cumulative over the lifetime of the cache.
+func (c *LRUCache) Stats() Stats {
+	return Stats{
+		Hits:             c.hits.Load(),
+		Misses:           c.misses.Load(),
+		Evictions:        c.evictions.Load(),
+		CompressionSaved: c.compressionSaved.Load(),
+	}
 }
 
 // cacheEntry holds the key/value pair stored in each linked-list element.
 type cacheEntry struct {
 	key        string
 	value      any
+	size       int64 // Stored byte length as counted against the byte budget
 	compressed bool
 	vtype      valueType
 }
@@ -53,8 +103,10 @@ type cacheEntry struct {
 // NewLRUCache creates a new cache with the specified maximum size.
 //
 // If compress is true, string and []byte values are stored in a compressed form
-// when this reduces space and are transparently decompressed by [LRUCache.Get]
-// and [LRUCache.Peek]. Values of other types are stored uncompressed.
+// (using zstd at its default level) when this reduces space and are
+// transparently decompressed by [LRUCache.Get] and [LRUCache.Peek]. Values of
+// other types are stored uncompressed. Use [NewLRUCacheWithOptions] to choose
+// a different codec or level.
 //
 // It returns an error if size is not a positive integer.
 func NewLRUCache(size int, compress bool) (*LRUCache, error) {
@@ -62,31 +114,55 @@ func NewLRUCache(size int, compress bool) (*LRUCache, error) {
 		return nil, ErrInvalidSize
 	}
 
-	c := &LRUCache{
-		size:            size,
-		evictList:       list.New(),
-		items:           make(map[string]*list.Element),
-		compressEnabled: compress,
+	return NewLRUCacheWithOptions(Options{Size: size, Codec: compressCodecName(compress)})
+}
+
+// NewLRUCacheBytes creates a cache bounded by the total stored byte length of
+// its values rather than by entry count. Add accounts for the stored
+// (possibly compressed) length of string and []byte values and evicts
+// least-recently-used entries until the total fits within maxBytes; values of
+// other types count zero bytes.
+//
+// It returns an error if maxBytes is not a positive integer.
+func NewLRUCacheBytes(maxBytes int64, compress bool) (*LRUCache, error) {
+	if maxBytes <= 0 {
+		return nil, ErrInvalidSize
 	}
 
+	return NewLRUCacheWithOptions(Options{MaxBytes: maxBytes, Codec: compressCodecName(compress)})
+}
+
+// compressCodecName maps the legacy compress flag to an [Options.Codec] value.
+func compressCodecName(compress bool) string {
 	if compress {
-		// Create reusable encoder/decoder for block (stateless) operations.
-		// A nil writer/reader lets us use EncodeAll/DecodeAll without streams.
-		enc, err := zstd.NewWriter(nil)
-		if err != nil {
-			return nil, err
-		}
+		return CodecZstd
+	}
 
-		dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
-		if err != nil {
-			return nil, err
-		}
+	return CodecNone
+}
 
-		c.zstdEnc = enc
-		c.zstdDec = dec
+// NewLRUCacheWithOptions builds a cache from opts.
+//
+// It returns [ErrInvalidSize] when neither a size nor a byte budget is set,
+// and [ErrUnknownCodec] for an unrecognized codec name.
+func NewLRUCacheWithOptions(opts Options) (*LRUCache, error) {
+	if opts.Size <= 0 && opts.MaxBytes <= 0 {
+		return nil, ErrInvalidSize
+	}
+
+	cdc, err := newCodec(opts.Codec, opts.Level)
+	if err != nil {
+		return nil, err
 	}
 
-	return c, nil
+	return &LRUCache{
+		size:            opts.Size,
+		maxBytes:        opts.MaxBytes,
+		evictList:       list.New(),
+		items:           make(map[string]*list.Element),
+		compressEnabled: cdc != nil,
+		codec:           cdc,
+	}, nil
 }
 
 // Add adds or updates the value for key.
@@ -97,6 +173,11 @@ func NewLRUCache(size int, compress bool) (*LRUCache, error) {
 func (c *LRUCache) Add(key string, value any) bool {
 	// Prepare (and possibly compress) the value before acquiring the lock.
 	storedVal, compressed, vtype := c.prepareValue(value)
+	size := storedSize(storedVal)
+
+	if compressed {
+		c.compressionSaved.Add(storedSize(value) - size)
+	}
 
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -106,31 +187,67 @@ func (c *LRUCache) Add(key string, value any) bool {
 		c.evictList.MoveToFront(ent)
 
 		if cacheEnt, ok := ent.Value.(*cacheEntry); ok {
+			c.bytes += size - cacheEnt.size
+
 			cacheEnt.value = storedVal
+			cacheEnt.size = size
 			cacheEnt.compressed = compressed
 			cacheEnt.vtype = vtype
 		}
 
-		return false
+		return c.enforceBudget()
 	}
 
 	// Otherwise, create a new entry and place it at the front.
 	c.items[key] = c.evictList.PushFront(&cacheEntry{
 		key:        key,
 		value:      storedVal,
+		size:       size,
 		compressed: compressed,
 		vtype:      vtype,
 	})
 
-	// If we've exceeded our capacity, remove the oldest item from the back of the list.
-	evicted := c.evictList.Len() > c.size
-	if evicted {
+	c.bytes += size
+
+	// If we've exceeded a limit, remove the oldest items from the back of the list.
+	return c.enforceBudget()
+}
+
+// enforceBudget evicts least-recently-used entries until both the entry-count
+// cap and the byte budget are satisfied, reporting whether any eviction
+// occurred. A single entry larger than the whole byte budget is itself
+// evicted, leaving the cache empty.
+func (c *LRUCache) enforceBudget() bool {
+	evicted := false
+
+	for (c.size > 0 && c.evictList.Len() > c.size) ||
+		(c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		if c.evictList.Len() == 0 {
+			break
+		}
+
 		c.removeOldest()
+		c.evictions.Add(1)
+
+		evicted = true
 	}
 
 	return evicted
 }
 
+// storedSize returns the stored byte length counted against the byte budget.
+// Only string and []byte values are accounted.
+func storedSize(stored any) int64 {
+	switch v := stored.(type) {
+	case []byte:
+		return int64(len(v))
+	case string:
+		return int64(len(v))
+	default:
+		return 0
+	}
+}
+
 // Get retrieves the value for key and marks it as most recently used.
 //
 // The second result reports whether the key was found.
@@ -144,6 +261,8 @@ func (c *LRUCache) Get(key string) (any, bool) {
 	ent, ok := c.items[key]
 	if !ok {
 		c.lock.Unlock()
+		c.misses.Add(1)
+
 		return nil, false
 	}
 
@@ -152,6 +271,8 @@ func (c *LRUCache) Get(key string) (any, bool) {
 	cacheEnt, ok := ent.Value.(*cacheEntry)
 	if !ok {
 		c.lock.Unlock()
+		c.misses.Add(1)
+
 		return nil, false
 	}
 
@@ -161,6 +282,7 @@ func (c *LRUCache) Get(key string) (any, bool) {
 	vtype := cacheEnt.vtype
 
 	c.lock.Unlock()
+	c.hits.Add(1)
 
 	return c.decompressValue(stored, compressed, vtype)
 }
@@ -177,12 +299,16 @@ func (c *LRUCache) Peek(key string) (any, bool) {
 	ent, ok := c.items[key]
 	if !ok {
 		c.lock.RUnlock()
+		c.misses.Add(1)
+
 		return nil, false
 	}
 
 	cacheEnt, ok := ent.Value.(*cacheEntry)
 	if !ok {
 		c.lock.RUnlock()
+		c.misses.Add(1)
+
 		return nil, false
 	}
 
@@ -192,6 +318,7 @@ func (c *LRUCache) Peek(key string) (any, bool) {
 	vtype := cacheEnt.vtype
 
 	c.lock.RUnlock()
+	c.hits.Add(1)
 
 	return c.decompressValue(stored, compressed, vtype)
 }
@@ -239,6 +366,15 @@ func (c *LRUCache) Len() int {
 	return c.evictList.Len()
 }
 
+// Bytes returns the total stored byte length of all entries, counting
+// compressed entries at their compressed size.
+func (c *LRUCache) Bytes() int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.bytes
+}
+
 // removeOldest removes the oldest item from both the linked list and the map.
 func (c *LRUCache) removeOldest() {
 	ent := c.evictList.Back()
@@ -255,6 +391,8 @@ func (c *LRUCache) removeElement(e *list.Element) {
 	c.evictList.Remove(e)
 
 	if kv, ok := e.Value.(*cacheEntry); ok {
+		c.bytes -= kv.size
+
 		delete(c.items, kv.key)
 	}
 }
@@ -265,7 +403,7 @@ func (c *LRUCache) removeElement(e *list.Element) {
 // To prevent callers from mutating the cache, uncompressed []byte values are copied before being stored.
 //
 // This function performs the heavy work of compression and is safe to call without holding the lock,
-// as the underlying zstd Encoder supports concurrent EncodeAll calls.
+// as the codecs support concurrent encoding.
 func (c *LRUCache) prepareValue(value any) (stored any, compressed bool, vtype valueType) {
 	switch v := value.(type) {
 	case []byte:
@@ -280,8 +418,7 @@ func (c *LRUCache) prepareValue(value any) (stored any, compressed bool, vtype v
 
 		// Try to compress if enabled.
 		if c.compressEnabled {
-			compressedBytes := c.zstdEnc.EncodeAll(v, nil)
-			if len(compressedBytes) < len(v) {
+			if compressedBytes, err := c.codec.Encode(v); err == nil && len(compressedBytes) < len(v) {
 				return compressedBytes, true, vtype
 			}
 		}
@@ -306,8 +443,7 @@ func (c *LRUCache) prepareValue(value any) (stored any, compressed bool, vtype v
 		if c.compressEnabled {
 			orig := []byte(v)
 
-			compressedBytes := c.zstdEnc.EncodeAll(orig, nil)
-			if len(compressedBytes) < len(orig) {
+			if compressedBytes, err := c.codec.Encode(orig); err == nil && len(compressedBytes) < len(orig) {
 				return compressedBytes, true, vtype
 			}
 		}
@@ -344,11 +480,11 @@ func (c *LRUCache) decompressValue(stored any, compressed bool, vtype valueType)
 
 	// Only strings/bytes can be compressed by our implementation.
 	comp, ok := stored.([]byte)
-	if !ok || c.zstdDec == nil {
+	if !ok || c.codec == nil {
 		return nil, false
 	}
 
-	decoded, err := c.zstdDec.DecodeAll(comp, nil)
+	decoded, err := c.codec.Decode(comp)
 	if err != nil {
 		return nil, false
 	}
@@ -577,7 +577,7 @@ func TestLRUCache_RealizeValue_DecoderNil(t *testing.T) {
 
 	ce.compressed = true
 	ce.value = []byte{0x01, 0x02, 0x03}
-	cache.zstdDec = nil
+	cache.codec = nil
 	cache.lock.Unlock()
 
 	if v, ok := cache.Get("k"); ok || v != nil {
@@ -738,3 +738,191 @@ func TestLRUCache_Keys_Empty(t *testing.T) {
 		t.Fatalf("expected empty Keys() on new cache, got %v", keys)
 	}
 }
+
+// TestNewLRUCacheBytes checks creation of a byte-budgeted cache with both valid and invalid budgets.
+func TestNewLRUCacheBytes(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewLRUCacheBytes(1024, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cache.Len() != 0 || cache.Bytes() != 0 {
+		t.Errorf("expected empty cache, got Len()=%d Bytes()=%d", cache.Len(), cache.Bytes())
+	}
+
+	if _, err := NewLRUCacheBytes(0, false); err == nil {
+		t.Error("expected error for a non-positive byte budget")
+	}
+}
+
+// TestLRUCache_ByteBudgetEviction verifies that eviction triggers on the byte budget,
+// not on entry count.
+func TestLRUCache_ByteBudgetEviction(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewLRUCacheBytes(100, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	value := strings.Repeat("x", 40)
+
+	if evicted := cache.Add("a", value); evicted {
+		t.Error("unexpected eviction while under budget")
+	}
+
+	if evicted := cache.Add("b", value); evicted {
+		t.Error("unexpected eviction while under budget")
+	}
+
+	if cache.Bytes() != 80 {
+		t.Errorf("expected Bytes()=80, got %d", cache.Bytes())
+	}
+
+	// A third 40-byte value exceeds the 100-byte budget and must evict "a".
+	if evicted := cache.Add("c", value); !evicted {
+		t.Error("expected eviction once the byte budget is exceeded")
+	}
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected the least-recently-used entry to be evicted")
+	}
+
+	if cache.Len() != 2 || cache.Bytes() != 80 {
+		t.Errorf("expected Len()=2 Bytes()=80 after eviction, got Len()=%d Bytes()=%d",
+			cache.Len(), cache.Bytes())
+	}
+
+	// Growing an existing entry must also trigger eviction.
+	if evicted := cache.Add("c", strings.Repeat("y", 90)); !evicted {
+		t.Error("expected eviction after growing an entry past the budget")
+	}
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected 'b' to be evicted to make room for the grown entry")
+	}
+}
+
+// TestLRUCache_ByteBudget_CompressedSize verifies that compressed entries count
+// their compressed size against the budget.
+func TestLRUCache_ByteBudget_CompressedSize(t *testing.T) {
+	t.Parallel()
+
+	// A budget far below the raw value size, but far above its compressed size.
+	cache, err := NewLRUCacheBytes(2048, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Highly compressible 4KiB values.
+	value := strings.Repeat("a", 4096)
+
+	for i := range 3 {
+		cache.Add("key"+strconv.Itoa(i), value)
+	}
+
+	// All three fit only if the compressed size is what's accounted.
+	if cache.Len() != 3 {
+		t.Fatalf("expected 3 entries under the budget, got %d", cache.Len())
+	}
+
+	if cache.Bytes() >= 3*4096 {
+		t.Errorf("expected Bytes() to reflect compressed sizes, got %d", cache.Bytes())
+	}
+
+	// Values must still round-trip through decompression.
+	got, ok := cache.Get("key0")
+	if !ok || got.(string) != value {
+		t.Error("expected compressed entry to round-trip")
+	}
+}
+
+// TestLRUCache_ByteBudget_OversizedEntry verifies that an entry larger than the
+// whole budget is evicted immediately.
+func TestLRUCache_ByteBudget_OversizedEntry(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewLRUCacheBytes(10, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if evicted := cache.Add("huge", strings.Repeat("x", 100)); !evicted {
+		t.Error("expected an oversized entry to be evicted immediately")
+	}
+
+	if cache.Len() != 0 || cache.Bytes() != 0 {
+		t.Errorf("expected empty cache, got Len()=%d Bytes()=%d", cache.Len(), cache.Bytes())
+	}
+}
+
+// TestLRUCache_Stats verifies the counters after a known sequence of
+// operations.
+func TestLRUCache_Stats(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewLRUCache(2, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := cache.Get("absent"); ok {
+		t.Fatal("expected a miss for an absent key")
+	}
+
+	cache.Add("a", "1")
+	cache.Add("b", "2")
+
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected a hit for key a")
+	}
+
+	if _, ok := cache.Peek("b"); !ok {
+		t.Fatal("expected a hit for key b")
+	}
+
+	// Exceeding the capacity evicts the least recently used entry.
+	if evicted := cache.Add("c", "3"); !evicted {
+		t.Error("expected Add to report an eviction")
+	}
+
+	// An explicit Remove is not an eviction.
+	cache.Remove("a")
+
+	want := Stats{Hits: 2, Misses: 1, Evictions: 1}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+// TestLRUCache_Stats_CompressionSaved verifies that bytes saved by compressed
+// entries are accumulated.
+func TestLRUCache_Stats_CompressionSaved(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewLRUCache(10, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	value := strings.Repeat("compressible ", 512)
+	cache.Add("key", value)
+
+	saved := cache.Stats().CompressionSaved
+	if saved <= 0 {
+		t.Fatalf("expected positive CompressionSaved, got %d", saved)
+	}
+
+	if want := int64(len(value)) - cache.Bytes(); saved != want {
+		t.Errorf("CompressionSaved = %d, want %d (original minus stored)", saved, want)
+	}
+
+	// Uncompressible entries must not affect the counter.
+	cache.Add("raw", []byte{0x01})
+
+	if got := cache.Stats().CompressionSaved; got != saved {
+		t.Errorf("CompressionSaved changed to %d after storing an uncompressed entry, want %d", got, saved)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"net/url"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// pixivAPIHost is the host every pixiv API endpoint lives on. A page's
+// fan-out almost always starts with a warm connection to it, so its requests
+// are issued first when grouping is on.
+const pixivAPIHost = "www.pixiv.net"
+
+// OrderByHost returns urls in a connection-reuse-friendly issue order:
+// API-host requests first, then the remaining URLs grouped by host in
+// first-appearance order. Within a host the original order is kept, and the
+// returned slice always holds exactly the input URLs.
+//
+// Under a bounded fan-out, issuing same-host requests back-to-back lets them
+// pick up the idle keep-alive connection the previous one opened instead of
+// racing fresh dials against other hosts. The reordering is gated behind
+// Request.GroupByHost; when disabled, urls is returned untouched.
+func OrderByHost(urls []string) []string {
+	if !config.Global.Request.GroupByHost || len(urls) < 2 {
+		return urls
+	}
+
+	var (
+		hosts  []string
+		groups = make(map[string][]string)
+	)
+
+	for _, u := range urls {
+		host := requestHost(u)
+
+		if _, seen := groups[host]; !seen && host != pixivAPIHost {
+			hosts = append(hosts, host)
+		}
+
+		groups[host] = append(groups[host], u)
+	}
+
+	ordered := make([]string, 0, len(urls))
+	ordered = append(ordered, groups[pixivAPIHost]...)
+
+	for _, host := range hosts {
+		ordered = append(ordered, groups[host]...)
+	}
+
+	return ordered
+}
+
+// requestHost extracts the host of a request URL, returning an empty string
+// for unparsable input so such URLs still group together deterministically.
+func requestHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Host
+}
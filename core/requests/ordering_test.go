@@ -0,0 +1,182 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// setupOrderingTest turns host grouping on and restores the previous config
+// when the test completes.
+func setupOrderingTest(t *testing.T) {
+	t.Helper()
+
+	origConfig := config.Global
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+	})
+
+	config.Global.Request.GroupByHost = true
+}
+
+func TestOrderByHost(t *testing.T) {
+	setupOrderingTest(t)
+
+	urls := []string{
+		"https://i.pximg.net/img-master/a_p0.jpg",
+		"https://www.pixiv.net/ajax/illust/1",
+		"https://s.pximg.net/common/logo.png",
+		"https://www.pixiv.net/ajax/illust/2",
+		"https://i.pximg.net/img-master/b_p0.jpg",
+	}
+
+	want := []string{
+		"https://www.pixiv.net/ajax/illust/1",
+		"https://www.pixiv.net/ajax/illust/2",
+		"https://i.pximg.net/img-master/a_p0.jpg",
+		"https://i.pximg.net/img-master/b_p0.jpg",
+		"https://s.pximg.net/common/logo.png",
+	}
+
+	got := OrderByHost(urls)
+
+	if len(got) != len(want) {
+		t.Fatalf("OrderByHost() = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("OrderByHost()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// TestOrderByHost_SameRequests asserts grouping only permutes the fan-out:
+// every input URL is issued exactly once, duplicates included.
+func TestOrderByHost_SameRequests(t *testing.T) {
+	setupOrderingTest(t)
+
+	urls := []string{
+		"https://i.pximg.net/a.jpg",
+		"https://www.pixiv.net/ajax/illust/1",
+		"https://i.pximg.net/a.jpg",
+		"://not a url",
+	}
+
+	got := OrderByHost(urls)
+
+	counts := make(map[string]int)
+	for _, u := range urls {
+		counts[u]++
+	}
+
+	for _, u := range got {
+		counts[u]--
+	}
+
+	for u, n := range counts {
+		if n != 0 {
+			t.Errorf("OrderByHost() changed the multiplicity of %q by %d", u, -n)
+		}
+	}
+}
+
+func TestOrderByHost_Disabled(t *testing.T) {
+	setupOrderingTest(t)
+
+	config.Global.Request.GroupByHost = false
+
+	urls := []string{
+		"https://i.pximg.net/a.jpg",
+		"https://www.pixiv.net/ajax/illust/1",
+	}
+
+	got := OrderByHost(urls)
+
+	for i := range urls {
+		if got[i] != urls[i] {
+			t.Fatalf("OrderByHost() = %v, want the input order %v with grouping off", got, urls)
+		}
+	}
+}
+
+// BenchmarkOrderByHostConnectionReuse measures new connections per fan-out
+// with a single-slot idle pool: grouped order re-dials once per host while
+// interleaved order re-dials on nearly every host switch.
+func BenchmarkOrderByHostConnectionReuse(b *testing.B) {
+	origConfig := config.Global
+
+	b.Cleanup(func() {
+		config.Global = origConfig
+	})
+
+	config.Global.Request.GroupByHost = true
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	hostA := httptest.NewServer(handler)
+	defer hostA.Close()
+
+	hostB := httptest.NewServer(handler)
+	defer hostB.Close()
+
+	interleaved := []string{
+		hostA.URL + "/1", hostB.URL + "/1",
+		hostA.URL + "/2", hostB.URL + "/2",
+		hostA.URL + "/3", hostB.URL + "/3",
+	}
+
+	run := func(b *testing.B, urls []string) {
+		b.Helper()
+
+		var (
+			dials  atomic.Int64
+			dialer net.Dialer
+		)
+
+		client := &http.Client{Transport: &http.Transport{
+			// One idle slot total, so conns to the other host are evicted.
+			MaxIdleConns: 1,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dials.Add(1)
+
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}}
+		defer client.CloseIdleConnections()
+
+		for b.Loop() {
+			for _, u := range urls {
+				resp, err := client.Get(u)
+				if err != nil {
+					b.Fatalf("Get(%q) error: %v", u, err)
+				}
+
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+		}
+
+		b.ReportMetric(float64(dials.Load())/float64(b.N), "dials/op")
+	}
+
+	b.Run("interleaved", func(b *testing.B) {
+		run(b, interleaved)
+	})
+
+	b.Run("grouped", func(b *testing.B) {
+		run(b, OrderByHost(interleaved))
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// stubProxyTransport serves a fixed body with the configured content type.
+type stubProxyTransport struct {
+	contentType string
+}
+
+func (t stubProxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {t.contentType}},
+		Body:       io.NopCloser(strings.NewReader("payload")),
+		Request:    req,
+	}, nil
+}
+
+// setupProxyTest points the shared HTTP client at a stub upstream and installs
+// the default content-type allowlist, restoring both when the test completes.
+func setupProxyTest(t *testing.T, contentType string) {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+	})
+
+	config.Global.SetDefaults()
+
+	utils.HTTPClient.Transport = stubProxyTransport{contentType: contentType}
+}
+
+func TestProxyHandler_AllowedContentType(t *testing.T) {
+	setupProxyTest(t, "image/jpeg")
+
+	recorder := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/img/example.jpg", nil)
+
+	if err := ProxyHandler(recorder, r, "https://i.pximg.net/"); err != nil {
+		t.Fatalf("ProxyHandler() error: %v", err)
+	}
+
+	resp := recorder.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if got := resp.Header.Get("Content-Type"); got != "image/jpeg" {
+		t.Errorf("Content-Type = %q, want %q", got, "image/jpeg")
+	}
+
+	if got := resp.Header.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want %q", got, "nosniff")
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "payload" {
+		t.Errorf("body = %q, want %q", body, "payload")
+	}
+}
+
+func TestProxyHandler_DisallowedContentType(t *testing.T) {
+	setupProxyTest(t, "text/html; charset=utf-8")
+
+	recorder := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/img/example.jpg", nil)
+
+	if err := ProxyHandler(recorder, r, "https://i.pximg.net/"); err != nil {
+		t.Fatalf("ProxyHandler() error: %v", err)
+	}
+
+	resp := recorder.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnsupportedMediaType)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if strings.Contains(string(body), "payload") {
+		t.Error("disallowed upstream body was relayed")
+	}
+}
+
+func TestAllowedProxyMediaType(t *testing.T) {
+	origConfig := config.Global
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+	})
+
+	config.Global.SetDefaults()
+
+	tests := []struct {
+		contentType string
+		wantType    string
+		wantAllowed bool
+	}{
+		{"image/png", "image/png", true},
+		{"IMAGE/JPEG", "image/jpeg", true},
+		{"video/mp4", "video/mp4", true},
+		{"text/css; charset=utf-8", "text/css", true},
+		{"text/html", "", false},
+		{"application/javascript", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		gotType, gotAllowed := allowedProxyMediaType(tt.contentType)
+		if gotType != tt.wantType || gotAllowed != tt.wantAllowed {
+			t.Errorf("allowedProxyMediaType(%q) = (%q, %v), want (%q, %v)",
+				tt.contentType, gotType, gotAllowed, tt.wantType, tt.wantAllowed)
+		}
+	}
+}
+
+func TestProxyHandler_RefererGuard(t *testing.T) {
+	cases := []struct {
+		name       string
+		referer    string
+		secSite    string
+		allowed    []string
+		wantStatus int
+	}{
+		{
+			name:       "Same-origin referer is allowed",
+			referer:    "http://instance.test/artworks/1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Cross-origin referer is denied",
+			referer:    "https://hotlinker.example/gallery",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Allowlisted referer is allowed",
+			referer:    "https://mirror.example/page",
+			allowed:    []string{"mirror.example"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Same-origin fetch metadata is allowed",
+			secSite:    "same-origin",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Cross-site fetch metadata without referer is denied",
+			secSite:    "cross-site",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Direct fetch without referer is allowed",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupProxyTest(t, "image/jpeg")
+			config.Global.ContentProxies.RequireSameOriginReferer = true
+			config.Global.ContentProxies.AllowedReferers = tc.allowed
+
+			recorder := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "http://instance.test/img/example.jpg", nil)
+
+			if tc.referer != "" {
+				r.Header.Set("Referer", tc.referer)
+			}
+
+			if tc.secSite != "" {
+				r.Header.Set("Sec-Fetch-Site", tc.secSite)
+			}
+
+			if err := ProxyHandler(recorder, r, "https://i.pximg.net/"); err != nil {
+				t.Fatalf("ProxyHandler() error: %v", err)
+			}
+
+			if recorder.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tc.wantStatus)
+			}
+
+			if tc.wantStatus == http.StatusForbidden && strings.Contains(recorder.Body.String(), "payload") {
+				t.Error("hotlinked upstream body was relayed")
+			}
+		})
+	}
+}
+
+func TestProxyHandler_RefererGuardDisabled(t *testing.T) {
+	setupProxyTest(t, "image/jpeg")
+
+	recorder := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "http://instance.test/img/example.jpg", nil)
+	r.Header.Set("Referer", "https://hotlinker.example/gallery")
+
+	if err := ProxyHandler(recorder, r, "https://i.pximg.net/"); err != nil {
+		t.Fatalf("ProxyHandler() error: %v", err)
+	}
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want cross-origin requests allowed when the guard is off", recorder.Code)
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"mime"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/tidwall/gjson"
+	"golang.org/x/sync/singleflight"
 
 	"codeberg.org/pixivfe/pixivfe/v3/config"
 	"codeberg.org/pixivfe/pixivfe/v3/core/audit"
@@ -80,6 +83,12 @@ func (e *APIError) Unwrap() error {
 	return e.Err
 }
 
+// Transient reports whether the error represents a transient upstream failure
+// (a retryable 5xx) that idempotent callers may safely retry.
+func (e *APIError) Transient() bool {
+	return retryableStatus(e.StatusCode)
+}
+
 // GetJSONBody makes a GET request and extracts the JSON payload from the response.
 //
 // For standard API responses, it returns the content of the `body` field. For non-standard
@@ -96,6 +105,21 @@ func GetJSONBody(
 	cookies map[string]string,
 	incomingHeaders http.Header,
 ) ([]byte, error) {
+	body, _, err := GetJSONBodyWithMeta(ctx, url, cookies, incomingHeaders)
+
+	return body, err
+}
+
+// GetJSONBodyWithMeta performs the same request and JSON extraction as
+// [GetJSONBody] but also surfaces the response headers, letting callers read
+// pixiv's caching hints or rate-limit headers. The header map is a clone, so
+// mutating it cannot corrupt a cached response.
+func GetJSONBodyWithMeta(
+	ctx context.Context,
+	url string,
+	cookies map[string]string,
+	incomingHeaders http.Header,
+) ([]byte, http.Header, error) {
 	opts := RequestOptions{
 		Method:          http.MethodGet,
 		URL:             url,
@@ -103,12 +127,17 @@ func GetJSONBody(
 		IncomingHeaders: incomingHeaders,
 	}
 
-	respBody, err := do(ctx, opts)
+	respBody, header, err := doWithHeader(ctx, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return processJSONResponse(respBody)
+	payload, err := processJSONResponse(respBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return payload, header, nil
 }
 
 // Get performs a GET request and wraps the returned response.Body in a bytes.Reader.
@@ -184,39 +213,150 @@ func Do(ctx context.Context, opts RequestOptions) (*http.Response, []byte, error
 	tokenManager := tokenmanager.DefaultTokenManager
 	userToken := opts.Cookies["PHPSESSID"]
 
+	// Bound the upstream call so a single slow pixiv endpoint fails fast; an
+	// even shorter caller-supplied deadline still wins.
+	if timeout := config.Global.Request.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// For GET requests, determine cache policy and check for a cached response.
 	var cachePolicy cachePolicy
 	if opts.Method == http.MethodGet {
-		cachePolicy = determineCachePolicy(opts.URL, userToken, opts.IncomingHeaders)
+		if opts.forceRefresh {
+			// A stale-while-revalidate refresh: skip the lookup, store the result.
+			cachePolicy.shouldUseCache = config.Global.Cache.Enabled && cache != nil
+		} else {
+			cachePolicy = determineCachePolicy(opts.URL, userToken, opts.IncomingHeaders)
+		}
+
 		if cachePolicy.cachedItem != nil {
 			// A valid cached item was found. Construct a response and return it with the body bytes.
 			item := cachePolicy.cachedItem
 
+			header := item.Header.Clone()
+			if header == nil {
+				header = http.Header{}
+			}
+
+			if cachePolicy.stale {
+				// Serve the stale body immediately and replace it in the background.
+				header.Set("X-Pixivfe-Cache", "stale")
+				refreshInBackground(opts, userToken)
+			}
+
 			return &http.Response{
 				StatusCode: item.StatusCode,
-				Header:     item.Header.Clone(),
+				Header:     header,
 				Body:       io.NopCloser(bytes.NewReader(item.Body)),
 			}, item.Body, nil
 		}
+
+		if item := cachePolicy.revalidate; item != nil {
+			// Ask pixiv to skip the body if the expired entry is still current.
+			opts.ifNoneMatch = item.ETag
+			opts.ifModifiedSince = item.LastModified
+		}
 	}
 
-	token, err := retrieveToken(tokenManager, userToken)
+	// Deduplicate concurrent GET requests for the same key: every caller that
+	// arrives while a fetch is in flight shares its result instead of
+	// spawning another upstream request.
+	if opts.Method == http.MethodGet {
+		shared, err, _ := upstreamGroup.Do(generateCacheKey(opts.URL, userToken), func() (any, error) {
+			// Detach from the caller so one disconnect doesn't cancel a fetch
+			// other callers are waiting on. The per-call timeout still
+			// applies, since it bounds the upstream rather than the caller.
+			timeout := sharedFetchTimeout
+			if configured := config.Global.Request.Timeout; configured > 0 && configured < timeout {
+				timeout = configured
+			}
+
+			sharedCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+			defer cancel()
+
+			return fetchUpstream(sharedCtx, opts, tokenManager, userToken, cachePolicy)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		result, ok := shared.(*fetchResult)
+		if !ok {
+			return nil, nil, fmt.Errorf("unexpected singleflight result type %T", shared)
+		}
+
+		// Give every caller its own response wrapper over the shared bytes.
+		resp := *result.resp
+		resp.Body = io.NopCloser(bytes.NewReader(result.body))
+
+		return &resp, result.body, nil
+	}
+
+	result, err := fetchUpstream(ctx, opts, tokenManager, userToken, cachePolicy)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Create a request object.
-	req, err := newRequest(ctx, opts, token)
+	return result.resp, result.body, nil
+}
+
+// sharedFetchTimeout bounds an upstream fetch that is detached from its
+// callers' contexts for singleflight sharing.
+const sharedFetchTimeout = 30 * time.Second
+
+// upstreamGroup deduplicates concurrent upstream fetches per cache key.
+var upstreamGroup singleflight.Group
+
+// fetchResult carries one upstream response between Do and fetchUpstream.
+type fetchResult struct {
+	resp *http.Response
+	body []byte
+}
+
+// fetchUpstream performs a single upstream request with a managed token and
+// stores the response in the cache when the policy allows it.
+func fetchUpstream(
+	ctx context.Context,
+	opts RequestOptions,
+	tokenManager *tokenmanager.TokenManager,
+	userToken string,
+	cachePolicy cachePolicy,
+) (*fetchResult, error) {
+	// Short-circuit while the breaker is open; cached responses were already
+	// considered by the caller and are unaffected.
+	if !upstreamBreaker.allow() {
+		return nil, &APIError{
+			StatusCode: http.StatusServiceUnavailable,
+			Err:        ErrUpstreamUnavailable,
+		}
+	}
+
+	token, err := retrieveToken(tokenManager, userToken)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	// Perform the request.
-	resp, bodyBytes, err := sendRequest(ctx, req)
+	// Perform the request, retrying transient GET failures with backoff.
+	resp, bodyBytes, err := sendRequestWithRetry(ctx, opts, token)
 	if err != nil {
+		upstreamBreaker.record(false)
+
 		// If making the request itself failed, don't mark the token as timed out.
 		// Return nil for the body bytes.
-		return nil, nil, err
+		return nil, err
+	}
+
+	upstreamBreaker.record(!retryableStatus(resp.StatusCode))
+
+	// A 304 during revalidation: the stored body is still current, so bump
+	// its expiry and serve it without re-downloading.
+	if resp.StatusCode == http.StatusNotModified && cachePolicy.revalidate != nil {
+		tokenManager.MarkTokenStatus(token, tokenmanager.Good)
+
+		return refreshRevalidatedItem(ctx, opts, userToken, *cachePolicy.revalidate), nil
 	}
 
 	// Handle token status based on the response
@@ -232,29 +372,80 @@ func Do(ctx context.Context, opts RequestOptions) (*http.Response, []byte, error
 	if opts.Method == http.MethodGet && resp.StatusCode == http.StatusOK && cachePolicy.shouldUseCache {
 		var buf bytes.Buffer
 		if err := gob.NewEncoder(&buf).Encode(cachedItem{
-			StatusCode: resp.StatusCode,
-			Header:     resp.Header.Clone(),
-			Body:       bodyBytes,
-			ExpiresAt:  time.Now().Add(config.Global.Cache.TTL),
-			URL:        opts.URL,
+			StatusCode:   resp.StatusCode,
+			Header:       resp.Header.Clone(),
+			Body:         bodyBytes,
+			ExpiresAt:    time.Now().Add(cacheTTLForURL(opts.URL)),
+			URL:          opts.URL,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
 		}); err != nil {
 			// Log the error but don't fail the request.
 			log.Ctx(ctx).Warn().Err(err).Msg("Failed to serialize item for cache")
 		} else {
-			cache.Add(
-				generateCacheKey(opts.URL, userToken),
-				buf.Bytes(),
-			)
+			cacheKey := generateCacheKey(opts.URL, userToken)
+
+			cache.Add(cacheKey, buf.Bytes())
+
+			if diskCacheEnabled() {
+				writeDiskCache(cacheKey, buf.Bytes())
+			}
+		}
+	}
+
+	return &fetchResult{resp: resp, body: bodyBytes}, nil
+}
+
+// refreshRevalidatedItem re-stores a revalidated cache entry with a fresh
+// expiry and returns a result built from its stored response.
+func refreshRevalidatedItem(
+	ctx context.Context,
+	opts RequestOptions,
+	userToken string,
+	item cachedItem,
+) *fetchResult {
+	item.ExpiresAt = time.Now().Add(cacheTTLForURL(opts.URL))
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(item); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to serialize revalidated item for cache")
+	} else {
+		cacheKey := generateCacheKey(opts.URL, userToken)
+
+		cache.Add(cacheKey, buf.Bytes())
+
+		if diskCacheEnabled() {
+			writeDiskCache(cacheKey, buf.Bytes())
 		}
 	}
 
-	return resp, bodyBytes, nil
+	header := item.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	header.Set("X-Pixivfe-Cache", "revalidated")
+
+	return &fetchResult{
+		resp: &http.Response{
+			StatusCode: item.StatusCode,
+			Header:     header,
+			Body:       io.NopCloser(bytes.NewReader(item.Body)),
+		},
+		body: item.Body,
+	}
 }
 
 // ProxyHandler proxies a request to the specified base URL.
 //
 // NOTE: We intentionally don't copy headers from the response.
 func ProxyHandler(w http.ResponseWriter, r *http.Request, baseURL string) error {
+	if config.Global.ContentProxies.RequireSameOriginReferer && !proxyRefererAllowed(r) {
+		http.Error(w, "cross-origin proxy requests are not allowed", http.StatusForbidden)
+
+		return nil
+	}
+
 	base, err := url.Parse(baseURL)
 	if err != nil {
 		// This should not happen if baseURL is configured correctly.
@@ -282,6 +473,8 @@ func ProxyHandler(w http.ResponseWriter, r *http.Request, baseURL string) error
 	req.Header.Add("User-Agent", config.GetRandomUserAgent())
 	req.Header.Add("Referer", "https://www.pixiv.net/")
 
+	sanitizeForwardedHeaders(req.Header, r.Header)
+
 	//nolint:bodyclose // sendRequest closes the original body and returns a NopCloser.
 	resp, bodyBytes, err := sendRequest(r.Context(), req)
 	if err != nil {
@@ -294,6 +487,17 @@ func ProxyHandler(w http.ResponseWriter, r *http.Request, baseURL string) error
 	}
 	// The body from makeRequest is already closed, we just use the bytes.
 
+	mediaType, allowed := allowedProxyMediaType(resp.Header.Get("Content-Type"))
+	if !allowed {
+		http.Error(w, "upstream returned a disallowed content type", http.StatusUnsupportedMediaType)
+
+		return nil
+	}
+
+	// Relay only the parsed media type and forbid sniffing so a hostile
+	// upstream body is never interpreted as HTML/JS on our origin.
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.WriteHeader(resp.StatusCode)
 
 	if _, err := w.Write(bodyBytes); err != nil {
@@ -303,13 +507,95 @@ func ProxyHandler(w http.ResponseWriter, r *http.Request, baseURL string) error
 	return nil
 }
 
+// proxyRefererAllowed reports whether a proxy request comes from the
+// instance's own pages or a configured referrer, for the optional
+// anti-hotlink guard.
+func proxyRefererAllowed(r *http.Request) bool {
+	// Sec-Fetch-Site is the most reliable signal on modern browsers: "none"
+	// covers direct navigations and address-bar fetches.
+	secFetchSite := r.Header.Get("Sec-Fetch-Site")
+	switch secFetchSite {
+	case "same-origin", "same-site", "none":
+		return true
+	}
+
+	referer := r.Header.Get("Referer")
+	if referer == "" {
+		// Without Sec-Fetch-Site, an absent Referer means a direct fetch
+		// (curl, feed readers); with an explicit cross-site marker it's a
+		// third-party embed hiding its origin.
+		return secFetchSite != "cross-site"
+	}
+
+	parsed, err := url.Parse(referer)
+	if err != nil {
+		return false
+	}
+
+	refererHost := strings.ToLower(parsed.Hostname())
+	if refererHost == "" {
+		return false
+	}
+
+	ownHost := r.Host
+	if host, _, err := net.SplitHostPort(ownHost); err == nil {
+		ownHost = host
+	}
+
+	if strings.EqualFold(refererHost, ownHost) {
+		return true
+	}
+
+	for _, allowed := range config.Global.ContentProxies.AllowedReferers {
+		if strings.EqualFold(strings.TrimSpace(allowed), refererHost) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedProxyMediaType parses an upstream Content-Type header and reports
+// whether ContentProxies.AllowedContentTypes permits relaying it. Allowlist
+// entries are exact media types or wildcard subtypes like "image/*".
+func allowedProxyMediaType(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	for _, entry := range config.Global.ContentProxies.AllowedContentTypes {
+		if prefix, ok := strings.CutSuffix(entry, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return mediaType, true
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(mediaType, entry) {
+			return mediaType, true
+		}
+	}
+
+	return "", false
+}
+
 // do performs a request using the given options, receives the already-read response body,
 // and handles standard API error responses.
 // It returns the raw body on success.
 func do(ctx context.Context, opts RequestOptions) ([]byte, error) {
+	body, _, err := doWithHeader(ctx, opts)
+
+	return body, err
+}
+
+// doWithHeader is do, but additionally returns a clone of the response
+// headers so callers can't mutate a cached copy.
+func doWithHeader(ctx context.Context, opts RequestOptions) ([]byte, http.Header, error) {
 	resp, body, err := Do(ctx, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
@@ -327,14 +613,19 @@ func do(ctx context.Context, opts RequestOptions) ([]byte, error) {
 			message = "An unknown API error occurred"
 		}
 
-		return nil, &APIError{
+		return nil, nil, &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    message,
 			Err:        errAPIResponseError,
 		}
 	}
 
-	return body, nil
+	header := resp.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return body, header, nil
 }
 
 // processJSONResponse parses a raw JSON response body from the Pixiv API.
@@ -444,6 +735,16 @@ func newRequest(ctx context.Context, opts RequestOptions, token *tokenmanager.To
 	// The /rpc/cps.php endpoint requires a Referer header
 	req.Header.Set("Referer", "https://www.pixiv.net/")
 
+	if opts.ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", opts.ifNoneMatch)
+	}
+
+	if opts.ifModifiedSince != "" {
+		req.Header.Set("If-Modified-Since", opts.ifModifiedSince)
+	}
+
+	sanitizeForwardedHeaders(req.Header, opts.IncomingHeaders)
+
 	return req, nil
 }
 
@@ -0,0 +1,210 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+func TestDo_SingleflightDedup(t *testing.T) {
+	transport := &gatedTransport{release: make(chan struct{})}
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	opts := RequestOptions{
+		Method:  http.MethodGet,
+		URL:     "https://www.pixiv.net/ajax/illust/987654",
+		Cookies: map[string]string{"PHPSESSID": "123456_sessionsecret"},
+	}
+
+	const callers = 8
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		bodies []string
+		errs   []error
+	)
+
+	for range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, body, err := Do(t.Context(), opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			bodies = append(bodies, string(body))
+			errs = append(errs, err)
+		}()
+	}
+
+	// Wait for the leader to reach the (gated) transport, give the remaining
+	// callers time to join the in-flight fetch, then let it complete.
+	deadline := time.Now().Add(2 * time.Second)
+	for transport.calls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("No caller reached the transport")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(transport.release)
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Do() caller %d error: %v", i, err)
+		}
+	}
+
+	for i, body := range bodies {
+		if body != `{"fresh":true}` {
+			t.Errorf("Do() caller %d body = %q, want the shared response", i, body)
+		}
+	}
+
+	if got := transport.calls.Load(); got != 1 {
+		t.Errorf("Upstream fetches = %d, want exactly 1 for %d concurrent callers", got, callers)
+	}
+}
+
+// headerStubTransport serves a JSON envelope with identifying headers.
+type headerStubTransport struct{}
+
+func (headerStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type":          {"application/json"},
+			"X-Ratelimit-Remaining": {"42"},
+			"Date":                  {"Tue, 01 Jul 2025 00:00:00 GMT"},
+		},
+		Body:    io.NopCloser(strings.NewReader(`{"error":false,"body":{"works":[1,2]}}`)),
+		Request: req,
+	}, nil
+}
+
+func TestGetJSONBodyWithMeta(t *testing.T) {
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	utils.HTTPClient.Transport = headerStubTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	const rawURL = "https://www.pixiv.net/ajax/illust/555555"
+
+	body, header, err := GetJSONBodyWithMeta(t.Context(), rawURL, nil, nil)
+	if err != nil {
+		t.Fatalf("GetJSONBodyWithMeta() error: %v", err)
+	}
+
+	if string(body) != `{"works":[1,2]}` {
+		t.Errorf("GetJSONBodyWithMeta() body = %q, want the unwrapped payload", body)
+	}
+
+	if got := header.Get("X-Ratelimit-Remaining"); got != "42" {
+		t.Errorf("X-Ratelimit-Remaining = %q, want %q", got, "42")
+	}
+
+	if got := header.Get("Date"); got != "Tue, 01 Jul 2025 00:00:00 GMT" {
+		t.Errorf("Date = %q, want the upstream value", got)
+	}
+
+	// The plain variant extracts the same payload.
+	plain, err := GetJSONBody(t.Context(), rawURL, nil, nil)
+	if err != nil {
+		t.Fatalf("GetJSONBody() error: %v", err)
+	}
+
+	if string(plain) != string(body) {
+		t.Errorf("GetJSONBody() body = %q, want %q", plain, body)
+	}
+}
+
+func TestProcessJSONResponse(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		resp    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "standard envelope returns the body field",
+			resp: `{"error":false,"body":{"id":"1"}}`,
+			want: `{"id":"1"}`,
+		},
+		{
+			name: "cps-style response without an envelope passes through whole",
+			resp: `{"candidates":[{"tag_name":"東方"}]}`,
+			want: `{"candidates":[{"tag_name":"東方"}]}`,
+		},
+		{
+			name:    "error envelope surfaces the API message",
+			resp:    `{"error":true,"message":"rate limited"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON is rejected",
+			resp:    `<!DOCTYPE html>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := processJSONResponse([]byte(tc.resp))
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("processJSONResponse() = %s, want an error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("processJSONResponse() error: %v", err)
+			}
+
+			if string(got) != tc.want {
+				t.Errorf("processJSONResponse() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+)
+
+// Backoff bounds for transient upstream failures. Variables rather than
+// constants so tests can shrink the delays.
+var (
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 4 * time.Second
+)
+
+// retryableStatus reports whether a status code indicates a transient
+// upstream failure worth retrying.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns the backoff before retry number attempt (0-based),
+// doubling per attempt up to retryMaxDelay with jitter in [delay/2, delay].
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay << attempt
+	if delay <= 0 || delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+
+	half := delay / 2
+
+	return half + rand.N(half+1) //nolint:gosec // Jitter does not need crypto randomness.
+}
+
+// sendRequestWithRetry builds and sends the upstream request, retrying
+// idempotent GETs on connection errors and transient 5xx responses with
+// exponential backoff and jitter. Other methods are sent exactly once, and
+// retries stop as soon as the request context is done.
+//
+// On exhaustion the last response (or error) is returned so the caller can
+// mark token status and surface the failure as usual.
+func sendRequestWithRetry(
+	ctx context.Context,
+	opts RequestOptions,
+	token *tokenmanager.Token,
+) (*http.Response, []byte, error) {
+	maxRetries := 0
+	if opts.Method == http.MethodGet {
+		maxRetries = config.Global.Request.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest(ctx, opts, token)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, bodyBytes, err := sendRequest(ctx, req)
+		if err == nil && !retryableStatus(resp.StatusCode) {
+			return resp, bodyBytes, nil
+		}
+
+		if attempt >= maxRetries {
+			return resp, bodyBytes, err
+		}
+
+		select {
+		case <-ctx.Done():
+			// Out of patience; hand back the last outcome.
+			return resp, bodyBytes, err
+		case <-time.After(retryDelay(attempt)):
+		}
+
+		logEvent := log.Ctx(ctx).Debug().
+			Str("url", opts.URL).
+			Int("attempt", attempt+1)
+
+		if err != nil {
+			logEvent = logEvent.Err(err)
+		} else {
+			logEvent = logEvent.Int("status", resp.StatusCode)
+		}
+
+		logEvent.Msg("Retrying transient upstream failure")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+var errConnRefused = errors.New("dial tcp: connection refused")
+
+// flakyTransport fails a fixed number of times before succeeding. The first
+// failure is a connection error, subsequent ones are 503 responses.
+type flakyTransport struct {
+	calls    atomic.Int32
+	failures int32
+}
+
+func (t *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := t.calls.Add(1)
+
+	if call <= t.failures {
+		if call == 1 {
+			return nil, errConnRefused
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("unavailable")),
+			Request:    req,
+		}, nil
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"error":false,"body":{}}`)),
+		Request:    req,
+	}, nil
+}
+
+// failingTransport always returns the configured status code.
+type failingTransport struct {
+	calls  atomic.Int32
+	status int
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+
+	return &http.Response{
+		StatusCode: t.status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("upstream error")),
+		Request:    req,
+	}, nil
+}
+
+// setupRetryTest installs a stub transport with fast backoff delays and the
+// given retry budget.
+func setupRetryTest(t *testing.T, transport http.RoundTripper, maxRetries int) {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+	origBaseDelay := retryBaseDelay
+	origMaxDelay := retryMaxDelay
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+		retryBaseDelay = origBaseDelay
+		retryMaxDelay = origMaxDelay
+	})
+
+	config.Global.SetDefaults()
+	config.Global.Request.MaxRetries = maxRetries
+
+	retryBaseDelay = time.Millisecond
+	retryMaxDelay = 2 * time.Millisecond
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+}
+
+func TestDo_RetriesTransientFailures(t *testing.T) {
+	transport := &flakyTransport{failures: 2}
+	setupRetryTest(t, transport, 2)
+
+	resp, _, err := Do(t.Context(), RequestOptions{
+		Method: http.MethodGet,
+		URL:    "https://www.pixiv.net/ajax/illust/111111",
+	})
+	if err != nil {
+		t.Fatalf("Do() error after retries: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Do() status = %d, want 200 after transient failures", resp.StatusCode)
+	}
+
+	if got := transport.calls.Load(); got != 3 {
+		t.Errorf("upstream attempts = %d, want 3 (two failures, one success)", got)
+	}
+}
+
+func TestDo_RetryCapExhausted(t *testing.T) {
+	transport := &failingTransport{status: http.StatusInternalServerError}
+	setupRetryTest(t, transport, 2)
+
+	resp, _, err := Do(t.Context(), RequestOptions{
+		Method: http.MethodGet,
+		URL:    "https://www.pixiv.net/ajax/illust/222222",
+	})
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+
+	// The final 500 is surfaced once the retry budget is spent.
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Do() status = %d, want the final 500", resp.StatusCode)
+	}
+
+	if got := transport.calls.Load(); got != 3 {
+		t.Errorf("upstream attempts = %d, want 3 (initial try plus two retries)", got)
+	}
+}
+
+func TestDo_NeverRetriesPost(t *testing.T) {
+	transport := &failingTransport{status: http.StatusServiceUnavailable}
+	setupRetryTest(t, transport, 2)
+
+	resp, _, err := Do(t.Context(), RequestOptions{
+		Method:      http.MethodPost,
+		URL:         "https://www.pixiv.net/ajax/illusts/bookmarks/add",
+		ContentType: "application/json",
+		Payload:     `{"illust_id":"222222"}`,
+	})
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Do() status = %d, want 503", resp.StatusCode)
+	}
+
+	if got := transport.calls.Load(); got != 1 {
+		t.Errorf("upstream attempts = %d, want exactly 1 for POST", got)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package requests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// slowTransport simulates a slow upstream that only responds after delay,
+// honoring the request context like a real connection would.
+type slowTransport struct {
+	delay time.Duration
+}
+
+func (t slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case <-time.After(t.delay):
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"slow":true}`)),
+			Request:    req,
+		}, nil
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+// setupTimeoutTest installs a slow transport and restores global state on
+// cleanup. Not parallel-safe: it mutates config.Global.
+func setupTimeoutTest(t *testing.T, delay time.Duration) {
+	t.Helper()
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+	origConfig := config.Global
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+		config.Global = origConfig
+	})
+
+	utils.HTTPClient.Transport = slowTransport{delay: delay}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	config.Global.SetDefaults()
+	config.Global.Request.MaxRetries = 0 // keep timings deterministic
+}
+
+// TestDo_PerCallTimeout verifies that the configured per-call timeout fires
+// when the upstream is slower than allowed.
+func TestDo_PerCallTimeout(t *testing.T) {
+	setupTimeoutTest(t, 2*time.Second)
+
+	config.Global.Request.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+
+	_, _, err := Do(context.Background(), RequestOptions{
+		Method: http.MethodGet,
+		URL:    "https://www.pixiv.net/ajax/slow/percall",
+	})
+	if err == nil {
+		t.Fatal("Expected a timeout error from a slow upstream, got nil")
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the call to fail fast, took %v", elapsed)
+	}
+}
+
+// TestDo_ShorterParentContextWins verifies that a caller-supplied deadline
+// shorter than the configured timeout still cancels the call first.
+func TestDo_ShorterParentContextWins(t *testing.T) {
+	setupTimeoutTest(t, 2*time.Second)
+
+	config.Global.Request.Timeout = 10 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, _, err := Do(ctx, RequestOptions{
+		Method:  http.MethodPost,
+		URL:     "https://www.pixiv.net/ajax/slow/parent",
+		Payload: `{"probe":true}`,
+		Cookies: map[string]string{"PHPSESSID": "123456_sessionsecret"},
+	})
+	if err == nil {
+		t.Fatal("Expected the parent deadline to cancel the call, got nil error")
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the parent deadline to win quickly, took %v", elapsed)
+	}
+}
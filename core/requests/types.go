@@ -16,4 +16,14 @@ type RequestOptions struct {
 	Payload         any
 	CSRF            string
 	ContentType     string
+
+	// forceRefresh bypasses the cache lookup while still storing the fresh
+	// response. Set internally for stale-while-revalidate refreshes.
+	forceRefresh bool
+
+	// ifNoneMatch and ifModifiedSince carry the validators of an expired
+	// cache entry so the upstream fetch can be made conditional. Set
+	// internally from the cache policy.
+	ifNoneMatch     string
+	ifModifiedSince string
 }
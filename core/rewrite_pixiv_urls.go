@@ -17,6 +17,7 @@ import (
 	"regexp"
 	"strings"
 
+	"codeberg.org/pixivfe/pixivfe/v3/config"
 	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
 	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
 )
@@ -49,6 +50,10 @@ var (
 	// distinct from those found within /jump.php redirects.
 	absolutePixivLinkRegexp = regexp.MustCompile(`https?://(?:[a-zA-Z0-9\-]+\.)*pixiv\.net[^\s<>"']*`)
 
+	// externalServiceLinkRegexp matches standalone sketch.pixiv.net and
+	// fanbox.cc URLs (including creator subdomains) in descriptions.
+	externalServiceLinkRegexp = regexp.MustCompile(`https?://(?:sketch\.pixiv\.net|(?:[a-zA-Z0-9\-]+\.)?fanbox\.cc)[^\s<>"']*`)
+
 	// excludedPaths defines i.pximg.net URL patterns that should not be converted to WebP.
 	excludedPaths = []string{
 		"/background/",
@@ -125,6 +130,12 @@ func rewriteImageURLsInternal(r *http.Request, data string, useEscapedPatterns b
 			func(u string) string {
 				proxyBase := utils.GetProxyBase(untrusted.GetImageProxy(r))
 
+				// Users can opt out of WebP conversion entirely; the original
+				// path is proxied unchanged.
+				if untrusted.PrefersOriginalImages(r) {
+					return strings.Replace(u, "https://i.pximg.net", proxyBase, 1)
+				}
+
 				// Handle image URLs that should not be converted to WebP.
 				for _, excludedPath := range excludedPaths {
 					if strings.Contains(u, excludedPath) {
@@ -201,9 +212,44 @@ func parseDescriptionURLs(description string) string {
 		return match
 	})
 
+	// Third, optionally route recognized sketch/fanbox links through the
+	// /goto warning page so they are clearly labeled as external.
+	if config.Global.ExternalLinks.RouteDescriptionLinks {
+		result = externalServiceLinkRegexp.ReplaceAllStringFunc(result, func(match string) string {
+			if routed, ok := tryRouteExternalServiceURL(match); ok {
+				return routed
+			}
+
+			return match
+		})
+	}
+
 	return result
 }
 
+// tryRouteExternalServiceURL wraps a sketch.pixiv.net or fanbox.cc URL into
+// the /goto external-link redirector, which shows a labeled warning page for
+// hosts outside the operator's policy.
+//
+// It returns the new URL string and a boolean indicating if a conversion occurred.
+func tryRouteExternalServiceURL(fullURLString string) (string, bool) {
+	parsedTargetURL, err := url.Parse(fullURLString)
+	if err != nil {
+		return fullURLString, false
+	}
+
+	if parsedTargetURL.Scheme != "http" && parsedTargetURL.Scheme != "https" {
+		return fullURLString, false
+	}
+
+	host := strings.ToLower(parsedTargetURL.Hostname())
+	if host != "sketch.pixiv.net" && host != "fanbox.cc" && !strings.HasSuffix(host, ".fanbox.cc") {
+		return fullURLString, false
+	}
+
+	return "/goto?url=" + url.QueryEscape(fullURLString), true
+}
+
 // tryMakePixivURLRelative attempts to convert a full pixiv.net URL string to a relative path
 // if it matches specific patterns (users, artworks, novels).
 //
@@ -299,6 +345,14 @@ func processPixivRedirectMatch(match string) string {
 		return relativeURL
 	}
 
+	// Optionally route recognized sketch/fanbox targets through /goto so
+	// jump.php-wrapped links get the same labeling as standalone ones.
+	if config.Global.ExternalLinks.RouteDescriptionLinks {
+		if routed, converted := tryRouteExternalServiceURL(decodedURL); converted {
+			return routed
+		}
+	}
+
 	// If not converted by tryMakePixivURLRelative, the decodedURL could be:
 	// - An external HTTP/S link.
 	// - A pixiv URL not matching relativization patterns (e.g., pixiv.net/home).
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"codeberg.org/pixivfe/pixivfe/v3/config"
 	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
 )
 
@@ -288,6 +289,136 @@ func TestGenerateMasterWebpURLWithArbitraryProxy(t *testing.T) {
 	}
 }
 
+// TestGenerateMasterWebpURLCustomSpec verifies that a configured target size
+// and quality are threaded into the generated /c/ segment. Deliberately not
+// parallel: it mutates config.Global.Image.
+func TestGenerateMasterWebpURLCustomSpec(t *testing.T) {
+	origConfig := config.Global
+	t.Cleanup(func() { config.Global = origConfig })
+
+	config.Global.Image.WebpSize = 600
+	config.Global.Image.WebpQuality = 90
+
+	tests := []struct {
+		name        string
+		input       string
+		proxyPrefix string
+		expected    string
+	}{
+		{
+			name:     "img-master with custom spec",
+			input:    "https://i.pximg.net/img-master/img/2025/06/05/18/10/08/131206066_p0_master1200.jpg",
+			expected: "https://i.pximg.net/c/600x600_90_webp/img-master/img/2025/06/05/18/10/08/131206066_p0_master1200.jpg",
+		},
+		{
+			name:     "existing size segment replaced with custom spec",
+			input:    "https://i.pximg.net/c/250x250_80_a2/img-master/img/2025/06/05/18/10/08/131206066_p0_square1200.jpg",
+			expected: "https://i.pximg.net/c/600x600_90_webp/img-master/img/2025/06/05/18/10/08/131206066_p0_master1200.jpg",
+		},
+		{
+			name:     "novel cover with custom spec",
+			input:    "https://i.pximg.net/novel-cover-original/img/1970/01/01/00/00/00/deadbeef.jpg",
+			expected: "https://i.pximg.net/c/600x600_90_webp/novel-cover-master/img/1970/01/01/00/00/00/deadbeef_master1200.jpg",
+		},
+		{
+			name:        "built-in proxy with custom spec",
+			input:       "https://i.pximg.net/img-master/img/2025/06/05/18/10/08/131206066_p0_master1200.jpg",
+			proxyPrefix: "/proxy/i.pximg.net",
+			expected:    "/proxy/i.pximg.net/c/600x600_90_webp/img-master/img/2025/06/05/18/10/08/131206066_p0_master1200.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := generateMasterWebpURL(tt.input, tt.proxyPrefix)
+
+			if result != tt.expected {
+				t.Errorf("generateMasterWebpURL(%q, %q) = %q, expected %q", tt.input, tt.proxyPrefix, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGenerateMasterWebpURLCategoryConfig verifies that categories removed
+// from Image.WebpCategories are proxied unchanged instead of being converted.
+// Deliberately not parallel: it mutates config.Global.Image.
+func TestGenerateMasterWebpURLCategoryConfig(t *testing.T) {
+	origConfig := config.Global
+	t.Cleanup(func() { config.Global = origConfig })
+
+	config.Global.SetDefaults()
+	config.Global.Image.WebpCategories = []string{"img-master", "custom-thumb"}
+
+	tests := []struct {
+		name        string
+		input       string
+		proxyPrefix string
+		expected    string
+	}{
+		{
+			name:     "img-original excluded without proxy",
+			input:    "https://i.pximg.net/img-original/img/2025/06/05/18/10/08/131206066_p0.png",
+			expected: "https://i.pximg.net/img-original/img/2025/06/05/18/10/08/131206066_p0.png",
+		},
+		{
+			name:        "img-original excluded keeps original path under proxy",
+			input:       "https://i.pximg.net/img-original/img/2025/06/05/18/10/08/131206066_p0.png",
+			proxyPrefix: "/proxy/i.pximg.net",
+			expected:    "/proxy/i.pximg.net/img-original/img/2025/06/05/18/10/08/131206066_p0.png",
+		},
+		{
+			name:     "img-master still converted",
+			input:    "https://i.pximg.net/img-master/img/2025/06/05/18/10/08/131206066_p0_master1200.jpg",
+			expected: "https://i.pximg.net/c/1200x1200_80_webp/img-master/img/2025/06/05/18/10/08/131206066_p0_master1200.jpg",
+		},
+		{
+			name:     "novel cover excluded",
+			input:    "https://i.pximg.net/novel-cover-original/img/1970/01/01/00/00/00/deadbeef.jpg",
+			expected: "https://i.pximg.net/novel-cover-original/img/1970/01/01/00/00/00/deadbeef.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := generateMasterWebpURL(tt.input, tt.proxyPrefix)
+
+			if result != tt.expected {
+				t.Errorf("generateMasterWebpURL(%q, %q) = %q, expected %q", tt.input, tt.proxyPrefix, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMasterWebpSpecFallbacks verifies that unset or out-of-range values fall
+// back to the standard 1200px, quality 80 target. Deliberately not parallel:
+// it mutates config.Global.Image.
+func TestMasterWebpSpecFallbacks(t *testing.T) {
+	origConfig := config.Global
+	t.Cleanup(func() { config.Global = origConfig })
+
+	tests := []struct {
+		name     string
+		size     int
+		quality  int
+		expected string
+	}{
+		{"Zero values use the standard spec", 0, 0, "1200x1200_80_webp"},
+		{"Quality above 100 falls back", 768, 150, "768x768_80_webp"},
+		{"Negative size falls back", -1, 70, "1200x1200_70_webp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Global.Image.WebpSize = tt.size
+			config.Global.Image.WebpQuality = tt.quality
+
+			if got := masterWebpSpec(); got != tt.expected {
+				t.Errorf("masterWebpSpec() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestRewriteEscapedContentURLs(t *testing.T) {
 	t.Parallel()
 
@@ -772,6 +903,101 @@ func TestRewriteContentURLs(t *testing.T) {
 	}
 }
 
+// TestRewriteContentURLsOriginalImages verifies that the 'OriginalImages'
+// cookie suppresses master WebP conversion: i.pximg.net paths are proxied
+// verbatim instead of being rewritten into the /c/…_webp master form.
+func TestRewriteContentURLsOriginalImages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "img-master stays unconverted",
+			input:    `<img src="https://i.pximg.net/img-master/img/2025/01/01/12/00/00/123456789_p0_master1200.jpg">`,
+			expected: `<img src="/proxy/i.pximg.net/img-master/img/2025/01/01/12/00/00/123456789_p0_master1200.jpg">`,
+		},
+		{
+			name:     "img-original stays unconverted",
+			input:    `<img src="https://i.pximg.net/img-original/img/2025/01/01/12/00/00/123456789_p0.png">`,
+			expected: `<img src="/proxy/i.pximg.net/img-original/img/2025/01/01/12/00/00/123456789_p0.png">`,
+		},
+		{
+			name:     "custom-thumb stays unconverted",
+			input:    `<img src="https://i.pximg.net/custom-thumb/img/2025/01/01/12/00/00/123456789_p0_custom1200.jpg">`,
+			expected: `<img src="/proxy/i.pximg.net/custom-thumb/img/2025/01/01/12/00/00/123456789_p0_custom1200.jpg">`,
+		},
+		{
+			name:     "existing size segment is preserved",
+			input:    `<img src="https://i.pximg.net/c/600x600/img-master/img/2025/01/01/12/00/00/123456789_p0_master1200.jpg">`,
+			expected: `<img src="/proxy/i.pximg.net/c/600x600/img-master/img/2025/01/01/12/00/00/123456789_p0_master1200.jpg">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: string(cookie.ImageProxyCookie), Value: "/proxy/i.pximg.net"})
+			req.AddCookie(&http.Cookie{Name: string(cookie.OriginalImagesCookie), Value: "true"})
+
+			result := RewriteImageURLs(req, tt.input)
+
+			if result != tt.expected {
+				t.Errorf("RewriteImageURLs(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRewriteEscapedContentURLsOriginalImages covers the escaped-JSON path
+// with the 'OriginalImages' cookie set; the rewritten payload must keep its
+// escaped forward slashes.
+func TestRewriteEscapedContentURLsOriginalImages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "escaped img-master stays unconverted",
+			input:    `{"url":"https:\/\/i.pximg.net\/img-master\/img\/2025\/01\/01\/12\/00\/00\/123456789_p0_master1200.jpg"}`,
+			expected: `{"url":"\/proxy\/i.pximg.net\/img-master\/img\/2025\/01\/01\/12\/00\/00\/123456789_p0_master1200.jpg"}`,
+		},
+		{
+			name:     "escaped img-original stays unconverted",
+			input:    `{"url":"https:\/\/i.pximg.net\/img-original\/img\/2025\/01\/01\/12\/00\/00\/123456789_p0.jpg"}`,
+			expected: `{"url":"\/proxy\/i.pximg.net\/img-original\/img\/2025\/01\/01\/12\/00\/00\/123456789_p0.jpg"}`,
+		},
+		{
+			name:     "escaped custom-thumb stays unconverted",
+			input:    `{"url":"https:\/\/i.pximg.net\/custom-thumb\/img\/2025\/01\/01\/12\/00\/00\/123456789_p0_custom1200.jpg"}`,
+			expected: `{"url":"\/proxy\/i.pximg.net\/custom-thumb\/img\/2025\/01\/01\/12\/00\/00\/123456789_p0_custom1200.jpg"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: string(cookie.ImageProxyCookie), Value: "/proxy/i.pximg.net"})
+			req.AddCookie(&http.Cookie{Name: string(cookie.OriginalImagesCookie), Value: "true"})
+
+			result := string(RewriteEscapedImageURLs(req, []byte(tt.input)))
+
+			if result != tt.expected {
+				t.Errorf("RewriteEscapedImageURLs(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestParseDescriptionURLs(t *testing.T) {
 	t.Parallel()
 
@@ -996,3 +1222,71 @@ func TestParseDescriptionURLs(t *testing.T) {
 		})
 	}
 }
+
+// TestParseDescriptionURLsExternalServices verifies the optional routing of
+// sketch.pixiv.net and fanbox.cc links through the /goto warning page.
+// Deliberately not parallel: it mutates config.Global.ExternalLinks.
+func TestParseDescriptionURLsExternalServices(t *testing.T) {
+	origConfig := config.Global
+	t.Cleanup(func() { config.Global = origConfig })
+
+	config.Global.ExternalLinks.RouteDescriptionLinks = true
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "jump.php-wrapped sketch link",
+			input:    "/jump.php?https%3A%2F%2Fsketch.pixiv.net%2F%40someuser",
+			expected: "/goto?url=https%3A%2F%2Fsketch.pixiv.net%2F%40someuser",
+		},
+		{
+			name:     "jump.php-wrapped fanbox creator link",
+			input:    "/jump.php?https%3A%2F%2Fsomeuser.fanbox.cc%2Fposts%2F123",
+			expected: "/goto?url=https%3A%2F%2Fsomeuser.fanbox.cc%2Fposts%2F123",
+		},
+		{
+			name:     "standalone sketch link",
+			input:    "Streams at https://sketch.pixiv.net/@someuser sometimes",
+			expected: "Streams at /goto?url=https%3A%2F%2Fsketch.pixiv.net%2F%40someuser sometimes",
+		},
+		{
+			name:     "standalone fanbox apex link",
+			input:    "Support me: https://fanbox.cc/@someuser",
+			expected: "Support me: /goto?url=https%3A%2F%2Ffanbox.cc%2F%40someuser",
+		},
+		{
+			name:     "pixiv artwork redirect is still relativized",
+			input:    "/jump.php?https%3A%2F%2Fwww.pixiv.net%2Fartworks%2F67890",
+			expected: "/artworks/67890",
+		},
+		{
+			name:     "javascript redirect is still sanitized",
+			input:    "/jump.php?javascript%3Aalert(1)",
+			expected: "",
+		},
+		{
+			name:     "unrelated external link passes through",
+			input:    "https://example.com/page",
+			expected: "https://example.com/page",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDescriptionURLs(tt.input); got != tt.expected {
+				t.Errorf("parseDescriptionURLs(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	// With the option disabled, sketch/fanbox links pass through untouched.
+	config.Global.ExternalLinks.RouteDescriptionLinks = false
+
+	passthrough := "https://sketch.pixiv.net/@someuser and https://someuser.fanbox.cc/"
+	if got := parseDescriptionURLs(passthrough); got != passthrough {
+		t.Errorf("parseDescriptionURLs(%q) with routing disabled = %q, expected it unchanged", passthrough, got)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
 	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
 	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
+	"codeberg.org/pixivfe/pixivfe/v3/i18n"
 )
 
 type SearchCategory = string
@@ -37,6 +38,37 @@ const (
 	SearchFilterModeR18  = "r18"
 )
 
+// Smode values for [WorkSearchSettings.Smode], mapping directly to pixiv's
+// s_mode query parameter.
+const (
+	SearchSmodePartial = "s_tag"      // partial tag match
+	SearchSmodeExact   = "s_tag_full" // exact tag match
+	SearchSmodeTitle   = "s_tc"       // title and caption match
+)
+
+// SearchAITypeExclude is the only AI filter pixiv supports at the search
+// level: ai_type=1 removes AI-generated works from the results. Isolating
+// AI works has no server-side equivalent and stays a client-side concern.
+const SearchAITypeExclude = "1"
+
+// SplitSearchTerms splits raw search keywords into included and excluded
+// terms. Terms prefixed with "-" are exclusions, following pixiv's own search
+// syntax; the keywords are forwarded verbatim in the word query parameter,
+// prefixes included, which is how pixiv expects exclusions to arrive.
+func SplitSearchTerms(name string) (included, excluded []string) {
+	for _, term := range strings.Fields(name) {
+		if rest, ok := strings.CutPrefix(term, "-"); ok && rest != "" {
+			excluded = append(excluded, rest)
+
+			continue
+		}
+
+		included = append(included, term)
+	}
+
+	return included, excluded
+}
+
 type SearchOrder string
 
 const (
@@ -81,6 +113,8 @@ var (
 
 	errInvalidCategory = errors.New("invalid category")
 
+	errTagCompletionsShape = errors.New("unexpected tag completion response shape")
+
 	// SearchToolValues defines the possible values for the "tool" parameter.
 	SearchToolValues = []string{"", "SAI", "Photoshop", "CLIP STUDIO PAINT", "IllustStudio", "ComicStudio", "Pixia", "AzPainter2", "Painter", "Illustrator", "GIMP", "FireAlpaca", "Oekaki BBS", "AzPainter", "CGillust", "Oekaki Chat", "Tegaki Blog", "MS_Paint", "PictBear", "openCanvas", "PaintShopPro", "EDGE", "drawr", "COMICWORKS", "AzDrawing", "SketchBookPro", "PhotoStudio", "Paintgraphic", "MediBang Paint", "NekoPaint", "Inkscape", "ArtRage", "AzDrawing2", "Fireworks", "ibisPaint", "AfterEffects", "mdiapp", "GraphicsGale", "Krita", "kokuban.in", "RETAS STUDIO", "e-mote", "4thPaint", "ComiLabo", "pixiv Sketch", "Pixelmator", "Procreate", "Expression", "PicturePublisher", "Processing", "Live2D", "dotpict", "Aseprite", "Pastela", "Poser", "Metasequoia", "Blender", "Shade", "3dsMax", "DAZ Studio", "ZBrush", "Comi Po!", "Maya", "Lightwave3D", "Hexagon King", "Vue", "SketchUp", "CINEMA4D", "XSI", "CARRARA", "Bryce", "STRATA", "Sculptris", "modo", "AnimationMaster", "VistaPro", "Sunny3D", "3D-Coat", "Paint 3D", "VRoid Studio", "Mechanical pencil", "Pencil", "Ballpoint pen", "Thin marker", "Colored pencil", "Copic marker", "Dip pen", "Watercolors", "Brush", "Calligraphy pen", "Felt-tip pen", "Magic marker", "Watercolor brush", "Paint", "Acrylic paint", "Fountain pen", "Pastels", "Airbrush", "Color ink", "Crayon", "Oil paint", "Coupy pencil", "Gansai", "Pastel Crayons"}
 
@@ -104,6 +138,26 @@ type SearchData struct {
 	CurrentPage          int
 	LastPage             int
 	PopularSearchEnabled bool
+	NoResults            bool                 // NoResults reports whether the search returned zero works for the selected category
+	Suggestions          SearchSuggestions    // Suggestions is populated when NoResults is true
+	Combined             []CombinedSearchItem // Combined is populated only for IncludeNovels "artworks" searches
+}
+
+// Pagination returns navigation metadata for the search results, with up to
+// window page numbers centered on the current page.
+func (s *SearchData) Pagination(window int) Pagination {
+	return NewPagination(s.CurrentPage, s.LastPage, window)
+}
+
+// SearchSuggestions holds alternatives offered when a search returns no results.
+type SearchSuggestions struct {
+	DidYouMean  *Tag // A likely intended tag, e.g. when the query matches a tag's romaji or translation
+	RelatedTags Tags // Related tags from the search response, if pixiv returned any
+}
+
+// HasAny reports whether at least one suggestion is available.
+func (s SearchSuggestions) HasAny() bool {
+	return s.DidYouMean != nil || len(s.RelatedTags) > 0
 }
 
 // KeywordCompletions represents a keyword and its associated tag completions.
@@ -127,13 +181,13 @@ type tagCompletionsResponse struct {
 // WorkSearchSettings defines the settings for searches
 // when the chosen category is a work type (i.e., "artworks", "illustrations", "manga", or "novels").
 type WorkSearchSettings struct {
-	Name     string // Keywords to search for. Used in the URL path and the 'word' query param.
+	Name     string // Keywords to search for, including "-keyword" exclusions. Used in the URL path and the 'word' query param.
 	Category string // Filter by type (e.g., "illustrations", "manga"). Used in the URL path.
 	Order    string // Sort by date.
 	Mode     string // Safe, R18 or both.
 	Ratio    string // Landscape, portrait, or squared.
 	Page     string // Page number.
-	Smode    string // Exact match, partial match, or match with title.
+	Smode    string // Match mode; one of the SearchSmode constants, or "" for pixiv's default (partial tag match).
 	Wlt      string // Minimum image width.
 	Wgt      string // Maximum image width.
 	Hlt      string // Minimum image height.
@@ -141,6 +195,12 @@ type WorkSearchSettings struct {
 	Tool     string // Filter by production tools (e.g. Photoshop).
 	Scd      string // After this date.
 	Ecd      string // Before this date.
+	AIType   string // AI-generated work filter; SearchAITypeExclude, or "" to leave AI works in the results.
+
+	// IncludeNovels additionally fetches novel results for an "artworks"
+	// search and merges them into SearchData.Combined. Opt-in, since it
+	// changes what the result set contains.
+	IncludeNovels bool
 }
 
 // tagSearchResult is a custom type that extends tagSearchResponse
@@ -289,6 +349,28 @@ func GetSearch(r *http.Request, settings WorkSearchSettings) (*SearchData, error
 		settings.Category = SearchIllustrationsCategory
 	}
 
+	// Optionally fetch novels to merge into an "artworks" search.
+	var novelResult *SearchData
+
+	if settings.IncludeNovels && originalCategory == SearchArtworksCategory {
+		novelSettings := settings
+		novelSettings.Category = SearchNovelsCategory
+
+		// Popular ordering is an illust-only concept upstream; fetch the
+		// novels with pixiv's default ordering instead.
+		if strings.ToLower(novelSettings.Order) == "popular" {
+			novelSettings.Order = ""
+		}
+
+		g.Go(func() error {
+			var err error
+
+			novelResult, err = getStandardSearch(r, novelSettings)
+
+			return err
+		})
+	}
+
 	// Fetch search results and tag data concurrently
 	g.Go(func() error {
 		var err error
@@ -333,11 +415,19 @@ func GetSearch(r *http.Request, settings WorkSearchSettings) (*SearchData, error
 		tag.Metadata.Name = strings.TrimSpace(strings.TrimSuffix(tag.Metadata.Name, SearchUgoiraKeyword))
 	}
 
+	if novelResult != nil {
+		mergeNovelResults(result, novelResult)
+	}
+
 	// Set tag data and metadata in struct field order
 	result.Title = "Results for " + originalName
 	result.Tag = tag
 	result.PopularSearchEnabled = config.Global.Feature.PopularSearch
 
+	if config.Global.Feature.PrefetchSearchAuthors {
+		prefetchSearchAuthors(r, result)
+	}
+
 	return result, nil
 }
 
@@ -406,6 +496,15 @@ func GetSearchUsers(r *http.Request, settings WorkSearchSettings) (*SearchData,
 
 // getStandardSearch handles the standard search logic.
 func getStandardSearch(r *http.Request, settings WorkSearchSettings) (*SearchData, error) {
+	// Sorting by popularity natively is a pixiv premium feature; with a
+	// non-premium token, upstream silently falls back to date ordering.
+	// Surface the limitation instead of returning misleading results.
+	if SearchOrder(settings.Order) == SearchSortPopularFirst {
+		if err := checkPremiumSort(r); err != nil {
+			return nil, err
+		}
+	}
+
 	url, err := GetArtworkSearchURL(settings)
 	if err != nil {
 		return nil, err
@@ -494,9 +593,59 @@ func getStandardSearch(r *http.Request, settings WorkSearchSettings) (*SearchDat
 		return nil, fmt.Errorf("%w: %s", errInvalidCategory, settings.Category)
 	}
 
+	annotateArtworkFetchSource(searchData.IllustManga.Data, "search", url)
+	annotateArtworkFetchSource(searchData.Illustrations.Data, "search", url)
+	annotateArtworkFetchSource(searchData.Manga.Data, "search", url)
+	annotateArtworkFetchSource(searchData.Popular.Permanent, "search", url)
+	annotateArtworkFetchSource(searchData.Popular.Recent, "search", url)
+	annotateNovelFetchSource(searchData.Novels.Data, "search", url)
+
+	// A search that comes up empty still carries related tags and translations
+	// we can surface as alternatives instead of a blank page.
+	if searchData.Total == 0 {
+		searchData.NoResults = true
+		searchData.Suggestions = BuildSearchSuggestions(settings.Name, searchData.RelatedTags)
+	}
+
 	return searchData, nil
 }
 
+// BuildSearchSuggestions assembles suggestions for a query that returned zero
+// results, using only tag data already present in the search response.
+//
+// A "did you mean" candidate is picked when the query matches a related tag's
+// romaji or English translation (e.g. the user typed the romanization of a
+// Japanese tag); the related tags themselves are passed through unchanged.
+func BuildSearchSuggestions(query string, relatedTags Tags) SearchSuggestions {
+	suggestions := SearchSuggestions{
+		RelatedTags: relatedTags,
+	}
+
+	folded := strings.ToLower(strings.TrimSpace(query))
+	if folded == "" {
+		return suggestions
+	}
+
+	for i := range relatedTags {
+		tag := &relatedTags[i]
+
+		// An exact match on the tag name means the query wasn't a typo;
+		// the related tags alone are the best we can offer.
+		if strings.EqualFold(tag.Name, folded) {
+			continue
+		}
+
+		if strings.ToLower(tag.Romaji) == folded ||
+			strings.EqualFold(tag.TagTranslations.En, folded) {
+			suggestions.DidYouMean = tag
+
+			break
+		}
+	}
+
+	return suggestions
+}
+
 // GetTagCompletions retrieves tag completion suggestions for the last keyword in a search query.
 func GetTagCompletions(r *http.Request, keywords string) (*KeywordCompletions, error) {
 	// Split keywords by spaces and filter out empty strings.
@@ -508,8 +657,6 @@ func GetTagCompletions(r *http.Request, keywords string) (*KeywordCompletions, e
 	// We only need completions for the last tag in the user's input.
 	lastTag := tags[len(tags)-1]
 
-	var tagCompletionResponse tagCompletionsResponse
-
 	resp, err := requests.GetJSONBody(
 		r.Context(),
 		GetTagCompletionURL(lastTag),
@@ -519,7 +666,8 @@ func GetTagCompletions(r *http.Request, keywords string) (*KeywordCompletions, e
 		return nil, err
 	}
 
-	if err := json.Unmarshal(resp, &tagCompletionResponse); err != nil {
+	tagCompletionResponse, err := parseTagCompletions(resp)
+	if err != nil {
 		return nil, err
 	}
 
@@ -530,6 +678,56 @@ func GetTagCompletions(r *http.Request, keywords string) (*KeywordCompletions, e
 	}, nil
 }
 
+// parseTagCompletions decodes a /rpc/cps.php completion payload. The endpoint
+// skips the standard error/body envelope, so the raw response reaches us
+// whole; a payload without a candidates array is rejected instead of being
+// read as zero completions, so an upstream shape change fails loudly rather
+// than silently emptying tag completions.
+func parseTagCompletions(resp []byte) (tagCompletionsResponse, error) {
+	var probe struct {
+		Candidates json.RawMessage `json:"candidates"`
+	}
+
+	if err := json.Unmarshal(resp, &probe); err != nil {
+		return tagCompletionsResponse{}, fmt.Errorf("%w: %w", errTagCompletionsShape, err)
+	}
+
+	if len(probe.Candidates) == 0 {
+		return tagCompletionsResponse{}, fmt.Errorf("%w: missing candidates array", errTagCompletionsShape)
+	}
+
+	var parsed tagCompletionsResponse
+
+	if err := json.Unmarshal(probe.Candidates, &parsed.Candidates); err != nil {
+		return tagCompletionsResponse{}, fmt.Errorf("%w: %w", errTagCompletionsShape, err)
+	}
+
+	return parsed, nil
+}
+
+// checkPremiumSort verifies that the requesting token belongs to a premium
+// account before a premium-only sort order is forwarded upstream.
+//
+// When the premium status cannot be determined (e.g. the settings fetch
+// fails), the request is let through so upstream has the final say.
+func checkPremiumSort(r *http.Request) error {
+	if untrusted.GetUserToken(r) == "" {
+		return i18n.NewUserError(r.Context(), "Sorting by popularity requires a logged-in pixiv premium account.")
+	}
+
+	self, err := GetSettingsSelf(r)
+	if err != nil {
+		return nil
+	}
+
+	if !self.IsPremium() {
+		return i18n.NewUserError(r.Context(),
+			"Sorting by popularity requires pixiv premium, which the current account does not have.")
+	}
+
+	return nil
+}
+
 // getPopularSearch handles the popular search logic.
 func getPopularSearch(r *http.Request, settings WorkSearchSettings) (*SearchData, error) {
 	// Check if popular search is enabled
@@ -537,6 +735,16 @@ func getPopularSearch(r *http.Request, settings WorkSearchSettings) (*SearchData
 		return nil, fmt.Errorf("Popular search is disabled by server configuration.")
 	}
 
+	// A premium token can use pixiv's native popularity sort directly, which
+	// is both cheaper and more accurate than the threshold strategy below.
+	if untrusted.GetUserToken(r) != "" {
+		if self, err := GetSettingsSelf(r); err == nil && self.IsPremium() {
+			settings.Order = string(SearchSortPopularFirst)
+
+			return getStandardSearch(r, settings)
+		}
+	}
+
 	// Perform popular search
 	searchArtworks, err := searchPopular(r.Context(), r, settings)
 	if err != nil {
@@ -0,0 +1,82 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// prefetchSearchAuthorsLimit bounds the number of concurrent author info
+// fetches during a search result prefetch.
+const prefetchSearchAuthorsLimit = 4
+
+// prefetchSearchAuthors warms the request cache with basic information for
+// every unique author appearing in a search result set, so that author
+// popovers resolve instantly from cache instead of triggering a fresh
+// upstream call.
+//
+// The prefetch is best-effort: individual failures are ignored, since the
+// popover falls back to fetching on demand.
+func prefetchSearchAuthors(r *http.Request, result *SearchData) {
+	ids := collectSearchAuthorIDs(result)
+	if len(ids) == 0 {
+		return
+	}
+
+	var g errgroup.Group
+
+	g.SetLimit(prefetchSearchAuthorsLimit)
+
+	for _, id := range ids {
+		g.Go(func() error {
+			// Fetched purely for its caching side effect in the request layer.
+			_, _ = GetUserBasicInformation(r, id)
+
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+}
+
+// collectSearchAuthorIDs returns the deduplicated author IDs across every
+// work category in the result, preserving first-seen order.
+func collectSearchAuthorIDs(result *SearchData) []string {
+	var (
+		seen = make(map[string]bool)
+		ids  []string
+	)
+
+	addWorks := func(works []ArtworkItem) {
+		for _, work := range works {
+			if work.UserID == "" || seen[work.UserID] {
+				continue
+			}
+
+			seen[work.UserID] = true
+
+			ids = append(ids, work.UserID)
+		}
+	}
+
+	addWorks(result.IllustManga.Data)
+	addWorks(result.Illustrations.Data)
+	addWorks(result.Manga.Data)
+	addWorks(result.Popular.Permanent)
+	addWorks(result.Popular.Recent)
+
+	for _, novel := range result.Novels.Data {
+		if novel == nil || novel.UserID == "" || seen[novel.UserID] {
+			continue
+		}
+
+		seen[novel.UserID] = true
+
+		ids = append(ids, novel.UserID)
+	}
+
+	return ids
+}
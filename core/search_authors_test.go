@@ -0,0 +1,180 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// authorStubTransport serves a minimal user info response while recording
+// which user IDs were requested and the peak number of in-flight requests.
+type authorStubTransport struct {
+	mu        sync.Mutex
+	requested []string
+	active    int
+	maxActive int
+}
+
+func (t *authorStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+
+	t.active++
+	if t.active > t.maxActive {
+		t.maxActive = t.active
+	}
+
+	segments := strings.Split(req.URL.Path, "/")
+	userID := segments[len(segments)-1]
+	t.requested = append(t.requested, userID)
+
+	t.mu.Unlock()
+
+	// Hold the request open briefly so concurrent fetches overlap.
+	time.Sleep(20 * time.Millisecond)
+
+	t.mu.Lock()
+	t.active--
+	t.mu.Unlock()
+
+	body := fmt.Sprintf(`{"error":false,"body":{"userId":%q,"name":"User %s","imageBig":""}}`, userID, userID)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// setupAuthorPrefetchTest installs the stub transport and a token manager,
+// restoring global state on cleanup. Not parallel-safe.
+func setupAuthorPrefetchTest(t *testing.T) *authorStubTransport {
+	t.Helper()
+
+	transport := &authorStubTransport{}
+
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+	origConfig := config.Global
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+		config.Global = origConfig
+	})
+
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+	config.Global.SetDefaults()
+
+	return transport
+}
+
+// TestCollectSearchAuthorIDs verifies deduplication across work categories.
+func TestCollectSearchAuthorIDs(t *testing.T) {
+	t.Parallel()
+
+	result := &SearchData{}
+	result.IllustManga.Data = []ArtworkItem{
+		{ID: "1", UserID: "11"},
+		{ID: "2", UserID: "22"},
+		{ID: "3", UserID: "11"}, // duplicate author
+		{ID: "4", UserID: ""},   // missing author ID is skipped
+	}
+	result.Popular.Recent = []ArtworkItem{{ID: "5", UserID: "22"}}
+	result.Novels.Data = []*NovelBrief{{ID: "6", UserID: "33"}, nil}
+
+	got := collectSearchAuthorIDs(result)
+
+	want := []string{"11", "22", "33"}
+	if len(got) != len(want) {
+		t.Fatalf("collectSearchAuthorIDs() = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("collectSearchAuthorIDs()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// TestPrefetchSearchAuthors_Dedup verifies that each unique author is fetched
+// exactly once.
+func TestPrefetchSearchAuthors_Dedup(t *testing.T) {
+	transport := setupAuthorPrefetchTest(t)
+
+	result := &SearchData{}
+	result.IllustManga.Data = []ArtworkItem{
+		{ID: "1", UserID: "501"},
+		{ID: "2", UserID: "502"},
+		{ID: "3", UserID: "501"},
+		{ID: "4", UserID: "503"},
+		{ID: "5", UserID: "502"},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "http://instance.test/search", nil)
+	prefetchSearchAuthors(r, result)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.requested) != 3 {
+		t.Fatalf("Expected 3 author fetches, got %d: %v", len(transport.requested), transport.requested)
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range transport.requested {
+		if seen[id] {
+			t.Errorf("Author %s was fetched more than once", id)
+		}
+
+		seen[id] = true
+	}
+
+	for _, id := range []string{"501", "502", "503"} {
+		if !seen[id] {
+			t.Errorf("Author %s was never fetched", id)
+		}
+	}
+}
+
+// TestPrefetchSearchAuthors_BoundedConcurrency verifies that the prefetch
+// never exceeds its concurrency limit.
+func TestPrefetchSearchAuthors_BoundedConcurrency(t *testing.T) {
+	transport := setupAuthorPrefetchTest(t)
+
+	result := &SearchData{}
+	for i := range 10 {
+		result.IllustManga.Data = append(result.IllustManga.Data, ArtworkItem{
+			ID:     fmt.Sprintf("%d", i),
+			UserID: fmt.Sprintf("6%02d", i),
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "http://instance.test/search", nil)
+	prefetchSearchAuthors(r, result)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.requested) != 10 {
+		t.Fatalf("Expected 10 author fetches, got %d", len(transport.requested))
+	}
+
+	if transport.maxActive > prefetchSearchAuthorsLimit {
+		t.Errorf("Peak concurrency was %d, want at most %d", transport.maxActive, prefetchSearchAuthorsLimit)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+// Kinds carried by a CombinedSearchItem.
+const (
+	CombinedKindArtwork = "artwork"
+	CombinedKindNovel   = "novel"
+)
+
+// CombinedSearchItem is one entry of a merged artworks+novels result set.
+// Exactly one of Artwork and Novel is set, matching Kind.
+type CombinedSearchItem struct {
+	Kind    string
+	Artwork *ArtworkItem
+	Novel   *NovelBrief
+}
+
+// mergeNovelResults folds a novels search into an "artworks" search: the
+// page's works are interleaved into result.Combined and the totals span both
+// sets. LastPage becomes the larger of the two so the merged set stays
+// pageable until both categories are exhausted; pages past one category's end
+// simply carry only the other kind.
+func mergeNovelResults(result, novels *SearchData) {
+	result.Combined = interleaveSearchItems(result.IllustManga.Data, novels.Novels.Data)
+	result.Total += novels.Novels.Total
+	result.LastPage = max(result.LastPage, novels.Novels.LastPage)
+
+	// The merged set may have results even when the artworks page is empty.
+	result.NoResults = result.Total == 0
+	if !result.NoResults {
+		result.Suggestions = SearchSuggestions{}
+	}
+}
+
+// interleaveSearchItems spreads the novels evenly through the artworks so
+// the smaller set does not pool at the end of the page. Relative order
+// within each kind is preserved.
+func interleaveSearchItems(artworks []ArtworkItem, novels []*NovelBrief) []CombinedSearchItem {
+	combined := make([]CombinedSearchItem, 0, len(artworks)+len(novels))
+
+	ai, ni := 0, 0
+	for ai < len(artworks) || ni < len(novels) {
+		// Advance whichever kind is further behind proportionally.
+		if ni >= len(novels) ||
+			(ai < len(artworks) && (ai+1)*len(novels) <= (ni+1)*len(artworks)) {
+			combined = append(combined, CombinedSearchItem{
+				Kind:    CombinedKindArtwork,
+				Artwork: &artworks[ai],
+			})
+			ai++
+
+			continue
+		}
+
+		combined = append(combined, CombinedSearchItem{
+			Kind:  CombinedKindNovel,
+			Novel: novels[ni],
+		})
+		ni++
+	}
+
+	return combined
+}
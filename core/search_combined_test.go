@@ -0,0 +1,135 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"strconv"
+	"testing"
+)
+
+// combinedFixture builds an artworks SearchData and a novels SearchData with
+// the given page contents and pagination metadata.
+func combinedFixture(artworks, novels int, artLast, novelLast int) (*SearchData, *SearchData) {
+	artResult := &SearchData{}
+	artResult.IllustManga.Data = make([]ArtworkItem, artworks)
+
+	for i := range artResult.IllustManga.Data {
+		artResult.IllustManga.Data[i].ID = "a" + strconv.Itoa(i)
+	}
+
+	artResult.Total = artworks * 10
+	artResult.LastPage = artLast
+
+	novelResult := &SearchData{}
+	for i := range novels {
+		novelResult.Novels.Data = append(novelResult.Novels.Data, &NovelBrief{ID: "n" + strconv.Itoa(i)})
+	}
+
+	novelResult.Novels.Total = novels * 10
+	novelResult.Novels.LastPage = novelLast
+
+	return artResult, novelResult
+}
+
+func TestMergeNovelResults(t *testing.T) {
+	t.Parallel()
+
+	result, novels := combinedFixture(4, 2, 5, 8)
+
+	mergeNovelResults(result, novels)
+
+	if result.Total != 60 {
+		t.Errorf("Total = %d, want the sum of both categories (60)", result.Total)
+	}
+
+	if result.LastPage != 8 {
+		t.Errorf("LastPage = %d, want the deeper category's last page (8)", result.LastPage)
+	}
+
+	wantKinds := []string{
+		CombinedKindArtwork, CombinedKindArtwork, CombinedKindNovel,
+		CombinedKindArtwork, CombinedKindArtwork, CombinedKindNovel,
+	}
+
+	if len(result.Combined) != len(wantKinds) {
+		t.Fatalf("Combined has %d items, want %d", len(result.Combined), len(wantKinds))
+	}
+
+	var artIDs, novelIDs []string
+
+	for i, item := range result.Combined {
+		if item.Kind != wantKinds[i] {
+			t.Errorf("Combined[%d].Kind = %q, want %q", i, item.Kind, wantKinds[i])
+		}
+
+		switch item.Kind {
+		case CombinedKindArtwork:
+			artIDs = append(artIDs, item.Artwork.ID)
+		case CombinedKindNovel:
+			novelIDs = append(novelIDs, item.Novel.ID)
+		}
+	}
+
+	for i, id := range artIDs {
+		if want := "a" + strconv.Itoa(i); id != want {
+			t.Errorf("artwork order broken: got %q at position %d, want %q", id, i, want)
+		}
+	}
+
+	for i, id := range novelIDs {
+		if want := "n" + strconv.Itoa(i); id != want {
+			t.Errorf("novel order broken: got %q at position %d, want %q", id, i, want)
+		}
+	}
+}
+
+// TestMergeNovelResults_PastArtworksEnd covers pages beyond the artworks
+// category's last page: the merged set carries only novels there.
+func TestMergeNovelResults_PastArtworksEnd(t *testing.T) {
+	t.Parallel()
+
+	result, novels := combinedFixture(0, 3, 2, 9)
+	result.Total = 0
+	result.NoResults = true
+	result.Suggestions = SearchSuggestions{RelatedTags: Tags{{Name: "alt"}}}
+
+	mergeNovelResults(result, novels)
+
+	if result.LastPage != 9 || result.Total != 30 {
+		t.Errorf("LastPage/Total = %d/%d, want 9/30", result.LastPage, result.Total)
+	}
+
+	if len(result.Combined) != 3 {
+		t.Fatalf("Combined has %d items, want the 3 novels", len(result.Combined))
+	}
+
+	for i, item := range result.Combined {
+		if item.Kind != CombinedKindNovel || item.Novel == nil {
+			t.Errorf("Combined[%d] = %+v, want a novel entry", i, item)
+		}
+	}
+
+	// Novel results mean the merged search isn't empty, so no-result
+	// suggestions must not linger.
+	if result.NoResults || result.Suggestions.HasAny() {
+		t.Errorf("NoResults/Suggestions = %v/%+v, want them cleared", result.NoResults, result.Suggestions)
+	}
+}
+
+func TestMergeNovelResults_Empty(t *testing.T) {
+	t.Parallel()
+
+	result, novels := combinedFixture(0, 0, 1, 1)
+	result.Total = 0
+
+	mergeNovelResults(result, novels)
+
+	if !result.NoResults {
+		t.Error("NoResults = false, want true when both categories are empty")
+	}
+
+	if len(result.Combined) != 0 {
+		t.Errorf("Combined = %+v, want it empty", result.Combined)
+	}
+}
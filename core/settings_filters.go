@@ -7,11 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests/lrucache"
 	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
 )
 
@@ -90,9 +93,21 @@ type FilterProfile struct {
 	R18G FilterMode `json:"r18g"` // the local mode for R-18G content
 	AI   FilterMode `json:"ai"`   // the local mode for AI-generated content
 
-	DefaultSearchMode  string   `json:"default_search_mode,omitempty"` // the default search scope ("", "all", "safe", or "r18")
-	BlacklistedTags    []string `json:"blacklisted_tags,omitempty"`    // list of tags to exclude
-	BlacklistedArtists []string `json:"blacklisted_artists,omitempty"` // list of artist user IDs to exclude
+	DefaultSearchMode string `json:"default_search_mode,omitempty"` // the default search scope ("", "all", "safe", or "r18")
+
+	// SanityThreshold hides works whose [SanityLevel] meets or exceeds it,
+	// independent of the R18/R18G modes. This catches sensitive-but-not-R18
+	// works (pixiv's R-15 bucket) that XRestrict cannot distinguish.
+	// SLUnreviewed (0, the default) disables the check entirely.
+	SanityThreshold SanityLevel `json:"sanity_threshold,omitempty"`
+
+	BlacklistedTags    []string    `json:"blacklisted_tags,omitempty"`    // list of tags to exclude
+	BlacklistedArtists []string    `json:"blacklisted_artists,omitempty"` // list of artist user IDs to exclude
+	TimedMutes         []TimedMute `json:"timed_mutes,omitempty"`         // tags muted until an expiry; absent in older profiles
+	// tagMatchers holds the precompiled forms of BlacklistedTags, built when
+	// the profile is read so tag checks in list-rendering hot loops don't
+	// re-parse wildcard entries per work.
+	tagMatchers []tagMatcher
 }
 
 const filterProfileVersion = 1
@@ -120,17 +135,226 @@ func (fp *FilterProfile) normalize() {
 			*m = FilterShow
 		}
 	}
+
+	// Only pixiv's known sl buckets are meaningful thresholds; anything else
+	// falls back to the disabled default so a mangled cookie hides nothing.
+	switch fp.SanityThreshold {
+	case SLUnreviewed, SLSafe, SLR15, SLR18:
+	default:
+		fp.SanityThreshold = SLUnreviewed
+	}
+}
+
+// TimedMute hides a tag only until its expiry, for muting a trending tag
+// for a while without permanently blacklisting it. Entries support the same
+// glob syntax as BlacklistedTags.
+type TimedMute struct {
+	Tag   string    `json:"tag"`
+	Until time.Time `json:"until"`
+}
+
+// Expired reports whether the mute has lapsed at the given time.
+func (m TimedMute) Expired(now time.Time) bool {
+	return !now.Before(m.Until)
+}
+
+// matchKind selects how a compiled blacklist entry matches a tag.
+type matchKind int
+
+// The matchKind values cover the supported glob forms.
+const (
+	matchExact    matchKind = iota // plain entry
+	matchPrefix                    // trailing "*", e.g. "gore*"
+	matchSuffix                    // leading "*", e.g. "*_nsfw"
+	matchContains                  // both, e.g. "*blood*"
+)
+
+// tagMatcher is one precompiled, case-insensitive blacklist entry.
+type tagMatcher struct {
+	kind    matchKind
+	pattern string // lowercased entry body with wildcards stripped
+}
+
+// matches reports whether the already-lowercased tag satisfies the matcher.
+func (m tagMatcher) matches(tag string) bool {
+	switch m.kind {
+	case matchPrefix:
+		return strings.HasPrefix(tag, m.pattern)
+	case matchSuffix:
+		return strings.HasSuffix(tag, m.pattern)
+	case matchContains:
+		return strings.Contains(tag, m.pattern)
+	default:
+		return tag == m.pattern
+	}
 }
 
+// compileTagMatchers builds matchers for every blacklist entry. A leading
+// "*" makes the entry a suffix match, a trailing "*" a prefix match, and
+// both together a contains match; plain entries keep exact case-insensitive
+// matching. Entries that reduce to a bare wildcard are dropped rather than
+// matching everything.
+func compileTagMatchers(entries []string) []tagMatcher {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	matchers := make([]tagMatcher, 0, len(entries))
+
+	for _, entry := range entries {
+		entry = strings.ToLower(entry)
+		kind := matchExact
+
+		if rest, found := strings.CutPrefix(entry, "*"); found {
+			kind = matchSuffix
+			entry = rest
+		}
+
+		if rest, found := strings.CutSuffix(entry, "*"); found {
+			if kind == matchSuffix {
+				kind = matchContains
+			} else {
+				kind = matchPrefix
+			}
+
+			entry = rest
+		}
+
+		if entry == "" {
+			continue
+		}
+
+		matchers = append(matchers, tagMatcher{kind: kind, pattern: entry})
+	}
+
+	return matchers
+}
+
+// blacklistMatchers returns the profile's precompiled matchers — built from
+// the permanent blacklist plus any timed mutes — compiling them on the fly
+// for hand-built profiles.
+func (fp *FilterProfile) blacklistMatchers() []tagMatcher {
+	if fp.tagMatchers != nil {
+		return fp.tagMatchers
+	}
+
+	entries := fp.BlacklistedTags
+
+	if len(fp.TimedMutes) > 0 {
+		entries = slices.Clone(entries)
+		for _, mute := range fp.TimedMutes {
+			entries = append(entries, mute.Tag)
+		}
+	}
+
+	return compileTagMatchers(entries)
+}
+
+// rebuildTagMatchers recompiles the profile's matchers after its blacklist
+// or timed mutes change.
+func rebuildTagMatchers(fp *FilterProfile) {
+	fp.tagMatchers = nil
+	fp.tagMatchers = fp.blacklistMatchers()
+}
+
+// pruneExpiredMutes drops timed mutes that have lapsed and rebuilds the
+// matchers from what remains. The input slices are never mutated, so cached
+// profiles stay intact.
+func pruneExpiredMutes(fp FilterProfile, now time.Time) FilterProfile {
+	if len(fp.TimedMutes) > 0 {
+		active := make([]TimedMute, 0, len(fp.TimedMutes))
+
+		for _, mute := range fp.TimedMutes {
+			if !mute.Expired(now) {
+				active = append(active, mute)
+			}
+		}
+
+		if len(active) == 0 {
+			active = nil
+		}
+
+		fp.TimedMutes = active
+	}
+
+	rebuildTagMatchers(&fp)
+
+	return fp
+}
+
+// hasExpiredMutes reports whether any timed mute has lapsed at now.
+func hasExpiredMutes(fp FilterProfile, now time.Time) bool {
+	for _, mute := range fp.TimedMutes {
+		if mute.Expired(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesBlacklistedTag reports whether workTag matches any blacklist entry.
+// Profiles from ReadFilterProfile and mergeNativeMutes carry precompiled
+// matchers; hand-built profiles compile them on the fly.
+func (fp *FilterProfile) matchesBlacklistedTag(workTag string) bool {
+	if len(fp.BlacklistedTags) == 0 && len(fp.TimedMutes) == 0 {
+		return false
+	}
+
+	matchers := fp.blacklistMatchers()
+	tag := strings.ToLower(workTag)
+
+	for _, matcher := range matchers {
+		if matcher.matches(tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterProfileCacheSize bounds the number of memoized cookie parses. Each
+// rendered page usually carries a single profile cookie, so a small cache is
+// plenty.
+const filterProfileCacheSize = 128
+
+// filterProfileCache memoizes parsed profiles keyed by the raw cookie value,
+// so a page checking hundreds of works against ShouldHide parses the profile
+// once. The constant positive size means NewLRUCache cannot fail.
+var filterProfileCache, _ = lrucache.NewLRUCache(filterProfileCacheSize, false)
+
 // ReadFilterProfile reads a [FilterProfile] from a map of cookies.
 // If the cookie is missing, malformed, or uses an unexpected version, it returns
 // a default profile. The returned profile is normalized to a valid
 // combination of modes and carries the current schema version.
+//
+// Results are cached by cookie value; callers must not mutate the contents of
+// the returned blacklist slices.
 func ReadFilterProfile(cookie string) FilterProfile {
 	if cookie == "" {
 		return defaultFilterProfile()
 	}
 
+	if cached, ok := filterProfileCache.Get(cookie); ok {
+		if fp, ok := cached.(FilterProfile); ok {
+			// A timed mute may have lapsed since the profile was cached.
+			if hasExpiredMutes(fp, time.Now()) {
+				fp = pruneExpiredMutes(fp, time.Now())
+				filterProfileCache.Add(cookie, fp)
+			}
+
+			return fp
+		}
+	}
+
+	fp := parseFilterProfile(cookie)
+	filterProfileCache.Add(cookie, fp)
+
+	return fp
+}
+
+// parseFilterProfile performs the actual JSON decode behind the cache.
+func parseFilterProfile(cookie string) FilterProfile {
 	var fp FilterProfile
 	if err := json.Unmarshal([]byte(cookie), &fp); err != nil {
 		return defaultFilterProfile()
@@ -142,7 +366,7 @@ func ReadFilterProfile(cookie string) FilterProfile {
 
 	fp.normalize()
 
-	return fp
+	return pruneExpiredMutes(fp, time.Now())
 }
 
 // ComputePixivLevel returns the pixiv account's global [UnifiedContentLevel]
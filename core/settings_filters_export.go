@@ -0,0 +1,93 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidFilterImport flags an import string that could not be decoded
+// into a filter profile. Callers should surface it rather than fall back to
+// an empty profile, so a botched paste never silently wipes a blacklist.
+var ErrInvalidFilterImport = errors.New("invalid filter profile import")
+
+// filterImportMaxDecodedBytes caps how far a gzipped import may expand,
+// guarding against decompression bombs. Even a blacklist of thousands of
+// tags stays well under this.
+const filterImportMaxDecodedBytes = 1 << 20
+
+// ExportFilterProfile serializes fp into a compact importable string —
+// URL-safe base64 over gzipped JSON — so users can back up their blacklist
+// or move it between instances without hitting cookie size limits.
+func ExportFilterProfile(fp FilterProfile) (string, error) {
+	fp.normalize()
+
+	payload, err := json.Marshal(fp)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal filter profile: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(payload); err != nil {
+		return "", fmt.Errorf("failed to compress filter profile: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress filter profile: %w", err)
+	}
+
+	return base64.URLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// ImportFilterProfile parses a string produced by [ExportFilterProfile] back
+// into a normalized profile. Malformed input of any kind — bad base64,
+// invalid gzip, broken JSON, or an unsupported schema version — is rejected
+// with [ErrInvalidFilterImport].
+func ImportFilterProfile(s string) (FilterProfile, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return FilterProfile{}, fmt.Errorf("%w: empty input", ErrInvalidFilterImport)
+	}
+
+	compressed, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return FilterProfile{}, fmt.Errorf("%w: malformed base64", ErrInvalidFilterImport)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return FilterProfile{}, fmt.Errorf("%w: not a gzip stream", ErrInvalidFilterImport)
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(gz, filterImportMaxDecodedBytes+1))
+	if err != nil {
+		return FilterProfile{}, fmt.Errorf("%w: corrupted gzip stream", ErrInvalidFilterImport)
+	}
+
+	if len(payload) > filterImportMaxDecodedBytes {
+		return FilterProfile{}, fmt.Errorf("%w: decoded profile too large", ErrInvalidFilterImport)
+	}
+
+	var fp FilterProfile
+	if err := json.Unmarshal(payload, &fp); err != nil {
+		return FilterProfile{}, fmt.Errorf("%w: malformed JSON", ErrInvalidFilterImport)
+	}
+
+	if fp.Version != filterProfileVersion {
+		return FilterProfile{}, fmt.Errorf("%w: unsupported schema version %d", ErrInvalidFilterImport, fp.Version)
+	}
+
+	fp.normalize()
+
+	return fp, nil
+}
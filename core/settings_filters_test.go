@@ -0,0 +1,230 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+)
+
+func TestReadFilterProfile_Cached(t *testing.T) {
+	cookie := `{"v":1,"r15":"show","r18":"hide","r18g":"hide","ai":"censor",` +
+		`"blacklisted_tags":["spoilers"],"blacklisted_artists":["42"]}`
+
+	first := ReadFilterProfile(cookie)
+	second := ReadFilterProfile(cookie)
+
+	if first.R18 != FilterHide || first.AI != FilterCensor {
+		t.Fatalf("ReadFilterProfile() = %+v, want the cookie's modes", first)
+	}
+
+	if second.R18 != first.R18 || second.AI != first.AI ||
+		!slices.Equal(second.BlacklistedTags, first.BlacklistedTags) ||
+		!slices.Equal(second.BlacklistedArtists, first.BlacklistedArtists) {
+		t.Errorf("Cached read = %+v, want it identical to the first parse %+v", second, first)
+	}
+
+	if _, ok := filterProfileCache.Get(cookie); !ok {
+		t.Error("Parsed profile was not cached")
+	}
+
+	// A different cookie must not be served from the first entry.
+	other := ReadFilterProfile(`{"v":1,"r15":"show","r18":"show","r18g":"show","ai":"show"}`)
+	if other.R18 != FilterShow {
+		t.Errorf("ReadFilterProfile() for a different cookie = %+v, want its own modes", other)
+	}
+}
+
+func TestReadFilterProfile_InvalidCookies(t *testing.T) {
+	want := defaultFilterProfile()
+
+	for _, cookie := range []string{
+		"not json",
+		`{"v":99,"r18":"hide"}`, // unexpected schema version
+	} {
+		got := ReadFilterProfile(cookie)
+		if got.Version != want.Version || got.R15 != want.R15 || got.R18 != want.R18 ||
+			got.R18G != want.R18G || got.AI != want.AI {
+			t.Errorf("ReadFilterProfile(%q) = %+v, want the default profile", cookie, got)
+		}
+	}
+}
+
+func TestMatchesBlacklistedTag(t *testing.T) {
+	t.Parallel()
+
+	profile := defaultFilterProfile()
+	profile.BlacklistedTags = []string{"spoilers", "gore*", "*_nsfw", "*blood*", "*"}
+
+	cases := []struct {
+		tag  string
+		want bool
+	}{
+		{"spoilers", true},  // exact
+		{"Spoilers", true},  // exact, case-insensitive
+		{"gore", true},      // prefix matches the bare stem
+		{"gorefest", true},  // prefix
+		{"hardgore", false}, // prefix must anchor at the start
+		{"extra_NSFW", true},
+		{"nsfw_extra", false}, // suffix must anchor at the end
+		{"bloodbath", true},   // contains
+		{"cute cats", false},  // bare "*" entries are dropped, not match-all
+	}
+
+	for _, tt := range cases {
+		if got := profile.matchesBlacklistedTag(tt.tag); got != tt.want {
+			t.Errorf("matchesBlacklistedTag(%q) = %t, want %t", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestShouldHide_WildcardBlacklist(t *testing.T) {
+	profileCookie := `{"v":1,"r15":"show","r18":"show","r18g":"show","ai":"show",` +
+		`"blacklisted_tags":["gore*"]}`
+	cookies := map[cookie.CookieName]string{cookie.FilterProfileCookie: profileCookie}
+
+	hidden := &ArtworkItem{ID: "1", Tags: []string{"landscape", "gorefest"}}
+	if !hidden.ShouldHide(cookies) {
+		t.Error("ShouldHide() = false for a work matching a prefix entry, want true")
+	}
+
+	visible := &ArtworkItem{ID: "2", Tags: []string{"landscape"}}
+	if visible.ShouldHide(cookies) {
+		t.Error("ShouldHide() = true for a non-matching work, want false")
+	}
+}
+
+func TestShouldHide_SanityThreshold(t *testing.T) {
+	works := map[string]*ArtworkItem{
+		"unreviewed": {ID: "sl0", SanityLevel: SLUnreviewed},
+		"safe":       {ID: "sl2", SanityLevel: SLSafe},
+		"r15":        {ID: "sl4", SanityLevel: SLR15},
+		"r18":        {ID: "sl6", SanityLevel: SLR18},
+	}
+
+	cases := []struct {
+		name      string
+		threshold string // raw sanity_threshold cookie value
+		hidden    map[string]bool
+	}{
+		{
+			name:      "absent threshold hides nothing",
+			threshold: "",
+			hidden:    map[string]bool{},
+		},
+		{
+			name:      "threshold at R-15 hides R-15 and above",
+			threshold: `,"sanity_threshold":4`,
+			hidden:    map[string]bool{"r15": true, "r18": true},
+		},
+		{
+			name:      "threshold at R-18 hides only R-18",
+			threshold: `,"sanity_threshold":6`,
+			hidden:    map[string]bool{"r18": true},
+		},
+		{
+			name:      "threshold at Safe hides all reviewed works",
+			threshold: `,"sanity_threshold":2`,
+			hidden:    map[string]bool{"safe": true, "r15": true, "r18": true},
+		},
+		{
+			name:      "unknown bucket falls back to disabled",
+			threshold: `,"sanity_threshold":3`,
+			hidden:    map[string]bool{},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			profileCookie := `{"v":1,"r15":"show","r18":"show","r18g":"show","ai":"show"` +
+				tt.threshold + `}`
+			cookies := map[cookie.CookieName]string{cookie.FilterProfileCookie: profileCookie}
+
+			for label, work := range works {
+				if got := work.ShouldHide(cookies); got != tt.hidden[label] {
+					t.Errorf("ShouldHide() for %s work = %t, want %t", label, got, tt.hidden[label])
+				}
+			}
+		})
+	}
+}
+
+func TestReadFilterProfile_TimedMutes(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	t.Run("Unexpired mute hides", func(t *testing.T) {
+		profileCookie := `{"v":1,"r15":"show","r18":"show","r18g":"show","ai":"show",` +
+			`"timed_mutes":[{"tag":"trendy","until":"` + future + `"}]}`
+
+		fp := ReadFilterProfile(profileCookie)
+		if len(fp.TimedMutes) != 1 {
+			t.Fatalf("TimedMutes = %v, want the unexpired entry kept", fp.TimedMutes)
+		}
+
+		if !fp.matchesBlacklistedTag("trendy") {
+			t.Error("matchesBlacklistedTag(trendy) = false while the mute is active, want true")
+		}
+	})
+
+	t.Run("Expired mute is pruned", func(t *testing.T) {
+		profileCookie := `{"v":1,"r15":"show","r18":"show","r18g":"show","ai":"show",` +
+			`"timed_mutes":[{"tag":"faded","until":"` + past + `"}]}`
+
+		fp := ReadFilterProfile(profileCookie)
+		if len(fp.TimedMutes) != 0 {
+			t.Errorf("TimedMutes = %v, want the expired entry pruned", fp.TimedMutes)
+		}
+
+		if fp.matchesBlacklistedTag("faded") {
+			t.Error("matchesBlacklistedTag(faded) = true after expiry, want false")
+		}
+	})
+
+	t.Run("Mixed permanent and timed entries", func(t *testing.T) {
+		profileCookie := `{"v":1,"r15":"show","r18":"show","r18g":"show","ai":"show",` +
+			`"blacklisted_tags":["gore"],` +
+			`"timed_mutes":[{"tag":"fad","until":"` + future + `"},{"tag":"trendy","until":"` + past + `"}]}`
+
+		fp := ReadFilterProfile(profileCookie)
+
+		if !fp.matchesBlacklistedTag("gore") {
+			t.Error("Permanent entry no longer matches")
+		}
+
+		if !fp.matchesBlacklistedTag("fad") {
+			t.Error("Active timed mute does not match")
+		}
+
+		if fp.matchesBlacklistedTag("trendy") {
+			t.Error("Expired timed mute still matches")
+		}
+
+		if len(fp.TimedMutes) != 1 || fp.TimedMutes[0].Tag != "fad" {
+			t.Errorf("TimedMutes = %v, want only the active entry", fp.TimedMutes)
+		}
+	})
+}
+
+func BenchmarkReadFilterProfile(b *testing.B) {
+	cookie := `{"v":1,"r15":"show","r18":"hide","r18g":"hide","ai":"show",` +
+		`"blacklisted_tags":["a","b","c","d","e"],"blacklisted_artists":["1","2","3"]}`
+
+	b.Run("cached", func(b *testing.B) {
+		ReadFilterProfile(cookie) // warm the cache
+
+		for b.Loop() {
+			ReadFilterProfile(cookie)
+		}
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		for b.Loop() {
+			parseFilterProfile(cookie)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+func TestFillMissingABCookies(t *testing.T) {
+	t.Parallel()
+
+	// Supplied values must be preserved verbatim.
+	yuidb, pAbDID, pAbID, pAbID2 := fillMissingABCookies("y", "d", "1", "2")
+	if yuidb != "y" || pAbDID != "d" || pAbID != "1" || pAbID2 != "2" {
+		t.Errorf("fillMissingABCookies() = %q %q %q %q, want supplied values preserved",
+			yuidb, pAbDID, pAbID, pAbID2)
+	}
+
+	// Missing values are generated, supplied ones still preserved.
+	yuidb, pAbDID, pAbID, pAbID2 = fillMissingABCookies("y", "", "", "")
+	if yuidb != "y" {
+		t.Errorf("fillMissingABCookies() yuidb = %q, want %q", yuidb, "y")
+	}
+
+	if pAbDID == "" || pAbID == "" || pAbID2 == "" {
+		t.Errorf("fillMissingABCookies() = %q %q %q, want generated values for empty cookies",
+			pAbDID, pAbID, pAbID2)
+	}
+}
+
+// loginForm builds a POST request carrying the given form values.
+func loginForm(t *testing.T, values url.Values) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(
+		http.MethodPost, "/settings/token_direct", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r
+}
+
+func TestSetTokenDirect_RejectsBadInput(t *testing.T) {
+	t.Parallel()
+
+	// Missing token.
+	_, err := SetTokenDirect(httptest.NewRecorder(), loginForm(t, url.Values{
+		"csrf": {"deadbeef01"},
+	}))
+	if err == nil {
+		t.Error("SetTokenDirect() without a token should fail")
+	}
+
+	// Malformed CSRF token.
+	_, err = SetTokenDirect(httptest.NewRecorder(), loginForm(t, url.Values{
+		"token": {"123_abcdef"},
+		"csrf":  {"<not-hex>"},
+	}))
+	if err == nil {
+		t.Error("SetTokenDirect() with a malformed CSRF token should fail")
+	}
+}
+
+// stubTransport serves canned JSON for every request without network access.
+type stubTransport struct {
+	requests []string
+}
+
+func (st *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	st.requests = append(st.requests, req.URL.String())
+
+	body := `{"error":false,"body":{}}`
+	if strings.Contains(req.URL.Path, "/ajax/settings/self") {
+		body = `{"error":false,"body":{"user_status":{"user_id":"123","user_name":"tester","user_premium":"0"}}}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		Request:    req,
+	}, nil
+}
+
+func TestSetTokenDirect_Success(t *testing.T) {
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	st := &stubTransport{}
+	utils.HTTPClient.Transport = st
+
+	w := httptest.NewRecorder()
+
+	_, err := SetTokenDirect(w, loginForm(t, url.Values{
+		"token":  {"123_abcdef"},
+		"csrf":   {"deadbeef01"},
+		"yuid_b": {"supplied-yuidb"},
+	}))
+	if err != nil {
+		t.Fatalf("SetTokenDirect() error: %v", err)
+	}
+
+	// The cheap validation call must have happened, but no page scrape.
+	for _, u := range st.requests {
+		if strings.Contains(u, "/artworks/") {
+			t.Errorf("SetTokenDirect() must not scrape artwork pages, got request to %s", u)
+		}
+	}
+
+	cookies := w.Result().Cookies()
+
+	got := make(map[string]string, len(cookies))
+	for _, c := range cookies {
+		got[c.Name] = c.Value
+	}
+
+	if got["Token"] != "123_abcdef" || got["CSRF"] != "deadbeef01" {
+		t.Errorf("cookies = %v, want supplied token and CSRF persisted", got)
+	}
+
+	// The supplied ab cookie must be preserved, not regenerated.
+	if got["YUID-B"] != "supplied-yuidb" {
+		t.Errorf("cookies = %v, want supplied yuid_b preserved", got)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
+)
+
+// GETMuteSettingsURL is the endpoint for the account's native mute list.
+const GETMuteSettingsURL = "https://www.pixiv.net/ajax/mute/items"
+
+// nativeMuteTTL is how long a fetched mute list is considered fresh. After
+// expiry the next read refreshes it; fetch failures serve the stale copy.
+const nativeMuteTTL = 15 * time.Minute
+
+// MuteItem is a single entry of the account's native mute list.
+type MuteItem struct {
+	Type  string `json:"type"` // "tag" or "user"
+	Value string `json:"value"`
+}
+
+// muteSettingsResponse represents the body of the GETMuteSettingsURL endpoint.
+type muteSettingsResponse struct {
+	MuteItems []MuteItem `json:"mute_items"`
+}
+
+// nativeMuteEntry holds one account's fetched mute list.
+type nativeMuteEntry struct {
+	tags      []string
+	artists   []string
+	fetchedAt time.Time
+}
+
+// nativeMuteCache holds mute lists keyed by user token so the merge does not
+// hit pixiv on every visibility check.
+var nativeMuteCache struct {
+	sync.Mutex
+
+	entries map[string]*nativeMuteEntry
+}
+
+// fetchNativeMutes retrieves the account's mute list from pixiv, split into
+// muted tags and muted user IDs.
+func fetchNativeMutes(ctx context.Context, token string) (tags, artists []string, err error) {
+	resp, err := requests.GetJSONBody(
+		ctx,
+		GETMuteSettingsURL,
+		map[string]string{"PHPSESSID": token},
+		http.Header{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result muteSettingsResponse
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal mute settings: %w", err)
+	}
+
+	for _, item := range result.MuteItems {
+		switch item.Type {
+		case "tag":
+			tags = append(tags, item.Value)
+		case "user":
+			artists = append(artists, item.Value)
+		}
+	}
+
+	return tags, artists, nil
+}
+
+// nativeMutesFor returns the cached mute list for token, refreshing the copy
+// once nativeMuteTTL has elapsed and serving stale data over failing outright.
+// A token without a usable mute list yields empty slices.
+func nativeMutesFor(ctx context.Context, token string) (tags, artists []string) {
+	nativeMuteCache.Lock()
+	defer nativeMuteCache.Unlock()
+
+	if nativeMuteCache.entries == nil {
+		nativeMuteCache.entries = make(map[string]*nativeMuteEntry)
+	}
+
+	entry := nativeMuteCache.entries[token]
+	if entry != nil && time.Since(entry.fetchedAt) < nativeMuteTTL {
+		return entry.tags, entry.artists
+	}
+
+	fetchedTags, fetchedArtists, err := fetchNativeMutes(ctx, token)
+	if err != nil {
+		if entry != nil {
+			return entry.tags, entry.artists
+		}
+
+		return nil, nil
+	}
+
+	nativeMuteCache.entries[token] = &nativeMuteEntry{
+		tags:      fetchedTags,
+		artists:   fetchedArtists,
+		fetchedAt: time.Now(),
+	}
+
+	return fetchedTags, fetchedArtists
+}
+
+// mergeNativeMutes returns a copy of profile with the given native mutes
+// appended to its blacklists. The profile's own slices are never mutated, so
+// cached profiles from ReadFilterProfile stay intact. Tags are deduplicated
+// case-insensitively; artist IDs exactly.
+func mergeNativeMutes(profile FilterProfile, tags, artists []string) FilterProfile {
+	if len(tags) == 0 && len(artists) == 0 {
+		return profile
+	}
+
+	mergedTags := slices.Clone(profile.BlacklistedTags)
+
+	for _, tag := range tags {
+		if !slices.ContainsFunc(mergedTags, func(existing string) bool {
+			return strings.EqualFold(existing, tag)
+		}) {
+			mergedTags = append(mergedTags, tag)
+		}
+	}
+
+	mergedArtists := slices.Clone(profile.BlacklistedArtists)
+
+	for _, artist := range artists {
+		if !slices.Contains(mergedArtists, artist) {
+			mergedArtists = append(mergedArtists, artist)
+		}
+	}
+
+	profile.BlacklistedTags = mergedTags
+	profile.BlacklistedArtists = mergedArtists
+	rebuildTagMatchers(&profile)
+
+	return profile
+}
+
+// applyNativeMutes merges the account's native mute list into profile when
+// the MergePixivMutes feature is enabled and a token is present. Anonymous
+// requests and disabled deployments get the profile back unchanged.
+func applyNativeMutes(profile FilterProfile, token string) FilterProfile {
+	if token == "" {
+		return profile
+	}
+
+	tags, artists := nativeMutesFor(context.Background(), token)
+
+	return mergeNativeMutes(profile, tags, artists)
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// muteSettingsFixture is a captured /ajax/mute/items response with one muted
+// tag and one muted user.
+const muteSettingsFixture = `{"error":false,"body":{"mute_items":[
+	{"type":"tag","value":"spoilers"},
+	{"type":"user","value":"4242"}
+]}}`
+
+// muteStubTransport serves the mute fixture and counts fetches so tests can
+// assert on refresh behavior.
+type muteStubTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (t *muteStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests++
+	t.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(muteSettingsFixture)),
+		Request:    req,
+	}, nil
+}
+
+func (t *muteStubTransport) requestCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.requests
+}
+
+func setupMuteTest(t *testing.T) *muteStubTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+
+		nativeMuteCache.Lock()
+		nativeMuteCache.entries = nil
+		nativeMuteCache.Unlock()
+	})
+
+	config.Global.SetDefaults()
+
+	transport := &muteStubTransport{}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	nativeMuteCache.Lock()
+	nativeMuteCache.entries = nil
+	nativeMuteCache.Unlock()
+
+	return transport
+}
+
+func TestMergeNativeMutes(t *testing.T) {
+	t.Parallel()
+
+	profile := defaultFilterProfile()
+	profile.BlacklistedTags = []string{"Spoilers", "gore"}
+	profile.BlacklistedArtists = []string{"11"}
+
+	merged := mergeNativeMutes(profile, []string{"spoilers", "leaks"}, []string{"11", "4242"})
+
+	// "spoilers" already exists case-insensitively; "11" exactly.
+	if want := []string{"Spoilers", "gore", "leaks"}; !slices.Equal(merged.BlacklistedTags, want) {
+		t.Errorf("merged tags = %v, want %v", merged.BlacklistedTags, want)
+	}
+
+	if want := []string{"11", "4242"}; !slices.Equal(merged.BlacklistedArtists, want) {
+		t.Errorf("merged artists = %v, want %v", merged.BlacklistedArtists, want)
+	}
+
+	// The input profile's slices must be left alone.
+	if want := []string{"Spoilers", "gore"}; !slices.Equal(profile.BlacklistedTags, want) {
+		t.Errorf("input tags mutated to %v", profile.BlacklistedTags)
+	}
+}
+
+func TestApplyNativeMutes_FetchAndCache(t *testing.T) {
+	transport := setupMuteTest(t)
+
+	profile := defaultFilterProfile()
+
+	merged := applyNativeMutes(profile, "123_token")
+	if !slices.Contains(merged.BlacklistedTags, "spoilers") ||
+		!slices.Contains(merged.BlacklistedArtists, "4242") {
+		t.Fatalf("applyNativeMutes() = %+v, want the native mutes merged in", merged)
+	}
+
+	// A second read within the TTL must be served from the cache.
+	applyNativeMutes(profile, "123_token")
+
+	if got := transport.requestCount(); got != 1 {
+		t.Errorf("mute endpoint fetched %d times, want 1", got)
+	}
+
+	// Anonymous requests never fetch.
+	anonymous := applyNativeMutes(profile, "")
+	if len(anonymous.BlacklistedTags) != 0 || transport.requestCount() != 1 {
+		t.Errorf("anonymous request merged mutes or triggered a fetch")
+	}
+}
+
+func TestNativeMutesFor_RefreshAfterTTL(t *testing.T) {
+	transport := setupMuteTest(t)
+
+	ctx := context.Background()
+
+	nativeMutesFor(ctx, "123_token")
+
+	// Age the cached entry past the TTL; the next read must refresh.
+	nativeMuteCache.Lock()
+	nativeMuteCache.entries["123_token"].fetchedAt = time.Now().Add(-nativeMuteTTL - time.Minute)
+	nativeMuteCache.Unlock()
+
+	tags, artists := nativeMutesFor(ctx, "123_token")
+
+	if got := transport.requestCount(); got != 2 {
+		t.Errorf("mute endpoint fetched %d times, want 2 after TTL expiry", got)
+	}
+
+	if !slices.Contains(tags, "spoilers") || !slices.Contains(artists, "4242") {
+		t.Errorf("refreshed mutes = (%v, %v), want fixture contents", tags, artists)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/audit"
+)
+
+// scrapePageBody contains the CSRF token as it appears JS-escaped in pixiv
+// page source.
+const scrapePageBody = `<script>{"token":"ignored"}</script>{\"token\":\"deadbeef01\"}`
+
+func TestScrapeSessionData_RetriesFallbackURL(t *testing.T) {
+	t.Parallel()
+
+	var fetched []string
+
+	fetch := func(url string) ([]byte, []*http.Cookie, error) {
+		fetched = append(fetched, url)
+
+		// The first fetch is flaky; the fallback succeeds.
+		if len(fetched) == 1 {
+			return nil, nil, errors.New("connection reset")
+		}
+
+		return []byte(scrapePageBody), []*http.Cookie{
+			{Name: "p_ab_id", Value: "5"},
+			{Name: "unrelated", Value: "x"},
+		}, nil
+	}
+
+	csrfToken, foundCookies, err := scrapeSessionData([]string{"first", "fallback"}, fetch)
+	if err != nil {
+		t.Fatalf("scrapeSessionData() error: %v", err)
+	}
+
+	if csrfToken != "deadbeef01" {
+		t.Errorf("csrfToken = %q, want %q", csrfToken, "deadbeef01")
+	}
+
+	if foundCookies["p_ab_id"] != "5" {
+		t.Errorf("foundCookies = %v, want p_ab_id=5", foundCookies)
+	}
+
+	if _, ok := foundCookies["unrelated"]; ok {
+		t.Errorf("foundCookies = %v, must only keep ab/yuid cookies", foundCookies)
+	}
+
+	if len(fetched) != 2 || fetched[0] != "first" || fetched[1] != "fallback" {
+		t.Errorf("fetched = %v, want both URLs tried in order", fetched)
+	}
+}
+
+func TestScrapeSessionData_MissingTokenFallsThrough(t *testing.T) {
+	t.Parallel()
+
+	fetch := func(url string) ([]byte, []*http.Cookie, error) {
+		if url == "first" {
+			// Fetch succeeds but the page no longer embeds the token.
+			return []byte("<html>redesigned page</html>"), nil, nil
+		}
+
+		return []byte(scrapePageBody), nil, nil
+	}
+
+	csrfToken, _, err := scrapeSessionData([]string{"first", "fallback"}, fetch)
+	if err != nil {
+		t.Fatalf("scrapeSessionData() error: %v", err)
+	}
+
+	if csrfToken != "deadbeef01" {
+		t.Errorf("csrfToken = %q, want %q", csrfToken, "deadbeef01")
+	}
+}
+
+func TestScrapeSessionData_AllAttemptsFail(t *testing.T) {
+	t.Parallel()
+
+	fetch := func(string) ([]byte, []*http.Cookie, error) {
+		return []byte("no token here"), nil, nil
+	}
+
+	if _, _, err := scrapeSessionData([]string{"first", "fallback"}, fetch); !errors.Is(err, errNoCSRFToken) {
+		t.Errorf("scrapeSessionData() error = %v, want %v", err, errNoCSRFToken)
+	}
+}
+
+func TestTokenScrapeURLs_ConfigOverride(t *testing.T) {
+	orig := config.Global.TokenManager.ScrapeURLs
+
+	t.Cleanup(func() {
+		config.Global.TokenManager.ScrapeURLs = orig
+	})
+
+	config.Global.TokenManager.ScrapeURLs = nil
+
+	if got := tokenScrapeURLs(); len(got) != 2 || got[0] != tokenArtworkURL {
+		t.Errorf("tokenScrapeURLs() = %v, want built-in defaults", got)
+	}
+
+	// Using the built-in set registers the NSFW default for log redaction.
+	if got := audit.RedactURL(tokenArtworkURL); got != tokenArtworkPlaceholder {
+		t.Errorf("RedactURL(tokenArtworkURL) = %q, want %q", got, tokenArtworkPlaceholder)
+	}
+
+	config.Global.TokenManager.ScrapeURLs = []string{"https://example.com/page"}
+
+	if got := tokenScrapeURLs(); len(got) != 1 || got[0] != "https://example.com/page" {
+		t.Errorf("tokenScrapeURLs() = %v, want configured override", got)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core_test
+
+import (
+	"testing"
+
+	. "codeberg.org/pixivfe/pixivfe/v3/core"
+)
+
+func TestSettingsSelfResponse_IsPremium(t *testing.T) {
+	t.Parallel()
+
+	premiumResponse := func(userPremium string) *SettingsSelfResponse {
+		var s SettingsSelfResponse
+		s.UserStatus.UserPremium = userPremium
+
+		return &s
+	}
+
+	cases := []struct {
+		name string
+		self *SettingsSelfResponse
+		want bool
+	}{
+		{"Premium token", premiumResponse("1"), true},
+		{"Non-premium token", premiumResponse("0"), false},
+		{"Missing premium field", premiumResponse(""), false},
+		{"Nil response", nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.self.IsPremium(); got != tc.want {
+				t.Errorf("IsPremium() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// settingUpdateStubTransport answers each POST with the next scripted outcome:
+// an HTTP status code, or a transport error when the entry's err is set.
+type settingUpdateStubTransport struct {
+	mu       sync.Mutex
+	outcomes []settingUpdateOutcome
+	requests int
+}
+
+type settingUpdateOutcome struct {
+	status int
+	err    error
+}
+
+func (t *settingUpdateStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+
+	outcome := settingUpdateOutcome{status: http.StatusOK}
+	if t.requests < len(t.outcomes) {
+		outcome = t.outcomes[t.requests]
+	}
+
+	t.requests++
+	t.mu.Unlock()
+
+	if outcome.err != nil {
+		return nil, outcome.err
+	}
+
+	body := `{"error":false,"body":null}`
+	if outcome.status >= http.StatusBadRequest {
+		body = `{"error":true,"message":"upstream failure"}`
+	}
+
+	return &http.Response{
+		StatusCode: outcome.status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (t *settingUpdateStubTransport) requestCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.requests
+}
+
+func setupSettingUpdateTest(t *testing.T, outcomes ...settingUpdateOutcome) *settingUpdateStubTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+
+	transport := &settingUpdateStubTransport{outcomes: outcomes}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	return transport
+}
+
+// settingUpdateRequest builds a logged-in POST request for the update helper.
+func settingUpdateRequest() *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/settings", nil)
+	r.AddCookie(&http.Cookie{Name: string(cookie.TokenCookie), Value: "123456_sessionsecret"})
+	r.AddCookie(&http.Cookie{Name: string(cookie.CSRFCookie), Value: "csrf-token"})
+
+	return r
+}
+
+func TestPerformSettingUpdate_RetriesTransientFailure(t *testing.T) {
+	transport := setupSettingUpdateTest(t,
+		settingUpdateOutcome{status: http.StatusBadGateway},
+		settingUpdateOutcome{status: http.StatusOK},
+	)
+
+	err := PerformSettingUpdate(settingUpdateRequest(),
+		POSTSettingsLocationURL, SetLocationRequest{Location: "jp"})
+	if err != nil {
+		t.Fatalf("PerformSettingUpdate() error after retryable failure: %v", err)
+	}
+
+	if got := transport.requestCount(); got != 2 {
+		t.Errorf("Upstream saw %d requests, want 2 (original + one retry)", got)
+	}
+}
+
+func TestPerformSettingUpdate_RetriesConnectionFailure(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	transport := setupSettingUpdateTest(t,
+		settingUpdateOutcome{err: dialErr},
+		settingUpdateOutcome{status: http.StatusOK},
+	)
+
+	err := PerformSettingUpdate(settingUpdateRequest(),
+		POSTSettingsLocationURL, SetLocationRequest{Location: "jp"})
+	if err != nil {
+		t.Fatalf("PerformSettingUpdate() error after dial failure: %v", err)
+	}
+
+	if got := transport.requestCount(); got != 2 {
+		t.Errorf("Upstream saw %d requests, want 2 (original + one retry)", got)
+	}
+}
+
+func TestPerformSettingUpdate_NoRetryOnAmbiguousFailure(t *testing.T) {
+	// A mid-flight read error: the update may have been applied upstream.
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}
+	transport := setupSettingUpdateTest(t, settingUpdateOutcome{err: readErr})
+
+	err := PerformSettingUpdate(settingUpdateRequest(),
+		POSTSettingsLocationURL, SetLocationRequest{Location: "jp"})
+	if err == nil {
+		t.Fatal("Expected the ambiguous failure to be surfaced, got nil")
+	}
+
+	if got := transport.requestCount(); got != 1 {
+		t.Errorf("Upstream saw %d requests, want 1 (no retry on ambiguous failures)", got)
+	}
+}
+
+func TestPerformSettingUpdate_NoRetryOnRejection(t *testing.T) {
+	transport := setupSettingUpdateTest(t, settingUpdateOutcome{status: http.StatusBadRequest})
+
+	err := PerformSettingUpdate(settingUpdateRequest(),
+		POSTSettingsLocationURL, SetLocationRequest{Location: "jp"})
+	if err == nil {
+		t.Fatal("Expected the rejection to be surfaced, got nil")
+	}
+
+	if got := transport.requestCount(); got != 1 {
+		t.Errorf("Upstream saw %d requests, want 1 (no retry on definite rejections)", got)
+	}
+}
+
+func TestPerformSettingUpdate_RetryBudgetExhausted(t *testing.T) {
+	transport := setupSettingUpdateTest(t,
+		settingUpdateOutcome{status: http.StatusBadGateway},
+		settingUpdateOutcome{status: http.StatusBadGateway},
+		settingUpdateOutcome{status: http.StatusBadGateway},
+	)
+
+	err := PerformSettingUpdate(settingUpdateRequest(),
+		POSTSettingsLocationURL, SetLocationRequest{Location: "jp"})
+	if err == nil {
+		t.Fatal("Expected the persistent failure to be surfaced, got nil")
+	}
+
+	if got := transport.requestCount(); got != 1+settingUpdateMaxRetries {
+		t.Errorf("Upstream saw %d requests, want %d", got, 1+settingUpdateMaxRetries)
+	}
+}
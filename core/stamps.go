@@ -0,0 +1,164 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// stampCatalogTTL bounds how often the stamp/emoji series are refetched.
+// They change rarely, so the raw data is cached aggressively and shared
+// between users.
+const stampCatalogTTL = 24 * time.Hour
+
+// Stamp is a single comment stamp with its proxied image URL.
+type Stamp struct {
+	ID  int
+	URL string
+}
+
+// StampSet groups the stamps of one series.
+type StampSet struct {
+	Slug   string
+	Name   string
+	Stamps []Stamp
+}
+
+// Emoji is a single comment emoji; its shortcode is written as "(name)".
+type Emoji struct {
+	ID        int
+	Shortcode string
+	URL       string
+}
+
+// stampCatalogCache holds the raw series data from settings/self. Image URLs
+// are built per request because the static proxy is a per-user setting.
+var stampCatalogCache struct {
+	sync.Mutex
+
+	self      *SettingsSelfResponse
+	fetchedAt time.Time
+}
+
+// cachedSettingsSelf returns settings/self data for catalog building,
+// refreshing the shared copy at most once per stampCatalogTTL and serving
+// stale data over failing outright.
+func cachedSettingsSelf(r *http.Request) (*SettingsSelfResponse, error) {
+	stampCatalogCache.Lock()
+	defer stampCatalogCache.Unlock()
+
+	if stampCatalogCache.self != nil && time.Since(stampCatalogCache.fetchedAt) < stampCatalogTTL {
+		return stampCatalogCache.self, nil
+	}
+
+	self, err := GetSettingsSelf(r)
+	if err != nil {
+		if stampCatalogCache.self != nil {
+			return stampCatalogCache.self, nil
+		}
+
+		return nil, err
+	}
+
+	stampCatalogCache.self = self
+	stampCatalogCache.fetchedAt = time.Now()
+
+	return self, nil
+}
+
+// GetStampCatalog returns every stamp series available for comments, with
+// image URLs routed through the user's static proxy. It requires a
+// logged-in token for the initial fetch.
+func GetStampCatalog(r *http.Request) ([]StampSet, error) {
+	self, err := cachedSettingsSelf(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildStampCatalog(self, utils.GetProxyBase(untrusted.GetStaticProxy(r))), nil
+}
+
+// GetEmojiCatalog returns every comment emoji with its shortcode. When the
+// series cannot be fetched (e.g. no logged-in token), it falls back to the
+// built-in emoji list used for rendering comments.
+func GetEmojiCatalog(r *http.Request) ([]Emoji, error) {
+	proxyBase := utils.GetProxyBase(untrusted.GetStaticProxy(r))
+
+	self, err := cachedSettingsSelf(r)
+	if err != nil {
+		return builtinEmojiCatalog(proxyBase), nil
+	}
+
+	if catalog := buildEmojiCatalog(self, proxyBase); len(catalog) > 0 {
+		return catalog, nil
+	}
+
+	return builtinEmojiCatalog(proxyBase), nil
+}
+
+// stampImageURL mirrors the URL built by Comment.processStamp.
+func stampImageURL(proxyBase string, id int) string {
+	return proxyBase + "/common/images/stamp/generated-stamps/" + strconv.Itoa(id) + "_s.jpg"
+}
+
+// emojiImageURL mirrors the URL built by parseEmojis.
+func emojiImageURL(proxyBase, id string) string {
+	return proxyBase + "/common/images/emoji/" + id + ".png"
+}
+
+func buildStampCatalog(self *SettingsSelfResponse, proxyBase string) []StampSet {
+	sets := make([]StampSet, 0, len(self.UserStatus.StampSeries))
+
+	for _, series := range self.UserStatus.StampSeries {
+		stamps := make([]Stamp, 0, len(series.Stamps))
+		for _, id := range series.Stamps {
+			stamps = append(stamps, Stamp{ID: id, URL: stampImageURL(proxyBase, id)})
+		}
+
+		sets = append(sets, StampSet{Slug: series.Slug, Name: series.Name, Stamps: stamps})
+	}
+
+	return sets
+}
+
+func buildEmojiCatalog(self *SettingsSelfResponse, proxyBase string) []Emoji {
+	catalog := make([]Emoji, 0, len(self.UserStatus.EmojiSeries))
+
+	for _, series := range self.UserStatus.EmojiSeries {
+		catalog = append(catalog, Emoji{
+			ID:        series.ID,
+			Shortcode: "(" + series.Name + ")",
+			URL:       emojiImageURL(proxyBase, strconv.Itoa(series.ID)),
+		})
+	}
+
+	return catalog
+}
+
+// builtinEmojiCatalog derives a catalog from the static shortcode map used by
+// parseEmojis, sorted by shortcode for stable rendering.
+func builtinEmojiCatalog(proxyBase string) []Emoji {
+	catalog := make([]Emoji, 0, len(emojiList))
+
+	for name, id := range emojiList {
+		numericID, _ := strconv.Atoi(id)
+
+		catalog = append(catalog, Emoji{
+			ID:        numericID,
+			Shortcode: "(" + name + ")",
+			URL:       emojiImageURL(proxyBase, id),
+		})
+	}
+
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i].Shortcode < catalog[j].Shortcode })
+
+	return catalog
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// settingsSelfFixture is a trimmed settings/self payload carrying stamp and
+// emoji series.
+const settingsSelfFixture = `{
+	"user_status": {
+		"user_id": "123",
+		"stamp_series": [
+			{"slug": "stamp-set-01", "name": "Basic", "stamps": [301, 302]},
+			{"slug": "stamp-set-02", "name": "Seasonal", "stamps": [401]}
+		],
+		"emoji_series": [
+			{"id": 101, "name": "normal"},
+			{"id": 108, "name": "cry"}
+		]
+	}
+}`
+
+func fixtureSelf(t *testing.T) *SettingsSelfResponse {
+	t.Helper()
+
+	var self SettingsSelfResponse
+	if err := json.Unmarshal([]byte(settingsSelfFixture), &self); err != nil {
+		t.Fatalf("failed to unmarshal fixture: %v", err)
+	}
+
+	return &self
+}
+
+func TestBuildStampCatalog(t *testing.T) {
+	t.Parallel()
+
+	sets := buildStampCatalog(fixtureSelf(t), "/proxy/s.pximg.net")
+
+	if len(sets) != 2 {
+		t.Fatalf("buildStampCatalog() returned %d sets, want 2", len(sets))
+	}
+
+	if sets[0].Slug != "stamp-set-01" || sets[0].Name != "Basic" {
+		t.Errorf("sets[0] = %+v, want slug stamp-set-01 / name Basic", sets[0])
+	}
+
+	if len(sets[0].Stamps) != 2 || sets[0].Stamps[0].ID != 301 {
+		t.Fatalf("sets[0].Stamps = %+v, want stamps 301, 302", sets[0].Stamps)
+	}
+
+	wantURL := "/proxy/s.pximg.net/common/images/stamp/generated-stamps/301_s.jpg"
+	if sets[0].Stamps[0].URL != wantURL {
+		t.Errorf("stamp URL = %q, want %q", sets[0].Stamps[0].URL, wantURL)
+	}
+}
+
+func TestBuildEmojiCatalog(t *testing.T) {
+	t.Parallel()
+
+	catalog := buildEmojiCatalog(fixtureSelf(t), "/proxy/s.pximg.net")
+
+	if len(catalog) != 2 {
+		t.Fatalf("buildEmojiCatalog() returned %d emojis, want 2", len(catalog))
+	}
+
+	if catalog[0].Shortcode != "(normal)" {
+		t.Errorf("catalog[0].Shortcode = %q, want %q", catalog[0].Shortcode, "(normal)")
+	}
+
+	wantURL := "/proxy/s.pximg.net/common/images/emoji/101.png"
+	if catalog[0].URL != wantURL {
+		t.Errorf("emoji URL = %q, want %q", catalog[0].URL, wantURL)
+	}
+}
+
+func TestBuiltinEmojiCatalog(t *testing.T) {
+	t.Parallel()
+
+	catalog := builtinEmojiCatalog("/proxy/s.pximg.net")
+
+	if len(catalog) != len(emojiList) {
+		t.Fatalf("builtinEmojiCatalog() returned %d emojis, want %d", len(catalog), len(emojiList))
+	}
+
+	// The catalog must match the map used when rendering comments.
+	for _, emoji := range catalog {
+		if emoji.Shortcode == "(cry)" {
+			if wantURL := "/proxy/s.pximg.net/common/images/emoji/" + emojiList["cry"] + ".png"; emoji.URL != wantURL {
+				t.Errorf("emoji URL = %q, want %q", emoji.URL, wantURL)
+			}
+
+			return
+		}
+	}
+
+	t.Error(`builtinEmojiCatalog() is missing the "(cry)" shortcode`)
+}
@@ -7,10 +7,13 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
 )
 
 // filenameSuffixRegexp matches any suffix  that starts with an underscore
@@ -34,16 +37,66 @@ type Thumbnails struct {
 }
 
 func (work *ArtworkItem) PopulateThumbnails() error {
-	thumbnails, err := PopulateThumbnailsFor(work.Thumbnail)
+	// List views render only a subset of the variants, so operators can opt
+	// into the compact set here; detail pages keep the full set.
+	populate := PopulateThumbnailsFor
+	if config.Global.Image.CompactThumbnails {
+		populate = PopulateCompactThumbnailsFor
+	}
+
+	thumbnails, err := populate(work.Thumbnail)
 	if err != nil {
 		return err
 	}
 
+	// Carry the source dimensions into the thumbnail set so list views can
+	// reserve aspect-ratio space. Endpoints that omit width/height leave
+	// these at zero.
+	thumbnails.Width = work.Width
+	thumbnails.Height = work.Height
+
+	// Optionally probe the image header when the API data lacks dimensions.
+	if thumbnails.Width == 0 && thumbnails.Height == 0 &&
+		config.Global.Feature.ProbeThumbnailDimensions {
+		if width, height, ok := probeThumbnailDimensions(work.Thumbnail); ok {
+			thumbnails.Width = width
+			thumbnails.Height = height
+		}
+	}
+
+	// Fall back to the crop box encoded in the thumbnail path. Cropped
+	// variants render at exactly that box, so it's a faithful layout hint
+	// even though it isn't the original image's aspect ratio.
+	if thumbnails.Width == 0 && thumbnails.Height == 0 {
+		thumbnails.Width, thumbnails.Height = ThumbnailCropDimensions(work.Thumbnail)
+	}
+
 	work.Thumbnails = thumbnails
 
 	return nil
 }
 
+// thumbnailCropRegexp extracts the crop box from a standard pixiv thumbnail
+// path, e.g. the "250x250" in /c/250x250_80_a2/img-master/….
+var thumbnailCropRegexp = regexp.MustCompile(`/c/(\d+)x(\d+)`)
+
+// ThumbnailCropDimensions derives an aspect-ratio hint from the crop box
+// encoded in a standard pixiv thumbnail URL, so templates can emit intrinsic
+// width/height attributes and avoid layout shift. It returns zeros for URLs
+// without a crop segment, such as original image paths.
+func ThumbnailCropDimensions(thumbnailURL string) (width, height int) {
+	m := thumbnailCropRegexp.FindStringSubmatch(thumbnailURL)
+	if m == nil {
+		return 0, 0
+	}
+
+	// The submatches are all-digit by construction.
+	width, _ = strconv.Atoi(m[1])
+	height, _ = strconv.Atoi(m[2])
+
+	return width, height
+}
+
 func GetOriginalAvatarURL(avatarURL string) string {
 	if avatarURL == "" {
 		return ""
@@ -72,6 +125,17 @@ func GetOriginalAvatarURL(avatarURL string) string {
 
 // PopulateThumbnailsFor is a helper function that populates all thumbnail sizes, including Original.
 func PopulateThumbnailsFor(thumbnailURL string) (Thumbnails, error) {
+	return populateThumbnailsFor(thumbnailURL, false)
+}
+
+// PopulateCompactThumbnailsFor populates only the variants list views render:
+// MasterWebp_1200 for the grid image and OriginalJPG for its data attributes.
+// Webp_1200 and OriginalPNG are skipped, saving two URL computations per work.
+func PopulateCompactThumbnailsFor(thumbnailURL string) (Thumbnails, error) {
+	return populateThumbnailsFor(thumbnailURL, true)
+}
+
+func populateThumbnailsFor(thumbnailURL string, compact bool) (Thumbnails, error) {
 	var thumbnails Thumbnails
 
 	// auditor.SugaredLogger.Debugf("PopulateThumbnails called with Thumbnail URL: %s", thumbnailURL)
@@ -94,23 +158,25 @@ func PopulateThumbnailsFor(thumbnailURL string) (Thumbnails, error) {
 		return thumbnails, nil
 	}
 
-	// Define the desired sizes for the thumbnails along with corresponding fields
-	thumbSizes := []struct {
-		name  string
-		size  string
-		field *string
-	}{
-		{"Webp_1200", "1200x1200_80_webp", &thumbnails.Webp_1200},
-	}
-
-	// Generate regular thumbnails
-	for _, thumb := range thumbSizes {
-		finalURL, err := generateThumbnailURL(thumbnailURL, sizeQualityRe, thumb.size)
-		if err != nil {
-			return thumbnails, fmt.Errorf("error generating thumbnail URL for size %s: %w", thumb.size, err)
+	if !compact {
+		// Define the desired sizes for the thumbnails along with corresponding fields
+		thumbSizes := []struct {
+			name  string
+			size  string
+			field *string
+		}{
+			{"Webp_1200", masterWebpSpec(), &thumbnails.Webp_1200},
 		}
 
-		*thumb.field = finalURL
+		// Generate regular thumbnails
+		for _, thumb := range thumbSizes {
+			finalURL, err := generateThumbnailURL(thumbnailURL, sizeQualityRe, thumb.size)
+			if err != nil {
+				return thumbnails, fmt.Errorf("error generating thumbnail URL for size %s: %w", thumb.size, err)
+			}
+
+			*thumb.field = finalURL
+		}
 	}
 
 	// Generate MasterWebp_1200 URL
@@ -124,12 +190,14 @@ func PopulateThumbnailsFor(thumbnailURL string) (Thumbnails, error) {
 
 	thumbnails.OriginalJPG = originalJPGURL
 
-	originalPNGURL, err := generateOriginalURL(thumbnailURL, "png")
-	if err != nil {
-		return thumbnails, fmt.Errorf("error generating original PNG URL: %w", err)
-	}
+	if !compact {
+		originalPNGURL, err := generateOriginalURL(thumbnailURL, "png")
+		if err != nil {
+			return thumbnails, fmt.Errorf("error generating original PNG URL: %w", err)
+		}
 
-	thumbnails.OriginalPNG = originalPNGURL
+		thumbnails.OriginalPNG = originalPNGURL
+	}
 
 	return thumbnails, nil
 }
@@ -188,6 +256,69 @@ func generateOriginalURL(urlStr, extension string) (string, error) {
 	return clonedURL.String(), nil
 }
 
+// masterWebpTransform returns the transform spec used for master WebP
+// conversions, e.g. 1200x1200 at quality 80. The target dimension and
+// quality come from config.Global.Image; unset or out-of-range values fall
+// back to pixiv's standard 1200px box at quality 80.
+func masterWebpTransform() transformSpec {
+	size := config.Global.Image.WebpSize
+	if size <= 0 {
+		size = 1200
+	}
+
+	quality := config.Global.Image.WebpQuality
+	if quality <= 0 || quality > 100 {
+		quality = 80
+	}
+
+	return transformSpec{Width: size, Height: size, Quality: quality, Format: "webp"}
+}
+
+// masterWebpSpec renders masterWebpTransform as a path segment,
+// e.g. "1200x1200_80_webp".
+func masterWebpSpec() string {
+	return masterWebpTransform().String()
+}
+
+// webpCategorySegments maps the configurable WebP category names from
+// config.WebpConvertibleCategories to the path segments they cover.
+var webpCategorySegments = map[string]string{
+	"img-master":           "/img-master/",
+	"custom-thumb":         "/custom-thumb/",
+	"img-original":         "/img-original/",
+	"novel-cover-original": "/novel-cover-original/",
+	"novel-cover-master":   "/novel-cover-master/",
+}
+
+// webpEligible reports whether imagePath belongs to a category enabled for
+// master WebP conversion via Image.WebpCategories. Paths outside any known
+// category (e.g. bare /img/ layouts) stay eligible, matching the historical
+// behavior.
+func webpEligible(imagePath string) bool {
+	categories := config.Global.Image.WebpCategories
+
+	// An unset list keeps every category eligible.
+	if len(categories) == 0 {
+		return true
+	}
+
+	matchedKnown := false
+
+	for category, segment := range webpCategorySegments {
+		if !strings.Contains(imagePath, segment) {
+			continue
+		}
+
+		matchedKnown = true
+
+		if slices.Contains(categories, category) {
+			return true
+		}
+	}
+
+	return !matchedKnown
+}
+
 var replaceOriginalWithMaster = strings.NewReplacer(
 	"/img-original/", "/img-master/",
 	"/custom-thumb/", "/img-master/",
@@ -246,9 +377,30 @@ func generateMasterWebpURL(urlStr, proxyBase string) string {
 		imagePath = fullPath[lastSlash:]
 	}
 
-	// 2. Normalize the image path.
-	// a. Remove any existing size/quality segment (e.g., /c/250x250_80_a2/).
-	newPath := sizeQualityRe.ReplaceAllString(imagePath, "/")
+	// 2. Skip conversion for categories the operator disabled, proxying the
+	// path unchanged instead.
+	if !webpEligible(imagePath) {
+		if proxyBase != "" {
+			return strings.Replace(urlStr, "https://i.pximg.net", strings.TrimSuffix(proxyBase, "/"), 1)
+		}
+
+		return urlStr
+	}
+
+	// 3. Normalize the image path.
+	// a. Remove any existing transform segment (e.g., /c/250x250_80_a2/),
+	// operating on the parsed segment rather than via string surgery. An
+	// unparseable segment falls back to the regex strip for safety.
+	newPath := imagePath
+	if rest, found := strings.CutPrefix(imagePath, "/c/"); found {
+		if slash := strings.Index(rest, "/"); slash != -1 {
+			if _, ok := parseTransformSpec(rest[:slash]); ok {
+				newPath = rest[slash:]
+			} else {
+				newPath = sizeQualityRe.ReplaceAllString(imagePath, "/")
+			}
+		}
+	}
 	// b. Replace various path segments with the canonical master path.
 	newPath = replaceOriginalWithMaster.Replace(newPath)
 	// c. Handle the special case for /img/ which is not handled by the replacer.
@@ -256,17 +408,17 @@ func generateMasterWebpURL(urlStr, proxyBase string) string {
 		newPath = strings.Replace(newPath, "/img/", "/img-master/img/", 1)
 	}
 
-	// 3. Normalize the filename to the master format.
+	// 4. Normalize the filename to the master format.
 	// The master format uses a specific suffix and a .jpg extension, even for WebP.
 	newPath = baseFileRe.ReplaceAllString(newPath, "${1}_master1200.jpg")
 
-	// 4. Prepend the WebP quality/size specifier to request the correct image type.
-	newPath = "/c/1200x1200_80_webp" + newPath
+	// 5. Prepend the WebP quality/size specifier to request the correct image type.
+	newPath = "/c/" + masterWebpSpec() + newPath
 
-	// 5. Clean the final path to resolve any double slashes.
+	// 6. Clean the final path to resolve any double slashes.
 	newPath = path.Clean(newPath)
 
-	// 6. Re-assemble the final URL.
+	// 7. Re-assemble the final URL.
 	if proxyBase != "" {
 		// A new proxy base is provided, so return a relative path with this new base.
 		return strings.TrimSuffix(proxyBase, "/") + newPath
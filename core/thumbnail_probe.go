@@ -0,0 +1,135 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	// Header-only decoding for the formats pixiv serves thumbnails in.
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/rs/zerolog/log"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests/lrucache"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+const (
+	// probeRangeBytes is enough for a PNG IHDR chunk or JPEG SOF marker.
+	probeRangeBytes = 8192
+
+	// probeConcurrency bounds simultaneous dimension probes.
+	probeConcurrency = 8
+
+	probeTimeout = 5 * time.Second
+
+	// probeCacheSize bounds the per-image dimension cache.
+	probeCacheSize = 4096
+)
+
+// imageDimensions is the cached result of one dimension probe.
+type imageDimensions struct {
+	Width  int
+	Height int
+}
+
+var (
+	// thumbnailDimensionCache caches probed dimensions per upstream image URL.
+	thumbnailDimensionCache, _ = lrucache.NewLRUCache(probeCacheSize, false)
+
+	// probeSemaphore bounds concurrent dimension probes.
+	probeSemaphore = make(chan struct{}, probeConcurrency)
+)
+
+// probeThumbnailDimensions returns the pixel dimensions for a thumbnail URL,
+// fetching only the image header via a range request and caching the result
+// per image. It reports false when the URL cannot be probed.
+func probeThumbnailDimensions(thumbnailURL string) (int, int, bool) {
+	upstream := upstreamImageURL(thumbnailURL)
+	if upstream == "" {
+		return 0, 0, false
+	}
+
+	if cached, found := thumbnailDimensionCache.Get(upstream); found {
+		if dims, ok := cached.(imageDimensions); ok {
+			return dims.Width, dims.Height, true
+		}
+	}
+
+	probeSemaphore <- struct{}{}
+	defer func() { <-probeSemaphore }()
+
+	dims, err := fetchImageDimensions(upstream)
+	if err != nil {
+		log.Debug().Err(err).Str("url", upstream).Msg("Thumbnail dimension probe failed")
+
+		return 0, 0, false
+	}
+
+	thumbnailDimensionCache.Add(upstream, dims)
+
+	return dims.Width, dims.Height, true
+}
+
+// upstreamImageURL maps a (possibly proxied) thumbnail URL back to its
+// i.pximg.net source so the probe fetches from upstream directly.
+func upstreamImageURL(rawURL string) string {
+	if strings.HasPrefix(rawURL, "https://") {
+		return rawURL
+	}
+
+	for _, marker := range pathMarkers {
+		if idx := strings.Index(rawURL, marker); idx != -1 {
+			return "https://i.pximg.net" + rawURL[idx:]
+		}
+	}
+
+	return ""
+}
+
+// fetchImageDimensions issues a small range request for the image header and
+// decodes only its configuration.
+func fetchImageDimensions(upstream string) (imageDimensions, error) {
+	var dims imageDimensions
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream, nil)
+	if err != nil {
+		return dims, fmt.Errorf("failed to create probe request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", config.GetRandomUserAgent())
+	req.Header.Set("Referer", "https://www.pixiv.net/")
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", probeRangeBytes-1))
+
+	resp, err := utils.HTTPClient.Do(req)
+	if err != nil {
+		return dims, fmt.Errorf("probe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return dims, fmt.Errorf("probe returned status %d", resp.StatusCode) //nolint:err113
+	}
+
+	cfg, _, err := image.DecodeConfig(io.LimitReader(resp.Body, probeRangeBytes))
+	if err != nil {
+		return dims, fmt.Errorf("failed to decode image header: %w", err)
+	}
+
+	dims.Width = cfg.Width
+	dims.Height = cfg.Height
+
+	return dims, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// probeStubTransport serves a small PNG and counts how often it is hit.
+type probeStubTransport struct {
+	calls atomic.Int32
+	body  []byte
+}
+
+func (t *probeStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+
+	return &http.Response{
+		StatusCode: http.StatusPartialContent,
+		Header:     http.Header{"Content-Type": {"image/png"}},
+		Body:       io.NopCloser(bytes.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+// encodeTestPNG returns an encoded PNG with the given dimensions.
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode() error: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func setupProbeTest(t *testing.T, transport *probeStubTransport) {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+	})
+
+	config.Global.Feature.ProbeThumbnailDimensions = true
+
+	utils.HTTPClient.Transport = transport
+}
+
+func TestProbeThumbnailDimensions_ProbeAndCache(t *testing.T) {
+	transport := &probeStubTransport{body: encodeTestPNG(t, 300, 200)}
+	setupProbeTest(t, transport)
+
+	// Proxied URLs are mapped back to their i.pximg.net source.
+	const thumbnail = "/proxy/i.pximg.net/img-master/img/2024/03/03/00/00/00/555001_p0_square1200.jpg"
+
+	width, height, ok := probeThumbnailDimensions(thumbnail)
+	if !ok {
+		t.Fatal("probeThumbnailDimensions() failed for a probeable URL")
+	}
+
+	if width != 300 || height != 200 {
+		t.Errorf("dimensions = %dx%d, want 300x200", width, height)
+	}
+
+	// A repeat probe for the same image is served from the cache.
+	if _, _, ok := probeThumbnailDimensions(thumbnail); !ok {
+		t.Fatal("probeThumbnailDimensions() cache lookup failed")
+	}
+
+	if got := transport.calls.Load(); got != 1 {
+		t.Errorf("upstream probes = %d, want 1 (second lookup cached)", got)
+	}
+}
+
+func TestProbeThumbnailDimensions_UnprobeableURL(t *testing.T) {
+	transport := &probeStubTransport{body: encodeTestPNG(t, 1, 1)}
+	setupProbeTest(t, transport)
+
+	if _, _, ok := probeThumbnailDimensions("/static/local-asset.png"); ok {
+		t.Error("probeThumbnailDimensions() probed a URL without an upstream mapping")
+	}
+
+	if got := transport.calls.Load(); got != 0 {
+		t.Errorf("upstream probes = %d, want 0", got)
+	}
+}
+
+func TestPopulateThumbnails_ProbesMissingDimensions(t *testing.T) {
+	transport := &probeStubTransport{body: encodeTestPNG(t, 120, 90)}
+	setupProbeTest(t, transport)
+
+	work := ArtworkItem{
+		ID:        "555002",
+		Thumbnail: "https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/03/03/00/00/00/555002_p0_square1200.jpg",
+	}
+
+	if err := work.PopulateThumbnails(); err != nil {
+		t.Fatalf("PopulateThumbnails() error: %v", err)
+	}
+
+	if work.Thumbnails.Width != 120 || work.Thumbnails.Height != 90 {
+		t.Errorf("Thumbnails dimensions = %dx%d, want 120x90",
+			work.Thumbnails.Width, work.Thumbnails.Height)
+	}
+
+	// Works that already carry dimensions are not probed.
+	transport.calls.Store(0)
+
+	withDims := ArtworkItem{
+		ID:        "555003",
+		Width:     640,
+		Height:    480,
+		Thumbnail: "https://i.pximg.net/c/250x250_80_a2/img-master/img/2024/03/03/00/00/00/555003_p0_square1200.jpg",
+	}
+
+	if err := withDims.PopulateThumbnails(); err != nil {
+		t.Fatalf("PopulateThumbnails() error: %v", err)
+	}
+
+	if got := transport.calls.Load(); got != 0 {
+		t.Errorf("upstream probes = %d, want 0 when dimensions are known", got)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	. "codeberg.org/pixivfe/pixivfe/v3/core"
+)
+
+func TestPopulateThumbnails_Dimensions(t *testing.T) {
+	t.Parallel()
+
+	const listItem = `{
+		"id": "123456",
+		"title": "test",
+		"url": "https://i.pximg.net/c/250x250_80_a2/img-master/img/2025/01/01/00/00/00/123456_p0_square1200.jpg",
+		"width": 1920,
+		"height": 1080
+	}`
+
+	var work ArtworkItem
+	if err := json.Unmarshal([]byte(listItem), &work); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	if work.Width != 1920 || work.Height != 1080 {
+		t.Fatalf("Unmarshal() dimensions = %dx%d, want 1920x1080", work.Width, work.Height)
+	}
+
+	if err := work.PopulateThumbnails(); err != nil {
+		t.Fatalf("PopulateThumbnails() error: %v", err)
+	}
+
+	if work.Thumbnails.Width != 1920 || work.Thumbnails.Height != 1080 {
+		t.Errorf("Thumbnails dimensions = %dx%d, want 1920x1080",
+			work.Thumbnails.Width, work.Thumbnails.Height)
+	}
+}
+
+func TestPopulateThumbnails_MissingDimensions(t *testing.T) {
+	t.Parallel()
+
+	work := ArtworkItem{
+		ID:        "123456",
+		Thumbnail: "https://i.pximg.net/c/250x250_80_a2/img-master/img/2025/01/01/00/00/00/123456_p0_square1200.jpg",
+	}
+
+	if err := work.PopulateThumbnails(); err != nil {
+		t.Fatalf("PopulateThumbnails() error: %v", err)
+	}
+
+	// Without API dimensions, the crop box from the thumbnail path serves as
+	// the layout hint.
+	if work.Thumbnails.Width != 250 || work.Thumbnails.Height != 250 {
+		t.Errorf("Thumbnails dimensions = %dx%d, want the 250x250 crop box",
+			work.Thumbnails.Width, work.Thumbnails.Height)
+	}
+}
+
+func TestPopulateCompactThumbnailsFor(t *testing.T) {
+	t.Parallel()
+
+	const thumbnailURL = "https://i.pximg.net/c/250x250_80_a2/img-master/img/2025/01/01/00/00/00/654321_p0_square1200.jpg"
+
+	full, err := PopulateThumbnailsFor(thumbnailURL)
+	if err != nil {
+		t.Fatalf("PopulateThumbnailsFor() error: %v", err)
+	}
+
+	compact, err := PopulateCompactThumbnailsFor(thumbnailURL)
+	if err != nil {
+		t.Fatalf("PopulateCompactThumbnailsFor() error: %v", err)
+	}
+
+	// The compact set must carry the variants list views render, identical to
+	// the full set's.
+	if compact.MasterWebp_1200 == "" || compact.MasterWebp_1200 != full.MasterWebp_1200 {
+		t.Errorf("MasterWebp_1200 = %q, want %q", compact.MasterWebp_1200, full.MasterWebp_1200)
+	}
+
+	if compact.OriginalJPG == "" || compact.OriginalJPG != full.OriginalJPG {
+		t.Errorf("OriginalJPG = %q, want %q", compact.OriginalJPG, full.OriginalJPG)
+	}
+
+	// The detail-only variants are skipped entirely.
+	if compact.Webp_1200 != "" || compact.OriginalPNG != "" {
+		t.Errorf("Compact set = %+v, want Webp_1200 and OriginalPNG left empty", compact)
+	}
+}
+
+// TestPopulateThumbnails_CompactMode verifies the config switch on the
+// list-view populate path. Deliberately not parallel: it mutates
+// config.Global.Image.
+func TestPopulateThumbnails_CompactMode(t *testing.T) {
+	origConfig := config.Global
+	t.Cleanup(func() { config.Global = origConfig })
+
+	config.Global.Image.CompactThumbnails = true
+
+	work := ArtworkItem{
+		ID:        "654322",
+		Thumbnail: "https://i.pximg.net/c/250x250_80_a2/img-master/img/2025/01/01/00/00/00/654322_p0_square1200.jpg",
+	}
+
+	if err := work.PopulateThumbnails(); err != nil {
+		t.Fatalf("PopulateThumbnails() error: %v", err)
+	}
+
+	if work.Thumbnails.MasterWebp_1200 == "" {
+		t.Error("MasterWebp_1200 is empty, want it populated in compact mode")
+	}
+
+	if work.Thumbnails.Webp_1200 != "" || work.Thumbnails.OriginalPNG != "" {
+		t.Errorf("Thumbnails = %+v, want the detail-only variants skipped", work.Thumbnails)
+	}
+}
+
+func BenchmarkPopulateThumbnailsFor(b *testing.B) {
+	const thumbnailURL = "https://i.pximg.net/c/250x250_80_a2/img-master/img/2025/01/01/00/00/00/654321_p0_square1200.jpg"
+
+	b.Run("full", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := PopulateThumbnailsFor(thumbnailURL); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("compact", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := PopulateCompactThumbnailsFor(thumbnailURL); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestThumbnailCropDimensions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		url   string
+		wantW int
+		wantH int
+	}{
+		{
+			"Square thumbnail crop",
+			"https://i.pximg.net/c/250x250_80_a2/img-master/img/2025/01/01/00/00/00/1_p0_square1200.jpg",
+			250, 250,
+		},
+		{
+			"Custom aspect crop",
+			"https://i.pximg.net/c/600x1200_90_webp/img-master/img/2025/01/01/00/00/00/1_p0_master1200.jpg",
+			600, 1200,
+		},
+		{
+			"Proxied thumbnail path",
+			"/proxy/i.pximg.net/c/360x360_70/img-master/img/2025/01/01/00/00/00/1_p0_square1200.jpg",
+			360, 360,
+		},
+		{
+			"Original image path has no crop box",
+			"https://i.pximg.net/img-original/img/2025/01/01/00/00/00/1_p0.png",
+			0, 0,
+		},
+		{
+			"Empty URL",
+			"",
+			0, 0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, h := ThumbnailCropDimensions(tc.url)
+			if w != tc.wantW || h != tc.wantH {
+				t.Errorf("ThumbnailCropDimensions(%q) = %dx%d, want %dx%d",
+					tc.url, w, h, tc.wantW, tc.wantH)
+			}
+		})
+	}
+}
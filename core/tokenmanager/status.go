@@ -0,0 +1,84 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tokenmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// maskedPrefixLen is how many leading characters of a token value the status
+// output keeps. Enough to tell PIXIVFE_TOKEN entries apart, far too short to
+// reconstruct the credential.
+const maskedPrefixLen = 8
+
+// TokenStatus is an operator-facing snapshot of one managed token's health.
+// The token is identified by a stable masked prefix; the full value is never
+// exposed.
+type TokenStatus struct {
+	ID              string    `json:"id"`
+	Status          string    `json:"status"` // "good" or "timed-out"
+	FailureCount    int       `json:"failureCount"`
+	StatusChangedAt time.Time `json:"statusChangedAt"`
+	LastUsed        time.Time `json:"lastUsed"`
+
+	// TimeoutUntil is set while the token is timed out.
+	TimeoutUntil time.Time `json:"timeoutUntil,omitzero"`
+}
+
+// Status returns a snapshot of every managed token's health, in the order the
+// tokens were configured.
+func (tm *TokenManager) Status() []TokenStatus {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	statuses := make([]TokenStatus, len(tm.tokens))
+
+	for i, token := range tm.tokens {
+		status := TokenStatus{
+			ID:              maskTokenValue(token.Value),
+			Status:          "good",
+			FailureCount:    token.failureCount,
+			StatusChangedAt: token.statusChangedAt,
+			LastUsed:        token.lastUsed,
+		}
+
+		if token.status == TimedOut {
+			status.Status = "timed-out"
+			status.TimeoutUntil = token.timeoutUntil
+		}
+
+		statuses[i] = status
+	}
+
+	return statuses
+}
+
+// StatusHandler returns a handler that renders DefaultTokenManager.Status()
+// as JSON, for mounting on an operator-only route. A nil DefaultTokenManager
+// reports an empty list.
+func StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := []TokenStatus{}
+		if DefaultTokenManager != nil {
+			statuses = DefaultTokenManager.Status()
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		_ = json.NewEncoder(w).Encode(statuses)
+	})
+}
+
+// maskTokenValue reduces a token value to its identifying prefix. Values too
+// short to safely truncate are masked entirely.
+func maskTokenValue(value string) string {
+	if len(value) <= maskedPrefixLen {
+		return "********"
+	}
+
+	return value[:maskedPrefixLen] + "…"
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tokenmanager
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStatus verifies that Status reports a timed-out token as such and never
+// exposes full token values.
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	const secret = "supersecrettokenvalue123"
+
+	tm, _ := NewTokenManager([]string{secret, "short"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
+
+	tm.MarkTokenStatus(tm.tokens[0], TimedOut)
+
+	statuses := tm.Status()
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
+	}
+
+	if statuses[0].Status != "timed-out" {
+		t.Errorf("Expected first token status %q, got %q", "timed-out", statuses[0].Status)
+	}
+
+	if statuses[0].FailureCount != 1 {
+		t.Errorf("Expected failure count 1, got %d", statuses[0].FailureCount)
+	}
+
+	if statuses[0].TimeoutUntil.IsZero() {
+		t.Error("Expected TimeoutUntil to be set for a timed-out token")
+	}
+
+	if statuses[0].StatusChangedAt.IsZero() {
+		t.Error("Expected StatusChangedAt to be set")
+	}
+
+	if statuses[1].Status != "good" {
+		t.Errorf("Expected second token status %q, got %q", "good", statuses[1].Status)
+	}
+
+	if !statuses[1].TimeoutUntil.IsZero() {
+		t.Error("Expected TimeoutUntil to be zero for a good token")
+	}
+
+	for _, status := range statuses {
+		if strings.Contains(status.ID, secret) || strings.Contains(status.ID, "short") {
+			t.Errorf("Status ID %q exposes a full token value", status.ID)
+		}
+	}
+
+	if want := secret[:maskedPrefixLen] + "…"; statuses[0].ID != want {
+		t.Errorf("Expected masked ID %q, got %q", want, statuses[0].ID)
+	}
+
+	// Values too short to truncate are masked entirely.
+	if statuses[1].ID != "********" {
+		t.Errorf("Expected fully masked ID, got %q", statuses[1].ID)
+	}
+}
+
+// TestStatusHandler verifies the JSON rendering of the status endpoint,
+// including the nil DefaultTokenManager case.
+func TestStatusHandler(t *testing.T) {
+	const secret = "supersecrettokenvalue123"
+
+	origManager := DefaultTokenManager
+	t.Cleanup(func() {
+		DefaultTokenManager = origManager
+	})
+
+	DefaultTokenManager, _ = NewTokenManager([]string{secret}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
+	DefaultTokenManager.MarkTokenStatus(DefaultTokenManager.tokens[0], TimedOut)
+
+	recorder := httptest.NewRecorder()
+	StatusHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/tokens", nil))
+
+	body := recorder.Body.String()
+	if strings.Contains(body, secret) {
+		t.Errorf("Response body exposes the full token value: %s", body)
+	}
+
+	if !strings.Contains(body, `"timed-out"`) {
+		t.Errorf("Expected a timed-out entry in the response, got: %s", body)
+	}
+
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("Expected a JSON content type, got %q", got)
+	}
+
+	// A nil DefaultTokenManager reports an empty list rather than erroring.
+	DefaultTokenManager = nil
+
+	recorder = httptest.NewRecorder()
+	StatusHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/tokens", nil))
+
+	if got := strings.TrimSpace(recorder.Body.String()); got != "[]" {
+		t.Errorf("Expected an empty list for a nil DefaultTokenManager, got %q", got)
+	}
+}
@@ -7,6 +7,8 @@ Package tokenmanager provides functionality for managing and rotating API tokens
 package tokenmanager
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 	"sort"
@@ -16,6 +18,12 @@ import (
 	"time"
 )
 
+var (
+	errUnknownLoadBalancingMethod = errors.New("unknown load balancing method")
+	errTokenWeightCountMismatch   = errors.New("token weight count does not match token count")
+	errNonPositiveTokenWeight     = errors.New("token weights must be positive")
+)
+
 const (
 	yuidbChars  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	yuidbLength = 7
@@ -46,9 +54,11 @@ type Token struct {
 	PAbID2 string // A "p_ab_id_2" cookie value
 
 	status              tokenStatus   // Current status of the token
+	statusChangedAt     time.Time     // Last time the status actually changed
 	timeoutUntil        time.Time     // Time until which the token is timed out
 	failureCount        int           // Number of consecutive failures
 	lastUsed            time.Time     // Last time the token was used
+	weight              int           // Relative selection weight for the "weighted" strategy
 	baseTimeoutDuration time.Duration // Base duration for timeout calculations
 }
 
@@ -64,19 +74,49 @@ type TokenManager struct {
 }
 
 // NewTokenManager creates and initializes a new TokenManager with the given parameters.
+//
+// Optional per-token weights drive the "weighted" selection strategy; when
+// omitted, every token has weight 1. Weights must be positive and match the
+// token count.
 func NewTokenManager(
 	tokenValues []string,
 	maxRetries int,
 	baseTimeout, maxBackoffTime time.Duration,
 	loadBalancingMethod string,
-) *TokenManager {
+	weights ...int,
+) (*TokenManager, error) {
+	switch loadBalancingMethod {
+	case "round-robin", "random", "least-recently-used", "weighted":
+		// valid
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownLoadBalancingMethod, loadBalancingMethod)
+	}
+
+	if len(weights) > 0 && len(weights) != len(tokenValues) {
+		return nil, fmt.Errorf("%w: %d weights for %d tokens",
+			errTokenWeightCountMismatch, len(weights), len(tokenValues))
+	}
+
+	for _, weight := range weights {
+		if weight <= 0 {
+			return nil, fmt.Errorf("%w: got %d", errNonPositiveTokenWeight, weight)
+		}
+	}
+
 	tokens := make([]*Token, len(tokenValues))
 	// #nosec:G404 - ab cookie generation doesn't need to be cryptographically secure.
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
+	now := time.Now()
+
 	for i, value := range tokenValues {
 		yuidb, pAbDID, pAbID, pAbID2 := GenerateABCookies(r)
 
+		weight := 1
+		if len(weights) > 0 {
+			weight = weights[i]
+		}
+
 		tokens[i] = &Token{
 			Value:               value,
 			YUIDB:               yuidb,
@@ -84,6 +124,8 @@ func NewTokenManager(
 			PAbID:               pAbID,
 			PAbID2:              pAbID2,
 			status:              Good,
+			statusChangedAt:     now,
+			weight:              weight,
 			baseTimeoutDuration: baseTimeout,
 		}
 	}
@@ -95,7 +137,7 @@ func NewTokenManager(
 		maxBackoffTime:      maxBackoffTime,
 		loadBalancingMethod: loadBalancingMethod,
 		currentIndex:        0,
-	}
+	}, nil
 }
 
 // CreateRandomToken generates an arbitrary Token with a random 33-character
@@ -137,10 +179,12 @@ func (tm *TokenManager) GetToken() *Token {
 	defer tm.mu.Unlock()
 
 	now := time.Now()
-	healthyTokens := tm.getHealthyTokens()
+	healthyTokens := tm.getHealthyTokens(now)
 
 	if len(healthyTokens) == 0 {
-		return tm.getFallbackToken(now)
+		// Every token is still in cooldown; the caller decides whether to
+		// force a reset via ResetAllTokens.
+		return nil
 	}
 
 	var selectedToken *Token
@@ -152,6 +196,8 @@ func (tm *TokenManager) GetToken() *Token {
 		selectedToken = tm.randomSelection(healthyTokens)
 	case "least-recently-used":
 		selectedToken = tm.leastRecentlyUsedSelection(healthyTokens)
+	case "weighted":
+		selectedToken = tm.weightedSelection(healthyTokens)
 	default:
 		selectedToken = tm.roundRobinSelection(healthyTokens)
 	}
@@ -186,6 +232,10 @@ func (tm *TokenManager) MarkTokenStatus(token *Token, status tokenStatus) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
+	if token.status != status {
+		token.statusChangedAt = time.Now()
+	}
+
 	token.status = status
 	if status == TimedOut {
 		token.failureCount++
@@ -204,22 +254,51 @@ func (tm *TokenManager) MarkTokenStatus(token *Token, status tokenStatus) {
 	}
 }
 
-// ResetAllTokens resets all tokens to their initial good state.
+// ResetAllTokens returns tokens to service after a full outage.
+//
+// Rather than flipping every token to Good at once (which tends to get
+// freshly-reset tokens immediately re-throttled), the first timed-out token
+// returns to service right away and each subsequent one is given a staggered
+// cooldown of one additional baseTimeout.
 func (tm *TokenManager) ResetAllTokens() {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
+	now := time.Now()
+	staggered := 0
+
 	for _, token := range tm.tokens {
-		token.status = Good
 		token.failureCount = 0
+
+		if token.status == Good {
+			continue
+		}
+
+		token.statusChangedAt = now
+
+		if staggered == 0 {
+			token.status = Good
+		} else {
+			token.timeoutUntil = now.Add(time.Duration(staggered) * tm.baseTimeout)
+		}
+
+		staggered++
 	}
 }
 
-// getHealthyTokens returns a slice of tokens that are currently in a good state.
-func (tm *TokenManager) getHealthyTokens() []*Token {
+// getHealthyTokens returns the tokens currently available for selection.
+//
+// Timed-out tokens whose cooldown has elapsed are revived on the way through;
+// their failure count is kept so that a prompt re-timeout backs off further.
+func (tm *TokenManager) getHealthyTokens(now time.Time) []*Token {
 	healthyTokens := make([]*Token, 0)
 
 	for _, token := range tm.tokens {
+		if token.status == TimedOut && now.After(token.timeoutUntil) {
+			token.status = Good
+			token.statusChangedAt = now
+		}
+
 		if token.status == Good {
 			healthyTokens = append(healthyTokens, token)
 		}
@@ -228,25 +307,6 @@ func (tm *TokenManager) getHealthyTokens() []*Token {
 	return healthyTokens
 }
 
-// getFallbackToken attempts to find a timed-out token that can be reset and used.
-func (tm *TokenManager) getFallbackToken(now time.Time) *Token {
-	var bestToken *Token
-	for _, token := range tm.tokens {
-		if token.status == TimedOut && (bestToken == nil || token.timeoutUntil.Before(bestToken.timeoutUntil)) {
-			bestToken = token
-		}
-	}
-
-	if bestToken != nil && now.After(bestToken.timeoutUntil) {
-		bestToken.status = Good
-		bestToken.lastUsed = now
-
-		return bestToken
-	}
-
-	return bestToken
-}
-
 // roundRobinSelection implements round-robin token selection strategy.
 func (tm *TokenManager) roundRobinSelection(healthyTokens []*Token) *Token {
 	if tm.currentIndex >= len(healthyTokens) {
@@ -266,6 +326,28 @@ func (tm *TokenManager) randomSelection(healthyTokens []*Token) *Token {
 	return healthyTokens[rand.Intn(len(healthyTokens))]
 }
 
+// weightedSelection implements weighted random token selection, where a
+// token's chance of being picked is proportional to its configured weight.
+//
+// #nosec:G404 - token selection doesn't need to be cryptographically secure.
+func (tm *TokenManager) weightedSelection(healthyTokens []*Token) *Token {
+	totalWeight := 0
+	for _, token := range healthyTokens {
+		totalWeight += token.weight
+	}
+
+	pick := rand.Intn(totalWeight)
+	for _, token := range healthyTokens {
+		pick -= token.weight
+		if pick < 0 {
+			return token
+		}
+	}
+
+	// Unreachable: the loop above always terminates for positive weights.
+	return healthyTokens[len(healthyTokens)-1]
+}
+
 // leastRecentlyUsedSelection implements least recently used token selection.
 func (tm *TokenManager) leastRecentlyUsedSelection(healthyTokens []*Token) *Token {
 	sort.Slice(healthyTokens, func(i, j int) bool {
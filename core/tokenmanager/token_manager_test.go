@@ -20,7 +20,7 @@ func TestNewTokenManager(t *testing.T) {
 	maxBackoffTime := 32000 * time.Millisecond
 	loadBalancingMethod := "round-robin"
 
-	tm := NewTokenManager(tokenValues, maxRetries, baseTimeout, maxBackoffTime, loadBalancingMethod)
+	tm, _ := NewTokenManager(tokenValues, maxRetries, baseTimeout, maxBackoffTime, loadBalancingMethod)
 
 	// Check if the number of tokens matches the input
 	if len(tm.tokens) != len(tokenValues) {
@@ -121,7 +121,7 @@ func TestGetTokenAllMethods(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			tm := NewTokenManager([]string{"token1", "token2", "token3"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, tt.loadBalancingMethod)
+			tm, _ := NewTokenManager([]string{"token1", "token2", "token3"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, tt.loadBalancingMethod)
 			tt.expectedBehavior(t, tm)
 		})
 	}
@@ -132,7 +132,7 @@ func TestGetTokenAllMethods(t *testing.T) {
 func TestMarkTokenStatus(t *testing.T) {
 	t.Parallel()
 
-	tm := NewTokenManager([]string{"token1"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
+	tm, _ := NewTokenManager([]string{"token1"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
 	token := tm.GetToken()
 
 	// Test marking a token as TimedOut
@@ -158,12 +158,12 @@ func TestMarkTokenStatus(t *testing.T) {
 	}
 }
 
-// TestResetAllTokens checks if the ResetAllTokens method correctly
-// resets all tokens to their initial good state.
+// TestResetAllTokens checks that ResetAllTokens staggers the return-to-good
+// instead of flipping every token at once.
 func TestResetAllTokens(t *testing.T) {
 	t.Parallel()
 
-	tm := NewTokenManager([]string{"token1", "token2"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
+	tm, _ := NewTokenManager([]string{"token1", "token2", "token3"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
 
 	// Mark all tokens as TimedOut
 	for _, token := range tm.tokens {
@@ -173,24 +173,41 @@ func TestResetAllTokens(t *testing.T) {
 	// Reset all tokens
 	tm.ResetAllTokens()
 
-	// Check if all tokens are reset to Good status with 0 failure count
-	for _, token := range tm.tokens {
-		if token.status != Good {
-			t.Errorf("Expected all tokens to have Good status, got %v", token.status)
-		}
+	goodCount := 0
 
+	for i, token := range tm.tokens {
 		if token.failureCount != 0 {
 			t.Errorf("Expected all tokens to have FailureCount 0, got %d", token.failureCount)
 		}
+
+		if token.status == Good {
+			goodCount++
+
+			continue
+		}
+
+		// Still-cooling tokens must have a future, staggered cooldown.
+		if !token.timeoutUntil.After(time.Now()) {
+			t.Errorf("Token %d: expected a future cooldown after a staggered reset", i)
+		}
+	}
+
+	if goodCount != 1 {
+		t.Errorf("Expected exactly one token to return to service immediately, got %d", goodCount)
+	}
+
+	// Later tokens should cool down longer than earlier ones.
+	if !tm.tokens[2].timeoutUntil.After(tm.tokens[1].timeoutUntil) {
+		t.Error("Expected the third token's cooldown to extend beyond the second's")
 	}
 }
 
-// TestGetFallbackToken verifies that when all tokens are timed out,
-// the TokenManager correctly selects and resets a fallback token.
-func TestGetFallbackToken(t *testing.T) {
+// TestCooldownElapsedRevival verifies that timed-out tokens become selectable
+// again once their cooldown has passed.
+func TestCooldownElapsedRevival(t *testing.T) {
 	t.Parallel()
 
-	tm := NewTokenManager([]string{"token1", "token2"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
+	tm, _ := NewTokenManager([]string{"token1", "token2"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
 
 	// Mark all tokens as timed out
 	for _, token := range tm.tokens {
@@ -199,12 +216,44 @@ func TestGetFallbackToken(t *testing.T) {
 		token.timeoutUntil = time.Now().Add(-1000 * time.Millisecond) // Set timeout in the past
 	}
 
-	// Get a token, which should reset and return a previously timed-out token
+	// Get a token, which should revive and return a previously timed-out token
 	token := tm.GetToken()
 	if token == nil {
-		t.Error("Expected a fallback token, got nil")
+		t.Error("Expected a revived token, got nil")
 	} else if token.status != Good {
-		t.Errorf("Expected fallback token status to be Good, got %v", token.status)
+		t.Errorf("Expected revived token status to be Good, got %v", token.status)
+	}
+}
+
+// TestCooldownSkipsTokens verifies that a timed-out token is not selected
+// again until its backoff elapses, and that GetToken returns nil while every
+// token is still cooling down.
+func TestCooldownSkipsTokens(t *testing.T) {
+	t.Parallel()
+
+	tm, _ := NewTokenManager([]string{"token1", "token2"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
+
+	tm.MarkTokenStatus(tm.tokens[0], TimedOut)
+
+	// Only token2 is selectable while token1 cools down.
+	for range 5 {
+		if token := tm.GetToken(); token.Value != "token2" {
+			t.Fatalf("Expected only token2 while token1 is cooling down, got %s", token.Value)
+		}
+	}
+
+	// With both tokens cooling down, selection yields nothing.
+	tm.MarkTokenStatus(tm.tokens[1], TimedOut)
+
+	if token := tm.GetToken(); token != nil {
+		t.Errorf("Expected nil while all tokens are cooling down, got %s", token.Value)
+	}
+
+	// Once token1's backoff passes, it becomes selectable again.
+	tm.tokens[0].timeoutUntil = time.Now().Add(-time.Millisecond)
+
+	if token := tm.GetToken(); token == nil || token.Value != "token1" {
+		t.Errorf("Expected token1 after its cooldown elapsed, got %v", token)
 	}
 }
 
@@ -213,7 +262,7 @@ func TestGetFallbackToken(t *testing.T) {
 func TestExponentialBackoff(t *testing.T) {
 	t.Parallel()
 
-	tm := NewTokenManager([]string{"token1"}, 5, 1000*time.Millisecond, 8000*time.Millisecond, "round-robin")
+	tm, _ := NewTokenManager([]string{"token1"}, 5, 1000*time.Millisecond, 8000*time.Millisecond, "round-robin")
 	token := tm.GetToken()
 
 	expectedTimeouts := []time.Duration{
@@ -238,7 +287,7 @@ func TestExponentialBackoff(t *testing.T) {
 func TestConcurrentAccess(t *testing.T) {
 	t.Parallel()
 
-	tm := NewTokenManager([]string{"token1", "token2", "token3"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
+	tm, _ := NewTokenManager([]string{"token1", "token2", "token3"}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
 
 	var wg sync.WaitGroup
 	for range 100 {
@@ -260,10 +309,85 @@ func TestConcurrentAccess(t *testing.T) {
 func TestEmptyTokenList(t *testing.T) {
 	t.Parallel()
 
-	tm := NewTokenManager([]string{}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
+	tm, _ := NewTokenManager([]string{}, 5, 1000*time.Millisecond, 32000*time.Millisecond, "round-robin")
 
 	token := tm.GetToken()
 	if token != nil {
 		t.Errorf("Expected nil token for empty token list, got %v", token)
 	}
 }
+
+// TestNewTokenManagerValidation verifies that invalid strategies and weights
+// fail at construction.
+func TestNewTokenManagerValidation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewTokenManager([]string{"token1"}, 5, time.Second, time.Second, "fastest-first"); err == nil {
+		t.Error("Expected an error for an unknown load balancing method")
+	}
+
+	if _, err := NewTokenManager([]string{"token1", "token2"}, 5, time.Second, time.Second, "weighted", 3); err == nil {
+		t.Error("Expected an error for a weight count mismatch")
+	}
+
+	if _, err := NewTokenManager([]string{"token1"}, 5, time.Second, time.Second, "weighted", 0); err == nil {
+		t.Error("Expected an error for a non-positive weight")
+	}
+
+	if _, err := NewTokenManager([]string{"token1", "token2"}, 5, time.Second, time.Second, "weighted", 3, 1); err != nil {
+		t.Errorf("Expected valid weighted construction to succeed, got %v", err)
+	}
+}
+
+// TestLeastRecentlyUsedSelectionOrder verifies that the least-recently-used
+// strategy picks the token that has been idle the longest, given simulated
+// usage timestamps.
+func TestLeastRecentlyUsedSelectionOrder(t *testing.T) {
+	t.Parallel()
+
+	tm, _ := NewTokenManager([]string{"token1", "token2", "token3"}, 5, time.Second, time.Second, "least-recently-used")
+
+	now := time.Now()
+	tm.tokens[0].lastUsed = now.Add(-1 * time.Minute)
+	tm.tokens[1].lastUsed = now.Add(-3 * time.Minute) // idle longest
+	tm.tokens[2].lastUsed = now.Add(-2 * time.Minute)
+
+	if token := tm.GetToken(); token.Value != "token2" {
+		t.Errorf("Expected the longest-idle token2 first, got %s", token.Value)
+	}
+
+	// token2 was just used, so token3 is now the oldest.
+	if token := tm.GetToken(); token.Value != "token3" {
+		t.Errorf("Expected token3 second, got %s", token.Value)
+	}
+
+	if token := tm.GetToken(); token.Value != "token1" {
+		t.Errorf("Expected token1 third, got %s", token.Value)
+	}
+}
+
+// TestWeightedSelection verifies that weighted selection favours tokens in
+// proportion to their configured weights.
+func TestWeightedSelection(t *testing.T) {
+	t.Parallel()
+
+	tm, err := NewTokenManager([]string{"heavy", "light"}, 5, time.Second, time.Second, "weighted", 9, 1)
+	if err != nil {
+		t.Fatalf("NewTokenManager() error: %v", err)
+	}
+
+	counts := map[string]int{}
+	for range 2000 {
+		counts[tm.GetToken().Value]++
+	}
+
+	if counts["heavy"] == 0 || counts["light"] == 0 {
+		t.Fatalf("Expected both tokens to be selected, got %v", counts)
+	}
+
+	// With a 9:1 weighting over 2000 draws, the heavy token should dominate
+	// by a wide margin; a 4x threshold keeps the test robust against noise.
+	if counts["heavy"] < 4*counts["light"] {
+		t.Errorf("Expected heavy to be selected far more often than light, got %v", counts)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// transformSpec is a parsed "/c/{params}/" size/quality transform segment
+// from a pixiv image URL, e.g. "250x250_80_a2", "1200x1200_80_webp", or the
+// pixivision crop form "1200x630_q80_a2_g1_u1_icr0.093:0.014:0.938:0.758".
+//
+// Manipulating these structurally avoids the edge cases of regex surgery on
+// the raw segment.
+type transformSpec struct {
+	Width  int
+	Height int
+
+	// Quality is the compression quality; 0 when the segment carries none.
+	// qualityPrefixed records whether it was written as "q80" (pixivision
+	// style) rather than a bare "80", so round-trips stay byte-identical.
+	Quality         int
+	qualityPrefixed bool
+
+	// Format is a trailing output-format token such as "webp"; empty when
+	// the segment carries none.
+	Format string
+
+	// Crop holds the remaining parameters (e.g. "a2", "g1", "icr…") in
+	// their original order.
+	Crop []string
+}
+
+// parseTransformSpec parses the parameter portion of a "/c/{params}/" path
+// segment. It reports ok=false when the input doesn't look like a transform
+// spec (most importantly, when it lacks leading WxH dimensions).
+func parseTransformSpec(s string) (transformSpec, bool) {
+	var spec transformSpec
+
+	tokens := strings.Split(s, "_")
+
+	// The first token must be the WxH dimensions.
+	width, height, ok := parseDimensions(tokens[0])
+	if !ok {
+		return transformSpec{}, false
+	}
+
+	spec.Width = width
+	spec.Height = height
+
+	for _, token := range tokens[1:] {
+		if token == "" {
+			return transformSpec{}, false
+		}
+
+		// The first numeric token (optionally "q"-prefixed) is the quality.
+		if spec.Quality == 0 {
+			digits, prefixed := strings.CutPrefix(token, "q")
+			if quality, err := strconv.Atoi(digits); err == nil && quality > 0 {
+				spec.Quality = quality
+				spec.qualityPrefixed = prefixed
+
+				continue
+			}
+		}
+
+		// A trailing format token names the output encoding.
+		if token == "webp" {
+			spec.Format = token
+
+			continue
+		}
+
+		spec.Crop = append(spec.Crop, token)
+	}
+
+	return spec, true
+}
+
+// parseDimensions parses a "WxH" token into its two positive dimensions.
+func parseDimensions(token string) (width, height int, ok bool) {
+	rawWidth, rawHeight, found := strings.Cut(token, "x")
+	if !found {
+		return 0, 0, false
+	}
+
+	width, err := strconv.Atoi(rawWidth)
+	if err != nil || width <= 0 {
+		return 0, 0, false
+	}
+
+	height, err = strconv.Atoi(rawHeight)
+	if err != nil || height <= 0 {
+		return 0, 0, false
+	}
+
+	return width, height, true
+}
+
+// String renders the spec back into its path-segment form: dimensions,
+// then quality, then crop parameters, then the output format.
+func (spec transformSpec) String() string {
+	var builder strings.Builder
+
+	builder.WriteString(strconv.Itoa(spec.Width))
+	builder.WriteByte('x')
+	builder.WriteString(strconv.Itoa(spec.Height))
+
+	if spec.Quality > 0 {
+		builder.WriteByte('_')
+
+		if spec.qualityPrefixed {
+			builder.WriteByte('q')
+		}
+
+		builder.WriteString(strconv.Itoa(spec.Quality))
+	}
+
+	for _, param := range spec.Crop {
+		builder.WriteByte('_')
+		builder.WriteString(param)
+	}
+
+	if spec.Format != "" {
+		builder.WriteByte('_')
+		builder.WriteString(spec.Format)
+	}
+
+	return builder.String()
+}
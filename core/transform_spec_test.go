@@ -0,0 +1,87 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseTransformSpec_RoundTrip verifies that every observed transform
+// segment form survives a parse/build round-trip byte-identically.
+func TestParseTransformSpec_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	inputs := []string{
+		"600x600",
+		"250x250_80_a2",
+		"540x540_10_webp",
+		"1200x1200_80_webp",
+		"1200x630_q80_a2_g1_u1_icr0.093:0.014:0.938:0.758",
+		"768x1200_80",
+	}
+
+	for _, input := range inputs {
+		spec, ok := parseTransformSpec(input)
+		if !ok {
+			t.Errorf("parseTransformSpec(%q) failed to parse", input)
+
+			continue
+		}
+
+		if got := spec.String(); got != input {
+			t.Errorf("round-trip of %q = %q", input, got)
+		}
+	}
+}
+
+// TestParseTransformSpec_Fields verifies the structural decomposition of the
+// pixivision crop-parameter form.
+func TestParseTransformSpec_Fields(t *testing.T) {
+	t.Parallel()
+
+	spec, ok := parseTransformSpec("1200x630_q80_a2_g1_u1_icr0.093:0.014:0.938:0.758")
+	if !ok {
+		t.Fatal("parseTransformSpec() failed on the pixivision form")
+	}
+
+	if spec.Width != 1200 || spec.Height != 630 {
+		t.Errorf("dimensions = %dx%d, want 1200x630", spec.Width, spec.Height)
+	}
+
+	if spec.Quality != 80 || !spec.qualityPrefixed {
+		t.Errorf("quality = %d (prefixed %t), want a q-prefixed 80", spec.Quality, spec.qualityPrefixed)
+	}
+
+	if spec.Format != "" {
+		t.Errorf("format = %q, want none", spec.Format)
+	}
+
+	if want := []string{"a2", "g1", "u1", "icr0.093:0.014:0.938:0.758"}; !reflect.DeepEqual(spec.Crop, want) {
+		t.Errorf("crop = %v, want %v", spec.Crop, want)
+	}
+}
+
+// TestParseTransformSpec_Invalid verifies that non-transform segments are
+// rejected.
+func TestParseTransformSpec_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"", "w1200", "600x", "x600", "600x600_", "axb", "0x600"} {
+		if _, ok := parseTransformSpec(input); ok {
+			t.Errorf("parseTransformSpec(%q) = ok, want a parse failure", input)
+		}
+	}
+}
+
+// TestTransformSpecBuilder verifies that a spec built from fields renders the
+// canonical segment order.
+func TestTransformSpecBuilder(t *testing.T) {
+	t.Parallel()
+
+	spec := transformSpec{Width: 1200, Height: 1200, Quality: 80, Format: "webp"}
+	if got := spec.String(); got != "1200x1200_80_webp" {
+		t.Errorf("String() = %q, want %q", got, "1200x1200_80_webp")
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"net/http"
+
+	// Ugoira frames are served as JPEG or PNG.
+	_ "image/jpeg"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
+	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
+)
+
+// Output formats accepted by ConvertUgoira.
+const (
+	UgoiraFormatGIF  = "gif"
+	UgoiraFormatAPNG = "apng"
+)
+
+// ugoiraMaxFrames bounds server-side conversion; frame decoding and
+// re-encoding are CPU-heavy, and ugoira rarely exceed a few hundred frames.
+const ugoiraMaxFrames = 300
+
+var (
+	errUgoiraInvalidFormat = errors.New("unsupported ugoira output format")
+	errUgoiraNoFrames      = errors.New("ugoira has no frames")
+	errUgoiraTooManyFrames = errors.New("ugoira exceeds the frame cap")
+	errUgoiraMissingFrame  = errors.New("frame missing from ugoira zip")
+	errUgoiraFrameBounds   = errors.New("ugoira frames differ in size")
+)
+
+// UgoiraFrame describes a single frame of an ugoira animation.
+type UgoiraFrame struct {
+	File  string `json:"file"`
+	Delay int    `json:"delay"` // display duration in milliseconds
+}
+
+// UgoiraMeta defines the API structure of /ajax/illust/{id}/ugoira_meta.
+//
+// Src and OriginalSrc are upstream i.pximg.net zip URLs meant for
+// server-side fetching, so they are deliberately not rewritten through a
+// content proxy.
+type UgoiraMeta struct {
+	Src         string        `json:"src"`
+	OriginalSrc string        `json:"originalSrc"`
+	MimeType    string        `json:"mime_type"`
+	Frames      []UgoiraFrame `json:"frames"`
+}
+
+// GetUgoiraMeta retrieves the frame list, per-frame delays, and source zip
+// URLs for an ugoira work.
+func GetUgoiraMeta(r *http.Request, illustID string) (*UgoiraMeta, error) {
+	resp, err := requests.GetJSONBody(
+		r.Context(),
+		GetUgoiraMetaURL(illustID),
+		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+		r.Header,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta UgoiraMeta
+
+	if err := json.Unmarshal(resp, &meta); err != nil {
+		return nil, err
+	}
+
+	return &meta, nil
+}
+
+// ConvertUgoira assembles an animated image from a decoded ugoira zip,
+// honoring the per-frame delays in meta. Format is [UgoiraFormatGIF] or
+// [UgoiraFormatAPNG].
+//
+// Conversion decodes and re-encodes every frame, so callers should treat it
+// as optional and expensive; series beyond [ugoiraMaxFrames] are rejected.
+func ConvertUgoira(meta *UgoiraMeta, data []byte, format string) ([]byte, error) {
+	switch format {
+	case UgoiraFormatGIF, UgoiraFormatAPNG:
+	default:
+		return nil, fmt.Errorf("%w: %q", errUgoiraInvalidFormat, format)
+	}
+
+	if len(meta.Frames) == 0 {
+		return nil, errUgoiraNoFrames
+	}
+
+	if len(meta.Frames) > ugoiraMaxFrames {
+		return nil, fmt.Errorf("%w: %d frames", errUgoiraTooManyFrames, len(meta.Frames))
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("reading ugoira zip: %w", err)
+	}
+
+	frames, err := decodeUgoiraFrames(zipReader, meta.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == UgoiraFormatGIF {
+		return encodeUgoiraGIF(frames, meta.Frames)
+	}
+
+	return encodeUgoiraAPNG(frames, meta.Frames)
+}
+
+// decodeUgoiraFrames decodes each listed frame image from the zip, in meta
+// order.
+func decodeUgoiraFrames(zipReader *zip.Reader, frames []UgoiraFrame) ([]image.Image, error) {
+	files := make(map[string]*zip.File, len(zipReader.File))
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+
+	images := make([]image.Image, len(frames))
+
+	for i, frame := range frames {
+		file := files[frame.File]
+		if file == nil {
+			return nil, fmt.Errorf("%w: %q", errUgoiraMissingFrame, frame.File)
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening frame %q: %w", frame.File, err)
+		}
+
+		img, _, err := image.Decode(reader)
+
+		reader.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("decoding frame %q: %w", frame.File, err)
+		}
+
+		if i > 0 && img.Bounds() != images[0].Bounds() {
+			return nil, fmt.Errorf("%w: %q", errUgoiraFrameBounds, frame.File)
+		}
+
+		images[i] = img
+	}
+
+	return images, nil
+}
+
+// encodeUgoiraGIF quantizes the frames to a GIF animation. GIF delays are in
+// hundredths of a second, so sub-10ms precision is lost.
+func encodeUgoiraGIF(images []image.Image, frames []UgoiraFrame) ([]byte, error) {
+	anim := &gif.GIF{
+		Image: make([]*image.Paletted, len(images)),
+		Delay: make([]int, len(images)),
+	}
+
+	for i, img := range images {
+		paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, img.Bounds().Min)
+
+		anim.Image[i] = paletted
+		anim.Delay[i] = frames[i].Delay / 10
+	}
+
+	var buf bytes.Buffer
+
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, fmt.Errorf("encoding GIF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeUgoiraAPNG assembles an APNG by re-encoding every frame as PNG and
+// splicing the image data into one stream: acTL and fcTL chunks are
+// interleaved with the first frame's IDAT chunks and subsequent frames'
+// data repackaged as fdAT.
+func encodeUgoiraAPNG(images []image.Image, frames []UgoiraFrame) ([]byte, error) {
+	// Re-draw every frame into NRGBA so each per-frame PNG shares the first
+	// frame's IHDR parameters.
+	encoded := make([][]pngChunk, len(images))
+
+	for i, img := range images {
+		nrgba := image.NewNRGBA(img.Bounds())
+		draw.Draw(nrgba, img.Bounds(), img, img.Bounds().Min, draw.Src)
+
+		var buf bytes.Buffer
+
+		if err := png.Encode(&buf, nrgba); err != nil {
+			return nil, fmt.Errorf("encoding frame %d: %w", i, err)
+		}
+
+		chunks, err := splitPNGChunks(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("splitting frame %d: %w", i, err)
+		}
+
+		encoded[i] = chunks
+	}
+
+	var (
+		out bytes.Buffer
+		seq uint32
+	)
+
+	out.WriteString("\x89PNG\r\n\x1a\n")
+
+	for _, chunk := range encoded[0] {
+		if chunk.chunkType == "IHDR" {
+			writePNGChunk(&out, "IHDR", chunk.data)
+
+			break
+		}
+	}
+
+	// acTL: frame count, infinite loops.
+	acTL := make([]byte, 8)
+	binary.BigEndian.PutUint32(acTL, uint32(len(images)))
+	writePNGChunk(&out, "acTL", acTL)
+
+	bounds := images[0].Bounds()
+
+	for i, chunks := range encoded {
+		writePNGChunk(&out, "fcTL", ugoiraFrameControl(&seq, bounds, frames[i].Delay))
+
+		for _, chunk := range chunks {
+			if chunk.chunkType != "IDAT" {
+				continue
+			}
+
+			if i == 0 {
+				writePNGChunk(&out, "IDAT", chunk.data)
+
+				continue
+			}
+
+			fdAT := make([]byte, 4+len(chunk.data))
+			binary.BigEndian.PutUint32(fdAT, seq)
+			seq++
+
+			copy(fdAT[4:], chunk.data)
+			writePNGChunk(&out, "fdAT", fdAT)
+		}
+	}
+
+	writePNGChunk(&out, "IEND", nil)
+
+	return out.Bytes(), nil
+}
+
+// ugoiraFrameControl builds an fcTL chunk body covering the full canvas,
+// consuming sequence numbers from seq.
+func ugoiraFrameControl(seq *uint32, bounds image.Rectangle, delayMS int) []byte {
+	fcTL := make([]byte, 26)
+
+	binary.BigEndian.PutUint32(fcTL[0:], *seq)
+	*seq++
+
+	binary.BigEndian.PutUint32(fcTL[4:], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(fcTL[8:], uint32(bounds.Dy()))
+	// x/y offsets stay zero: every frame covers the whole canvas.
+	binary.BigEndian.PutUint16(fcTL[20:], uint16(delayMS)) //nolint:gosec // delays are small
+	binary.BigEndian.PutUint16(fcTL[22:], 1000)            // denominator: delays are milliseconds
+	// dispose_op and blend_op stay zero (none/source).
+
+	return fcTL
+}
+
+// pngChunk is one chunk of a PNG stream, without its length and CRC framing.
+type pngChunk struct {
+	chunkType string
+	data      []byte
+}
+
+// splitPNGChunks parses a PNG stream into its chunks, skipping the signature.
+func splitPNGChunks(data []byte) ([]pngChunk, error) {
+	const signatureLen = 8
+
+	if len(data) < signatureLen {
+		return nil, errors.New("short PNG stream")
+	}
+
+	var chunks []pngChunk
+
+	rest := data[signatureLen:]
+	for len(rest) >= 12 {
+		length := binary.BigEndian.Uint32(rest)
+		if uint32(len(rest)) < 12+length {
+			return nil, errors.New("truncated PNG chunk")
+		}
+
+		chunks = append(chunks, pngChunk{
+			chunkType: string(rest[4:8]),
+			data:      rest[8 : 8+length],
+		})
+
+		rest = rest[12+length:]
+	}
+
+	return chunks, nil
+}
+
+// writePNGChunk frames data as a PNG chunk with length and CRC.
+func writePNGChunk(out *bytes.Buffer, chunkType string, data []byte) {
+	var header [8]byte
+
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], chunkType)
+
+	out.Write(header[:])
+	out.Write(data)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	var sum [4]byte
+
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	out.Write(sum[:])
+}
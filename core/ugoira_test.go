@@ -0,0 +1,232 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+const ugoiraMetaFixture = `{"error":false,"body":{
+	"src":"https://i.pximg.net/img-zip-ugoira/img/2024/01/01/00/00/00/111000_ugoira600x600.zip",
+	"originalSrc":"https://i.pximg.net/img-zip-ugoira/img/2024/01/01/00/00/00/111000_ugoira1920x1080.zip",
+	"mime_type":"image/jpeg",
+	"frames":[
+		{"file":"000000.jpg","delay":100},
+		{"file":"000001.jpg","delay":200}
+	]
+}}`
+
+type ugoiraStubTransport struct{}
+
+func (ugoiraStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(ugoiraMetaFixture)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetUgoiraMeta(t *testing.T) {
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+
+	utils.HTTPClient.Transport = ugoiraStubTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	r := httptest.NewRequest(http.MethodGet, "/artworks/111000", nil)
+
+	meta, err := GetUgoiraMeta(r, "111000")
+	if err != nil {
+		t.Fatalf("GetUgoiraMeta() error: %v", err)
+	}
+
+	if !strings.HasSuffix(meta.OriginalSrc, "111000_ugoira1920x1080.zip") {
+		t.Errorf("OriginalSrc = %q, want the original zip URL", meta.OriginalSrc)
+	}
+
+	if meta.MimeType != "image/jpeg" {
+		t.Errorf("MimeType = %q, want %q", meta.MimeType, "image/jpeg")
+	}
+
+	want := []UgoiraFrame{
+		{File: "000000.jpg", Delay: 100},
+		{File: "000001.jpg", Delay: 200},
+	}
+
+	if len(meta.Frames) != len(want) {
+		t.Fatalf("Frames = %+v, want %+v", meta.Frames, want)
+	}
+
+	for i := range want {
+		if meta.Frames[i] != want[i] {
+			t.Errorf("Frames[%d] = %+v, want %+v", i, meta.Frames[i], want[i])
+		}
+	}
+}
+
+// buildUgoiraZip packs solid-colored PNG frames into an in-memory ugoira zip.
+func buildUgoiraZip(t *testing.T, files []string, colors []color.NRGBA) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := zip.NewWriter(&buf)
+
+	for i, name := range files {
+		frame := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+		for y := range 4 {
+			for x := range 4 {
+				frame.SetNRGBA(x, y, colors[i])
+			}
+		}
+
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("creating zip entry: %v", err)
+		}
+
+		if err := png.Encode(entry, frame); err != nil {
+			t.Fatalf("encoding frame: %v", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestConvertUgoiraGIF(t *testing.T) {
+	t.Parallel()
+
+	meta := &UgoiraMeta{
+		Frames: []UgoiraFrame{
+			{File: "000000.png", Delay: 100},
+			{File: "000001.png", Delay: 250},
+		},
+	}
+	data := buildUgoiraZip(t,
+		[]string{"000000.png", "000001.png"},
+		[]color.NRGBA{{R: 255, A: 255}, {B: 255, A: 255}})
+
+	out, err := ConvertUgoira(meta, data, UgoiraFormatGIF)
+	if err != nil {
+		t.Fatalf("ConvertUgoira() error: %v", err)
+	}
+
+	anim, err := gif.DecodeAll(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("DecodeAll() error: %v", err)
+	}
+
+	if len(anim.Image) != 2 {
+		t.Fatalf("GIF has %d frames, want 2", len(anim.Image))
+	}
+
+	if anim.Delay[0] != 10 || anim.Delay[1] != 25 {
+		t.Errorf("Delay = %v, want [10 25] (hundredths of a second)", anim.Delay)
+	}
+}
+
+func TestConvertUgoiraAPNG(t *testing.T) {
+	t.Parallel()
+
+	meta := &UgoiraMeta{
+		Frames: []UgoiraFrame{
+			{File: "000000.png", Delay: 100},
+			{File: "000001.png", Delay: 200},
+		},
+	}
+	data := buildUgoiraZip(t,
+		[]string{"000000.png", "000001.png"},
+		[]color.NRGBA{{R: 255, A: 255}, {G: 255, A: 255}})
+
+	out, err := ConvertUgoira(meta, data, UgoiraFormatAPNG)
+	if err != nil {
+		t.Fatalf("ConvertUgoira() error: %v", err)
+	}
+
+	// The stream must remain a valid PNG for decoders unaware of APNG.
+	if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+
+	for _, chunk := range []string{"acTL", "fcTL", "fdAT"} {
+		if !bytes.Contains(out, []byte(chunk)) {
+			t.Errorf("APNG output is missing a %s chunk", chunk)
+		}
+	}
+}
+
+func TestConvertUgoiraErrors(t *testing.T) {
+	t.Parallel()
+
+	data := buildUgoiraZip(t,
+		[]string{"000000.png"},
+		[]color.NRGBA{{R: 255, A: 255}})
+
+	cases := []struct {
+		name   string
+		meta   *UgoiraMeta
+		format string
+	}{
+		{
+			"unsupported format",
+			&UgoiraMeta{Frames: []UgoiraFrame{{File: "000000.png", Delay: 100}}},
+			"webm",
+		},
+		{
+			"no frames",
+			&UgoiraMeta{},
+			UgoiraFormatGIF,
+		},
+		{
+			"frame missing from zip",
+			&UgoiraMeta{Frames: []UgoiraFrame{{File: "999999.png", Delay: 100}}},
+			UgoiraFormatGIF,
+		},
+		{
+			"too many frames",
+			&UgoiraMeta{Frames: make([]UgoiraFrame, ugoiraMaxFrames+1)},
+			UgoiraFormatGIF,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ConvertUgoira(tc.meta, data, tc.format); err == nil {
+				t.Error("ConvertUgoira() = nil error, want an error")
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package untrusted
 import (
 	"net/http"
 	"net/url"
+	"strings"
 
 	"codeberg.org/pixivfe/pixivfe/v3/config"
 	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
@@ -17,6 +18,46 @@ func GetUserToken(r *http.Request) string {
 	return GetCookie(r, cookie.TokenCookie)
 }
 
+// GetUserID returns the pixiv user ID of the logged-in user, or an empty
+// string for anonymous requests.
+//
+// The 'UserID' cookie is set at login; as a fallback the ID is derived from
+// the token value, which pixiv prefixes with the user ID (e.g. "123456_...").
+func GetUserID(r *http.Request) string {
+	if id := GetCookie(r, cookie.UserIDCookie); id != "" {
+		return id
+	}
+
+	if id, _, found := strings.Cut(GetUserToken(r), "_"); found {
+		return id
+	}
+
+	return ""
+}
+
+// IsMinimalMode reports whether the request asked for the stripped-down,
+// low-bandwidth page variant.
+//
+// A 'minimal' query parameter takes precedence so individual pages can be
+// toggled via the URL; otherwise the 'MinimalMode' cookie applies.
+func IsMinimalMode(r *http.Request) bool {
+	switch r.URL.Query().Get("minimal") {
+	case "1", "true":
+		return true
+	case "0", "false":
+		return false
+	}
+
+	return GetCookie(r, cookie.MinimalModeCookie) == "true"
+}
+
+// PrefersOriginalImages reports whether the request opted out of master WebP
+// conversion via the 'OriginalImages' cookie, keeping pixiv's original
+// JPG/PNG paths (still routed through the configured image proxy).
+func PrefersOriginalImages(r *http.Request) bool {
+	return GetCookie(r, cookie.OriginalImagesCookie) == "true"
+}
+
 // GetImageProxy returns the content proxy URL for i.pximg.net content.
 //
 // The proxy URL is retrieved from cookies if available, otherwise falls back
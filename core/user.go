@@ -46,7 +46,7 @@ type userWorkCollections struct {
 // GetUserProfile retrieves the user profile, including counts, artworks/bookmarks, and social data.
 //
 // Goroutines are used to avoid blocking on network requests.
-func GetUserProfile(r *http.Request, id, category, mode string, currentPage int) (UserData, error) {
+func GetUserProfile(r *http.Request, id, category, mode, bookmarkTag string, currentPage int) (UserData, error) {
 	if _, err := strconv.Atoi(id); err != nil {
 		return UserData{}, err
 	}
@@ -134,7 +134,7 @@ func GetUserProfile(r *http.Request, id, category, mode string, currentPage int)
 	errGroup.Go(func() error {
 		var err error
 
-		works, err = getPopulatedWorks(r, id, category, currentPage, mode)
+		works, err = getPopulatedWorks(r, id, category, currentPage, mode, bookmarkTag)
 		if err != nil {
 			return err
 		}
@@ -189,6 +189,10 @@ func GetUserBasicInformation(r *http.Request, id string) (*User, error) {
 		return nil, err
 	}
 
+	if user.Avatar == "" {
+		user.Avatar = FallbackAvatarURL(r)
+	}
+
 	return user, nil
 }
 
@@ -196,7 +200,7 @@ func GetUserBasicInformation(r *http.Request, id string) (*User, error) {
 // frequently used tags and series data.
 //
 // Goroutines are used to avoid blocking on network requests.
-func getPopulatedWorks(r *http.Request, id, currentCategoryValue string, page int, mode string) (userWorkCollections, error) {
+func getPopulatedWorks(r *http.Request, id, currentCategoryValue string, page int, mode, bookmarkTag string) (userWorkCollections, error) {
 	works, err := fetchWorkIDsAndSeriesData(r, id, currentCategoryValue, page)
 	if err != nil {
 		return works, err
@@ -290,7 +294,7 @@ func getPopulatedWorks(r *http.Request, id, currentCategoryValue string, page in
 
 	// Bookmarks
 	g.Go(func() error {
-		bookmarks, total, err := populateIllustBookmarks(r, id, mode, page)
+		bookmarks, total, err := populateIllustBookmarks(r, id, mode, bookmarkTag, page)
 		if err != nil {
 			return err
 		}
@@ -302,6 +306,20 @@ func getPopulatedWorks(r *http.Request, id, currentCategoryValue string, page in
 		return nil
 	})
 
+	// Bookmark tags, for filtering the bookmark listing by the user's own tags
+	if currentCategoryValue == UserBookmarksCategory {
+		g.Go(func() error {
+			tags, err := GetUserBookmarkTags(r, id, mode)
+			if err != nil {
+				return err
+			}
+
+			works.Bookmarks.BookmarkTags = tags
+
+			return nil
+		})
+	}
+
 	// Following
 	if currentCategoryValue == UserFollowingCategory {
 		g.Go(func() error {
@@ -584,20 +602,70 @@ func populateNovelIDs(r *http.Request, id, ids string) ([]*NovelBrief, error) {
 	return novelPointers, nil
 }
 
+// coerceBookmarkMode normalizes a bookmark listing mode.
+//
+// "all" means the public listing. Private bookmarks are only visible to
+// their owner; force the public listing for everyone else so the upstream
+// query never asks for private entries on another user's behalf.
+func coerceBookmarkMode(r *http.Request, id, mode string) string {
+	if mode == "all" {
+		mode = "show"
+	}
+
+	if mode != "show" && untrusted.GetUserID(r) != id {
+		mode = "show"
+	}
+
+	return mode
+}
+
+// userBookmarkTagsResponse represents the response structure for
+// /ajax/user/{id}/illusts/bookmark/tags.
+type userBookmarkTagsResponse struct {
+	Public  []BookmarkTag `json:"public"`
+	Private []BookmarkTag `json:"private"`
+}
+
+// GetUserBookmarkTags fetches the tags a user has assigned to their
+// bookmarked artworks, each with its usage count. The mode selects the
+// public ("show"/"all") or private ("hide") tag set, with the same owner
+// coercion as the bookmark listing itself.
+func GetUserBookmarkTags(r *http.Request, userID, mode string) ([]BookmarkTag, error) {
+	mode = coerceBookmarkMode(r, userID, mode)
+
+	rawResp, err := requests.GetJSONBody(
+		r.Context(),
+		GetUserIllustBookmarkTagsURL(userID),
+		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+		r.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp userBookmarkTagsResponse
+	if err := json.Unmarshal(rawResp, &resp); err != nil {
+		return nil, err
+	}
+
+	if mode == "hide" {
+		return resp.Private, nil
+	}
+
+	return resp.Public, nil
+}
+
 // populateIllustBookmarks populates a []ArtworkBrief for a given set of bookmarked work IDs.
 //
 // This function cannot be neatly refactored to use getWorkIDs due to having
 // a different API response structure.
-func populateIllustBookmarks(r *http.Request, id, mode string, page int) ([]ArtworkItem, int, error) {
+func populateIllustBookmarks(r *http.Request, id, mode, tag string, page int) ([]ArtworkItem, int, error) {
 	page--
 
-	if mode == "all" {
-		mode = "show"
-	}
+	mode = coerceBookmarkMode(r, id, mode)
 
 	rawResp, err := requests.GetJSONBody(
 		r.Context(),
-		GetUserIllustBookmarksURL(id, mode, page),
+		GetUserIllustBookmarksURL(id, tag, mode, page),
 		map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
 		r.Header)
 	if err != nil {
@@ -622,7 +690,7 @@ func populateIllustBookmarks(r *http.Request, id, mode string, page int) ([]Artw
 				ID:        "#",
 				Title:     "Deleted or private",
 				UserName:  "Deleted or private",
-				Thumbnail: "https://s.pximg.net/common/images/limit_unknown_360.png",
+				Thumbnail: FallbackThumbnailURL(r),
 			}
 
 			continue
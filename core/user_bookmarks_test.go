@@ -0,0 +1,208 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// recordingBookmarksTransport serves an empty bookmarks payload and records
+// the URLs it was asked for.
+type recordingBookmarksTransport struct {
+	urls []string
+}
+
+func (t *recordingBookmarksTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, req.URL.String())
+
+	const body = `{"error":false,"body":{"works":[],"total":0}}`
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func setupBookmarksTest(t *testing.T) *recordingBookmarksTransport {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+	})
+
+	config.Global.SetDefaults()
+
+	transport := &recordingBookmarksTransport{}
+	utils.HTTPClient.Transport = transport
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	return transport
+}
+
+func TestPopulateIllustBookmarks_NonOwnerForcedPublic(t *testing.T) {
+	transport := setupBookmarksTest(t)
+
+	// A logged-in user browsing someone else's bookmarks.
+	r := httptest.NewRequest(http.MethodGet, "/users/123/bookmarks", nil)
+	r.AddCookie(&http.Cookie{Name: string(cookie.UserIDCookie), Value: "999"})
+
+	if _, _, err := populateIllustBookmarks(r, "123", "hide", "", 1); err != nil {
+		t.Fatalf("populateIllustBookmarks() error: %v", err)
+	}
+
+	if len(transport.urls) != 1 {
+		t.Fatalf("upstream requests = %d, want 1", len(transport.urls))
+	}
+
+	if strings.Contains(transport.urls[0], "rest=hide") {
+		t.Errorf("non-owner request asked for private bookmarks: %s", transport.urls[0])
+	}
+
+	if !strings.Contains(transport.urls[0], "rest=show") {
+		t.Errorf("non-owner request did not force the public listing: %s", transport.urls[0])
+	}
+}
+
+func TestPopulateIllustBookmarks_OwnerKeepsPrivateMode(t *testing.T) {
+	transport := setupBookmarksTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/users/123/bookmarks", nil)
+	r.AddCookie(&http.Cookie{Name: string(cookie.UserIDCookie), Value: "123"})
+
+	if _, _, err := populateIllustBookmarks(r, "123", "hide", "", 1); err != nil {
+		t.Fatalf("populateIllustBookmarks() error: %v", err)
+	}
+
+	if len(transport.urls) != 1 {
+		t.Fatalf("upstream requests = %d, want 1", len(transport.urls))
+	}
+
+	if !strings.Contains(transport.urls[0], "rest=hide") {
+		t.Errorf("owner request lost its private mode: %s", transport.urls[0])
+	}
+}
+
+func TestPopulateIllustBookmarks_AnonymousForcedPublic(t *testing.T) {
+	transport := setupBookmarksTest(t)
+
+	// No UserID cookie and no token: the owner check can never pass.
+	r := httptest.NewRequest(http.MethodGet, "/users/123/bookmarks", nil)
+
+	if _, _, err := populateIllustBookmarks(r, "123", "hide", "", 1); err != nil {
+		t.Fatalf("populateIllustBookmarks() error: %v", err)
+	}
+
+	if len(transport.urls) != 1 {
+		t.Fatalf("upstream requests = %d, want 1", len(transport.urls))
+	}
+
+	if !strings.Contains(transport.urls[0], "rest=show") {
+		t.Errorf("anonymous request did not force the public listing: %s", transport.urls[0])
+	}
+}
+
+// bookmarkTagsTransport serves a bookmark-tags fixture alongside the empty
+// bookmarks payload, recording requested URLs.
+type bookmarkTagsTransport struct {
+	urls []string
+}
+
+func (t *bookmarkTagsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, req.URL.String())
+
+	body := `{"error":false,"body":{"works":[],"total":0}}`
+	if strings.Contains(req.URL.Path, "/bookmark/tags") {
+		body = `{"error":false,"body":{
+			"public":[{"tag":"未分類","cnt":12},{"tag":"fanart","cnt":3}],
+			"private":[{"tag":"secret","cnt":2}]
+		}}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetUserBookmarkTags(t *testing.T) {
+	setupBookmarksTest(t)
+
+	transport := &bookmarkTagsTransport{}
+	utils.HTTPClient.Transport = transport
+
+	// The owner reading their private tag set.
+	r := httptest.NewRequest(http.MethodGet, "/users/123/bookmarks", nil)
+	r.AddCookie(&http.Cookie{Name: string(cookie.UserIDCookie), Value: "123"})
+
+	private, err := GetUserBookmarkTags(r, "123", "hide")
+	if err != nil {
+		t.Fatalf("GetUserBookmarkTags() error: %v", err)
+	}
+
+	if len(private) != 1 || private[0].Name != "secret" || private[0].Count != 2 {
+		t.Errorf("GetUserBookmarkTags(hide) = %+v, want the private tag set", private)
+	}
+
+	// The public set, with counts intact.
+	public, err := GetUserBookmarkTags(r, "123", "all")
+	if err != nil {
+		t.Fatalf("GetUserBookmarkTags() error: %v", err)
+	}
+
+	if len(public) != 2 || public[0].Name != "未分類" || public[0].Count != 12 {
+		t.Errorf("GetUserBookmarkTags(all) = %+v, want the public tag set", public)
+	}
+
+	// A non-owner asking for private tags gets the public set instead.
+	other := httptest.NewRequest(http.MethodGet, "/users/123/bookmarks", nil)
+	other.AddCookie(&http.Cookie{Name: string(cookie.UserIDCookie), Value: "999"})
+
+	coerced, err := GetUserBookmarkTags(other, "123", "hide")
+	if err != nil {
+		t.Fatalf("GetUserBookmarkTags() error: %v", err)
+	}
+
+	if len(coerced) != 2 || coerced[0].Name != "未分類" {
+		t.Errorf("GetUserBookmarkTags(hide) for a non-owner = %+v, want the public tag set", coerced)
+	}
+}
+
+func TestPopulateIllustBookmarks_TagFilter(t *testing.T) {
+	transport := setupBookmarksTest(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/users/123/bookmarks", nil)
+
+	if _, _, err := populateIllustBookmarks(r, "123", "show", "fanart", 1); err != nil {
+		t.Fatalf("populateIllustBookmarks() error: %v", err)
+	}
+
+	if len(transport.urls) != 1 {
+		t.Fatalf("upstream requests = %d, want 1", len(transport.urls))
+	}
+
+	if !strings.Contains(transport.urls[0], "tag=fanart") {
+		t.Errorf("bookmark listing did not carry the selected tag: %s", transport.urls[0])
+	}
+}
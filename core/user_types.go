@@ -148,6 +148,13 @@ type workCategory struct {
 	MangaSeries  []IllustSeries // Populated for the "manga" category
 	NovelSeries  []NovelSeries  // Populated for the "novels" category
 	Users        []*User        // Populated for the "following" and "followers" category
+	BookmarkTags []BookmarkTag  // Populated for the "bookmarks" category
+}
+
+// BookmarkTag is one of the user's own bookmark tags with its usage count.
+type BookmarkTag struct {
+	Name  string `json:"tag"`
+	Count int    `json:"cnt"`
 }
 
 // personalField represents a key/value pair for a personal field.
@@ -31,7 +31,7 @@ require (
 	github.com/rs/zerolog v1.34.0
 
 	// Assertions for tests
-	github.com/stretchr/testify v1.10.0
+	github.com/stretchr/testify v1.11.1
 
 	// Easy querying of pixiv API JSON responses and validation - removal is TODO
 	github.com/tidwall/gjson v1.18.0
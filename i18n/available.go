@@ -0,0 +1,174 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/leonelquinteros/gotext"
+	"golang.org/x/text/language"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/server/assets"
+)
+
+// templateKey identifies one message in the pot template.
+type templateKey struct {
+	msgctxt string
+	msgid   string
+}
+
+var (
+	// templateOnce guards the one-time parse of the embedded pot template,
+	// which is immutable at runtime.
+	templateOnce sync.Once
+
+	// templateMsgs holds the message keys of the pot template. They are the
+	// coverage denominator: a locale's coverage is the fraction of these keys
+	// it translates.
+	templateMsgs []templateKey
+)
+
+// templateKeys parses the embedded pot template on first use and returns its
+// message keys.
+func templateKeys() []templateKey {
+	templateOnce.Do(func() {
+		pot := gotext.NewPoFS(assets.FS)
+		pot.ParseFile(path.Join("po", poDomain+".pot"))
+
+		dom := pot.GetDomain()
+
+		for msgid := range dom.GetTranslations() {
+			if msgid == "" {
+				continue // gettext header entry
+			}
+
+			templateMsgs = append(templateMsgs, templateKey{msgid: msgid})
+		}
+
+		for msgctxt, entries := range dom.GetCtxTranslations() {
+			for msgid := range entries {
+				if msgid == "" {
+					continue
+				}
+
+				templateMsgs = append(templateMsgs, templateKey{msgctxt: msgctxt, msgid: msgid})
+			}
+		}
+	})
+
+	return templateMsgs
+}
+
+// Coverage returns the fraction (0 to 1) of template messages translated by
+// the locale for the given canonical tag. The base locale is the source
+// language and always reports full coverage; unknown tags report zero.
+func Coverage(tag string) float64 {
+	if tag == BaseLocale {
+		return 1
+	}
+
+	cat := currentCatalog.Load()
+	if cat == nil {
+		return 0
+	}
+
+	loc, ok := cat.localesByTag[tag]
+	if !ok {
+		return 0
+	}
+
+	keys := templateKeys()
+	if len(keys) == 0 {
+		return 1
+	}
+
+	translated := 0
+
+	for _, key := range keys {
+		if key.msgctxt != "" {
+			if loc.IsTranslatedDC(poDomain, key.msgid, key.msgctxt) {
+				translated++
+			}
+
+			continue
+		}
+
+		if loc.IsTranslatedD(poDomain, key.msgid) {
+			translated++
+		}
+	}
+
+	return float64(translated) / float64(len(keys))
+}
+
+// AvailableLocales returns the loaded locales to offer in the language
+// switcher, applying the operator's allowlist, denylist, and minimum coverage
+// threshold from the Internationalization configuration. With none configured
+// every loaded locale is returned. The base locale is always included, as it
+// remains the default fallback regardless of filtering.
+//
+// Setup must be called successfully before using AvailableLocales; otherwise
+// it panics.
+func AvailableLocales() []language.Tag {
+	cfg := config.Global.Internationalization
+
+	allow := normalizeTags(cfg.LocaleAllowlist)
+	deny := normalizeTags(cfg.LocaleDenylist)
+
+	loaded := Languages()
+	out := make([]language.Tag, 0, len(loaded))
+
+	for _, t := range loaded {
+		canonical := t.String()
+
+		if canonical == BaseLocale {
+			out = append(out, t)
+
+			continue
+		}
+
+		if len(allow) > 0 && !allow[canonical] {
+			continue
+		}
+
+		if deny[canonical] {
+			continue
+		}
+
+		if cfg.MinLocaleCoverage > 0 && Coverage(canonical) < cfg.MinLocaleCoverage {
+			continue
+		}
+
+		out = append(out, t)
+	}
+
+	return out
+}
+
+// normalizeTags canonicalises operator-supplied locale strings ("pt_BR",
+// "zh-hans") to the BCP 47 form used for matching. Unparseable entries are
+// kept verbatim so an exact match still works.
+func normalizeTags(raw []string) map[string]bool {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	out := make(map[string]bool, len(raw))
+
+	for _, s := range raw {
+		t, err := language.Parse(strings.ReplaceAll(s, "_", "-"))
+		if err != nil {
+			out[s] = true
+
+			continue
+		}
+
+		out[t.String()] = true
+	}
+
+	return out
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// setTestTemplateKeys replaces the parsed pot template for the duration of a
+// test so coverage is computed against a known set of message keys.
+func setTestTemplateKeys(t *testing.T, keys []templateKey) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		templateOnce = sync.Once{}
+		templateMsgs = nil
+	})
+
+	templateOnce = sync.Once{}
+	templateMsgs = keys
+	templateOnce.Do(func() {}) // mark the template as already parsed
+}
+
+// installCoverageLocales loads a complete "de" catalog and a partial "pt"
+// catalog (two of three template messages translated).
+func installCoverageLocales(t *testing.T) {
+	t.Helper()
+
+	setTestTemplateKeys(t, []templateKey{
+		{msgid: "Follow"},
+		{msgctxt: "button", msgid: "Close"},
+		{msgid: "Goodbye"},
+	})
+
+	installTestLocales(t, map[string]string{
+		"de": `msgid "Follow"
+msgstr "Folgen"
+
+msgctxt "button"
+msgid "Close"
+msgstr "Schließen"
+
+msgid "Goodbye"
+msgstr "Tschüss"
+`,
+		"pt": `msgid "Follow"
+msgstr "Seguir"
+
+msgctxt "button"
+msgid "Close"
+msgstr "Fechar"
+
+msgid "Goodbye"
+msgstr ""
+`,
+	})
+}
+
+func TestCoverage(t *testing.T) {
+	installCoverageLocales(t)
+
+	if got := Coverage("de"); got != 1 {
+		t.Errorf("Coverage(de) = %v, want 1", got)
+	}
+
+	if got, want := Coverage("pt"), 2.0/3.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Coverage(pt) = %v, want %v", got, want)
+	}
+
+	// The base locale is the source language.
+	if got := Coverage(BaseLocale); got != 1 {
+		t.Errorf("Coverage(%s) = %v, want 1", BaseLocale, got)
+	}
+
+	if got := Coverage("xx"); got != 0 {
+		t.Errorf("Coverage(xx) = %v, want 0 for an unknown tag", got)
+	}
+}
+
+func TestAvailableLocales(t *testing.T) {
+	installCoverageLocales(t)
+
+	origI18n := config.Global.Internationalization
+
+	t.Cleanup(func() {
+		config.Global.Internationalization = origI18n
+	})
+
+	available := func() []string {
+		tags := AvailableLocales()
+
+		out := make([]string, len(tags))
+		for i, tag := range tags {
+			out[i] = tag.String()
+		}
+
+		return out
+	}
+
+	reset := func() {
+		config.Global.Internationalization = origI18n
+	}
+
+	// No configuration exposes every loaded locale.
+	reset()
+
+	if got, want := available(), []string{"de", "en", "pt"}; !slices.Equal(got, want) {
+		t.Errorf("AvailableLocales() = %v, want %v", got, want)
+	}
+
+	// The allowlist restricts to the listed tags; entries are canonicalised
+	// and the base locale stays regardless.
+	reset()
+	config.Global.Internationalization.LocaleAllowlist = []string{"PT"}
+
+	if got, want := available(), []string{"en", "pt"}; !slices.Equal(got, want) {
+		t.Errorf("AvailableLocales() with allowlist = %v, want %v", got, want)
+	}
+
+	// The denylist hides the listed tags.
+	reset()
+	config.Global.Internationalization.LocaleDenylist = []string{"pt"}
+
+	if got, want := available(), []string{"de", "en"}; !slices.Equal(got, want) {
+		t.Errorf("AvailableLocales() with denylist = %v, want %v", got, want)
+	}
+
+	// A coverage threshold hides incomplete locales.
+	reset()
+	config.Global.Internationalization.MinLocaleCoverage = 0.9
+
+	if got, want := available(), []string{"de", "en"}; !slices.Equal(got, want) {
+		t.Errorf("AvailableLocales() with coverage threshold = %v, want %v", got, want)
+	}
+}
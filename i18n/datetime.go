@@ -0,0 +1,112 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Date formatting styles accepted by FormatDate.
+const (
+	DateStyleLong  = "long"
+	DateStyleShort = "short"
+)
+
+// isoDateLayout is the ISO 8601 layout used when a locale has no date data.
+const isoDateLayout = "2006-01-02"
+
+// timeNow wraps time.Now so tests can pin the reference time for
+// relative formatting.
+var timeNow = time.Now
+
+// dateFormat holds the Go reference layouts a locale uses for each style.
+type dateFormat struct {
+	long  string
+	short string
+}
+
+// baseDateFormat is the formatting used for BaseLocale.
+var baseDateFormat = dateFormat{long: "January 2, 2006", short: "Jan 2, 2006"}
+
+// dateFormats maps base language codes to their date layouts.
+//
+// Only layouts expressible with Go's time package are listed (numeric or CJK
+// forms); locales that would need localised month names fall back to ISO 8601
+// rather than showing English month names in the wrong order.
+var dateFormats = map[string]dateFormat{
+	"en":  baseDateFormat,
+	"fil": baseDateFormat,
+	"ja":  {long: "2006年1月2日", short: "2006/01/02"},
+	"ko":  {long: "2006년 1월 2일", short: "2006. 1. 2."},
+	"uk":  {long: "02.01.2006", short: "02.01.2006"},
+	"vi":  {long: "02/01/2006", short: "02/01/2006"},
+	"zh":  {long: "2006年1月2日", short: "2006/01/02"},
+}
+
+// FormatDate formats t using the date conventions of the locale stored in ctx.
+// The style is one of [DateStyleLong] or [DateStyleShort]; unknown styles are
+// treated as short. Locales without date data fall back to ISO 8601.
+func FormatDate(ctx context.Context, t time.Time, style string) string {
+	format, ok := dateFormatForTag(TagFrom(ctx))
+	if !ok {
+		return t.Format(isoDateLayout)
+	}
+
+	if style == DateStyleLong {
+		return t.Format(format.long)
+	}
+
+	return t.Format(format.short)
+}
+
+// FormatRelative renders the time elapsed since t as a translatable phrase
+// such as "3 days ago", using the TrN plural machinery so translators control
+// the wording per locale. Times less than a minute ago (or in the future)
+// render as "just now"; anything older than a year falls back to FormatDate.
+func FormatRelative(ctx context.Context, t time.Time) string {
+	elapsed := timeNow().Sub(t)
+
+	switch {
+	case elapsed < time.Minute:
+		return Tr(ctx, "just now")
+	case elapsed < time.Hour:
+		n := int(elapsed / time.Minute)
+
+		return TrN(ctx, "{{.Count}} minute ago", "{{.Count}} minutes ago", n, "Count", n)
+	case elapsed < 24*time.Hour:
+		n := int(elapsed / time.Hour)
+
+		return TrN(ctx, "{{.Count}} hour ago", "{{.Count}} hours ago", n, "Count", n)
+	case elapsed < 7*24*time.Hour:
+		n := int(elapsed / (24 * time.Hour))
+
+		return TrN(ctx, "{{.Count}} day ago", "{{.Count}} days ago", n, "Count", n)
+	case elapsed < 30*24*time.Hour:
+		n := int(elapsed / (7 * 24 * time.Hour))
+
+		return TrN(ctx, "{{.Count}} week ago", "{{.Count}} weeks ago", n, "Count", n)
+	case elapsed < 365*24*time.Hour:
+		n := int(elapsed / (30 * 24 * time.Hour))
+
+		return TrN(ctx, "{{.Count}} month ago", "{{.Count}} months ago", n, "Count", n)
+	default:
+		return FormatDate(ctx, t, DateStyleLong)
+	}
+}
+
+// dateFormatForTag resolves the dateFormat for a language tag by its base
+// language. The second return value is false when the locale has no date data.
+func dateFormatForTag(tag language.Tag) (dateFormat, bool) {
+	base, confidence := tag.Base()
+	if confidence == language.No {
+		return baseDateFormat, true
+	}
+
+	format, ok := dateFormats[base.String()]
+
+	return format, ok
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+func TestFormatDate(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2025, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		locale string
+		style  string
+		want   string
+	}{
+		{"en long", "en", DateStyleLong, "March 7, 2025"},
+		{"en short", "en", DateStyleShort, "Mar 7, 2025"},
+		{"ja long", "ja", DateStyleLong, "2025年3月7日"},
+		{"ja short", "ja", DateStyleShort, "2025/03/07"},
+		{"uk short", "uk", DateStyleShort, "07.03.2025"},
+		{"Unknown style treated as short", "en", "medium", "Mar 7, 2025"},
+		{"Locale without date data falls back to ISO 8601", "sw", DateStyleLong, "2025-03-07"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := WithTag(t.Context(), language.Make(tc.locale))
+
+			if got := FormatDate(ctx, date, tc.style); got != tc.want {
+				t.Errorf("FormatDate(%q, %q) = %q, want %q", tc.locale, tc.style, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatRelative(t *testing.T) {
+	now := time.Date(2025, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	origTimeNow := timeNow
+	timeNow = func() time.Time { return now }
+
+	t.Cleanup(func() { timeNow = origTimeNow })
+
+	cases := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"Just now", now.Add(-30 * time.Second), "just now"},
+		{"Future time", now.Add(time.Hour), "just now"},
+		{"Minutes", now.Add(-5 * time.Minute), "5 minutes ago"},
+		{"Just under one hour", now.Add(-time.Hour + time.Second), "59 minutes ago"},
+		{"Just over one hour", now.Add(-time.Hour - time.Second), "1 hour ago"},
+		{"Days", now.Add(-3 * 24 * time.Hour), "3 days ago"},
+		{"Weeks", now.Add(-10 * 24 * time.Hour), "1 week ago"},
+		{"Months", now.Add(-70 * 24 * time.Hour), "2 months ago"},
+		{"Over a year falls back to FormatDate", now.Add(-400 * 24 * time.Hour), "February 1, 2024"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FormatRelative(t.Context(), tc.t); got != tc.want {
+				t.Errorf("FormatRelative(%v) = %q, want %q", tc.t, got, tc.want)
+			}
+		})
+	}
+}
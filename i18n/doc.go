@@ -40,8 +40,9 @@ to any of the Tr functions:
 
 	i18n.Tr(ctx, "Welcome, {{.Name}}!", "Name", user.Name)
 
-Numbers are not localised automatically; convert values to strings
-yourself if you need locale-specific presentation.
+Numbers are not localised automatically by the Tr functions; use TrNum or
+TrNNum to format numeric substitutions with the locale's grouping and
+decimal separators, or FormatNum to format a value directly.
 
 # Content tag translations
 
@@ -0,0 +1,108 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// exportCache holds serialized per-locale subsets keyed by the sorted key
+// list, so repeated renders of the same page don't re-marshal. It is cleared
+// whenever a new catalog is published.
+var exportCache sync.Map // key: locale + "\x1f" + keys, value: []byte
+
+// exportKeySeparator is the gettext EOT convention separating a
+// disambiguating context from its msgid in an export key.
+const exportKeySeparator = "\x04"
+
+// ExportCatalog returns a JSON object mapping each requested msgid to its
+// resolved translation for the locale in ctx, so a template can embed the
+// subset of strings a page's client-side JS needs.
+//
+// Keys may carry a disambiguating context as "context\x04msgid". Plural
+// entries are emitted as an array holding every plural form from the catalog;
+// simple entries are emitted as strings. Untranslated keys fall back to the
+// msgid itself. Template placeholders are left intact for the client to
+// substitute.
+func ExportCatalog(ctx context.Context, keys []string) ([]byte, error) {
+	_, matched := resolveLocale(TagFrom(ctx))
+
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	cacheKey := matched.String() + "\x1f" + strings.Join(sorted, "\x1f")
+	if data, ok := exportCache.Load(cacheKey); ok {
+		return data.([]byte), nil
+	}
+
+	chain := localeChain(matched)
+
+	out := make(map[string]any, len(sorted))
+	for _, key := range sorted {
+		msgctxt, msgid, _ := strings.Cut(key, exportKeySeparator)
+		if msgid == "" {
+			msgctxt, msgid = "", msgctxt
+		}
+
+		out[key] = exportEntry(chain, msgctxt, msgid)
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+
+	exportCache.Store(cacheKey, data)
+
+	return data, nil
+}
+
+// exportEntry resolves one msgid against the locale chain, returning either a
+// plain string or, for plural entries, the full list of plural forms.
+func exportEntry(chain []*gotext.Locale, msgctxt, msgid string) any {
+	for _, loc := range chain {
+		if loc == nil {
+			continue
+		}
+
+		if msgctxt != "" {
+			if loc.IsTranslatedDC(poDomain, msgid, msgctxt) {
+				return loc.GetDC(poDomain, msgid, msgctxt)
+			}
+
+			continue
+		}
+
+		trans, ok := loc.GetTranslations()[msgid]
+		if !ok {
+			continue
+		}
+
+		if trans.PluralID != "" {
+			forms := make([]string, 0, len(trans.Trs))
+			for i := 0; i < len(trans.Trs); i++ {
+				forms = append(forms, trans.Trs[i])
+			}
+
+			if len(forms) > 0 {
+				return forms
+			}
+
+			continue
+		}
+
+		if loc.IsTranslatedD(poDomain, msgid) {
+			return loc.GetD(poDomain, msgid, noSprintfVars...)
+		}
+	}
+
+	return msgid
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestExportCatalog(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt": `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+msgid "Follow"
+msgstr "Seguir"
+
+msgctxt "button"
+msgid "Close"
+msgstr "Fechar"
+
+msgid "{{.Count}} bookmark"
+msgid_plural "{{.Count}} bookmarks"
+msgstr[0] "{{.Count}} marcador"
+msgstr[1] "{{.Count}} marcadores"
+
+msgid "Say \"hello\""
+msgstr "Diga \"olá\" <b>"
+`,
+	})
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	data, err := ExportCatalog(ctx, []string{
+		"Follow",
+		"button\x04Close",
+		"{{.Count}} bookmark",
+		`Say "hello"`,
+		"Untranslated key",
+	})
+	if err != nil {
+		t.Fatalf("ExportCatalog() error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("ExportCatalog() produced invalid JSON: %v\n%s", err, data)
+	}
+
+	want := map[string]any{
+		"Follow":          "Seguir",
+		"button\x04Close": "Fechar",
+		// Plural entries carry every form, placeholders intact.
+		"{{.Count}} bookmark": []any{"{{.Count}} marcador", "{{.Count}} marcadores"},
+		// Quotes survive the round-trip escaped correctly.
+		`Say "hello"`: `Diga "olá" <b>`,
+		// Untranslated keys fall back to the msgid.
+		"Untranslated key": "Untranslated key",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExportCatalog() = %v, want %v", got, want)
+	}
+}
+
+func TestExportCatalog_Cached(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt": `msgid "Follow"
+msgstr "Seguir"
+`,
+	})
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	// Key order must not affect the cache entry or the result.
+	first, err := ExportCatalog(ctx, []string{"Follow", "Other"})
+	if err != nil {
+		t.Fatalf("ExportCatalog() error: %v", err)
+	}
+
+	second, err := ExportCatalog(ctx, []string{"Other", "Follow"})
+	if err != nil {
+		t.Fatalf("ExportCatalog() error: %v", err)
+	}
+
+	if &first[0] != &second[0] {
+		t.Error("ExportCatalog() should serve the cached serialization for equal key sets")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"sort"
+
+	"github.com/leonelquinteros/gotext"
+	"golang.org/x/text/language"
+)
+
+// storeCatalog derives a fallback chain for every locale and atomically
+// publishes the resulting catalog for lookups.
+func storeCatalog(localesByTag map[string]*gotext.Locale) {
+	fallbackChains := make(map[string][]*gotext.Locale, len(localesByTag))
+
+	for canonical := range localesByTag {
+		fallbackChains[canonical] = deriveFallbackChain(localesByTag, canonical)
+	}
+
+	currentCatalog.Store(&catalog{
+		localesByTag:   localesByTag,
+		fallbackChains: fallbackChains,
+	})
+
+	// Serialized exports are derived from the replaced catalog.
+	exportCache.Clear()
+}
+
+// deriveFallbackChain returns the lookup order for a canonical tag:
+// the locale itself, then its base language if loaded (so "pt-BR" falls back
+// to "pt"), then any sibling locales sharing the base language in sorted
+// order (so "pt-BR" also falls back to "pt-PT" when no plain "pt" exists).
+// The msgid (or the strict-mode wrapper) remains the final fallback and is
+// not part of the chain.
+func deriveFallbackChain(localesByTag map[string]*gotext.Locale, canonical string) []*gotext.Locale {
+	chain := []*gotext.Locale{localesByTag[canonical]}
+
+	base, confidence := language.Make(canonical).Base()
+	if confidence == language.No {
+		return chain
+	}
+
+	baseStr := base.String()
+
+	// The chain for a region code defaults to its base language.
+	if baseStr != canonical {
+		if loc, ok := localesByTag[baseStr]; ok {
+			chain = append(chain, loc)
+		}
+	}
+
+	// Then any other loaded locales of the same base language.
+	var siblings []string
+
+	for other := range localesByTag {
+		if other == canonical || other == baseStr {
+			continue
+		}
+
+		otherBase, otherConfidence := language.Make(other).Base()
+		if otherConfidence != language.No && otherBase.String() == baseStr {
+			siblings = append(siblings, other)
+		}
+	}
+
+	sort.Strings(siblings)
+
+	for _, sibling := range siblings {
+		chain = append(chain, localesByTag[sibling])
+	}
+
+	return chain
+}
+
+// localeChain returns the fallback chain for a matched tag, or a single-locale
+// chain when no derived chain exists (e.g. before Setup has run).
+func localeChain(matched language.Tag) []*gotext.Locale {
+	cat := currentCatalog.Load()
+	if cat == nil {
+		return nil
+	}
+
+	if chain, ok := cat.fallbackChains[matched.String()]; ok {
+		return chain
+	}
+
+	if loc := cat.localesByTag[matched.String()]; loc != nil {
+		return []*gotext.Locale{loc}
+	}
+
+	return nil
+}
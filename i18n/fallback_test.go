@@ -0,0 +1,142 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/leonelquinteros/gotext"
+	"github.com/rs/zerolog"
+	"golang.org/x/text/language"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// installTestLocales replaces the loaded locales with an in-memory set and
+// restores the previous package state when the test completes.
+func installTestLocales(t *testing.T, catalogs map[string]string) {
+	t.Helper()
+
+	origCatalog := currentCatalog.Load()
+	origTags := supportedTags
+	origMatcher := matcher
+
+	t.Cleanup(func() {
+		currentCatalog.Store(origCatalog)
+		supportedTags = origTags
+		matcher = origMatcher
+	})
+
+	// Without a Plural-Forms header gotext cannot resolve singular lookups.
+	const poHeader = `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+`
+
+	localesByTag := make(map[string]*gotext.Locale, len(catalogs))
+	tagsList := []language.Tag{baseTag}
+
+	for canonical, poSource := range catalogs {
+		// Catalogs may carry their own header, e.g. to declare a different
+		// Plural-Forms rule.
+		if !strings.HasPrefix(poSource, `msgid ""`) {
+			poSource = poHeader + poSource
+		}
+
+		po := gotext.NewPo()
+		po.Parse([]byte(poSource))
+
+		loc := gotext.NewLocale("", canonical)
+		loc.AddTranslator(poDomain, po)
+
+		localesByTag[canonical] = loc
+		tagsList = append(tagsList, language.Make(canonical))
+	}
+
+	matcher = language.NewMatcher(tagsList)
+	supportedTags = tagsList
+
+	storeCatalog(localesByTag)
+}
+
+func TestFallbackChain_SiblingLocale(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		// pt-BR is loaded but does not translate "Hello".
+		"pt-BR": `msgid "Goodbye"
+msgstr "Tchau"
+`,
+		// pt-PT does.
+		"pt-PT": `msgid "Hello"
+msgstr "Olá"
+`,
+	})
+
+	ctx := WithTag(t.Context(), language.Make("pt-BR"))
+
+	// A pt-BR miss should resolve from the pt-PT sibling catalog.
+	if got := Tr(ctx, "Hello"); got != "Olá" {
+		t.Errorf("Tr() = %q, want %q", got, "Olá")
+	}
+
+	// A direct pt-BR hit must not be shadowed by the chain.
+	if got := Tr(ctx, "Goodbye"); got != "Tchau" {
+		t.Errorf("Tr() = %q, want %q", got, "Tchau")
+	}
+}
+
+func TestFallbackChain_BaseLanguage(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt": `msgid "Hello"
+msgstr "Olá"
+`,
+		"pt-BR": ``,
+	})
+
+	ctx := WithTag(t.Context(), language.Make("pt-BR"))
+
+	// The chain for a region code defaults to its base language.
+	if got := Tr(ctx, "Hello"); got != "Olá" {
+		t.Errorf("Tr() = %q, want %q", got, "Olá")
+	}
+}
+
+func TestFallbackChain_TotalMissLogsOnce(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt-BR": ``,
+		"pt-PT": ``,
+	})
+
+	origStrict := config.Global.Internationalization.StrictMissingKeys
+	origLogger := Logger
+
+	t.Cleanup(func() {
+		config.Global.Internationalization.StrictMissingKeys = origStrict
+		Logger = origLogger
+		missingKeyOnce.Clear()
+	})
+
+	config.Global.Internationalization.StrictMissingKeys = true
+	missingKeyOnce.Clear()
+
+	var buf bytes.Buffer
+
+	Logger = zerolog.New(&buf)
+
+	ctx := WithTag(t.Context(), language.Make("pt-BR"))
+
+	// The whole chain misses, so strict mode wraps the msgid visibly.
+	if got := Tr(ctx, "Untranslated"); got != "⟦Untranslated⟧" {
+		t.Errorf("Tr() = %q, want %q", got, "⟦Untranslated⟧")
+	}
+
+	// A second lookup must not log again.
+	Tr(ctx, "Untranslated")
+
+	if got := strings.Count(buf.String(), "Missing i18n translation"); got != 1 {
+		t.Errorf("Expected exactly 1 missing-translation log, got %d: %s", got, buf.String())
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MissingKey identifies a template message a locale does not yet translate.
+type MissingKey struct {
+	Msgctxt string
+	Msgid   string
+}
+
+// MissingKeys returns the template messages the locale for the given
+// canonical tag does not translate, sorted by context then msgid for stable
+// output. The base locale is the source language and never reports missing
+// keys; an unknown tag reports every template message.
+func MissingKeys(tag string) []MissingKey {
+	if tag == BaseLocale {
+		return nil
+	}
+
+	cat := currentCatalog.Load()
+
+	var missing []MissingKey
+
+	for _, key := range templateKeys() {
+		if cat != nil {
+			if loc, ok := cat.localesByTag[tag]; ok {
+				if key.msgctxt != "" {
+					if loc.IsTranslatedDC(poDomain, key.msgid, key.msgctxt) {
+						continue
+					}
+				} else if loc.IsTranslatedD(poDomain, key.msgid) {
+					continue
+				}
+			}
+		}
+
+		missing = append(missing, MissingKey{Msgctxt: key.msgctxt, Msgid: key.msgid})
+	}
+
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Msgctxt != missing[j].Msgctxt {
+			return missing[i].Msgctxt < missing[j].Msgctxt
+		}
+
+		return missing[i].Msgid < missing[j].Msgid
+	})
+
+	return missing
+}
+
+// MissingPoFragment renders the locale's untranslated template messages as a
+// gettext .po fragment with empty msgstr entries, ready for a translator to
+// fill in and merge into the locale's catalog.
+func MissingPoFragment(tag string) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "# Untranslated messages for locale %q.\n", tag)
+	fmt.Fprintf(&buf, "# Coverage: %.0f%% of template messages.\n\n", Coverage(tag)*100)
+
+	for _, key := range MissingKeys(tag) {
+		if key.Msgctxt != "" {
+			fmt.Fprintf(&buf, "msgctxt %s\n", poQuote(key.Msgctxt))
+		}
+
+		fmt.Fprintf(&buf, "msgid %s\n", poQuote(key.Msgid))
+		fmt.Fprintf(&buf, "msgstr \"\"\n\n")
+	}
+
+	return buf.Bytes()
+}
+
+// poQuote renders s as a quoted gettext string literal.
+func poQuote(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\t", `\t`,
+	)
+
+	return `"` + replacer.Replace(s) + `"`
+}
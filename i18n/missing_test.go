@@ -0,0 +1,62 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMissingKeys(t *testing.T) {
+	installCoverageLocales(t)
+
+	missing := MissingKeys("pt")
+	if len(missing) != 1 {
+		t.Fatalf("MissingKeys(pt) = %v, want the single untranslated message", missing)
+	}
+
+	if missing[0].Msgid != "Goodbye" || missing[0].Msgctxt != "" {
+		t.Errorf("MissingKeys(pt)[0] = %+v, want msgid Goodbye without context", missing[0])
+	}
+
+	if got := MissingKeys("de"); len(got) != 0 {
+		t.Errorf("MissingKeys(de) = %v, want none for a complete catalog", got)
+	}
+
+	// The base locale is the source language.
+	if got := MissingKeys(BaseLocale); len(got) != 0 {
+		t.Errorf("MissingKeys(%s) = %v, want none", BaseLocale, got)
+	}
+
+	// An unknown tag misses everything.
+	if got := MissingKeys("xx"); len(got) != 3 {
+		t.Errorf("MissingKeys(xx) = %v, want every template message", got)
+	}
+}
+
+func TestMissingPoFragment(t *testing.T) {
+	installCoverageLocales(t)
+
+	fragment := string(MissingPoFragment("pt"))
+
+	if !strings.Contains(fragment, "msgid \"Goodbye\"\nmsgstr \"\"\n") {
+		t.Errorf("fragment missing the untranslated entry:\n%s", fragment)
+	}
+
+	if strings.Contains(fragment, `msgid "Follow"`) {
+		t.Errorf("fragment contains an already-translated entry:\n%s", fragment)
+	}
+
+	// Context and special characters survive as valid po syntax.
+	setTestTemplateKeys(t, []templateKey{
+		{msgctxt: "button", msgid: "Say \"hi\"\nnow"},
+	})
+
+	fragment = string(MissingPoFragment("pt"))
+
+	if !strings.Contains(fragment, `msgctxt "button"`) ||
+		!strings.Contains(fragment, `msgid "Say \"hi\"\nnow"`) {
+		t.Errorf("fragment did not escape the context entry:\n%s", fragment)
+	}
+}
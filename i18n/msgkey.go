@@ -35,3 +35,41 @@ func (s MsgKey) Render(ctx context.Context, w io.Writer) error {
 
 	return err
 }
+
+// TrAll translates each key, resolving the locale chain once for the whole
+// batch instead of once per call. The result matches calling [MsgKey.Tr] on
+// each key individually; use it for enum-style lists such as genres or
+// prefectures on hot list pages.
+func TrAll(ctx context.Context, keys []MsgKey) []string {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, matched := resolveLocale(TagFrom(ctx))
+	chain := localeChain(matched)
+
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = trResolved(matched, chain, string(key), nil)
+	}
+
+	return out
+}
+
+// TrMap is the map form of [TrAll]: it translates each value of keys while
+// preserving the associated key, e.g. a genre ID to its translated label.
+func TrMap(ctx context.Context, keys map[string]MsgKey) map[string]string {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, matched := resolveLocale(TagFrom(ctx))
+	chain := localeChain(matched)
+
+	out := make(map[string]string, len(keys))
+	for name, key := range keys {
+		out[name] = trResolved(matched, chain, string(key), nil)
+	}
+
+	return out
+}
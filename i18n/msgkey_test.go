@@ -4,11 +4,67 @@
 package i18n
 
 import (
+	"maps"
 	"testing"
 
 	"github.com/a-h/templ"
+	"golang.org/x/text/language"
 )
 
 func TestMsgKeyAsComponent(t *testing.T) {
 	var _ templ.Component = MsgKey("foo")
 }
+
+func TestTrAll_MatchesIndividualTr(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		// "Horror" is deliberately left untranslated.
+		"pt": `msgid "Romance"
+msgstr "Romance (pt)"
+
+msgid "Mystery"
+msgstr "Mistério"
+`,
+	})
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	keys := []MsgKey{"Romance", "Mystery", "Horror"}
+
+	got := TrAll(ctx, keys)
+	if len(got) != len(keys) {
+		t.Fatalf("TrAll() returned %d results, want %d", len(got), len(keys))
+	}
+
+	for i, key := range keys {
+		if want := key.Tr(ctx); got[i] != want {
+			t.Errorf("TrAll()[%d] = %q, want %q (Tr of %q)", i, got[i], want, key)
+		}
+	}
+
+	if TrAll(ctx, nil) != nil {
+		t.Error("TrAll(nil) should return nil")
+	}
+}
+
+func TestTrMap_MatchesIndividualTr(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt": `msgid "Romance"
+msgstr "Romance (pt)"
+`,
+	})
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	keys := map[string]MsgKey{"1": "Romance", "5": "Horror"}
+
+	got := TrMap(ctx, keys)
+
+	want := make(map[string]string, len(keys))
+	for name, key := range keys {
+		want[name] = key.Tr(ctx)
+	}
+
+	if !maps.Equal(got, want) {
+		t.Errorf("TrMap() = %v, want %v", got, want)
+	}
+}
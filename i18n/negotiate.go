@@ -0,0 +1,59 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"golang.org/x/text/language"
+)
+
+// NegotiateLocale picks the best locale for an RFC 7231 Accept-Language header
+// value from the given set of available locales.
+//
+// The header is parsed with q-value ordering and wildcard support, and matching
+// uses language-tag matching so regional variants fall back to their base
+// language (e.g. "en-GB" matches an available "en").
+//
+// The first entry of available acts as the default and is returned when the
+// header is empty, unparsable, or matches nothing. If available is empty,
+// BaseLocale is returned.
+func NegotiateLocale(acceptLanguage string, available []string) string {
+	if len(available) == 0 {
+		return BaseLocale
+	}
+
+	// Keep the parseable locales alongside their tags so the matcher index
+	// maps back to the caller's strings.
+	tags := make([]language.Tag, 0, len(available))
+	locales := make([]string, 0, len(available))
+
+	for _, locale := range available {
+		t, err := language.Parse(locale)
+		if err != nil {
+			continue
+		}
+
+		tags = append(tags, t)
+		locales = append(locales, locale)
+	}
+
+	if len(tags) == 0 {
+		return BaseLocale
+	}
+
+	m := language.NewMatcher(tags)
+
+	// ParseAcceptLanguage handles q-values (sorted by weight) and the
+	// wildcard; an empty or malformed header yields no preferences.
+	desired, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(desired) == 0 {
+		return locales[0]
+	}
+
+	_, index, confidence := m.Match(desired...)
+	if confidence == language.No {
+		return locales[0]
+	}
+
+	return locales[index]
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import "testing"
+
+func TestNegotiateLocale(t *testing.T) {
+	t.Parallel()
+
+	available := []string{"en", "ja", "pt-BR", "zh-Hans"}
+
+	cases := []struct {
+		name           string
+		acceptLanguage string
+		available      []string
+		want           string
+	}{
+		{"Exact match", "ja", available, "ja"},
+		{"Q-value ordering", "fr;q=0.8, ja;q=0.9", available, "ja"},
+		{"Q-value ordering reversed", "ja;q=0.3, pt-BR;q=0.9", available, "pt-BR"},
+		{"Region fallback", "en-GB", available, "en"},
+		{"Region fallback with q-values", "en-GB;q=1.0, ja;q=0.5", available, "en"},
+		{"Wildcard", "*", available, "en"},
+		{"Wildcard after unavailable language", "tlh, *;q=0.1", available, "en"},
+		{"Empty header returns default", "", available, "en"},
+		{"Malformed header returns default", ";;;", available, "en"},
+		{"Unavailable language returns default", "ko", available, "en"},
+		{"Default is first available", "", []string{"ja", "en"}, "ja"},
+		{"Empty available returns base locale", "ja", nil, "en"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := NegotiateLocale(tc.acceptLanguage, tc.available); got != tc.want {
+				t.Errorf("NegotiateLocale(%q, %v) = %q, want %q",
+					tc.acceptLanguage, tc.available, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// numberFormat describes how a locale renders numbers.
+type numberFormat struct {
+	group   string // separator inserted between 3-digit groups; empty disables grouping
+	decimal string // decimal separator
+}
+
+// baseNumberFormat is the formatting used for BaseLocale and for any locale
+// without an entry in numberFormats.
+var baseNumberFormat = numberFormat{group: ",", decimal: "."}
+
+// numberFormats maps base language codes (the first subtag of a BCP 47 tag)
+// to their number formatting rules. Locales not listed here fall back to
+// baseNumberFormat.
+var numberFormats = map[string]numberFormat{
+	"de":  {group: ".", decimal: ","},
+	"fil": {group: ",", decimal: "."},
+	"fr":  {group: "\u00a0", decimal: ","},
+	"ja":  {group: ",", decimal: "."},
+	"ko":  {group: ",", decimal: "."},
+	"uk":  {group: "\u00a0", decimal: ","},
+	"vi":  {group: ".", decimal: ","},
+	"zh":  {group: ",", decimal: "."},
+}
+
+// FormatNum formats a numeric value using the grouping and decimal separators
+// of the locale stored in ctx. Supported kinds are the built-in integer,
+// unsigned integer, and float types; any other value yields an empty string.
+func FormatNum(ctx context.Context, v any) string {
+	return formatNum(TagFrom(ctx), v)
+}
+
+// TrNum behaves like [Tr], but numeric values among the key-value pairs are
+// localised with [FormatNum] before placeholder substitution:
+//
+//	i18n.TrNum(ctx, "{{.Count}} views", "Count", n)
+//
+// Non-numeric values pass through untouched, so the substitution pipeline is
+// byte-for-byte identical to Tr when no numbers are supplied.
+func TrNum(ctx context.Context, msgid string, kv ...any) string {
+	return Tr(ctx, msgid, localizeNumbers(ctx, kv)...)
+}
+
+// TrNNum is the plural counterpart of [TrNum], behaving like [TrN] with
+// numeric values localised before substitution.
+func TrNNum(ctx context.Context, singular, plural string, n int, kv ...any) string {
+	return TrN(ctx, singular, plural, n, localizeNumbers(ctx, kv)...)
+}
+
+// localizeNumbers returns a copy of kv with numeric values (the odd-indexed
+// elements) replaced by their locale-formatted string representation.
+func localizeNumbers(ctx context.Context, kv []any) []any {
+	tag := TagFrom(ctx)
+
+	out := make([]any, len(kv))
+	for i, v := range kv {
+		if i%2 == 0 || !isNumeric(v) {
+			out[i] = v
+			continue
+		}
+
+		out[i] = formatNum(tag, v)
+	}
+
+	return out
+}
+
+// isNumeric reports whether v is one of the built-in numeric types
+// handled by formatNum.
+func isNumeric(v any) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// formatNum renders v for the given language tag.
+func formatNum(tag language.Tag, v any) string {
+	format := formatForTag(tag)
+
+	switch n := v.(type) {
+	case int:
+		return formatDecimalString(strconv.FormatInt(int64(n), 10), format)
+	case int8:
+		return formatDecimalString(strconv.FormatInt(int64(n), 10), format)
+	case int16:
+		return formatDecimalString(strconv.FormatInt(int64(n), 10), format)
+	case int32:
+		return formatDecimalString(strconv.FormatInt(int64(n), 10), format)
+	case int64:
+		return formatDecimalString(strconv.FormatInt(n, 10), format)
+	case uint:
+		return formatDecimalString(strconv.FormatUint(uint64(n), 10), format)
+	case uint8:
+		return formatDecimalString(strconv.FormatUint(uint64(n), 10), format)
+	case uint16:
+		return formatDecimalString(strconv.FormatUint(uint64(n), 10), format)
+	case uint32:
+		return formatDecimalString(strconv.FormatUint(uint64(n), 10), format)
+	case uint64:
+		return formatDecimalString(strconv.FormatUint(n, 10), format)
+	case float32:
+		return formatDecimalString(strconv.FormatFloat(float64(n), 'f', -1, 32), format)
+	case float64:
+		return formatDecimalString(strconv.FormatFloat(n, 'f', -1, 64), format)
+	default:
+		// Not a number; leave the caller's value untouched.
+		return ""
+	}
+}
+
+// formatForTag resolves the numberFormat for a language tag by its base
+// language, falling back to baseNumberFormat.
+func formatForTag(tag language.Tag) numberFormat {
+	base, confidence := tag.Base()
+	if confidence == language.No {
+		return baseNumberFormat
+	}
+
+	if format, ok := numberFormats[base.String()]; ok {
+		return format
+	}
+
+	return baseNumberFormat
+}
+
+// formatDecimalString applies grouping and decimal separators to a plain
+// base-10 number string as produced by strconv (optional leading minus,
+// '.' as the decimal separator).
+func formatDecimalString(s string, format numberFormat) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign = "-"
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+
+	if format.group != "" && len(intPart) > 3 {
+		var b strings.Builder
+
+		lead := len(intPart) % 3
+		if lead > 0 {
+			b.WriteString(intPart[:lead])
+		}
+
+		for i := lead; i < len(intPart); i += 3 {
+			if b.Len() > 0 {
+				b.WriteString(format.group)
+			}
+
+			b.WriteString(intPart[i : i+3])
+		}
+
+		intPart = b.String()
+	}
+
+	if hasFrac {
+		return sign + intPart + format.decimal + fracPart
+	}
+
+	return sign + intPart
+}
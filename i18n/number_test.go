@@ -0,0 +1,71 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestFormatNum(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		locale string
+		value  any
+		want   string
+	}{
+		{"en-US integer grouping", "en-US", 1234567, "1,234,567"},
+		{"de-DE integer grouping", "de-DE", 1234567, "1.234.567"},
+		{"uk integer grouping", "uk", 1234567, "1\u00a0234\u00a0567"},
+		{"vi integer grouping", "vi", 1234567, "1.234.567"},
+		{"en-US float", "en-US", 1234567.89, "1,234,567.89"},
+		{"de-DE float", "de-DE", 1234567.89, "1.234.567,89"},
+		{"Negative number", "de-DE", -1234567, "-1.234.567"},
+		{"No grouping below four digits", "en-US", 999, "999"},
+		{"Unknown locale falls back to base", "tlh", 1234567, "1,234,567"},
+		{"Non-numeric value", "en-US", "already a string", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := WithTag(t.Context(), language.Make(tc.locale))
+
+			if got := FormatNum(ctx, tc.value); got != tc.want {
+				t.Errorf("FormatNum(%q, %v) = %q, want %q", tc.locale, tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatDecimalString_NoGrouping(t *testing.T) {
+	t.Parallel()
+
+	// A format with an empty group separator must leave the integer part
+	// ungrouped while still localising the decimal separator.
+	format := numberFormat{group: "", decimal: ","}
+
+	if got := formatDecimalString("1234567.89", format); got != "1234567,89" {
+		t.Errorf("formatDecimalString() = %q, want %q", got, "1234567,89")
+	}
+}
+
+func TestTrNum(t *testing.T) {
+	t.Parallel()
+
+	deCtx := WithTag(t.Context(), language.Make("de-DE"))
+
+	if got := TrNum(deCtx, "{{.Count}} views", "Count", 1234567); got != "1.234.567 views" {
+		t.Errorf("TrNum() = %q, want %q", got, "1.234.567 views")
+	}
+
+	// Non-numeric substitutions must pass through byte-for-byte, matching Tr.
+	if got := TrNum(deCtx, "Welcome, {{.Name}}!", "Name", "Alice"); got != "Welcome, Alice!" {
+		t.Errorf("TrNum() = %q, want %q", got, "Welcome, Alice!")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// pluralProbeLimit bounds the search for an integer representative of a CLDR
+// plural category; every CLDR cardinal rule for integers is decided well
+// below this value.
+const pluralProbeLimit = 1000
+
+// pluralLookupN converts a float count into the integer fed to gotext's
+// plural lookup, so the catalog's own Plural-Forms expression keeps choosing
+// the msgstr index.
+//
+// Integral values pass through unchanged. Gettext plural expressions are
+// defined over integers only, so for fractional values we compute the CLDR
+// plural category of the number in the matched locale and substitute the
+// smallest non-negative integer in the same category.
+func pluralLookupN(ctx context.Context, n float64) int {
+	if n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32 {
+		return int(n)
+	}
+
+	_, matched := resolveLocale(TagFrom(ctx))
+
+	if k, ok := probeForm(matched, cardinalForm(matched, n)); ok {
+		return k
+	}
+
+	// No integer shares the category: e.g. Russian fractions are "other"
+	// while its integers split into one/few/many. Fractions there read like
+	// the few form ("1,5 часа"), so prefer it before a generic plural.
+	for _, form := range []plural.Form{plural.Few, plural.Many} {
+		if k, ok := probeForm(matched, form); ok {
+			return k
+		}
+	}
+
+	return 2
+}
+
+// probeForm returns the smallest non-negative integer whose CLDR cardinal
+// category for t equals want.
+func probeForm(t language.Tag, want plural.Form) (int, bool) {
+	for k := 0; k <= pluralProbeLimit; k++ {
+		if plural.Cardinal.MatchPlural(t, k, 0, 0, 0, 0) == want {
+			return k, true
+		}
+	}
+
+	return 0, false
+}
+
+// cardinalForm computes the CLDR cardinal category of n for locale t, taking
+// the visible fraction digits into account.
+func cardinalForm(t language.Tag, n float64) plural.Form {
+	s := strconv.FormatFloat(math.Abs(n), 'f', -1, 64)
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	// MatchPlural accepts operands modulo 10,000,000.
+	if len(intPart) > 7 {
+		intPart = intPart[len(intPart)-7:]
+	}
+
+	if len(fracPart) > 7 {
+		fracPart = fracPart[:7]
+	}
+
+	i, _ := strconv.Atoi(intPart)
+	f, _ := strconv.Atoi(fracPart)
+
+	return plural.Cardinal.MatchPlural(t, i, len(fracPart), len(fracPart), f, f)
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+// ruPluralPo declares the classic three-form Russian rule so the tests
+// exercise msgstr indices beyond singular/plural.
+const ruPluralPo = `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "{{.Count}} bookmark"
+msgid_plural "{{.Count}} bookmarks"
+msgstr[0] "{{.Count}} закладка"
+msgstr[1] "{{.Count}} закладки"
+msgstr[2] "{{.Count}} закладок"
+`
+
+func TestTrN_RussianPluralForms(t *testing.T) {
+	installTestLocales(t, map[string]string{"ru": ruPluralPo})
+
+	ctx := WithTag(t.Context(), language.Make("ru"))
+
+	cases := []struct {
+		n    int
+		want string
+	}{
+		{1, "1 закладка"},
+		{21, "21 закладка"},
+		{2, "2 закладки"},     // few
+		{104, "104 закладки"}, // few
+		{5, "5 закладок"},     // many
+		{11, "11 закладок"},   // many
+		{0, "0 закладок"},     // many
+	}
+
+	for _, tc := range cases {
+		got := TrN(ctx, "{{.Count}} bookmark", "{{.Count}} bookmarks", tc.n, "Count", tc.n)
+		if got != tc.want {
+			t.Errorf("TrN(n=%d) = %q, want %q", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestTrNf(t *testing.T) {
+	installTestLocales(t, map[string]string{"ru": ruPluralPo})
+
+	ruCtx := WithTag(t.Context(), language.Make("ru"))
+
+	// Integral floats behave exactly like TrN.
+	if got := TrNf(ruCtx, "{{.Count}} bookmark", "{{.Count}} bookmarks", 1.0, "Count", 1); got != "1 закладка" {
+		t.Errorf("TrNf(1.0) = %q, want %q", got, "1 закладка")
+	}
+
+	// Russian fractions read like the few form: "1,5 закладки".
+	if got := TrNf(ruCtx, "{{.Count}} bookmark", "{{.Count}} bookmarks", 1.5, "Count", "1,5"); got != "1,5 закладки" {
+		t.Errorf("TrNf(1.5) = %q, want %q", got, "1,5 закладки")
+	}
+
+	// English fractions take the plural msgid, even below one.
+	enCtx := WithTag(t.Context(), language.Make("en"))
+
+	if got := TrNf(enCtx, "{{.Count}} hour", "{{.Count}} hours", 0.5, "Count", "0.5"); got != "0.5 hours" {
+		t.Errorf("TrNf(0.5) = %q, want %q", got, "0.5 hours")
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// pseudoRunes maps ASCII letters to accented counterparts so pseudolocalized
+// text stays readable while looking obviously transformed.
+var pseudoRunes = map[rune]rune{
+	'a': 'á', 'c': 'ç', 'e': 'é', 'g': 'ĝ', 'i': 'î', 'n': 'ñ',
+	'o': 'ô', 's': 'ŝ', 'u': 'û', 'w': 'ŵ', 'y': 'ý', 'z': 'ž',
+	'A': 'Á', 'C': 'Ç', 'E': 'É', 'G': 'Ĝ', 'I': 'Î', 'N': 'Ñ',
+	'O': 'Ô', 'S': 'Ŝ', 'U': 'Û', 'W': 'Ŵ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// pseudolocalizeEnabled reports whether all translations should be
+// pseudolocalized for layout testing.
+func pseudolocalizeEnabled() bool {
+	return config.Global.Development.Pseudolocalize
+}
+
+// pseudolocalize wraps s in ⟦...⟧ brackets, accents its ASCII letters, and
+// appends padding roughly a third of the original length so truncation and
+// overflow issues show up. Unlike the StrictMissingKeys wrapping this applies
+// to every key, translated or not.
+//
+// Template placeholders ({{...}}) are copied through untouched so
+// substitution still works on the transformed text.
+func pseudolocalize(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "{{") {
+			end := strings.Index(s[i:], "}}")
+			if end < 0 {
+				b.WriteString(s[i:])
+
+				break
+			}
+
+			b.WriteString(s[i : i+end+2])
+			i += end + 2
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if repl, ok := pseudoRunes[r]; ok {
+			r = repl
+		}
+
+		b.WriteRune(r)
+
+		i += size
+	}
+
+	padding := strings.Repeat("≈", (utf8.RuneCountInString(s)+2)/3)
+
+	return "⟦" + b.String() + padding + "⟧"
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+func TestPseudolocalize_PreservesPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	got := pseudolocalize("Hello {{.Name}}, welcome!")
+
+	if !strings.HasPrefix(got, "⟦") || !strings.HasSuffix(got, "⟧") {
+		t.Errorf("pseudolocalize() = %q, want bracket wrapping", got)
+	}
+
+	if !strings.Contains(got, "{{.Name}}") {
+		t.Errorf("pseudolocalize() = %q, placeholder must survive intact", got)
+	}
+
+	// Surrounding text must be visibly transformed and padded.
+	if !strings.Contains(got, "Héllô") || !strings.Contains(got, "≈") {
+		t.Errorf("pseudolocalize() = %q, want accented text with padding", got)
+	}
+}
+
+func TestTr_PseudoMode(t *testing.T) {
+	installTestLocales(t, map[string]string{"pt": ``})
+
+	origPseudo := config.Global.Development.Pseudolocalize
+
+	t.Cleanup(func() {
+		config.Global.Development.Pseudolocalize = origPseudo
+	})
+
+	config.Global.Development.Pseudolocalize = true
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	got := Tr(ctx, "Bookmarked by {{.Count}} users", "Count", 42)
+
+	// Substitution still works on the transformed text.
+	if !strings.Contains(got, "42") {
+		t.Errorf("Tr() = %q, want substituted count", got)
+	}
+
+	if !strings.HasPrefix(got, "⟦") || !strings.HasSuffix(got, "⟧") {
+		t.Errorf("Tr() = %q, want pseudo wrapping regardless of translation state", got)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+	"golang.org/x/text/language"
+)
+
+// DefaultLocaleWatchInterval is the polling interval used by WatchLocales
+// when no other interval is configured.
+const DefaultLocaleWatchInterval = 2 * time.Second
+
+// fileStamp records the attributes used to detect that a .po file changed
+// between polls.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// localeWatcher polls a directory of .po files and swaps the in-memory
+// catalog for a locale when its file changes.
+type localeWatcher struct {
+	dir    string
+	stamps map[string]fileStamp
+}
+
+// newLocaleWatcher creates a watcher for dir and primes its file stamps from
+// the directory's current contents, so only subsequent edits trigger reloads.
+func newLocaleWatcher(dir string) *localeWatcher {
+	w := &localeWatcher{dir: dir}
+	w.stamps = w.currentStamps()
+
+	return w
+}
+
+// WatchLocales polls dir for changes to .po files and reloads the affected
+// locales in place, replacing the published catalog atomically so concurrent
+// Tr calls are never dropped. It is intended for development instances where
+// translators iterate on catalogs; the embedded catalogs loaded by Setup are
+// left untouched until a file actually changes.
+//
+// New locale files are loaded too, but are only reachable once the language
+// matcher knows about them, i.e. after a restart.
+//
+// WatchLocales returns a function that stops the watcher.
+func WatchLocales(dir string, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultLocaleWatchInterval
+	}
+
+	w := newLocaleWatcher(dir)
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	Logger.Info().
+		Str("dir", dir).
+		Dur("interval", interval).
+		Msg("Watching locale directory for changes")
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.scan()
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// scan compares the directory's .po files against the recorded stamps and
+// reloads any file that appeared or changed.
+func (w *localeWatcher) scan() {
+	for fileName, stamp := range w.currentStamps() {
+		if previous, ok := w.stamps[fileName]; ok &&
+			previous.modTime.Equal(stamp.modTime) && previous.size == stamp.size {
+			continue
+		}
+
+		w.stamps[fileName] = stamp
+
+		w.reload(fileName)
+	}
+}
+
+// currentStamps returns the stamps for the .po files currently in the
+// watched directory. The template file, "pixivfe.pot", is ignored.
+func (w *localeWatcher) currentStamps() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		Logger.Warn().Err(err).Str("dir", w.dir).Msg("Failed to read locale directory")
+
+		return stamps
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".po") {
+			continue
+		}
+
+		if entry.Name() == poDomain+".pot" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		stamps[entry.Name()] = fileStamp{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+	}
+
+	return stamps
+}
+
+// reload parses fileName from the watched directory and publishes a catalog
+// in which only that locale is replaced.
+func (w *localeWatcher) reload(fileName string) {
+	localeName := strings.TrimSuffix(fileName, ".po")
+
+	t, err := language.Parse(strings.ReplaceAll(localeName, "_", "-"))
+	if err != nil {
+		Logger.Warn().Err(err).Str("file", fileName).Msg("Skipping invalid locale file")
+
+		return
+	}
+
+	canonical := t.String()
+
+	data, err := os.ReadFile(filepath.Join(w.dir, fileName))
+	if err != nil {
+		Logger.Warn().Err(err).Str("file", fileName).Msg("Failed to read locale file")
+
+		return
+	}
+
+	po := gotext.NewPo()
+	po.Parse(data)
+
+	loc := gotext.NewLocale("", canonical) // Base path is unused when manually adding translators.
+	loc.AddTranslator(poDomain, po)
+
+	// Clone the published locale map so in-flight lookups keep reading a
+	// consistent catalog; the swap itself is a single atomic store.
+	previous := currentCatalog.Load()
+
+	localesByTag := make(map[string]*gotext.Locale)
+	if previous != nil {
+		for tag, loaded := range previous.localesByTag {
+			localesByTag[tag] = loaded
+		}
+	}
+
+	localesByTag[canonical] = loc
+
+	storeCatalog(localesByTag)
+
+	Logger.Info().
+		Str("locale", canonical).
+		Str("file", fileName).
+		Msg("Reloaded locale")
+}
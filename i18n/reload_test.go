@@ -0,0 +1,161 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Without a Plural-Forms header gotext cannot resolve singular lookups.
+const testPoHeader = `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+`
+
+// writeTestPo writes a .po catalog translating "Hello" to translation and
+// bumps the file's modification time so a watcher poll sees the change.
+func writeTestPo(t *testing.T, path, translation string, modTime time.Time) {
+	t.Helper()
+
+	content := testPoHeader + `msgid "Hello"
+msgstr "` + translation + `"
+`
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes() error: %v", err)
+	}
+}
+
+func TestLocaleWatcher_Reload(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt": `msgid "Hello"
+msgstr "Olá"
+`,
+	})
+
+	dir := t.TempDir()
+	poPath := filepath.Join(dir, "pt.po")
+
+	writeTestPo(t, poPath, "Olá", time.Now().Add(-time.Minute))
+
+	w := newLocaleWatcher(dir)
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	if got := Tr(ctx, "Hello"); got != "Olá" {
+		t.Fatalf("Tr() = %q, want %q before reload", got, "Olá")
+	}
+
+	// An unchanged file must not trigger a reload.
+	w.scan()
+
+	writeTestPo(t, poPath, "Olá, mundo", time.Now())
+
+	w.scan()
+
+	if got := Tr(ctx, "Hello"); got != "Olá, mundo" {
+		t.Errorf("Tr() = %q, want %q after reload", got, "Olá, mundo")
+	}
+}
+
+func TestLocaleWatcher_ConcurrentLookups(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt": `msgid "Hello"
+msgstr "Olá"
+`,
+	})
+
+	dir := t.TempDir()
+	poPath := filepath.Join(dir, "pt.po")
+
+	writeTestPo(t, poPath, "Olá", time.Now().Add(-time.Minute))
+
+	w := newLocaleWatcher(dir)
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	// Hammer Tr while the watcher swaps the catalog repeatedly; every lookup
+	// must observe one of the two published translations, never a miss.
+	var wg sync.WaitGroup
+
+	stop := make(chan struct{})
+
+	for range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				if got := Tr(ctx, "Hello"); got != "Olá" && got != "Olá, mundo" {
+					t.Errorf("Tr() = %q, want a published translation", got)
+
+					return
+				}
+			}
+		}()
+	}
+
+	for i := range 10 {
+		translation := "Olá"
+		if i%2 == 0 {
+			translation = "Olá, mundo"
+		}
+
+		writeTestPo(t, poPath, translation, time.Now().Add(time.Duration(i+1)*time.Second))
+
+		w.scan()
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestWatchLocales_PicksUpChanges(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt": `msgid "Hello"
+msgstr "Olá"
+`,
+	})
+
+	dir := t.TempDir()
+	poPath := filepath.Join(dir, "pt.po")
+
+	writeTestPo(t, poPath, "Olá", time.Now().Add(-time.Minute))
+
+	stop := WatchLocales(dir, 10*time.Millisecond)
+	defer stop()
+
+	writeTestPo(t, poPath, "Olá, mundo", time.Now())
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if Tr(ctx, "Hello") == "Olá, mundo" {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("Tr() = %q, want %q within the watch deadline", Tr(ctx, "Hello"), "Olá, mundo")
+}
@@ -30,8 +30,8 @@ func ResetForTests() {
 	// Clear missing translation dedupe state.
 	missingKeyOnce = sync.Map{}
 
-	// Clear loaded locales and matcher.
-	localesByTag = nil
+	// Clear loaded locales, fallback chains and matcher.
+	currentCatalog.Store(nil)
 	supportedTags = nil
 	matcher = nil
 
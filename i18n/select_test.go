@@ -0,0 +1,54 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package i18n
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestTrSelect(t *testing.T) {
+	installTestLocales(t, map[string]string{
+		"pt": `msgid "Following"
+msgstr "Seguindo"
+
+msgid "Follow {{.Name}}"
+msgstr "Seguir {{.Name}}"
+`,
+	})
+
+	ctx := WithTag(t.Context(), language.Make("pt"))
+
+	followCases := map[string]string{
+		"following": "Following",
+		"mutual":    "Mutual follows",
+		"other":     "Follow {{.Name}}",
+	}
+
+	// A matching selector picks its case and translates it like Tr.
+	if got := TrSelect(ctx, "following", followCases); got != "Seguindo" {
+		t.Errorf("TrSelect() = %q, want %q", got, "Seguindo")
+	}
+
+	// An untranslated case falls through to the msgid.
+	if got := TrSelect(ctx, "mutual", followCases); got != "Mutual follows" {
+		t.Errorf("TrSelect() = %q, want %q", got, "Mutual follows")
+	}
+
+	// An unknown selector falls back to "other", with template substitution.
+	if got := TrSelect(ctx, "not_following", followCases, "Name", "VnPower"); got != "Seguir VnPower" {
+		t.Errorf("TrSelect() = %q, want %q", got, "Seguir VnPower")
+	}
+}
+
+func TestTrSelect_MissingOtherPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error(`TrSelect() did not panic for cases without an "other" default`)
+		}
+	}()
+
+	TrSelect(t.Context(), "unknown", map[string]string{"following": "Following"})
+}
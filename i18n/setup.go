@@ -9,6 +9,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"sync/atomic"
 
 	"github.com/goccy/go-yaml"
 	"github.com/leonelquinteros/gotext"
@@ -19,13 +20,26 @@ import (
 	"codeberg.org/pixivfe/pixivfe/v3/server/assets"
 )
 
+// catalog bundles the loaded locales with their derived fallback chains
+// so both can be swapped as a unit.
+type catalog struct {
+	// localesByTag maps canonical BCP 47 tags, for example
+	// "en", "ja", "pt-BR", to their loaded gotext.Locale.
+	localesByTag map[string]*gotext.Locale
+
+	// fallbackChains maps canonical BCP 47 tags to the ordered list of locales
+	// consulted when a translation is missing from the matched locale itself.
+	fallbackChains map[string][]*gotext.Locale
+}
+
 var (
 	// poDomain is the gettext domain to load under each locale.
 	poDomain = "pixivfe"
 
-	// localesByTag maps canonical BCP 47 tags, for example
-	// "en", "ja", "pt-BR", to their loaded gotext.Locale.
-	localesByTag map[string]*gotext.Locale
+	// currentCatalog is read by lookups and replaced wholesale by Setup and by
+	// the development locale watcher; it is never mutated in place, which keeps
+	// hot reloads race-free without locking on the lookup path.
+	currentCatalog atomic.Pointer[catalog]
 
 	// supportedTags holds the list of BCP 47 tags for which a locale was successfully loaded.
 	supportedTags []language.Tag
@@ -34,6 +48,12 @@ var (
 	matcher language.Matcher
 )
 
+// Ready reports whether a catalogue is loaded, i.e. whether Setup has
+// completed successfully. Until then every lookup falls back to msgids.
+func Ready() bool {
+	return currentCatalog.Load() != nil
+}
+
 // Setup initialises package i18n by loading gettext catalogues from embedded assets
 // and constructing a language matcher.
 //
@@ -53,7 +73,7 @@ var (
 func Setup() error {
 	Logger = log.With().Str("sys", "i18n").Logger()
 
-	localesByTag = make(map[string]*gotext.Locale)
+	localesByTag := make(map[string]*gotext.Locale)
 	supportedTags = nil
 	matcher = nil
 
@@ -122,6 +142,8 @@ func Setup() error {
 	matcher = language.NewMatcher(all)
 	supportedTags = all
 
+	storeCatalog(localesByTag)
+
 	if err := loadTagTranslations(); err != nil {
 		return err
 	}
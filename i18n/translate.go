@@ -18,6 +18,10 @@ import (
 // templateCache caches compiled templates per unique template text.
 var templateCache sync.Map // key: text, value: *template.Template
 
+// noSprintfVars is passed to gotext lookups whose msgid is not a printf
+// format string, keeping vet's printf check quiet.
+var noSprintfVars []any
+
 type Vars map[string]any
 
 // NewUserError creates a new UserFacingError.
@@ -57,13 +61,43 @@ func TrC(ctx context.Context, contextKey, msgid string, kv ...any) string {
 	return translate(ctx, contextKey, msgid, "", 0, false, v(kv...))
 }
 
-// TrN translates a singular or plural message depending on n. If a translation
-// is missing, we choose singular when n == 1, otherwise plural. If key-value pairs
-// are provided, the translation is formatted using named placeholders.
+// TrN translates a singular or plural message depending on n. Translated
+// catalogs select the form through their own Plural-Forms rule, so languages
+// with more than two forms (e.g. Russian few/many) get the right msgstr index.
+// If a translation is missing, we choose singular when n == 1, otherwise plural.
+// If key-value pairs are provided, the translation is formatted using named
+// placeholders.
 func TrN(ctx context.Context, singular, plural string, n int, kv ...any) string {
 	return translate(ctx, "", singular, plural, n, true, v(kv...))
 }
 
+// TrNf is the float-count variant of TrN, for quantities that are naturally
+// fractional such as "0.5 hours". Integral values behave exactly like TrN;
+// fractional values select the plural form from the CLDR plural category of
+// the number in the matched locale.
+func TrNf(ctx context.Context, singular, plural string, n float64, kv ...any) string {
+	return translate(ctx, "", singular, plural, pluralLookupN(ctx, n), true, v(kv...))
+}
+
+// TrSelect translates a message chosen by a non-numeric selector, analogous
+// to TrN but keyed on an arbitrary string (e.g. a follow state such as
+// "following", "follows_you", or "mutual"). cases maps selector values to
+// msgids; an "other" case is required and is used when the selector has no
+// entry. The chosen msgid is looked up and formatted exactly like Tr.
+//
+// TrSelect panics when cases lacks an "other" entry; that is a programmer error.
+func TrSelect(ctx context.Context, selector string, cases map[string]string, kv ...any) string {
+	msgid, ok := cases[selector]
+	if !ok {
+		msgid, ok = cases["other"]
+		if !ok {
+			panic(`i18n.TrSelect: cases must include an "other" default`)
+		}
+	}
+
+	return translate(ctx, "", msgid, "", 0, false, v(kv...))
+}
+
 // TrNC is the contextual variant of TrN, similar to gettext's npgettext.
 // It disambiguates plural forms under a context key and formats the result
 // using any provided key-value pairs.
@@ -71,6 +105,11 @@ func TrNC(ctx context.Context, contextKey, singular, plural string, n int, kv ..
 	return translate(ctx, contextKey, singular, plural, n, true, v(kv...))
 }
 
+// TrNCf is the float-count variant of TrNC.
+func TrNCf(ctx context.Context, contextKey, singular, plural string, n float64, kv ...any) string {
+	return translate(ctx, contextKey, singular, plural, pluralLookupN(ctx, n), true, v(kv...))
+}
+
 // translate performs the underlying lookup and formatting.
 func translate(
 	ctx context.Context,
@@ -79,7 +118,12 @@ func translate(
 	pluralMode bool,
 	vars Vars,
 ) string {
-	loc, matched := resolveLocale(TagFrom(ctx))
+	_, matched := resolveLocale(TagFrom(ctx))
+
+	// The plain msgid path shares its lookup core with the batch helpers.
+	if !pluralMode && contextKey == "" {
+		return trResolved(matched, localeChain(matched), singular, vars)
+	}
 
 	// Fallback message
 	base := singular
@@ -90,7 +134,13 @@ func translate(
 	finalText := base
 	found := false
 
-	if loc != nil {
+	// Walk the locale's fallback chain (e.g. pt-BR → pt → pt-PT) before
+	// giving up and returning the msgid.
+	for _, loc := range localeChain(matched) {
+		if loc == nil {
+			continue
+		}
+
 		switch {
 		case pluralMode && contextKey != "":
 			found = loc.IsTranslatedNDC(poDomain, singular, n, contextKey)
@@ -107,11 +157,10 @@ func translate(
 			if found {
 				finalText = loc.GetDC(poDomain, singular, contextKey)
 			}
-		default:
-			found = loc.IsTranslatedD(poDomain, singular)
-			if found {
-				finalText = loc.GetD(poDomain, singular)
-			}
+		}
+
+		if found {
+			break
 		}
 	}
 
@@ -124,8 +173,46 @@ func translate(
 	return render(matched, finalText, vars)
 }
 
+// trResolved translates a plain msgid against a pre-resolved locale chain.
+// It is the non-plural, context-free core of translate, split out so the
+// batch helpers can reuse one locale resolution across many lookups.
+func trResolved(matched language.Tag, chain []*gotext.Locale, msgid string, vars Vars) string {
+	finalText := msgid
+	found := false
+
+	for _, loc := range chain {
+		if loc == nil {
+			continue
+		}
+
+		if loc.IsTranslatedD(poDomain, msgid) {
+			// Spread an empty vars slice: msgids are not printf format
+			// strings, and gotext returns the translation verbatim when
+			// no vars are given.
+			finalText = loc.GetD(poDomain, msgid, noSprintfVars...)
+			found = true
+
+			break
+		}
+	}
+
+	if !found && strictMissingKeys() {
+		logMissingOnce(strippedTagString(matched), buildLogKey("", msgid))
+
+		finalText = "⟦" + msgid + "⟧"
+	}
+
+	return render(matched, finalText, vars)
+}
+
 // render formats s as a text/template using the provided data.
 func render(locale language.Tag, s string, data Vars) string {
+	// Pseudolocalize before template execution so placeholders stay intact
+	// and substituted values remain readable.
+	if pseudolocalizeEnabled() {
+		s = pseudolocalize(s)
+	}
+
 	// If no data is provided, skip template execution unless the string
 	// contains template markers, in which case `missingkey=error` surface the issue.
 	if len(data) == 0 && !strings.Contains(s, "{{") {
@@ -182,7 +269,12 @@ func resolveLocale(t language.Tag) (*gotext.Locale, language.Tag) {
 
 	matched, _ := language.MatchStrings(matcher, t.String())
 
-	return localesByTag[matched.String()], matched
+	cat := currentCatalog.Load()
+	if cat == nil {
+		return nil, matched
+	}
+
+	return cat.localesByTag[matched.String()], matched
 }
 
 // v builds Vars from alternating key, value pairs.
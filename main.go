@@ -27,6 +27,7 @@ import (
 	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
 	"codeberg.org/pixivfe/pixivfe/v3/i18n"
 	"codeberg.org/pixivfe/pixivfe/v3/server/assets"
+	"codeberg.org/pixivfe/pixivfe/v3/server/middleware"
 	"codeberg.org/pixivfe/pixivfe/v3/server/middleware/limiter"
 	"codeberg.org/pixivfe/pixivfe/v3/server/router"
 	"codeberg.org/pixivfe/pixivfe/v3/server/template"
@@ -86,6 +87,14 @@ func run() error {
 
 	log.Info().Msg("Initialized i18n engine")
 
+	if config.Global.Development.InDevelopment && config.Global.Development.LocaleWatchPath != "" {
+		stopLocaleWatch := i18n.WatchLocales(
+			config.Global.Development.LocaleWatchPath,
+			i18n.DefaultLocaleWatchInterval,
+		)
+		defer stopLocaleWatch()
+	}
+
 	if err := template.LoadIcons("assets/icons"); err != nil {
 		return fmt.Errorf("failed to load icons: %w", err)
 	}
@@ -133,17 +142,29 @@ func run() error {
 		}
 	case s := <-quit:
 		log.Info().Str("signal", s.String()).Msg("Shutdown signal received")
-		log.Info().Msg("Shutting down server...")
 
-		ctx, cancel := context.WithTimeout(context.Background(), serverShutdownDeadline)
+		deadline := shutdownDeadline()
+
+		log.Info().
+			Int("inFlight", middleware.InFlightCount()).
+			Dur("deadline", deadline).
+			Msg("Shutting down server; draining in-flight requests")
+
+		ctx, cancel := context.WithTimeout(context.Background(), deadline)
 
 		defer cancel()
 
 		if err := server.Shutdown(ctx); err != nil {
+			log.Error().
+				Strs("requestIds", middleware.InFlightRequestIDs()).
+				Msg("Shutdown deadline exceeded; abandoning still-running requests")
+
 			return fmt.Errorf("server forced to shutdown: %w", err)
 		}
 	}
 
+	// Only finalize the limiter once in-flight requests have drained; they
+	// may still consult it while being served.
 	limiter.Fini()
 
 	log.Info().Msg("Server exited gracefully")
@@ -151,7 +172,49 @@ func run() error {
 	return nil
 }
 
+// listenFdsStart is the first file descriptor passed by systemd socket
+// activation (SD_LISTEN_FDS_START).
+const listenFdsStart = 3
+
+// shutdownDeadline returns the configured bound on draining in-flight
+// requests during shutdown, falling back to serverShutdownDeadline when the
+// configuration leaves it unset.
+func shutdownDeadline() time.Duration {
+	if deadline := config.Global.Basic.ShutdownTimeout; deadline > 0 {
+		return deadline
+	}
+
+	return serverShutdownDeadline
+}
+
 func chooseListener() (net.Listener, error) {
+	// Prefer a listener passed in via systemd socket activation: systemd
+	// already owns the socket (including any Unix socket file's ownership
+	// and permissions), so no chown/chmod privileges are needed here, and
+	// the held-open socket enables zero-downtime restarts.
+	if nfds := activationFds(os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")); nfds > 0 {
+		// The descriptors are consumed here; don't leak the activation
+		// environment to child processes.
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+
+		if nfds > 1 {
+			log.Warn().Int("fds", nfds).Msg("Multiple activated sockets passed; adopting only the first")
+		}
+
+		activatedListener, err := adoptListener(listenFdsStart)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info().
+			Str("address", activatedListener.Addr().String()).
+			Msg("Listening on systemd-activated socket")
+
+		return activatedListener, nil
+	}
+
 	// Check if we should use a Unix domain socket
 	if config.Global.Basic.UnixSocket != "" {
 		unixAddr := config.Global.Basic.UnixSocket
@@ -200,9 +263,51 @@ func chooseListener() (net.Listener, error) {
 		Str("url", fmt.Sprintf("http://pixivfe.localhost:%v/", port)).
 		Msg("Listening on address")
 
+	// Optionally cap simultaneous connections per client IP; allowlisted
+	// networks are exempt.
+	if maxConns := config.Global.Limiter.MaxConnsPerIP; maxConns > 0 {
+		return limiter.LimitConnsPerIP(tcpListener, maxConns, config.Global.Limiter.PassIPs), nil
+	}
+
 	return tcpListener, nil
 }
 
+// activationFds parses the systemd socket-activation environment, returning
+// the number of descriptors passed, or 0 when activation is absent, the
+// values are malformed, or the descriptors are addressed to another process.
+func activationFds(listenPID, listenFds string) int {
+	if listenPID == "" || listenFds == "" {
+		return 0
+	}
+
+	pid, err := strconv.Atoi(listenPID)
+	if err != nil || pid != os.Getpid() {
+		return 0
+	}
+
+	nfds, err := strconv.Atoi(listenFds)
+	if err != nil || nfds < 1 {
+		return 0
+	}
+
+	return nfds
+}
+
+// adoptListener wraps an inherited, already-listening file descriptor in a
+// net.Listener. The descriptor is duplicated by net.FileListener, so the
+// wrapping file is closed before returning.
+func adoptListener(fd uintptr) (net.Listener, error) {
+	file := os.NewFile(fd, "systemd-activated socket")
+	defer file.Close()
+
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to adopt systemd-activated socket: %w", err)
+	}
+
+	return listener, nil
+}
+
 func setupSocket() error {
 	cfg := config.Global.Basic
 
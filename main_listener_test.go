@@ -0,0 +1,113 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestActivationFds(t *testing.T) {
+	t.Parallel()
+
+	ownPID := strconv.Itoa(os.Getpid())
+
+	cases := []struct {
+		name      string
+		listenPID string
+		listenFds string
+		want      int
+	}{
+		{"not activated", "", "", 0},
+		{"pid only", ownPID, "", 0},
+		{"fds only", "", "1", 0},
+		{"descriptors for another process", "1", "1", 0},
+		{"malformed pid", "not-a-pid", "1", 0},
+		{"malformed fd count", ownPID, "some", 0},
+		{"zero descriptors", ownPID, "0", 0},
+		{"one descriptor", ownPID, "1", 1},
+		{"several descriptors", ownPID, "3", 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := activationFds(tc.listenPID, tc.listenFds); got != tc.want {
+				t.Errorf("activationFds(%q, %q) = %d, want %d", tc.listenPID, tc.listenFds, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestAdoptListener adopts a pre-created, already-listening socket by file
+// descriptor, the way chooseListener does for a systemd-passed one, and
+// verifies connections are accepted through the adopted listener.
+func TestAdoptListener(t *testing.T) {
+	t.Parallel()
+
+	socketPath := filepath.Join(t.TempDir(), "pixivfe.sock")
+
+	original, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("creating socket: %v", err)
+	}
+	defer original.Close()
+
+	file, err := original.(*net.UnixListener).File()
+	if err != nil {
+		t.Fatalf("extracting socket fd: %v", err)
+	}
+	defer file.Close()
+
+	adopted, err := adoptListener(file.Fd())
+	if err != nil {
+		t.Fatalf("adoptListener() error: %v", err)
+	}
+	defer adopted.Close()
+
+	if adopted.Addr().String() != socketPath {
+		t.Errorf("Addr() = %q, want %q", adopted.Addr(), socketPath)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		conn, err := adopted.Accept()
+		if err == nil {
+			conn.Close()
+		}
+
+		done <- err
+	}()
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("dialing adopted socket: %v", err)
+	}
+
+	conn.Close()
+
+	if err := <-done; err != nil {
+		t.Errorf("Accept() error: %v", err)
+	}
+}
+
+// TestAdoptListener_NotAListener rejects descriptors that are not sockets.
+func TestAdoptListener_NotAListener(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.CreateTemp(t.TempDir(), "not-a-socket")
+	if err != nil {
+		t.Fatalf("creating file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := adoptListener(file.Fd()); err == nil {
+		t.Error("adoptListener() = nil error, want an error for a non-socket fd")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// TestShutdownDeadline verifies that the configured shutdown timeout is used
+// when set and that the compiled-in default applies otherwise.
+func TestShutdownDeadline(t *testing.T) {
+	originalTimeout := config.Global.Basic.ShutdownTimeout
+
+	t.Cleanup(func() {
+		config.Global.Basic.ShutdownTimeout = originalTimeout
+	})
+
+	tests := []struct {
+		name       string
+		configured time.Duration
+		want       time.Duration
+	}{
+		{
+			name:       "configured timeout wins",
+			configured: 42 * time.Second,
+			want:       42 * time.Second,
+		},
+		{
+			name:       "unset falls back to the default",
+			configured: 0,
+			want:       serverShutdownDeadline,
+		},
+		{
+			name:       "negative values fall back to the default",
+			configured: -time.Second,
+			want:       serverShutdownDeadline,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Global.Basic.ShutdownTimeout = tt.configured
+
+			if got := shutdownDeadline(); got != tt.want {
+				t.Errorf("shutdownDeadline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,11 +11,11 @@ import (
 
 	"github.com/rs/zerolog/log"
 
-	"github.com/megalamo/pixivfe/assets/views"
-	"github.com/megalamo/pixivfe/config"
-	"github.com/megalamo/pixivfe/core/audit"
-	"github.com/megalamo/pixivfe/server/request_context"
-	"github.com/megalamo/pixivfe/server/routes"
+	"codeberg.org/pixivfe/pixivfe/v3/assets/views"
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/audit"
+	"codeberg.org/pixivfe/pixivfe/v3/server/request_context"
+	"codeberg.org/pixivfe/pixivfe/v3/server/routes"
 )
 
 // CatchError wraps HTTP handlers that return an error, providing centralized error handling,
@@ -40,6 +40,9 @@ import (
 //   - In all other cases (e.g., a successful response), the buffered response
 //     is written to the client.
 //
+// Clients that negotiate "Accept: application/json" receive every error
+// response as a JSON envelope instead of an HTML page; see acceptsJSONError.
+//
 // Finally, it logs the completed request details (status, duration, error, etc.)
 // via the audit package.
 func CatchError(handler func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
@@ -70,6 +73,12 @@ func CatchError(handler func(w http.ResponseWriter, r *http.Request) error) http
 			// Discard the recorder's content and render the unauthorized page.
 			ctx.StatusCode = http.StatusUnauthorized
 
+			if acceptsJSONError(r) {
+				writeJSONError(w, r, ctx.StatusCode, ctx.RequestError)
+
+				break
+			}
+
 			w.Header().Add("HX-Push-Url", "/unauthorized")
 			w.WriteHeader(ctx.StatusCode)
 
@@ -96,6 +105,12 @@ func CatchError(handler func(w http.ResponseWriter, r *http.Request) error) http
 				ctx.StatusCode = http.StatusInternalServerError
 			}
 
+			if acceptsJSONError(r) {
+				writeJSONError(w, r, ctx.StatusCode, ctx.RequestError)
+
+				break
+			}
+
 			w.WriteHeader(ctx.StatusCode)
 			routes.ErrorPage(w, r) // ErrorPage uses ctx.RequestError and ctx.StatusCode
 
@@ -106,6 +121,15 @@ func CatchError(handler func(w http.ResponseWriter, r *http.Request) error) http
 			}
 
 			ctx.StatusCode = recorder.Code // Ensure ctx.StatusCode reflects the actual code for logging.
+
+			// Handled errors keep their status, but API clients get the
+			// consistent JSON envelope instead of the buffered error page.
+			if recorder.Code >= http.StatusBadRequest && acceptsJSONError(r) {
+				writeJSONError(w, r, recorder.Code, ctx.RequestError)
+
+				break
+			}
+
 			maps.Copy(w.Header(), recorder.Header())
 			w.WriteHeader(recorder.Code)
 
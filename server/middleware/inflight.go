@@ -0,0 +1,72 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"codeberg.org/pixivfe/pixivfe/v3/server/request_context"
+)
+
+// inflight tracks the requests currently being served, keyed by request ID,
+// so the shutdown path can report what it is still draining.
+var inflight = struct {
+	sync.Mutex
+
+	ids map[string]int
+}{ids: make(map[string]int)}
+
+// TrackInFlight counts a request as in flight for the duration of its
+// handling. It runs after the request context is attached so entries carry
+// the request ID used in logs.
+func TrackInFlight(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	id := request_context.FromContext(r.Context()).RequestID
+
+	inflight.Lock()
+	inflight.ids[id]++
+	inflight.Unlock()
+
+	defer func() {
+		inflight.Lock()
+		defer inflight.Unlock()
+
+		inflight.ids[id]--
+		if inflight.ids[id] <= 0 {
+			delete(inflight.ids, id)
+		}
+	}()
+
+	next.ServeHTTP(w, r)
+}
+
+// InFlightCount returns the number of requests currently being served.
+func InFlightCount() int {
+	inflight.Lock()
+	defer inflight.Unlock()
+
+	count := 0
+	for _, n := range inflight.ids {
+		count += n
+	}
+
+	return count
+}
+
+// InFlightRequestIDs returns the IDs of the requests currently being served,
+// sorted for stable log output.
+func InFlightRequestIDs() []string {
+	inflight.Lock()
+	defer inflight.Unlock()
+
+	ids := make([]string, 0, len(inflight.ids))
+	for id := range inflight.ids {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
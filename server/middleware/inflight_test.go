@@ -0,0 +1,76 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/server/request_context"
+)
+
+// TestTrackInFlight verifies that a request is counted while its handler runs
+// and removed again once handling completes.
+func TestTrackInFlight(t *testing.T) {
+	req := createTestRequest(t)
+	requestID := request_context.FromRequest(req).RequestID
+
+	before := InFlightCount()
+
+	var (
+		countDuring int
+		idsDuring   []string
+	)
+
+	handler := Wrap(TrackInFlight, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		countDuring = InFlightCount()
+		idsDuring = InFlightRequestIDs()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if countDuring != before+1 {
+		t.Errorf("InFlightCount during handling = %d, want %d", countDuring, before+1)
+	}
+
+	if !slices.Contains(idsDuring, requestID) {
+		t.Errorf("InFlightRequestIDs during handling = %v, want it to contain %q", idsDuring, requestID)
+	}
+
+	if after := InFlightCount(); after != before {
+		t.Errorf("InFlightCount after handling = %d, want %d", after, before)
+	}
+
+	if slices.Contains(InFlightRequestIDs(), requestID) {
+		t.Errorf("InFlightRequestIDs still contains %q after handling", requestID)
+	}
+}
+
+// TestTrackInFlight_Panic verifies that the counter is decremented even when
+// the handler panics, so a crashed request can't wedge shutdown reporting.
+func TestTrackInFlight_Panic(t *testing.T) {
+	before := InFlightCount()
+
+	handler := Wrap(TrackInFlight, http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		panic("handler exploded")
+	}))
+
+	func() {
+		defer func() {
+			if recovered := recover(); recovered == nil {
+				t.Fatal("expected the handler panic to propagate")
+			}
+		}()
+
+		handler.ServeHTTP(httptest.NewRecorder(), createTestRequest(t))
+	}()
+
+	if after := InFlightCount(); after != before {
+		t.Errorf("InFlightCount after panic = %d, want %d", after, before)
+	}
+}
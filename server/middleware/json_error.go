@@ -0,0 +1,67 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"codeberg.org/pixivfe/pixivfe/v3/i18n"
+)
+
+// acceptsJSONError reports whether the client negotiated a JSON error body.
+//
+// The Accept header's media ranges are checked in order; the first one naming
+// either application/json or an HTML type decides. Browsers lead with HTML
+// types, so they keep the themed error pages, while API clients sending
+// "Accept: application/json" get the envelope.
+func acceptsJSONError(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+
+		switch strings.ToLower(mediaType) {
+		case "application/json":
+			return true
+		case "text/html", "application/xhtml+xml", "*/*":
+			return false
+		}
+	}
+
+	return false
+}
+
+// jsonErrorEnvelope is the body served to API clients in place of an HTML
+// error page.
+type jsonErrorEnvelope struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// writeJSONError writes the JSON error envelope for the given status code,
+// using the handler's error message when one exists and the localized status
+// text otherwise.
+func writeJSONError(w http.ResponseWriter, r *http.Request, code int, requestErr error) {
+	message := i18n.Tr(r.Context(), http.StatusText(code))
+	if requestErr != nil {
+		message = requestErr.Error()
+	}
+
+	var envelope jsonErrorEnvelope
+
+	envelope.Error.Code = code
+	envelope.Error.Message = message
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(envelope); err != nil {
+		log.Err(err).Msg("Failed to write JSON error body")
+	}
+}
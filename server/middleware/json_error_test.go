@@ -0,0 +1,154 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/server/routes"
+)
+
+func TestAcceptsJSONError(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", true},
+		{"application/json;q=0.9, text/html", true},
+		{"text/html,application/xhtml+xml,application/json", false},
+		{"*/*", false},
+		{"image/webp, application/json", true},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/test", nil)
+		r.Header.Set("Accept", tc.accept)
+
+		if got := acceptsJSONError(r); got != tc.want {
+			t.Errorf("acceptsJSONError(Accept: %q) = %t, want %t", tc.accept, got, tc.want)
+		}
+	}
+}
+
+// decodeErrorEnvelope unmarshals a recorded JSON error response.
+func decodeErrorEnvelope(t *testing.T, rr *httptest.ResponseRecorder) jsonErrorEnvelope {
+	t.Helper()
+
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+
+	var envelope jsonErrorEnvelope
+	if err := json.Unmarshal(rr.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("Response body %q is not a JSON envelope: %v", rr.Body.String(), err)
+	}
+
+	return envelope
+}
+
+func TestCatchError_JSONEnvelope(t *testing.T) {
+	cases := []struct {
+		name        string
+		handler     func(w http.ResponseWriter, r *http.Request) error
+		wantCode    int
+		wantMessage string
+	}{
+		{
+			"Unhandled error becomes a 500 envelope",
+			func(w http.ResponseWriter, r *http.Request) error {
+				return errors.New("upstream exploded")
+			},
+			http.StatusInternalServerError, "upstream exploded",
+		},
+		{
+			"Handler-written 404 becomes an envelope",
+			func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusNotFound)
+
+				return nil
+			},
+			http.StatusNotFound, "Not Found",
+		},
+		{
+			"Handled 403 keeps its status",
+			func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte("<h1>blocked</h1>"))
+
+				return nil
+			},
+			http.StatusForbidden, "Forbidden",
+		},
+		{
+			"Unauthorized error becomes a 401 envelope",
+			func(w http.ResponseWriter, r *http.Request) error {
+				return routes.NewUnauthorizedError("/", "/self")
+			},
+			http.StatusUnauthorized, "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := createTestRequest(t)
+			req.Header.Set("Accept", "application/json")
+
+			rr := httptest.NewRecorder()
+			CatchError(tc.handler).ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("Status = %d, want %d", rr.Code, tc.wantCode)
+			}
+
+			envelope := decodeErrorEnvelope(t, rr)
+			if envelope.Error.Code != tc.wantCode {
+				t.Errorf("envelope code = %d, want %d", envelope.Error.Code, tc.wantCode)
+			}
+
+			if tc.wantMessage != "" && envelope.Error.Message != tc.wantMessage {
+				t.Errorf("envelope message = %q, want %q", envelope.Error.Message, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestCatchError_HTMLWithoutJSONAccept(t *testing.T) {
+	req := createTestRequest(t)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	rr := httptest.NewRecorder()
+	CatchError(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("upstream exploded")
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("Status = %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+
+	if ct := rr.Header().Get("Content-Type"); strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, want the HTML error page for browser clients", ct)
+	}
+}
+
+func TestCatchError_JSONAcceptKeepsSuccessBody(t *testing.T) {
+	req := createTestRequest(t)
+	req.Header.Set("Accept", "application/json")
+
+	rr := httptest.NewRecorder()
+	CatchError(func(w http.ResponseWriter, r *http.Request) error {
+		w.Write([]byte(`{"status":"success"}`))
+
+		return nil
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || rr.Body.String() != `{"status":"success"}` {
+		t.Errorf("Response = %d %q, want the handler's body untouched", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package limiter
+
+import (
+	"net"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// perIPConnListener caps the number of simultaneously open connections any
+// single client IP may hold.
+type perIPConnListener struct {
+	net.Listener
+
+	maxPerIP int
+	passList []string
+
+	mu    sync.Mutex
+	conns map[string]int
+}
+
+// LimitConnsPerIP wraps inner so that each client IP may hold at most
+// maxPerIP simultaneously open connections. Connections beyond the cap are
+// closed immediately after being accepted. IPs matching passList (exact
+// addresses or CIDRs) are exempt.
+//
+// This complements request rate limiting at a lower layer: a single abusive
+// IP cannot tie up the server by opening TCP connections without ever
+// issuing requests.
+func LimitConnsPerIP(inner net.Listener, maxPerIP int, passList []string) net.Listener {
+	return &perIPConnListener{
+		Listener: inner,
+		maxPerIP: maxPerIP,
+		passList: passList,
+		conns:    make(map[string]int),
+	}
+}
+
+// Accept waits for the next connection whose source IP is below its cap,
+// closing over-cap connections as they arrive.
+func (l *perIPConnListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		ip := remoteIPOf(conn)
+		if ip == "" || l.isExempt(ip) {
+			return conn, nil
+		}
+
+		l.mu.Lock()
+		if l.conns[ip] >= l.maxPerIP {
+			l.mu.Unlock()
+
+			log.Debug().
+				Str("ip", ip).
+				Int("max", l.maxPerIP).
+				Msg("Rejecting connection above the per-IP cap")
+
+			_ = conn.Close()
+
+			continue
+		}
+
+		l.conns[ip]++
+		l.mu.Unlock()
+
+		return &countedConn{Conn: conn, release: func() { l.release(ip) }}, nil
+	}
+}
+
+// isExempt reports whether connections from ip should bypass the cap.
+func (l *perIPConnListener) isExempt(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		// The connection can't be attributed to an IP; don't count it.
+		return true
+	}
+
+	return ipMatchesList(parsed, l.passList)
+}
+
+// release frees the connection slot held by ip.
+func (l *perIPConnListener) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conns[ip] <= 1 {
+		delete(l.conns, ip)
+	} else {
+		l.conns[ip]--
+	}
+}
+
+// remoteIPOf extracts the bare IP from a connection's remote address,
+// returning "" for non-IP transports such as unix sockets.
+func remoteIPOf(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return ""
+	}
+
+	return host
+}
+
+// countedConn releases its slot in the per-IP count exactly once on Close.
+type countedConn struct {
+	net.Conn
+
+	release func()
+	once    sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(c.release)
+
+	return c.Conn.Close()
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package limiter
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubAddr is a net.Addr with a fixed host:port string.
+type stubAddr string
+
+func (a stubAddr) Network() string { return "tcp" }
+func (a stubAddr) String() string  { return string(a) }
+
+// stubConn is a net.Conn that only tracks its remote address and whether it
+// has been closed.
+type stubConn struct {
+	addr string
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (c *stubConn) Read([]byte) (int, error)         { return 0, nil }
+func (c *stubConn) Write([]byte) (int, error)        { return 0, nil }
+func (c *stubConn) LocalAddr() net.Addr              { return stubAddr("127.0.0.1:80") }
+func (c *stubConn) RemoteAddr() net.Addr             { return stubAddr(c.addr) }
+func (c *stubConn) SetDeadline(time.Time) error      { return nil }
+func (c *stubConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *stubConn) SetWriteDeadline(time.Time) error { return nil }
+
+func (c *stubConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+
+	return nil
+}
+
+func (c *stubConn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
+
+// stubListener hands out a queued sequence of connections.
+type stubListener struct {
+	conns chan net.Conn
+}
+
+func newStubListener(conns ...net.Conn) *stubListener {
+	l := &stubListener{conns: make(chan net.Conn, len(conns))}
+	for _, conn := range conns {
+		l.conns <- conn
+	}
+
+	return l
+}
+
+func (l *stubListener) Accept() (net.Conn, error) {
+	conn, ok := <-l.conns
+	if !ok {
+		return nil, net.ErrClosed
+	}
+
+	return conn, nil
+}
+
+func (l *stubListener) Close() error   { return nil }
+func (l *stubListener) Addr() net.Addr { return stubAddr("127.0.0.1:80") }
+
+func TestLimitConnsPerIP_CapsSingleIP(t *testing.T) {
+	// Simulate one IP opening many connections at once, with a well-behaved
+	// client queued behind it.
+	abusive := make([]*stubConn, 10)
+	queued := make([]net.Conn, 0, len(abusive)+1)
+
+	for i := range abusive {
+		abusive[i] = &stubConn{addr: fmt.Sprintf("198.51.100.7:%d", 40000+i)}
+		queued = append(queued, abusive[i])
+	}
+
+	other := &stubConn{addr: "192.0.2.8:40000"}
+	queued = append(queued, other)
+
+	listener := LimitConnsPerIP(newStubListener(queued...), 2, nil)
+
+	for i := range 3 {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatalf("Accept() #%d error: %v", i, err)
+		}
+
+		want := "198.51.100.7"
+		if i == 2 {
+			want = "192.0.2.8"
+		}
+
+		if got := remoteIPOf(conn); got != want {
+			t.Errorf("Accept() #%d came from %s, want %s", i, got, want)
+		}
+	}
+
+	rejected := 0
+
+	for _, conn := range abusive {
+		if conn.isClosed() {
+			rejected++
+		}
+	}
+
+	if rejected != 8 {
+		t.Errorf("Rejected %d connections, want 8 above the cap of 2", rejected)
+	}
+}
+
+func TestLimitConnsPerIP_ReleaseOnClose(t *testing.T) {
+	first := &stubConn{addr: "198.51.100.7:40000"}
+	second := &stubConn{addr: "198.51.100.7:40001"}
+
+	listener := LimitConnsPerIP(newStubListener(first, second), 1, nil)
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept() error: %v", err)
+	}
+
+	// Closing the accepted connection frees the IP's only slot.
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	conn, err = listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept() error: %v", err)
+	}
+
+	if remoteIPOf(conn) != "198.51.100.7" {
+		t.Errorf("Accept() came from %s, want the freed IP readmitted", remoteIPOf(conn))
+	}
+
+	if second.isClosed() {
+		t.Error("Second connection was rejected, want it accepted after the first closed")
+	}
+}
+
+func TestLimitConnsPerIP_PassListExempt(t *testing.T) {
+	conns := make([]net.Conn, 3)
+	for i := range conns {
+		conns[i] = &stubConn{addr: fmt.Sprintf("203.0.113.9:%d", 40000+i)}
+	}
+
+	listener := LimitConnsPerIP(newStubListener(conns...), 1, []string{"203.0.113.0/24"})
+
+	for i := range conns {
+		if _, err := listener.Accept(); err != nil {
+			t.Fatalf("Accept() #%d error: %v", i, err)
+		}
+	}
+
+	for i, conn := range conns {
+		if conn.(*stubConn).isClosed() {
+			t.Errorf("Connection %d from an allowlisted network was rejected", i)
+		}
+	}
+}
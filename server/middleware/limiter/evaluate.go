@@ -50,6 +50,11 @@ func isAtomXMLPath(path string) bool {
 	return strings.Contains(path, "/atom.xml")
 }
 
+// isTagCompletionPath returns true if the request path is the tag-completion endpoint.
+func isTagCompletionPath(path string) bool {
+	return path == "/api/tag-completions"
+}
+
 // Evaluate is the entrypoint to the limiter middleware.
 //
 // The logic was originally based on the reference SearXNG code in searxng/searx/botdetection.
@@ -116,10 +121,14 @@ func Evaluate(w http.ResponseWriter, r *http.Request, next http.Handler) {
 		}
 	}
 
-	// 5: Special handling for atom.xml routes.
+	// 5: Special handling for atom.xml and tag-completion routes.
 	if isAtomXMLPath(r.URL.Path) {
 		// For atom.xml routes, use unconditional intermediate token bucket config.
 		client.limiter = getOrCreateAtomXMLLimiter(client.network.String())
+	} else if isTagCompletionPath(r.URL.Path) {
+		// Tag completions fire on every search keystroke; give them their own,
+		// stricter bucket so they can't starve regular page loads (or vice versa).
+		client.limiter = getOrCreateTagCompletionLimiter(client.network.String())
 	} else {
 		// 5: DetectionMethod handling for non-atom.xml routes.
 		detectionMethod := config.Global.Limiter.DetectionMethod
@@ -37,6 +37,8 @@ const (
 	SuspiciousBurst         = 90              // Maximum tokens for a suspicious network.
 	AtomXMLRate             = 1.0             // 1 token per second for atom.xml routes.
 	AtomXMLBurst            = 90              // Maximum tokens for atom.xml routes.
+	TagCompletionRate       = 1.0             // Fallback rate for the tag-completion endpoint when unconfigured.
+	TagCompletionBurst      = 15              // Fallback burst for the tag-completion endpoint when unconfigured.
 	LimiterExpiryDuration   = time.Hour       // How long to keep limiters in memory before cleanup.
 	CleanupInterval         = 5 * time.Minute // Interval between limiter cleanup runs.
 	MaxNetworkClientHistory = 60              // Max. number of client histories to track per network.
@@ -298,6 +300,41 @@ func getOrCreateAtomXMLLimiter(networkStr string) *limiterWrapper {
 	return limWrapper
 }
 
+// getOrCreateTagCompletionLimiter returns a limiterWrapper dedicated to the
+// tag-completion endpoint, which is triggered on every search keystroke and
+// therefore gets a stricter bucket than regular page loads.
+//
+// Rate and burst come from config.Global.Limiter (rate is configured in tokens
+// per minute); non-positive values fall back to TagCompletionRate/TagCompletionBurst.
+// It uses a separate key space (networkStr + ":completions") to avoid conflicts
+// with regular limiters.
+func getOrCreateTagCompletionLimiter(networkStr string) *limiterWrapper {
+	completionKey := networkStr + ":completions"
+
+	// Try to load existing tag-completion limiter from memory
+	if limWrapper, found := loadLimiterFromMemory(completionKey); found {
+		return limWrapper
+	}
+
+	rateLim := TagCompletionRate
+	if perMinute := config.Global.Limiter.TagCompletionRate; perMinute > 0 {
+		rateLim = float64(perMinute) / 60
+	}
+
+	burstLim := TagCompletionBurst
+	if burst := config.Global.Limiter.TagCompletionBurst; burst > 0 {
+		burstLim = burst
+	}
+
+	// Create new tag-completion limiter with the configured rate and burst
+	limWrapper := newLimiterWrapper(rateLim, burstLim, completionKey, false)
+
+	// Store the new limiter
+	limiters.Store(completionKey, limWrapper)
+
+	return limWrapper
+}
+
 // loadLimiterFromMemory tries to load from memory a limiterWrapper
 // for a given network.
 //
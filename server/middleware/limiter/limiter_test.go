@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
 )
 
 // TestCheckRateLimit ensures tokens are consumed correctly.
@@ -121,6 +123,85 @@ func TestGetOrCreateAtomXMLLimiter(t *testing.T) {
 	}
 }
 
+// TestGetOrCreateTagCompletionLimiter verifies creation or retrieval of a tag-completion rate limiter.
+func TestGetOrCreateTagCompletionLimiter(t *testing.T) {
+	setupLimiterTest(t)
+
+	// Configure the bucket: 120 tokens per minute (2 per second), burst 10.
+	config.Global.Limiter.TagCompletionRate = 120
+	config.Global.Limiter.TagCompletionBurst = 10
+
+	networkStr := "192.168.0.0/24"
+
+	// Test creating a new tag-completion limiter
+	limiter1 := getOrCreateTagCompletionLimiter(networkStr)
+	if limiter1 == nil {
+		t.Fatal("Expected a non-nil tag-completion limiter wrapper")
+	}
+
+	// Verify it uses the configured rate and burst
+	if float64(limiter1.limiter.Limit()) != 2.0 {
+		t.Errorf("Expected tag-completion rate %f, got %f", 2.0, float64(limiter1.limiter.Limit()))
+	}
+
+	if limiter1.limiter.Burst() != 10 {
+		t.Errorf("Expected tag-completion burst %d, got %d", 10, limiter1.limiter.Burst())
+	}
+
+	// Verify it's not marked as suspicious
+	if limiter1.isSuspicious {
+		t.Error("Tag-completion limiter should not be marked as suspicious")
+	}
+
+	// Test retrieving the same limiter
+	limiter2 := getOrCreateTagCompletionLimiter(networkStr)
+	if limiter1 != limiter2 {
+		t.Error("Expected same tag-completion limiter wrapper instance for repeated calls")
+	}
+
+	// Verify that regular and tag-completion limiters are separate
+	regularLimiter := getOrCreateLimiter(networkStr, false)
+	if limiter1 == regularLimiter {
+		t.Error("Tag-completion limiter should be separate from regular limiter")
+	}
+
+	// Verify the key space separation
+	expectedCompletionKey := networkStr + ":completions"
+	if limiter1.network != expectedCompletionKey {
+		t.Errorf("Expected tag-completion limiter network key to be %s, got %s", expectedCompletionKey, limiter1.network)
+	}
+
+	// Exhausting the tag-completion bucket must not consume regular tokens
+	for range limiter1.limiter.Burst() {
+		limiter1.limiter.Allow()
+	}
+
+	if reason := checkRateLimit(limiter1, networkStr); reason == "" {
+		t.Error("Expected tag-completion limiter to block after burst exhaustion")
+	}
+
+	if reason := checkRateLimit(regularLimiter, networkStr); reason != "" {
+		t.Errorf("Expected regular limiter to remain unaffected, got block reason %q", reason)
+	}
+}
+
+// TestGetOrCreateTagCompletionLimiter_Fallback verifies the built-in fallbacks
+// when no bucket configuration is provided.
+func TestGetOrCreateTagCompletionLimiter_Fallback(t *testing.T) {
+	setupLimiterTest(t)
+
+	// setupTestConfig leaves the tag-completion settings at their zero values.
+	limiter1 := getOrCreateTagCompletionLimiter("192.168.0.0/24")
+
+	if float64(limiter1.limiter.Limit()) != TagCompletionRate {
+		t.Errorf("Expected fallback rate %f, got %f", TagCompletionRate, float64(limiter1.limiter.Limit()))
+	}
+
+	if limiter1.limiter.Burst() != TagCompletionBurst {
+		t.Errorf("Expected fallback burst %d, got %d", TagCompletionBurst, limiter1.limiter.Burst())
+	}
+}
+
 func TestLoadLimiterFromMemory(t *testing.T) {
 	mockTime := setupLimiterTest(t)
 
@@ -8,11 +8,12 @@ import (
 	"runtime/trace"
 	"time"
 
-	"github.com/megalamo/pixivfe/configs"
-	"github.com/megalamo/pixivfe/server/assets"
-	"github.com/megalamo/pixivfe/server/middleware"
-	"github.com/megalamo/pixivfe/server/middleware/limiter"
-	"github.com/megalamo/pixivfe/server/routes"
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/assets"
+	"codeberg.org/pixivfe/pixivfe/v3/server/middleware"
+	"codeberg.org/pixivfe/pixivfe/v3/server/middleware/limiter"
+	"codeberg.org/pixivfe/pixivfe/v3/server/routes"
 )
 
 // DefineRoutes sets up all the routes for the application using our custom Router.
@@ -45,6 +46,12 @@ func (router *Router) DefineRoutes() {
 	// About routes
 	router.HandleFunc("GET /about", middleware.CatchError(routes.AboutPage))
 
+	// Health endpoint for readiness probes
+	router.HandleFunc("GET /health", middleware.CatchError(routes.HealthPage))
+
+	// External-link redirector
+	router.HandleFunc("GET /goto", middleware.CatchError(routes.GotoPage))
+
 	// Newest routes
 	router.HandleFunc("GET /newest", middleware.CatchError(routes.NewestPage))
 
@@ -113,6 +120,7 @@ func (router *Router) DefineRoutes() {
 	router.HandleFunc("GET /api/discovery", middleware.CatchError(routes.DiscoveryPartial))
 	router.HandleFunc("GET /api/tag-completions", middleware.CatchError(routes.TagCompletionsPartial))
 	router.HandleFunc("GET /api/street", middleware.CatchError(routes.StreetPartial))
+	router.HandleFunc("GET /api/feed-token", middleware.CatchError(routes.FeedTokenRoute))
 
 	// Challenge page route
 	if config.Global.Limiter.Enabled {
@@ -131,6 +139,7 @@ func (router *Router) DefineRoutes() {
 
 	if config.Global.Development.InDevelopment {
 		router.HandleFunc("GET /dev/components", middleware.CatchError(routes.ComponentsPage))
+		router.HandleFunc("GET /dev/i18n/missing/{locale}", middleware.CatchError(routes.DevMissingTranslations))
 	}
 
 	// Index page routes
@@ -181,4 +190,5 @@ func registerDebugRoutes(router *Router) {
 	router.HandleFunc("GET /debug/flight", func(w http.ResponseWriter, r *http.Request) {
 		_, _ = flightRecorder.WriteTo(w)
 	})
+	router.Handle("GET /debug/tokens", tokenmanager.StatusHandler())
 }
@@ -1,10 +1,10 @@
 package router
 
 import (
-	"github.com/megalamo/pixivfe/config"
-	"github.com/megalamo/pixivfe/server/middleware"
-	"github.com/megalamo/pixivfe/server/middleware/limiter"
-	"github.com/megalamo/pixivfe/server/middleware/set_request_context"
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/server/middleware"
+	"codeberg.org/pixivfe/pixivfe/v3/server/middleware/limiter"
+	"codeberg.org/pixivfe/pixivfe/v3/server/middleware/set_request_context"
 )
 
 func (router *Router) RegisterMiddleware() {
@@ -12,6 +12,7 @@ func (router *Router) RegisterMiddleware() {
 	router.Use(middleware.WithServerTiming)
 	router.Use(middleware.NormalizeURL)                // handle trailing slashes and /en/ prefix removal
 	router.Use(set_request_context.WithRequestContext) // needed for everything else
+	router.Use(middleware.TrackInFlight)               // so shutdown can report what it is draining
 	router.Use(middleware.SetResponseHeaders)          // all pages need this
 
 	if config.Global.Limiter.Enabled {
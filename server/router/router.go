@@ -6,7 +6,7 @@ package router
 import (
 	"net/http"
 
-	"github.com/megalamo/pixivfe/server/middleware"
+	"codeberg.org/pixivfe/pixivfe/v3/server/middleware"
 )
 
 // Router wraps http.ServeMux and provides middleware chaining functionality.
@@ -0,0 +1,41 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"golang.org/x/text/language"
+
+	"codeberg.org/pixivfe/pixivfe/v3/i18n"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+var errUnknownLocale = errors.New("unknown locale")
+
+// DevMissingTranslations serves a locale's untranslated messages as a
+// downloadable .po fragment, letting translators start from the live catalog
+// without running cmd/i18n_extract locally. The route is registered only when
+// development mode is enabled.
+func DevMissingTranslations(w http.ResponseWriter, r *http.Request) error {
+	locale := utils.GetPathVar(r, "locale")
+
+	known := slices.ContainsFunc(i18n.Languages(), func(t language.Tag) bool {
+		return t.String() == locale
+	})
+	if !known {
+		return fmt.Errorf("%w: %q", errUnknownLocale, locale)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/x-gettext-translation; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", locale+"-missing.po"))
+
+	_, err := w.Write(i18n.MissingPoFragment(locale))
+
+	return err
+}
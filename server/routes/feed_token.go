@@ -0,0 +1,134 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/rs/zerolog/log"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core"
+	"codeberg.org/pixivfe/pixivfe/v3/core/authenticated"
+	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+const (
+	// feedTokenSubject disambiguates feed tokens from other paseto tokens
+	// signed with the instance key.
+	feedTokenSubject = "feed access"
+
+	// feedTokenQueryParam is the query parameter carrying a feed token on feed URLs.
+	feedTokenQueryParam = "feed_token"
+)
+
+var (
+	errFeedTokenUnavailable   = errors.New("feed tokens require basic.secret to be configured")
+	errFeedTokenRequiresLogin = errors.New("including R-18 works in a feed token requires a logged-in session")
+
+	// feedTokenParser verifies the subject claim; feed tokens deliberately
+	// carry no expiration, as feed readers poll them indefinitely.
+	feedTokenParser = paseto.MakeParser([]paseto.Rule{
+		paseto.Subject(feedTokenSubject),
+	})
+)
+
+// FeedFilter encodes a subscriber's filter preferences for Atom feeds.
+//
+// It is carried on feed URLs as a signed token so a logged-in user can
+// subscribe with their settings without exposing their session cookie
+// to the feed reader.
+type FeedFilter struct {
+	IncludeR18 bool
+}
+
+// allows reports whether a work with the given restriction level may appear in the feed.
+func (f FeedFilter) allows(xRestrict core.XRestrict) bool {
+	return xRestrict == core.Safe || f.IncludeR18
+}
+
+// GenerateFeedToken signs filter into a feed token using the instance's
+// paseto secret key.
+func GenerateFeedToken(filter FeedFilter) (string, error) {
+	if !config.PasetoValidator.HasSecretKey() {
+		return "", errFeedTokenUnavailable
+	}
+
+	token := paseto.NewToken()
+	token.SetSubject(feedTokenSubject)
+
+	if err := token.Set("includeR18", filter.IncludeR18); err != nil {
+		return "", err
+	}
+
+	return token.V4Sign(config.PasetoValidator.SecretKey, []byte(authenticated.Implicit)), nil
+}
+
+// ValidateFeedToken verifies a feed token's signature and subject
+// and returns the filter preferences it encodes.
+func ValidateFeedToken(tokenStr string) (FeedFilter, error) {
+	var filter FeedFilter
+
+	if !config.PasetoValidator.HasSecretKey() {
+		return filter, errFeedTokenUnavailable
+	}
+
+	token, err := feedTokenParser.ParseV4Public(
+		config.PasetoValidator.SecretKey.Public(), tokenStr, []byte(authenticated.Implicit))
+	if err != nil {
+		return filter, err
+	}
+
+	if err := token.Get("includeR18", &filter.IncludeR18); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// feedFilterFromRequest returns the filter encoded in the request's feed_token
+// query parameter.
+//
+// Without a token, behavior matches the session: a logged-in requester sees
+// whatever upstream returned for their session, while anonymous pollers only
+// see safe works. An invalid token falls back to the safe-only filter.
+func feedFilterFromRequest(r *http.Request) FeedFilter {
+	tokenStr := utils.GetQueryParam(r, feedTokenQueryParam)
+	if tokenStr == "" {
+		return FeedFilter{IncludeR18: untrusted.GetUserToken(r) != ""}
+	}
+
+	filter, err := ValidateFeedToken(tokenStr)
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid feed token; serving safe works only")
+
+		return FeedFilter{}
+	}
+
+	return filter
+}
+
+// FeedTokenRoute issues a signed feed token encoding the requested filter
+// preferences. Including R-18 works requires a logged-in session, since the
+// token grants the same visibility to anyone polling the feed URL.
+func FeedTokenRoute(w http.ResponseWriter, r *http.Request) error {
+	includeR18 := utils.GetQueryParam(r, "r18") == "true"
+	if includeR18 && untrusted.GetUserToken(r) == "" {
+		return errFeedTokenRequiresLogin
+	}
+
+	token, err := GenerateFeedToken(FeedFilter{IncludeR18: includeR18})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	_, err = w.Write([]byte(token))
+
+	return err
+}
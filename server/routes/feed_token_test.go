@@ -0,0 +1,151 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package routes
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core"
+	"codeberg.org/pixivfe/pixivfe/v3/core/authenticated"
+)
+
+// setupFeedTokenTest loads a fresh instance secret key and restores the
+// previous validator when the test completes.
+func setupFeedTokenTest(t *testing.T) {
+	t.Helper()
+
+	origValidator := config.PasetoValidator
+
+	t.Cleanup(func() {
+		config.PasetoValidator = origValidator
+	})
+
+	if err := config.PasetoValidator.LoadSecretKeyFromHex(authenticated.NewSecretKeyHex()); err != nil {
+		t.Fatalf("LoadSecretKeyFromHex() error: %v", err)
+	}
+}
+
+func TestFeedTokenRoundTrip(t *testing.T) {
+	setupFeedTokenTest(t)
+
+	for _, includeR18 := range []bool{true, false} {
+		token, err := GenerateFeedToken(FeedFilter{IncludeR18: includeR18})
+		if err != nil {
+			t.Fatalf("GenerateFeedToken() error: %v", err)
+		}
+
+		filter, err := ValidateFeedToken(token)
+		if err != nil {
+			t.Fatalf("ValidateFeedToken() error: %v", err)
+		}
+
+		if filter.IncludeR18 != includeR18 {
+			t.Errorf("ValidateFeedToken() IncludeR18 = %v, want %v", filter.IncludeR18, includeR18)
+		}
+	}
+}
+
+func TestValidateFeedToken_Invalid(t *testing.T) {
+	setupFeedTokenTest(t)
+
+	token, err := GenerateFeedToken(FeedFilter{IncludeR18: true})
+	if err != nil {
+		t.Fatalf("GenerateFeedToken() error: %v", err)
+	}
+
+	cases := []struct {
+		name  string
+		token string
+	}{
+		{"Garbage", "not-a-token"},
+		{"Empty", ""},
+		{"Tampered signature", token[:len(token)-4] + "AAAA"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ValidateFeedToken(tc.token); err == nil {
+				t.Errorf("ValidateFeedToken(%q) succeeded, want error", tc.token)
+			}
+		})
+	}
+
+	// A token signed with a previous instance key must not validate either.
+	if err := config.PasetoValidator.LoadSecretKeyFromHex(authenticated.NewSecretKeyHex()); err != nil {
+		t.Fatalf("LoadSecretKeyFromHex() error: %v", err)
+	}
+
+	if _, err := ValidateFeedToken(token); err == nil {
+		t.Error("ValidateFeedToken() succeeded for a token signed with a different key")
+	}
+}
+
+func TestGenerateFeedToken_NoSecretKey(t *testing.T) {
+	origValidator := config.PasetoValidator
+
+	t.Cleanup(func() {
+		config.PasetoValidator = origValidator
+	})
+
+	config.PasetoValidator = authenticated.Validator{}
+
+	if _, err := GenerateFeedToken(FeedFilter{}); err == nil {
+		t.Error("GenerateFeedToken() succeeded without a secret key, want error")
+	}
+}
+
+func TestFeedFilter_Allows(t *testing.T) {
+	cases := []struct {
+		name      string
+		filter    FeedFilter
+		xRestrict core.XRestrict
+		want      bool
+	}{
+		{"Safe work without R-18", FeedFilter{}, core.Safe, true},
+		{"R-18 work without R-18", FeedFilter{}, core.R18, false},
+		{"R-18G work without R-18", FeedFilter{}, core.R18G, false},
+		{"Safe work with R-18", FeedFilter{IncludeR18: true}, core.Safe, true},
+		{"R-18 work with R-18", FeedFilter{IncludeR18: true}, core.R18, true},
+		{"R-18G work with R-18", FeedFilter{IncludeR18: true}, core.R18G, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.allows(tc.xRestrict); got != tc.want {
+				t.Errorf("allows(%d) = %v, want %v", tc.xRestrict, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFeedFilterFromRequest(t *testing.T) {
+	setupFeedTokenTest(t)
+
+	r18Token, err := GenerateFeedToken(FeedFilter{IncludeR18: true})
+	if err != nil {
+		t.Fatalf("GenerateFeedToken() error: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{"Valid token applies encoded filter", "/users/1/atom.xml?feed_token=" + r18Token, true},
+		{"No token for anonymous poller", "/users/1/atom.xml", false},
+		{"Invalid token falls back to safe only", "/users/1/atom.xml?feed_token=garbage", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.url, nil)
+
+			if got := feedFilterFromRequest(r); got.IncludeR18 != tc.want {
+				t.Errorf("feedFilterFromRequest() IncludeR18 = %v, want %v", got.IncludeR18, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+var (
+	errMissingRedirectTarget = errors.New("no url parameter provided")
+	errInvalidRedirectTarget = errors.New("invalid redirect target")
+)
+
+// GotoPage is the handler for the /goto external-link redirector.
+//
+// Only validated http/https targets are redirected to; malformed URLs,
+// non-web schemes (e.g. javascript:), and hosts outside the operator's
+// allowlist/denylist get a warning page instead, so the route cannot be
+// abused as an open redirector.
+func GotoPage(w http.ResponseWriter, r *http.Request) error {
+	target := utils.GetQueryParam(r, "url")
+	if target == "" {
+		return errMissingRedirectTarget
+	}
+
+	if err := validateRedirectTarget(target); err != nil {
+		writeRedirectWarning(w, target, err)
+
+		return nil
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+
+	return nil
+}
+
+// validateRedirectTarget reports whether target is safe to redirect to.
+//
+// The scheme check mirrors tryMakePixivURLRelative in core: only http and
+// https URLs are eligible. Host policy comes from the ExternalLinks config.
+func validateRedirectTarget(target string) error {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalidRedirectTarget, err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q is not allowed", errInvalidRedirectTarget, parsed.Scheme)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: missing host", errInvalidRedirectTarget)
+	}
+
+	if hostListContains(config.Global.ExternalLinks.DeniedHosts, host) {
+		return fmt.Errorf("%w: host %q is denied by the instance operator", errInvalidRedirectTarget, host)
+	}
+
+	if allowed := config.Global.ExternalLinks.AllowedHosts; len(allowed) > 0 && !hostListContains(allowed, host) {
+		return fmt.Errorf("%w: host %q is not on the instance allowlist", errInvalidRedirectTarget, host)
+	}
+
+	return nil
+}
+
+// hostListContains reports whether host matches an entry of the configured
+// list, comparing case-insensitively.
+func hostListContains(list []string, host string) bool {
+	for _, entry := range list {
+		if strings.EqualFold(strings.TrimSpace(entry), host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeRedirectWarning renders a small warning page for a disallowed target
+// instead of redirecting.
+func writeRedirectWarning(w http.ResponseWriter, target string, reason error) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Link blocked</title></head>
+<body>
+<h1>Link blocked</h1>
+<p>This instance refused to redirect you to <code>%s</code>.</p>
+<p>%s.</p>
+</body>
+</html>
+`, html.EscapeString(target), html.EscapeString(reason.Error()))
+}
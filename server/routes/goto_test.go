@@ -0,0 +1,133 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+)
+
+// setupGotoTest sets host policy for the redirector and restores the
+// previous configuration when the test completes.
+func setupGotoTest(t *testing.T, allowed, denied []string) {
+	t.Helper()
+
+	origAllowed := config.Global.ExternalLinks.AllowedHosts
+	origDenied := config.Global.ExternalLinks.DeniedHosts
+
+	t.Cleanup(func() {
+		config.Global.ExternalLinks.AllowedHosts = origAllowed
+		config.Global.ExternalLinks.DeniedHosts = origDenied
+	})
+
+	config.Global.ExternalLinks.AllowedHosts = allowed
+	config.Global.ExternalLinks.DeniedHosts = denied
+}
+
+// doGoto runs GotoPage for the given target and returns the recorded response.
+func doGoto(t *testing.T, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/goto?url="+url.QueryEscape(target), nil)
+	w := httptest.NewRecorder()
+
+	if err := GotoPage(w, r); err != nil {
+		t.Fatalf("GotoPage() error: %v", err)
+	}
+
+	return w
+}
+
+func TestGotoPage_RedirectsAllowedTarget(t *testing.T) {
+	setupGotoTest(t, nil, nil)
+
+	w := doGoto(t, "https://example.com/page?x=1")
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("GotoPage() status = %d, want %d", w.Code, http.StatusFound)
+	}
+
+	if got := w.Header().Get("Location"); got != "https://example.com/page?x=1" {
+		t.Errorf("Location = %q, want the validated target", got)
+	}
+}
+
+func TestGotoPage_BlocksMaliciousScheme(t *testing.T) {
+	setupGotoTest(t, nil, nil)
+
+	for _, target := range []string{
+		"javascript:alert(1)",
+		"data:text/html,<script>alert(1)</script>",
+		"//example.com/protocol-relative",
+	} {
+		w := doGoto(t, target)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("GotoPage(%q) status = %d, want %d", target, w.Code, http.StatusForbidden)
+		}
+
+		if got := w.Header().Get("Location"); got != "" {
+			t.Errorf("GotoPage(%q) redirected to %q, want a warning page", target, got)
+		}
+	}
+}
+
+func TestGotoPage_HostPolicy(t *testing.T) {
+	cases := []struct {
+		name       string
+		allowed    []string
+		denied     []string
+		target     string
+		wantStatus int
+	}{
+		{
+			"Allowlisted host redirects",
+			[]string{"Example.COM"}, nil,
+			"https://example.com/ok", http.StatusFound,
+		},
+		{
+			"Host outside the allowlist is blocked",
+			[]string{"example.com"}, nil,
+			"https://evil.test/phish", http.StatusForbidden,
+		},
+		{
+			"Denylisted host is blocked",
+			nil, []string{"evil.test"},
+			"https://evil.test/phish", http.StatusForbidden,
+		},
+		{
+			"Other hosts pass with only a denylist",
+			nil, []string{"evil.test"},
+			"https://example.com/ok", http.StatusFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupGotoTest(t, tc.allowed, tc.denied)
+
+			w := doGoto(t, tc.target)
+			if w.Code != tc.wantStatus {
+				t.Errorf("GotoPage(%q) status = %d, want %d", tc.target, w.Code, tc.wantStatus)
+			}
+
+			if tc.wantStatus == http.StatusForbidden && !strings.Contains(w.Body.String(), "Link blocked") {
+				t.Errorf("GotoPage(%q) body = %q, want the warning page", tc.target, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGotoPage_MissingTarget(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/goto", nil)
+
+	if err := GotoPage(httptest.NewRecorder(), r); err == nil {
+		t.Error("GotoPage() error = nil, want an error for a missing url parameter")
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/core"
+	"codeberg.org/pixivfe/pixivfe/v3/core/requests"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
+	"codeberg.org/pixivfe/pixivfe/v3/i18n"
+)
+
+// upstreamProbeTTL is how long one upstream probe result is reused, so
+// frequent orchestrator polling cannot hammer pixiv.
+const upstreamProbeTTL = 30 * time.Second
+
+// upstreamProbe caches the last upstream reachability result.
+var upstreamProbe struct {
+	sync.Mutex
+
+	checkedAt time.Time
+	err       error
+}
+
+// i18nReady reports whether the translation catalogue is loaded; a hook so
+// tests can exercise the not-ready state without unloading the catalogue.
+var i18nReady = i18n.Ready
+
+// healthCheck is one readiness sub-check in the health endpoint's body.
+type healthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// healthData is the JSON body of the health endpoint.
+type healthData struct {
+	Status string               `json:"status"`
+	Checks []healthCheck        `json:"checks"`
+	Cache  requests.CacheStatus `json:"cache"`
+}
+
+// HealthPage reports service readiness: the i18n engine must be initialized
+// and at least one pixiv token must be usable. Requesting ?probe=upstream
+// additionally verifies pixiv reachability via a cheap cached fetch. Every
+// sub-check is enumerated in the JSON body, and any failure degrades the
+// response to 503 so orchestrators can act on it.
+//
+// The API response cache status rides along for operators, but never affects
+// the status code: a disabled cache is a performance concern, not an outage.
+func HealthPage(w http.ResponseWriter, r *http.Request) error {
+	checks := []healthCheck{checkI18n(), checkTokens()}
+
+	if r.URL.Query().Get("probe") == "upstream" {
+		checks = append(checks, checkUpstream(r))
+	}
+
+	status := "ok"
+
+	for _, check := range checks {
+		if !check.OK {
+			status = "degraded"
+
+			break
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	return json.NewEncoder(w).Encode(healthData{
+		Status: status,
+		Checks: checks,
+		Cache:  requests.GetCacheStatus(),
+	})
+}
+
+// checkI18n verifies the translation catalogue loaded at startup.
+func checkI18n() healthCheck {
+	check := healthCheck{Name: "i18n", OK: i18nReady()}
+	if !check.OK {
+		check.Detail = "translation catalogue not loaded"
+	}
+
+	return check
+}
+
+// checkTokens verifies at least one managed pixiv token is in a good state.
+func checkTokens() healthCheck {
+	check := healthCheck{Name: "tokens"}
+
+	if tokenmanager.DefaultTokenManager == nil {
+		check.Detail = "token manager not initialized"
+
+		return check
+	}
+
+	for _, status := range tokenmanager.DefaultTokenManager.Status() {
+		if status.Status == "good" {
+			check.OK = true
+
+			return check
+		}
+	}
+
+	check.Detail = "no token in good state"
+
+	return check
+}
+
+// checkUpstream verifies pixiv reachability with a cheap API fetch, reusing
+// the previous result within upstreamProbeTTL.
+func checkUpstream(r *http.Request) healthCheck {
+	upstreamProbe.Lock()
+	defer upstreamProbe.Unlock()
+
+	if time.Since(upstreamProbe.checkedAt) >= upstreamProbeTTL {
+		_, err := requests.GetJSONBody(
+			r.Context(),
+			core.GetTrendingTagsURL(),
+			map[string]string{"PHPSESSID": untrusted.GetUserToken(r)},
+			r.Header,
+		)
+
+		upstreamProbe.checkedAt = time.Now()
+		upstreamProbe.err = err
+	}
+
+	check := healthCheck{Name: "upstream", OK: upstreamProbe.err == nil}
+	if !check.OK {
+		check.Detail = upstreamProbe.err.Error()
+	}
+
+	return check
+}
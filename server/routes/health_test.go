@@ -0,0 +1,216 @@
+// Copyright 2023 - 2025, VnPower and the PixivFE contributors
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/pixivfe/pixivfe/v3/config"
+	"codeberg.org/pixivfe/pixivfe/v3/core/tokenmanager"
+	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
+)
+
+// healthOKTransport answers every upstream probe with an empty API body.
+type healthOKTransport struct{}
+
+func (healthOKTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"error":false,"body":{}}`)),
+		Request:    req,
+	}, nil
+}
+
+// healthFailTransport fails every upstream probe at the connection level.
+type healthFailTransport struct{}
+
+func (healthFailTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("upstream unreachable")
+}
+
+// setupHealthTest puts every health sub-check into its passing state and
+// restores the previous process state when the test completes.
+func setupHealthTest(t *testing.T) {
+	t.Helper()
+
+	origConfig := config.Global
+	origTransport := utils.HTTPClient.Transport
+	origTokenManager := tokenmanager.DefaultTokenManager
+	origI18nReady := i18nReady
+
+	t.Cleanup(func() {
+		config.Global = origConfig
+		utils.HTTPClient.Transport = origTransport
+		tokenmanager.DefaultTokenManager = origTokenManager
+		i18nReady = origI18nReady
+
+		resetUpstreamProbe()
+	})
+
+	config.Global.SetDefaults()
+
+	i18nReady = func() bool { return true }
+
+	utils.HTTPClient.Transport = healthOKTransport{}
+	tokenmanager.DefaultTokenManager, _ = tokenmanager.NewTokenManager(
+		[]string{"instance-token"}, 1, time.Second, time.Second, "round-robin")
+
+	resetUpstreamProbe()
+}
+
+// resetUpstreamProbe forgets the cached upstream probe result.
+func resetUpstreamProbe() {
+	upstreamProbe.Lock()
+	defer upstreamProbe.Unlock()
+
+	upstreamProbe.checkedAt = time.Time{}
+	upstreamProbe.err = nil
+}
+
+// doHealth runs HealthPage and decodes its JSON body.
+func doHealth(t *testing.T, target string) (*httptest.ResponseRecorder, healthData) {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	w := httptest.NewRecorder()
+
+	if err := HealthPage(w, r); err != nil {
+		t.Fatalf("HealthPage() error: %v", err)
+	}
+
+	var data healthData
+	if err := json.Unmarshal(w.Body.Bytes(), &data); err != nil {
+		t.Fatalf("decoding health body: %v", err)
+	}
+
+	return w, data
+}
+
+// findCheck returns the named sub-check, failing the test when absent.
+func findCheck(t *testing.T, data healthData, name string) healthCheck {
+	t.Helper()
+
+	for _, check := range data.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+
+	t.Fatalf("health body %+v is missing the %q check", data, name)
+
+	return healthCheck{}
+}
+
+func TestHealthPage_Healthy(t *testing.T) {
+	setupHealthTest(t)
+
+	w, data := doHealth(t, "/health?probe=upstream")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("HealthPage() status = %d, want %d (body %s)", w.Code, http.StatusOK, w.Body)
+	}
+
+	if data.Status != "ok" {
+		t.Errorf("Status = %q, want %q", data.Status, "ok")
+	}
+
+	for _, name := range []string{"i18n", "tokens", "upstream"} {
+		if check := findCheck(t, data, name); !check.OK {
+			t.Errorf("check %q failed: %+v", name, check)
+		}
+	}
+}
+
+func TestHealthPage_I18nNotReady(t *testing.T) {
+	setupHealthTest(t)
+
+	i18nReady = func() bool { return false }
+
+	w, data := doHealth(t, "/health")
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("HealthPage() status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	if check := findCheck(t, data, "i18n"); check.OK || check.Detail == "" {
+		t.Errorf("i18n check = %+v, want a failure with a detail", check)
+	}
+
+	if check := findCheck(t, data, "tokens"); !check.OK {
+		t.Errorf("tokens check = %+v, want it unaffected", check)
+	}
+}
+
+func TestHealthPage_NoUsableToken(t *testing.T) {
+	setupHealthTest(t)
+
+	tokenmanager.DefaultTokenManager = nil
+
+	w, data := doHealth(t, "/health")
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("HealthPage() status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	if check := findCheck(t, data, "tokens"); check.OK || check.Detail == "" {
+		t.Errorf("tokens check = %+v, want a failure with a detail", check)
+	}
+}
+
+func TestHealthPage_UpstreamProbe(t *testing.T) {
+	setupHealthTest(t)
+
+	utils.HTTPClient.Transport = healthFailTransport{}
+
+	w, data := doHealth(t, "/health?probe=upstream")
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("HealthPage() status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	if check := findCheck(t, data, "upstream"); check.OK || check.Detail == "" {
+		t.Errorf("upstream check = %+v, want a failure with a detail", check)
+	}
+
+	// A recovered upstream is not observed until the cached result expires,
+	// so the probe cannot be used to hammer pixiv.
+	utils.HTTPClient.Transport = healthOKTransport{}
+
+	if w, _ := doHealth(t, "/health?probe=upstream"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("HealthPage() status = %d, want the cached failure to stick", w.Code)
+	}
+
+	resetUpstreamProbe()
+
+	if w, _ := doHealth(t, "/health?probe=upstream"); w.Code != http.StatusOK {
+		t.Fatalf("HealthPage() status = %d, want %d after the probe window", w.Code, http.StatusOK)
+	}
+}
+
+func TestHealthPage_ProbeSkippedByDefault(t *testing.T) {
+	setupHealthTest(t)
+
+	// An unreachable upstream must not affect plain liveness checks.
+	utils.HTTPClient.Transport = healthFailTransport{}
+
+	w, data := doHealth(t, "/health")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("HealthPage() status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	for _, check := range data.Checks {
+		if check.Name == "upstream" {
+			t.Errorf("health body %+v ran the upstream probe without ?probe=upstream", data)
+		}
+	}
+}
@@ -102,6 +102,14 @@ func ArtworkPartial(w http.ResponseWriter, r *http.Request) error {
 }
 
 func RecentPartial(w http.ResponseWriter, r *http.Request) error {
+	// Minimal mode omits optional sections entirely, so lazily-loaded
+	// partials respond with no content instead of fetching upstream.
+	if untrusted.IsMinimalMode(r) {
+		w.WriteHeader(http.StatusNoContent)
+
+		return nil
+	}
+
 	var illust core.Illust
 
 	recentWorkIDs, err := parseWorkIDs(utils.GetQueryParam(r, "recentworkids"))
@@ -143,6 +151,12 @@ func RecentPartial(w http.ResponseWriter, r *http.Request) error {
 }
 
 func RelatedPartial(w http.ResponseWriter, r *http.Request) error {
+	if untrusted.IsMinimalMode(r) {
+		w.WriteHeader(http.StatusNoContent)
+
+		return nil
+	}
+
 	var illust core.Illust
 
 	params := relatedParams{
@@ -178,6 +192,12 @@ func RelatedPartial(w http.ResponseWriter, r *http.Request) error {
 }
 
 func CommentsPartial(w http.ResponseWriter, r *http.Request) error {
+	if untrusted.IsMinimalMode(r) {
+		w.WriteHeader(http.StatusNoContent)
+
+		return nil
+	}
+
 	var commentsData *core.CommentsData
 
 	// use most relaxed constraint by default
@@ -17,6 +17,7 @@ import (
 	"codeberg.org/pixivfe/pixivfe/v3/assets/views"
 	"codeberg.org/pixivfe/pixivfe/v3/config"
 	"codeberg.org/pixivfe/pixivfe/v3/core"
+	"codeberg.org/pixivfe/pixivfe/v3/core/cookie"
 	"codeberg.org/pixivfe/pixivfe/v3/core/untrusted"
 	"codeberg.org/pixivfe/pixivfe/v3/server/utils"
 )
@@ -65,6 +66,7 @@ func SearchPage(w http.ResponseWriter, r *http.Request) error {
 		Tool:     utils.GetQueryParam(r, "tool"),
 		Scd:      utils.GetQueryParam(r, "scd"),
 		Ecd:      utils.GetQueryParam(r, "ecd"),
+		AIType:   searchAIType(r),
 		Page:     utils.GetQueryParam(r, "page", core.SearchDefaultPage),
 	}
 
@@ -110,3 +112,19 @@ func SearchPage(w http.ResponseWriter, r *http.Request) error {
 
 	return views.SearchPage(*result).Render(r.Context(), w)
 }
+
+// searchAIType resolves the ai_type query parameter. An explicit value wins;
+// otherwise viewers whose filter profile hides AI works get the source-side
+// exclusion for free, since the client would discard those results anyway.
+func searchAIType(r *http.Request) string {
+	if aiType := utils.GetQueryParam(r, "ai_type"); aiType != "" {
+		return aiType
+	}
+
+	profile := core.ReadFilterProfile(untrusted.GetCookie(r, cookie.FilterProfileCookie))
+	if profile.AI == core.FilterHide {
+		return core.SearchAITypeExclude
+	}
+
+	return ""
+}
@@ -105,6 +105,19 @@ func setNovelViewMode(w http.ResponseWriter, r *http.Request) (string, error) {
 	return "", errors.New("Invalid view mode.")
 }
 
+//nolint:unparam
+func setNovelCollapseBlanks(w http.ResponseWriter, r *http.Request) (string, error) {
+	if r.FormValue("collapse_blanks") == "on" {
+		untrusted.SetCookie(w, r, cookie.NovelCollapseBlanksCookie, "true")
+
+		return "Consecutive blank lines in novels will now be collapsed.", nil
+	}
+
+	untrusted.SetCookie(w, r, cookie.NovelCollapseBlanksCookie, "false")
+
+	return "Novels will now render author spacing faithfully.", nil
+}
+
 //nolint:unparam
 func setThumbnailToNewTab(w http.ResponseWriter, r *http.Request) (string, error) {
 	ttnt := r.FormValue("ttnt")
@@ -288,24 +301,26 @@ func SettingsPage(w http.ResponseWriter, r *http.Request) error {
 }
 
 var actions = map[string]func(http.ResponseWriter, *http.Request) (string, error){
-	"image_server":         setImageServer,
-	"logout":               setLogout,
-	"reset_all":            resetAll,
-	"novel_font_type":      setNovelFontType,
-	"novel_view_mode":      setNovelViewMode,
-	"thumbnail_to_new_tab": setThumbnailToNewTab,
-	"visual_effects":       setVisualEffects,
-	"set_cookie":           setCookie,
-	"clear_cookie":         clearCookie,
-	"raw":                  setRawCookie,
-	"token":                core.SetToken,
-	"language":             core.SetLanguage,
-	"location":             core.SetLocation,
-	"reading_status":       core.SetReadingStatus,
-	"content_filters":      core.HandleContentFilters,
-	"default_search_mode":  core.HandleDefaultSearchMode,
-	"blacklisted_tags":     core.HandleBlacklistedTags,
-	"blacklisted_artists":  core.HandleBlacklistedArtists,
+	"image_server":          setImageServer,
+	"logout":                setLogout,
+	"reset_all":             resetAll,
+	"novel_font_type":       setNovelFontType,
+	"novel_view_mode":       setNovelViewMode,
+	"novel_collapse_blanks": setNovelCollapseBlanks,
+	"thumbnail_to_new_tab":  setThumbnailToNewTab,
+	"visual_effects":        setVisualEffects,
+	"set_cookie":            setCookie,
+	"clear_cookie":          clearCookie,
+	"raw":                   setRawCookie,
+	"token":                 core.SetToken,
+	"token_direct":          core.SetTokenDirect,
+	"language":              core.SetLanguage,
+	"location":              core.SetLocation,
+	"reading_status":        core.SetReadingStatus,
+	"content_filters":       core.HandleContentFilters,
+	"default_search_mode":   core.HandleDefaultSearchMode,
+	"blacklisted_tags":      core.HandleBlacklistedTags,
+	"blacklisted_artists":   core.HandleBlacklistedArtists,
 }
 
 func SettingsPOST(w http.ResponseWriter, r *http.Request) error {
@@ -37,6 +37,7 @@ func fetchUserData(r *http.Request) (core.UserData, error) {
 	id := utils.GetPathVar(r, "id")
 	category := utils.GetQueryParam(r, "category", "")
 	mode := utils.GetQueryParam(r, "mode", "show")
+	bookmarkTag := utils.GetQueryParam(r, "bookmark_tag", "")
 	currentPageStr := utils.GetQueryParam(r, "page", "1")
 
 	currentPage, err := strconv.Atoi(currentPageStr)
@@ -44,5 +45,5 @@ func fetchUserData(r *http.Request) (core.UserData, error) {
 		currentPage = 1
 	}
 
-	return core.GetUserProfile(r, id, category, mode, currentPage)
+	return core.GetUserProfile(r, id, category, mode, bookmarkTag, currentPage)
 }
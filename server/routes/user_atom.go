@@ -100,6 +100,7 @@ type atomFeedBuilder struct {
 	userURL     string
 	baseAtomURL string
 	category    string
+	filter      FeedFilter
 }
 
 // UserAtomFeed is the route handler for user atom feeds.
@@ -117,7 +118,7 @@ func UserAtomFeed(w http.ResponseWriter, r *http.Request) error {
 			int(config.Global.HTTPCache.StaleWhileRevalidate.Seconds())))
 	}
 
-	feed, err := newAtomFeedBuilder(request_context.FromRequest(r).CommonData, data).build()
+	feed, err := newAtomFeedBuilder(request_context.FromRequest(r).CommonData, data, feedFilterFromRequest(r)).build()
 	if err != nil {
 		return fmt.Errorf("failed to build user atom feed: %w", err)
 	}
@@ -133,7 +134,7 @@ func UserAtomFeed(w http.ResponseWriter, r *http.Request) error {
 }
 
 // newAtomFeedBuilder creates and initializes a new builder.
-func newAtomFeedBuilder(cd commondata.PageCommonData, data core.UserData) *atomFeedBuilder {
+func newAtomFeedBuilder(cd commondata.PageCommonData, data core.UserData, filter FeedFilter) *atomFeedBuilder {
 	userURL := fmt.Sprintf("%s/users/%s", cd.BaseURL, data.User.ID)
 
 	return &atomFeedBuilder{
@@ -142,6 +143,7 @@ func newAtomFeedBuilder(cd commondata.PageCommonData, data core.UserData) *atomF
 		userURL:     userURL,
 		baseAtomURL: userURL + "/atom.xml",
 		category:    cd.Queries["category"],
+		filter:      filter,
 	}
 }
 
@@ -298,6 +300,10 @@ func (b *atomFeedBuilder) buildArtworkEntries(artworks []core.ArtworkItem) ([]at
 	entries := make([]atomEntry, 0, len(artworks))
 
 	for _, artwork := range artworks {
+		if !b.filter.allows(artwork.XRestrict) {
+			continue
+		}
+
 		contentHTML, err := buildAtomContentHTML(artwork.Thumbnail, artwork.Title)
 		if err != nil {
 			return nil, fmt.Errorf("artwork %s: %w", artwork.ID, err)
@@ -344,6 +350,10 @@ func (b *atomFeedBuilder) buildNovelEntries(novels []*core.NovelBrief) ([]atomEn
 	entries := make([]atomEntry, 0, len(novels))
 
 	for _, novel := range novels {
+		if !b.filter.allows(novel.XRestrict) {
+			continue
+		}
+
 		contentHTML, err := buildAtomContentHTML(novel.CoverURL, novel.Title)
 		if err != nil {
 			return nil, fmt.Errorf("novel %s: %w", novel.ID, err)
@@ -60,6 +60,11 @@ type PageCommonData struct {
 	// IsFastRequest is true if request has a Fast-Request header set to "true".
 	IsFastRequest bool
 
+	// IsMinimalRequest is true if the request opted into the stripped-down,
+	// low-bandwidth page variant via the 'minimal' query parameter or the
+	// 'MinimalMode' cookie. Templates skip optional sections when set.
+	IsMinimalRequest bool
+
 	// HX-Trigger header value, if present
 	HXTrigger string
 
@@ -112,6 +117,7 @@ func PopulatePageCommonData(r *http.Request, data *PageCommonData, generateLinkT
 
 	data.IsHtmxRequest = r.Header.Get("HX-Request") == "true"
 	data.IsFastRequest = r.Header.Get("Fast-Request") == "true"
+	data.IsMinimalRequest = untrusted.IsMinimalMode(r)
 	data.HXTrigger = r.Header.Get("HX-Trigger")
 
 	if config.Global.Limiter.Enabled && generateLinkToken != nil {